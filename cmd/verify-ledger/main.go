@@ -0,0 +1,46 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"gitlab.com/ignitionrobotics/billing/credits/internal/conf"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/api"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/application"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/domain/persistence"
+	"log"
+	"os"
+)
+
+// main walks the hash chain of the ledger of an application and prints the result as JSON. It exits with a non-zero
+// status code if the chain is broken.
+func main() {
+	logger := log.New(os.Stderr, "[Verify ledger] ", log.LstdFlags|log.Lshortfile|log.Lmsgprefix)
+
+	app := flag.String("application", "", "application whose ledger is verified")
+	flag.Parse()
+
+	var db conf.Database
+	if err := db.Parse(); err != nil {
+		logger.Fatalln("Failed to parse database config:", err)
+	}
+
+	conn, err := persistence.OpenConn(db)
+	if err != nil {
+		logger.Fatalln("Failed to open database connection:", err)
+	}
+
+	s := application.NewCreditsService(conn, logger, 1)
+	res, err := s.VerifyLedger(context.Background(), api.VerifyLedgerRequest{Application: *app})
+	if err != nil {
+		logger.Fatalln("Failed to verify ledger:", err)
+	}
+
+	if err = json.NewEncoder(os.Stdout).Encode(res); err != nil {
+		logger.Fatalln("Failed to print result:", err)
+	}
+
+	if !res.Valid {
+		os.Exit(1)
+	}
+}
@@ -1,8 +1,10 @@
 package conf
 
 import (
+	"errors"
 	"fmt"
 	"github.com/caarlos0/env/v6"
+	"time"
 )
 
 // Database contains the config for initializing an SQL database.
@@ -44,13 +46,71 @@ type Config struct {
 	// Database contains the configuration needed to open an SQL connection.
 	Database Database
 
-	// ConversionRate represents how many USD cents are needed to get 1 credit.
+	// ConversionRate represents how many USD cents are needed to get 1 credit. It must be greater than zero.
 	ConversionRate uint `env:"CREDITS_CONVERSION_RATE,required"`
 
 	// Port defines the TCP port used to listen for incoming HTTP requests.
 	Port uint `env:"CREDITS_HTTP_SERVER_PORT" envDefault:"80"`
+
+	// MeteringInterval is the time between each charge of the open usage sessions.
+	MeteringInterval time.Duration `env:"CREDITS_METERING_INTERVAL" envDefault:"1m"`
+
+	// SessionHeartbeatTimeout is the maximum time a usage session can stay open without receiving heartbeats.
+	SessionHeartbeatTimeout time.Duration `env:"CREDITS_SESSION_HEARTBEAT_TIMEOUT" envDefault:"5m"`
+
+	// NotificationTimeout is the timeout used when sending notifications to external services.
+	NotificationTimeout time.Duration `env:"CREDITS_NOTIFICATION_TIMEOUT" envDefault:"10s"`
+
+	// WebhookInterval is the time between each run of the webhook dispatcher.
+	WebhookInterval time.Duration `env:"CREDITS_WEBHOOK_INTERVAL" envDefault:"5s"`
+
+	// WebhookMaxAttempts is the maximum amount of times a webhook delivery is attempted before marking it as failed.
+	WebhookMaxAttempts int `env:"CREDITS_WEBHOOK_MAX_ATTEMPTS" envDefault:"8"`
+
+	// WebhookBackoff is the time to wait after the first failed attempt of a webhook delivery. It doubles on every
+	// failed attempt.
+	WebhookBackoff time.Duration `env:"CREDITS_WEBHOOK_BACKOFF" envDefault:"30s"`
+
+	// OutboxInterval is the time between each run of the outbox relay.
+	OutboxInterval time.Duration `env:"CREDITS_OUTBOX_INTERVAL" envDefault:"5s"`
+
+	// OutboxSink is where the domain events are published. It's either "stdout" or the path of a file the events
+	// are appended to.
+	OutboxSink string `env:"CREDITS_OUTBOX_SINK" envDefault:"stdout"`
+
+	// LowBalanceInterval is the time between each run of the low balance alerter.
+	LowBalanceInterval time.Duration `env:"CREDITS_LOW_BALANCE_INTERVAL" envDefault:"10s"`
+
+	// LowBalanceNotificationURL is the URL that receives low balance alerts. Alerts are only logged if empty.
+	LowBalanceNotificationURL string `env:"CREDITS_LOW_BALANCE_NOTIFICATION_URL"`
+
+	// SubscriptionInterval is the time between each run of the subscription scheduler.
+	SubscriptionInterval time.Duration `env:"CREDITS_SUBSCRIPTION_INTERVAL" envDefault:"1m"`
+
+	// ScheduledOperationInterval is the time between each run of the operation scheduler.
+	ScheduledOperationInterval time.Duration `env:"CREDITS_SCHEDULED_OPERATION_INTERVAL" envDefault:"10s"`
+
+	// TrialExpirationInterval is the time between each run of the trial expirer.
+	TrialExpirationInterval time.Duration `env:"CREDITS_TRIAL_EXPIRATION_INTERVAL" envDefault:"1m"`
+
+	// AutoTopUpInterval is the time between each run of the automatic top-up worker.
+	AutoTopUpInterval time.Duration `env:"CREDITS_AUTO_TOP_UP_INTERVAL" envDefault:"1m"`
+
+	// PaymentProviderURL is the URL used to charge customers for automatic top-ups. Automatic top-ups are disabled
+	// if empty.
+	PaymentProviderURL string `env:"CREDITS_PAYMENT_PROVIDER_URL"`
+
+	// PaymentWebhookSecret is the secret used by the payment provider to sign its events. Payment webhooks are
+	// disabled if empty.
+	PaymentWebhookSecret string `env:"CREDITS_PAYMENT_WEBHOOK_SECRET"`
+
+	// PaymentWebhookTolerance is the maximum age of the payment provider events signatures.
+	PaymentWebhookTolerance time.Duration `env:"CREDITS_PAYMENT_WEBHOOK_TOLERANCE" envDefault:"5m"`
 }
 
+// ErrInvalidConversionRate is returned when parsing a configuration with a zero conversion rate.
+var ErrInvalidConversionRate = errors.New("conversion rate must be greater than zero")
+
 // Parse fills Config data from an external source.
 func (c *Config) Parse() error {
 	if err := c.Database.Parse(); err != nil {
@@ -59,5 +119,8 @@ func (c *Config) Parse() error {
 	if err := env.Parse(c); err != nil {
 		return err
 	}
+	if c.ConversionRate == 0 {
+		return ErrInvalidConversionRate
+	}
 	return nil
 }
@@ -0,0 +1,54 @@
+package server
+
+import (
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/api"
+	"net/http"
+)
+
+// ListAccounts is an HTTP handler to call the api.AccountingV1's ListAccounts method.
+func (s *Server) ListAccounts(w http.ResponseWriter, r *http.Request) {
+	var in api.ListAccountsRequest
+	if err := s.readBodyJSON(w, r, &in); err != nil {
+		return
+	}
+
+	out, err := s.credits.ListAccounts(r.Context(), in)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	s.writeResponse(w, &out)
+}
+
+// ListEntries is an HTTP handler to call the api.AccountingV1's ListEntries method.
+func (s *Server) ListEntries(w http.ResponseWriter, r *http.Request) {
+	var in api.ListEntriesRequest
+	if err := s.readBodyJSON(w, r, &in); err != nil {
+		return
+	}
+
+	out, err := s.credits.ListEntries(r.Context(), in)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	s.writeResponse(w, &out)
+}
+
+// CheckLedger is an HTTP handler to call the api.AccountingV1's CheckLedger method.
+func (s *Server) CheckLedger(w http.ResponseWriter, r *http.Request) {
+	var in api.CheckLedgerRequest
+	if err := s.readBodyJSON(w, r, &in); err != nil {
+		return
+	}
+
+	out, err := s.credits.CheckLedger(r.Context(), in)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	s.writeResponse(w, &out)
+}
@@ -0,0 +1,38 @@
+package server
+
+import (
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/api"
+	"net/http"
+)
+
+// SetConversionPolicy is an HTTP handler to call the api.ConversionV1's SetConversionPolicy method.
+func (s *Server) SetConversionPolicy(w http.ResponseWriter, r *http.Request) {
+	var in api.SetConversionPolicyRequest
+	if err := s.readBodyJSON(w, r, &in); err != nil {
+		return
+	}
+
+	out, err := s.credits.SetConversionPolicy(r.Context(), in)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	s.writeResponse(w, &out)
+}
+
+// GetConversionPolicy is an HTTP handler to call the api.ConversionV1's GetConversionPolicy method.
+func (s *Server) GetConversionPolicy(w http.ResponseWriter, r *http.Request) {
+	var in api.GetConversionPolicyRequest
+	if err := s.readBodyJSON(w, r, &in); err != nil {
+		return
+	}
+
+	out, err := s.credits.GetConversionPolicy(r.Context(), in)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	s.writeResponse(w, &out)
+}
@@ -0,0 +1,70 @@
+package server
+
+import (
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/api"
+	"net/http"
+)
+
+// CreateCoupon is an HTTP handler to call the api.CouponsV1's CreateCoupon method.
+func (s *Server) CreateCoupon(w http.ResponseWriter, r *http.Request) {
+	var in api.CreateCouponRequest
+	if err := s.readBodyJSON(w, r, &in); err != nil {
+		return
+	}
+
+	out, err := s.credits.CreateCoupon(r.Context(), in)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	s.writeResponse(w, &out)
+}
+
+// ListCoupons is an HTTP handler to call the api.CouponsV1's ListCoupons method.
+func (s *Server) ListCoupons(w http.ResponseWriter, r *http.Request) {
+	var in api.ListCouponsRequest
+	if err := s.readBodyJSON(w, r, &in); err != nil {
+		return
+	}
+
+	out, err := s.credits.ListCoupons(r.Context(), in)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	s.writeResponse(w, &out)
+}
+
+// RedeemCoupon is an HTTP handler to call the api.CouponsV1's RedeemCoupon method.
+func (s *Server) RedeemCoupon(w http.ResponseWriter, r *http.Request) {
+	var in api.RedeemCouponRequest
+	if err := s.readBodyJSON(w, r, &in); err != nil {
+		return
+	}
+
+	out, err := s.credits.RedeemCoupon(r.Context(), in)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	s.writeResponse(w, &out)
+}
+
+// GetCouponStats is an HTTP handler to call the api.CouponsV1's GetCouponStats method.
+func (s *Server) GetCouponStats(w http.ResponseWriter, r *http.Request) {
+	var in api.GetCouponStatsRequest
+	if err := s.readBodyJSON(w, r, &in); err != nil {
+		return
+	}
+
+	out, err := s.credits.GetCouponStats(r.Context(), in)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	s.writeResponse(w, &out)
+}
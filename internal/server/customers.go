@@ -0,0 +1,54 @@
+package server
+
+import (
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/api"
+	"net/http"
+)
+
+// ListCustomers is an HTTP handler to call the api.CustomersV1's ListCustomers method.
+func (s *Server) ListCustomers(w http.ResponseWriter, r *http.Request) {
+	var in api.ListCustomersRequest
+	if err := s.readBodyJSON(w, r, &in); err != nil {
+		return
+	}
+
+	out, err := s.credits.ListCustomers(r.Context(), in)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	s.writeResponse(w, &out)
+}
+
+// SetCustomerStatus is an HTTP handler to call the api.CustomersV1's SetCustomerStatus method.
+func (s *Server) SetCustomerStatus(w http.ResponseWriter, r *http.Request) {
+	var in api.SetCustomerStatusRequest
+	if err := s.readBodyJSON(w, r, &in); err != nil {
+		return
+	}
+
+	out, err := s.credits.SetCustomerStatus(r.Context(), in)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	s.writeResponse(w, &out)
+}
+
+// ListCustomerStatusChanges is an HTTP handler to call the api.CustomersV1's ListCustomerStatusChanges method.
+func (s *Server) ListCustomerStatusChanges(w http.ResponseWriter, r *http.Request) {
+	var in api.ListCustomerStatusChangesRequest
+	if err := s.readBodyJSON(w, r, &in); err != nil {
+		return
+	}
+
+	out, err := s.credits.ListCustomerStatusChanges(r.Context(), in)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	s.writeResponse(w, &out)
+}
@@ -24,6 +24,54 @@ func (s *Server) GetBalance(w http.ResponseWriter, r *http.Request) {
 	s.writeResponse(w, &out)
 }
 
+// GetBalances is an HTTP handler to call the api.CreditsV1's GetBalances method.
+func (s *Server) GetBalances(w http.ResponseWriter, r *http.Request) {
+	var in api.GetBalancesRequest
+	if err := s.readBodyJSON(w, r, &in); err != nil {
+		return
+	}
+
+	out, err := s.credits.GetBalances(r.Context(), in)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	s.writeResponse(w, &out)
+}
+
+// GetBalanceAt is an HTTP handler to call the api.CreditsV1's GetBalanceAt method.
+func (s *Server) GetBalanceAt(w http.ResponseWriter, r *http.Request) {
+	var in api.GetBalanceAtRequest
+	if err := s.readBodyJSON(w, r, &in); err != nil {
+		return
+	}
+
+	out, err := s.credits.GetBalanceAt(r.Context(), in)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	s.writeResponse(w, &out)
+}
+
+// GetBalanceHistory is an HTTP handler to call the api.CreditsV1's GetBalanceHistory method.
+func (s *Server) GetBalanceHistory(w http.ResponseWriter, r *http.Request) {
+	var in api.GetBalanceHistoryRequest
+	if err := s.readBodyJSON(w, r, &in); err != nil {
+		return
+	}
+
+	out, err := s.credits.GetBalanceHistory(r.Context(), in)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	s.writeResponse(w, &out)
+}
+
 // IncreaseCredits is an HTTP handler to call the api.CreditsV1's IncreaseCredits method.
 func (s *Server) IncreaseCredits(w http.ResponseWriter, r *http.Request) {
 	var in api.IncreaseCreditsRequest
@@ -88,6 +136,70 @@ func (s *Server) GetUnitPrice(w http.ResponseWriter, r *http.Request) {
 	s.writeResponse(w, &out)
 }
 
+// Charge is an HTTP handler to call the api.CreditsV1's Charge method.
+func (s *Server) Charge(w http.ResponseWriter, r *http.Request) {
+	var in api.ChargeRequest
+	if err := s.readBodyJSON(w, r, &in); err != nil {
+		return
+	}
+
+	out, err := s.credits.Charge(r.Context(), in)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	s.writeResponse(w, &out)
+}
+
+// ExecuteBatch is an HTTP handler to call the api.CreditsV1's ExecuteBatch method.
+func (s *Server) ExecuteBatch(w http.ResponseWriter, r *http.Request) {
+	var in api.ExecuteBatchRequest
+	if err := s.readBodyJSON(w, r, &in); err != nil {
+		return
+	}
+
+	out, err := s.credits.ExecuteBatch(r.Context(), in)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	s.writeResponse(w, &out)
+}
+
+// EstimateCost is an HTTP handler to call the api.CreditsV1's EstimateCost method.
+func (s *Server) EstimateCost(w http.ResponseWriter, r *http.Request) {
+	var in api.EstimateCostRequest
+	if err := s.readBodyJSON(w, r, &in); err != nil {
+		return
+	}
+
+	out, err := s.credits.EstimateCost(r.Context(), in)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	s.writeResponse(w, &out)
+}
+
+// ReverseTransaction is an HTTP handler to call the api.CreditsV1's ReverseTransaction method.
+func (s *Server) ReverseTransaction(w http.ResponseWriter, r *http.Request) {
+	var in api.ReverseTransactionRequest
+	if err := s.readBodyJSON(w, r, &in); err != nil {
+		return
+	}
+
+	out, err := s.credits.ReverseTransaction(r.Context(), in)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	s.writeResponse(w, &out)
+}
+
 func (s *Server) writeResponse(w http.ResponseWriter, out interface{}) {
 	body, err := json.Marshal(out)
 	if err != nil {
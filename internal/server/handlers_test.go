@@ -114,6 +114,31 @@ func (s *handlersTestSuite) TestGetBalanceOK() {
 	s.Assert().Equal(100, out.Credits)
 }
 
+func (s *handlersTestSuite) TestGetBalancesOK() {
+	s.Handler = s.Server.GetBalances
+
+	in := api.GetBalancesRequest{
+		Handles:     []string{"test3", "test2", "test4"},
+		Application: "cloudsim",
+	}
+	request := s.setupRequest(in, http.MethodPost)
+
+	s.Handler.ServeHTTP(s.ResponseRecorder, request)
+
+	s.Require().Equal(http.StatusOK, s.ResponseRecorder.Code)
+
+	var out api.GetBalancesResponse
+	s.parseResponseJSON(&out)
+
+	s.Require().Len(out.Balances, 3)
+	s.Assert().Equal("test3", out.Balances[0].Handle)
+	s.Assert().Equal(0, out.Balances[0].Credits)
+	s.Assert().Equal("test2", out.Balances[1].Handle)
+	s.Assert().Equal(-100, out.Balances[1].Credits)
+	s.Assert().Equal("test4", out.Balances[2].Handle)
+	s.Assert().Equal(0, out.Balances[2].Credits)
+}
+
 func (s *handlersTestSuite) TestIncreaseCreditsOK() {
 	s.Handler = s.Server.IncreaseCredits
 
@@ -211,6 +236,28 @@ func (s *handlersTestSuite) TestGetUnitPriceOK() {
 	s.Assert().Equal(uint(2), out.Amount)
 }
 
+func (s *handlersTestSuite) TestListCustomersOK() {
+	s.Handler = s.Server.ListCustomers
+
+	in := api.ListCustomersRequest{
+		Application: "cloudsim",
+		SortBy:      api.SortByBalance,
+		Limit:       1,
+	}
+	request := s.setupRequest(in, http.MethodGet)
+
+	s.Handler.ServeHTTP(s.ResponseRecorder, request)
+
+	s.Require().Equal(http.StatusOK, s.ResponseRecorder.Code)
+
+	var out api.ListCustomersResponse
+	s.parseResponseJSON(&out)
+
+	s.Require().Len(out.Customers, 1)
+	s.Assert().Equal("test2", out.Customers[0].Handle)
+	s.Assert().NotEmpty(out.NextCursor)
+}
+
 func (s *handlersTestSuite) setupRequest(in interface{}, method string) *http.Request {
 	body, err := json.Marshal(in)
 	s.Require().NoError(err)
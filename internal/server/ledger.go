@@ -0,0 +1,22 @@
+package server
+
+import (
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/api"
+	"net/http"
+)
+
+// VerifyLedger is an HTTP handler to call the api.LedgerV1's VerifyLedger method.
+func (s *Server) VerifyLedger(w http.ResponseWriter, r *http.Request) {
+	var in api.VerifyLedgerRequest
+	if err := s.readBodyJSON(w, r, &in); err != nil {
+		return
+	}
+
+	out, err := s.credits.VerifyLedger(r.Context(), in)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	s.writeResponse(w, &out)
+}
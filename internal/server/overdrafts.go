@@ -0,0 +1,54 @@
+package server
+
+import (
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/api"
+	"net/http"
+)
+
+// SetOverdraftLimit is an HTTP handler to call the api.OverdraftsV1's SetOverdraftLimit method.
+func (s *Server) SetOverdraftLimit(w http.ResponseWriter, r *http.Request) {
+	var in api.SetOverdraftLimitRequest
+	if err := s.readBodyJSON(w, r, &in); err != nil {
+		return
+	}
+
+	out, err := s.credits.SetOverdraftLimit(r.Context(), in)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	s.writeResponse(w, &out)
+}
+
+// GetOverdraftLimit is an HTTP handler to call the api.OverdraftsV1's GetOverdraftLimit method.
+func (s *Server) GetOverdraftLimit(w http.ResponseWriter, r *http.Request) {
+	var in api.GetOverdraftLimitRequest
+	if err := s.readBodyJSON(w, r, &in); err != nil {
+		return
+	}
+
+	out, err := s.credits.GetOverdraftLimit(r.Context(), in)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	s.writeResponse(w, &out)
+}
+
+// DeleteOverdraftLimit is an HTTP handler to call the api.OverdraftsV1's DeleteOverdraftLimit method.
+func (s *Server) DeleteOverdraftLimit(w http.ResponseWriter, r *http.Request) {
+	var in api.DeleteOverdraftLimitRequest
+	if err := s.readBodyJSON(w, r, &in); err != nil {
+		return
+	}
+
+	out, err := s.credits.DeleteOverdraftLimit(r.Context(), in)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	s.writeResponse(w, &out)
+}
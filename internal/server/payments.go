@@ -0,0 +1,44 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/api"
+	"io"
+	"net/http"
+)
+
+// ProcessPaymentEvent is an HTTP handler to call the api.PaymentsV1's ProcessPaymentEvent method. The body must be
+// signed by the payment provider with the configured payment webhook secret.
+func (s *Server) ProcessPaymentEvent(w http.ResponseWriter, r *http.Request) {
+	if len(s.paymentWebhookSecret) == 0 {
+		http.Error(w, fmt.Sprintf("%s - %s", http.StatusText(http.StatusNotFound), "Payment webhooks are disabled"), http.StatusNotFound)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("%s - %s", http.StatusText(http.StatusBadRequest), "Failed to read body"), http.StatusBadRequest)
+		return
+	}
+
+	err = api.VerifySignature(s.paymentWebhookSecret, r.Header.Get(api.PaymentSignatureHeader), body, s.paymentWebhookTolerance)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var in api.ProcessPaymentEventRequest
+	if err = json.Unmarshal(body, &in); err != nil {
+		http.Error(w, fmt.Sprintf("%s - %s", http.StatusText(http.StatusBadRequest), "Failed to read JSON body"), http.StatusBadRequest)
+		return
+	}
+
+	out, err := s.credits.ProcessPaymentEvent(r.Context(), in)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	s.writeResponse(w, &out)
+}
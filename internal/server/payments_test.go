@@ -0,0 +1,54 @@
+package server
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"gitlab.com/ignitionrobotics/billing/credits/internal/conf"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/api"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func newPaymentsTestServer(secret string) *Server {
+	return NewServer(Options{
+		config: conf.Config{
+			PaymentWebhookSecret:    secret,
+			PaymentWebhookTolerance: time.Minute,
+		},
+		logger: log.New(io.Discard, "", log.LstdFlags),
+	})
+}
+
+func TestProcessPaymentEventDisabled(t *testing.T) {
+	s := newPaymentsTestServer("")
+
+	body := []byte(`{"id":"evt_1"}`)
+	req := httptest.NewRequest(http.MethodPost, "/payments/webhook", bytes.NewBuffer(body))
+	req.Header.Set(api.PaymentSignatureHeader, api.SignPayload("", time.Now(), body))
+	rr := httptest.NewRecorder()
+	s.router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
+
+func TestProcessPaymentEventInvalidSignature(t *testing.T) {
+	s := newPaymentsTestServer("whsec")
+
+	body := []byte(`{"id":"evt_1"}`)
+	req := httptest.NewRequest(http.MethodPost, "/payments/webhook", bytes.NewBuffer(body))
+	req.Header.Set(api.PaymentSignatureHeader, api.SignPayload("other", time.Now(), body))
+	rr := httptest.NewRecorder()
+	s.router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	req = httptest.NewRequest(http.MethodPost, "/payments/webhook", bytes.NewBuffer(body))
+	rr = httptest.NewRecorder()
+	s.router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
@@ -0,0 +1,102 @@
+package server
+
+import (
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/api"
+	"net/http"
+)
+
+// CreateSKU is an HTTP handler to call the api.PricingV1's CreateSKU method.
+func (s *Server) CreateSKU(w http.ResponseWriter, r *http.Request) {
+	var in api.CreateSKURequest
+	if err := s.readBodyJSON(w, r, &in); err != nil {
+		return
+	}
+
+	out, err := s.credits.CreateSKU(r.Context(), in)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	s.writeResponse(w, &out)
+}
+
+// GetSKU is an HTTP handler to call the api.PricingV1's GetSKU method.
+func (s *Server) GetSKU(w http.ResponseWriter, r *http.Request) {
+	var in api.GetSKURequest
+	if err := s.readBodyJSON(w, r, &in); err != nil {
+		return
+	}
+
+	out, err := s.credits.GetSKU(r.Context(), in)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	s.writeResponse(w, &out)
+}
+
+// ListSKUs is an HTTP handler to call the api.PricingV1's ListSKUs method.
+func (s *Server) ListSKUs(w http.ResponseWriter, r *http.Request) {
+	var in api.ListSKUsRequest
+	if err := s.readBodyJSON(w, r, &in); err != nil {
+		return
+	}
+
+	out, err := s.credits.ListSKUs(r.Context(), in)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	s.writeResponse(w, &out)
+}
+
+// UpdateSKU is an HTTP handler to call the api.PricingV1's UpdateSKU method.
+func (s *Server) UpdateSKU(w http.ResponseWriter, r *http.Request) {
+	var in api.UpdateSKURequest
+	if err := s.readBodyJSON(w, r, &in); err != nil {
+		return
+	}
+
+	out, err := s.credits.UpdateSKU(r.Context(), in)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	s.writeResponse(w, &out)
+}
+
+// DeleteSKU is an HTTP handler to call the api.PricingV1's DeleteSKU method.
+func (s *Server) DeleteSKU(w http.ResponseWriter, r *http.Request) {
+	var in api.DeleteSKURequest
+	if err := s.readBodyJSON(w, r, &in); err != nil {
+		return
+	}
+
+	out, err := s.credits.DeleteSKU(r.Context(), in)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	s.writeResponse(w, &out)
+}
+
+// SetSKUPrice is an HTTP handler to call the api.PricingV1's SetSKUPrice method.
+func (s *Server) SetSKUPrice(w http.ResponseWriter, r *http.Request) {
+	var in api.SetSKUPriceRequest
+	if err := s.readBodyJSON(w, r, &in); err != nil {
+		return
+	}
+
+	out, err := s.credits.SetSKUPrice(r.Context(), in)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	s.writeResponse(w, &out)
+}
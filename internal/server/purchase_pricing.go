@@ -0,0 +1,86 @@
+package server
+
+import (
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/api"
+	"net/http"
+)
+
+// SetVolumeTiers is an HTTP handler to call the api.PurchasePricingV1's SetVolumeTiers method.
+func (s *Server) SetVolumeTiers(w http.ResponseWriter, r *http.Request) {
+	var in api.SetVolumeTiersRequest
+	if err := s.readBodyJSON(w, r, &in); err != nil {
+		return
+	}
+
+	out, err := s.credits.SetVolumeTiers(r.Context(), in)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	s.writeResponse(w, &out)
+}
+
+// ListVolumeTiers is an HTTP handler to call the api.PurchasePricingV1's ListVolumeTiers method.
+func (s *Server) ListVolumeTiers(w http.ResponseWriter, r *http.Request) {
+	var in api.ListVolumeTiersRequest
+	if err := s.readBodyJSON(w, r, &in); err != nil {
+		return
+	}
+
+	out, err := s.credits.ListVolumeTiers(r.Context(), in)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	s.writeResponse(w, &out)
+}
+
+// CreatePackage is an HTTP handler to call the api.PurchasePricingV1's CreatePackage method.
+func (s *Server) CreatePackage(w http.ResponseWriter, r *http.Request) {
+	var in api.CreatePackageRequest
+	if err := s.readBodyJSON(w, r, &in); err != nil {
+		return
+	}
+
+	out, err := s.credits.CreatePackage(r.Context(), in)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	s.writeResponse(w, &out)
+}
+
+// DeletePackage is an HTTP handler to call the api.PurchasePricingV1's DeletePackage method.
+func (s *Server) DeletePackage(w http.ResponseWriter, r *http.Request) {
+	var in api.DeletePackageRequest
+	if err := s.readBodyJSON(w, r, &in); err != nil {
+		return
+	}
+
+	out, err := s.credits.DeletePackage(r.Context(), in)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	s.writeResponse(w, &out)
+}
+
+// ListPackages is an HTTP handler to call the api.PurchasePricingV1's ListPackages method.
+func (s *Server) ListPackages(w http.ResponseWriter, r *http.Request) {
+	var in api.ListPackagesRequest
+	if err := s.readBodyJSON(w, r, &in); err != nil {
+		return
+	}
+
+	out, err := s.credits.ListPackages(r.Context(), in)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	s.writeResponse(w, &out)
+}
@@ -0,0 +1,54 @@
+package server
+
+import (
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/api"
+	"net/http"
+)
+
+// ScheduleOperation is an HTTP handler to call the api.ScheduledOperationsV1's ScheduleOperation method.
+func (s *Server) ScheduleOperation(w http.ResponseWriter, r *http.Request) {
+	var in api.ScheduleOperationRequest
+	if err := s.readBodyJSON(w, r, &in); err != nil {
+		return
+	}
+
+	out, err := s.credits.ScheduleOperation(r.Context(), in)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	s.writeResponse(w, &out)
+}
+
+// CancelScheduledOperation is an HTTP handler to call the api.ScheduledOperationsV1's CancelScheduledOperation method.
+func (s *Server) CancelScheduledOperation(w http.ResponseWriter, r *http.Request) {
+	var in api.CancelScheduledOperationRequest
+	if err := s.readBodyJSON(w, r, &in); err != nil {
+		return
+	}
+
+	out, err := s.credits.CancelScheduledOperation(r.Context(), in)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	s.writeResponse(w, &out)
+}
+
+// ListScheduledOperations is an HTTP handler to call the api.ScheduledOperationsV1's ListScheduledOperations method.
+func (s *Server) ListScheduledOperations(w http.ResponseWriter, r *http.Request) {
+	var in api.ListScheduledOperationsRequest
+	if err := s.readBodyJSON(w, r, &in); err != nil {
+		return
+	}
+
+	out, err := s.credits.ListScheduledOperations(r.Context(), in)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	s.writeResponse(w, &out)
+}
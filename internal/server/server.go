@@ -11,6 +11,7 @@ import (
 	"gitlab.com/ignitionrobotics/billing/credits/pkg/domain/persistence"
 	"log"
 	"net/http"
+	"time"
 )
 
 // Setup initializes the conf.Config to run the web server.
@@ -43,6 +44,55 @@ func Run(config conf.Config, logger *log.Logger) error {
 	logger.Println("Initializing Credits service")
 	cs := application.NewCreditsService(db, logger, config.ConversionRate)
 
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	logger.Println("Starting session metering")
+	meter := application.NewSessionMeter(db, logger, application.NewHTTPSessionNotifier(config.NotificationTimeout), config.SessionHeartbeatTimeout)
+	go meter.Run(ctx, config.MeteringInterval)
+
+	logger.Println("Starting webhook dispatcher")
+	dispatcher := application.NewWebhookDispatcher(db, logger, config.NotificationTimeout, config.WebhookMaxAttempts, config.WebhookBackoff)
+	go dispatcher.Run(ctx, config.WebhookInterval)
+
+	logger.Println("Starting outbox relay")
+	sink, err := newEventSink(config.OutboxSink)
+	if err != nil {
+		logger.Println("Failed to initialize event sink:", err)
+		return err
+	}
+	relay := application.NewOutboxRelay(db, logger, sink)
+	go relay.Run(ctx, config.OutboxInterval)
+
+	logger.Println("Starting low balance alerts")
+	notifier := application.NewLogLowBalanceNotifier(logger)
+	if len(config.LowBalanceNotificationURL) > 0 {
+		notifier = application.NewHTTPLowBalanceNotifier(config.LowBalanceNotificationURL, config.NotificationTimeout)
+	}
+	alerter := application.NewLowBalanceAlerter(db, logger, notifier)
+	go alerter.Run(ctx, config.LowBalanceInterval)
+
+	logger.Println("Starting subscription scheduler")
+	scheduler := application.NewSubscriptionScheduler(db, logger)
+	go scheduler.Run(ctx, config.SubscriptionInterval)
+
+	logger.Println("Starting operation scheduler")
+	operations := application.NewOperationScheduler(db, logger, config.ConversionRate)
+	go operations.Run(ctx, config.ScheduledOperationInterval)
+
+	logger.Println("Starting trial expiration")
+	expirer := application.NewTrialExpirer(db, logger)
+	go expirer.Run(ctx, config.TrialExpirationInterval)
+
+	if len(config.PaymentProviderURL) > 0 {
+		logger.Println("Starting automatic top-ups")
+		provider := application.NewHTTPPaymentProvider(config.PaymentProviderURL, config.NotificationTimeout)
+		topUps := application.NewAutoTopUpWorker(db, logger, provider, config.ConversionRate)
+		go topUps.Run(ctx, config.AutoTopUpInterval)
+	} else {
+		logger.Println("Automatic top-ups disabled: no payment provider URL configured")
+	}
+
 	logger.Println("Initializing HTTP server")
 	s := NewServer(Options{
 		config:  config,
@@ -57,6 +107,14 @@ func Run(config conf.Config, logger *log.Logger) error {
 	return nil
 }
 
+// newEventSink initializes the application.EventSink defined by the given conf.Config OutboxSink value.
+func newEventSink(sink string) (application.EventSink, error) {
+	if sink == "stdout" {
+		return application.NewStdoutEventSink(), nil
+	}
+	return application.NewFileEventSink(sink)
+}
+
 // Options contains a set of components to be used when initializing a web server.
 type Options struct {
 	// config is the config used to set the web server and its components up
@@ -86,6 +144,12 @@ type Server struct {
 
 	// httpServer is used to serve the router with fine-grained control of ListenAndServe and Shutdown operations.
 	httpServer http.Server
+
+	// paymentWebhookSecret is the secret used to verify the signature of the payment provider events.
+	paymentWebhookSecret string
+
+	// paymentWebhookTolerance is the maximum age of the payment provider events signatures.
+	paymentWebhookTolerance time.Duration
 }
 
 // NewServer initializes a new web server that will serve api.CreditsV1 methods.
@@ -94,6 +158,9 @@ func NewServer(opts Options) *Server {
 		credits: opts.credits,
 		logger:  opts.logger,
 		port:    opts.config.Port,
+
+		paymentWebhookSecret:    opts.config.PaymentWebhookSecret,
+		paymentWebhookTolerance: opts.config.PaymentWebhookTolerance,
 	}
 
 	s.router = chi.NewRouter()
@@ -106,12 +173,134 @@ func NewServer(opts Options) *Server {
 
 	s.router.Route("/credits", func(r chi.Router) {
 		r.Get("/", s.GetBalance)
+		r.Post("/balances", s.GetBalances)
+		r.Get("/at", s.GetBalanceAt)
+		r.Get("/history", s.GetBalanceHistory)
 		r.Post("/increase", s.IncreaseCredits)
 		r.Post("/decrease", s.DecreaseCredits)
 		r.Post("/convert", s.ConvertCurrency)
 		r.Post("/unit_price", s.GetUnitPrice)
+		r.Post("/charge", s.Charge)
+		r.Post("/estimate", s.EstimateCost)
+		r.Post("/batch", s.ExecuteBatch)
+		r.Post("/reverse", s.ReverseTransaction)
+	})
+
+	s.router.Route("/sessions", func(r chi.Router) {
+		r.Get("/", s.GetSession)
+		r.Post("/open", s.OpenSession)
+		r.Post("/heartbeat", s.SendHeartbeat)
+		r.Post("/close", s.CloseSession)
+	})
+
+	s.router.Route("/skus", func(r chi.Router) {
+		r.Get("/", s.ListSKUs)
+		r.Get("/detail", s.GetSKU)
+		r.Post("/create", s.CreateSKU)
+		r.Post("/update", s.UpdateSKU)
+		r.Post("/delete", s.DeleteSKU)
+		r.Post("/price", s.SetSKUPrice)
+	})
+
+	s.router.Route("/customers", func(r chi.Router) {
+		r.Get("/", s.ListCustomers)
+		r.Get("/status", s.ListCustomerStatusChanges)
+		r.Post("/status", s.SetCustomerStatus)
+	})
+
+	s.router.Route("/webhooks", func(r chi.Router) {
+		r.Get("/", s.ListWebhooks)
+		r.Post("/create", s.CreateWebhook)
+		r.Post("/delete", s.DeleteWebhook)
+		r.Get("/deliveries", s.ListWebhookDeliveries)
+		r.Post("/deliveries/replay", s.ReplayWebhookDelivery)
+	})
+
+	s.router.Route("/thresholds", func(r chi.Router) {
+		r.Get("/", s.GetLowBalanceThreshold)
+		r.Post("/set", s.SetLowBalanceThreshold)
+		r.Post("/delete", s.DeleteLowBalanceThreshold)
+	})
+
+	s.router.Route("/top_ups", func(r chi.Router) {
+		r.Get("/", s.ListTopUps)
+		r.Get("/auto", s.GetAutoTopUp)
+		r.Post("/auto/set", s.SetAutoTopUp)
+		r.Post("/auto/disable", s.DisableAutoTopUp)
+	})
+
+	s.router.Route("/overdrafts", func(r chi.Router) {
+		r.Get("/", s.GetOverdraftLimit)
+		r.Post("/set", s.SetOverdraftLimit)
+		r.Post("/delete", s.DeleteOverdraftLimit)
 	})
 
+	s.router.Route("/spending_limits", func(r chi.Router) {
+		r.Get("/", s.GetSpendingLimits)
+		r.Post("/set", s.SetSpendingLimits)
+		r.Post("/delete", s.DeleteSpendingLimits)
+		r.Get("/usage", s.GetSpendingUsage)
+	})
+
+	s.router.Route("/wallets", func(r chi.Router) {
+		r.Get("/members", s.ListWalletMembers)
+		r.Post("/members/set", s.SetWalletMember)
+		r.Post("/members/remove", s.RemoveWalletMember)
+		r.Post("/spend", s.SpendFromWallet)
+		r.Get("/spending", s.ListWalletSpending)
+	})
+
+	s.router.Route("/subscriptions", func(r chi.Router) {
+		r.Get("/", s.ListSubscriptions)
+		r.Post("/create", s.CreateSubscription)
+		r.Post("/cancel", s.CancelSubscription)
+		r.Get("/grants", s.ListSubscriptionGrants)
+	})
+
+	s.router.Route("/scheduled_operations", func(r chi.Router) {
+		r.Get("/", s.ListScheduledOperations)
+		r.Post("/create", s.ScheduleOperation)
+		r.Post("/cancel", s.CancelScheduledOperation)
+	})
+
+	s.router.Route("/coupons", func(r chi.Router) {
+		r.Get("/", s.ListCoupons)
+		r.Post("/create", s.CreateCoupon)
+		r.Post("/redeem", s.RedeemCoupon)
+		r.Get("/stats", s.GetCouponStats)
+	})
+
+	s.router.Route("/trials", func(r chi.Router) {
+		r.Get("/policy", s.GetTrialPolicy)
+		r.Post("/policy/set", s.SetTrialPolicy)
+		r.Post("/policy/delete", s.DeleteTrialPolicy)
+		r.Get("/grant", s.GetTrialGrant)
+		r.Post("/handle_change", s.RecordHandleChange)
+	})
+
+	s.router.Route("/purchase_pricing", func(r chi.Router) {
+		r.Get("/tiers", s.ListVolumeTiers)
+		r.Post("/tiers/set", s.SetVolumeTiers)
+		r.Get("/packages", s.ListPackages)
+		r.Post("/packages/create", s.CreatePackage)
+		r.Post("/packages/delete", s.DeletePackage)
+	})
+
+	s.router.Route("/conversion", func(r chi.Router) {
+		r.Get("/policy", s.GetConversionPolicy)
+		r.Post("/policy/set", s.SetConversionPolicy)
+	})
+
+	s.router.Post("/payments/webhook", s.ProcessPaymentEvent)
+
+	s.router.Route("/accounts", func(r chi.Router) {
+		r.Get("/", s.ListAccounts)
+		r.Get("/entries", s.ListEntries)
+		r.Get("/check", s.CheckLedger)
+	})
+
+	s.router.Get("/ledger/verify", s.VerifyLedger)
+
 	s.httpServer = http.Server{
 		Addr:    s.getAddress(),
 		Handler: s.router,
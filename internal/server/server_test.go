@@ -1,10 +1,13 @@
 package server
 
 import (
+	"errors"
 	"github.com/stretchr/testify/suite"
+	"gitlab.com/ignitionrobotics/billing/credits/internal/conf"
 	"log"
 	"os"
 	"testing"
+	"time"
 )
 
 type setupTestSuite struct {
@@ -77,6 +80,16 @@ func (s *setupTestSuite) TestDefaultValues() {
 
 	s.Assert().Equal(uint(80), cfg.Port)
 	s.Assert().Equal("utf8", cfg.Database.Charset)
+	s.Assert().Equal(time.Minute, cfg.MeteringInterval)
+	s.Assert().Equal(5*time.Minute, cfg.SessionHeartbeatTimeout)
+	s.Assert().Equal(8, cfg.WebhookMaxAttempts)
+	s.Assert().Equal(30*time.Second, cfg.WebhookBackoff)
+	s.Assert().Equal("stdout", cfg.OutboxSink)
+	s.Assert().Equal(time.Minute, cfg.SubscriptionInterval)
+	s.Assert().Equal(10*time.Second, cfg.ScheduledOperationInterval)
+	s.Assert().Equal(time.Minute, cfg.TrialExpirationInterval)
+	s.Assert().Equal(time.Minute, cfg.AutoTopUpInterval)
+	s.Assert().Empty(cfg.PaymentProviderURL)
 }
 
 func (s *setupTestSuite) TestMissingEnvVars() {
@@ -84,6 +97,18 @@ func (s *setupTestSuite) TestMissingEnvVars() {
 	s.Assert().Error(err)
 }
 
+func (s *setupTestSuite) TestZeroConversionRate() {
+	s.Require().NoError(os.Setenv("CREDITS_CONVERSION_RATE", "0"))
+	s.Require().NoError(os.Setenv("CREDITS_DATABASE_NAME", "db"))
+	s.Require().NoError(os.Setenv("CREDITS_DATABASE_USERNAME", "root"))
+	s.Require().NoError(os.Setenv("CREDITS_DATABASE_PASSWORD", "1234"))
+	s.Require().NoError(os.Setenv("CREDITS_DATABASE_HOST", "localhost"))
+	s.Require().NoError(os.Setenv("CREDITS_DATABASE_PORT", "3306"))
+
+	_, err := Setup(s.Logger)
+	s.Assert().True(errors.Is(err, conf.ErrInvalidConversionRate))
+}
+
 func (s *setupTestSuite) TestSetupWithErrors() {
 	s.Require().NoError(os.Setenv("PAYMENTS_HTTP_SERVER_PORT", "ABCD"))
 
@@ -0,0 +1,70 @@
+package server
+
+import (
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/api"
+	"net/http"
+)
+
+// OpenSession is an HTTP handler to call the api.SessionsV1's OpenSession method.
+func (s *Server) OpenSession(w http.ResponseWriter, r *http.Request) {
+	var in api.OpenSessionRequest
+	if err := s.readBodyJSON(w, r, &in); err != nil {
+		return
+	}
+
+	out, err := s.credits.OpenSession(r.Context(), in)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	s.writeResponse(w, &out)
+}
+
+// SendHeartbeat is an HTTP handler to call the api.SessionsV1's SendHeartbeat method.
+func (s *Server) SendHeartbeat(w http.ResponseWriter, r *http.Request) {
+	var in api.SendHeartbeatRequest
+	if err := s.readBodyJSON(w, r, &in); err != nil {
+		return
+	}
+
+	out, err := s.credits.SendHeartbeat(r.Context(), in)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	s.writeResponse(w, &out)
+}
+
+// CloseSession is an HTTP handler to call the api.SessionsV1's CloseSession method.
+func (s *Server) CloseSession(w http.ResponseWriter, r *http.Request) {
+	var in api.CloseSessionRequest
+	if err := s.readBodyJSON(w, r, &in); err != nil {
+		return
+	}
+
+	out, err := s.credits.CloseSession(r.Context(), in)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	s.writeResponse(w, &out)
+}
+
+// GetSession is an HTTP handler to call the api.SessionsV1's GetSession method.
+func (s *Server) GetSession(w http.ResponseWriter, r *http.Request) {
+	var in api.GetSessionRequest
+	if err := s.readBodyJSON(w, r, &in); err != nil {
+		return
+	}
+
+	out, err := s.credits.GetSession(r.Context(), in)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	s.writeResponse(w, &out)
+}
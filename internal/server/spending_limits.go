@@ -0,0 +1,70 @@
+package server
+
+import (
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/api"
+	"net/http"
+)
+
+// SetSpendingLimits is an HTTP handler to call the api.SpendingLimitsV1's SetSpendingLimits method.
+func (s *Server) SetSpendingLimits(w http.ResponseWriter, r *http.Request) {
+	var in api.SetSpendingLimitsRequest
+	if err := s.readBodyJSON(w, r, &in); err != nil {
+		return
+	}
+
+	out, err := s.credits.SetSpendingLimits(r.Context(), in)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	s.writeResponse(w, &out)
+}
+
+// GetSpendingLimits is an HTTP handler to call the api.SpendingLimitsV1's GetSpendingLimits method.
+func (s *Server) GetSpendingLimits(w http.ResponseWriter, r *http.Request) {
+	var in api.GetSpendingLimitsRequest
+	if err := s.readBodyJSON(w, r, &in); err != nil {
+		return
+	}
+
+	out, err := s.credits.GetSpendingLimits(r.Context(), in)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	s.writeResponse(w, &out)
+}
+
+// DeleteSpendingLimits is an HTTP handler to call the api.SpendingLimitsV1's DeleteSpendingLimits method.
+func (s *Server) DeleteSpendingLimits(w http.ResponseWriter, r *http.Request) {
+	var in api.DeleteSpendingLimitsRequest
+	if err := s.readBodyJSON(w, r, &in); err != nil {
+		return
+	}
+
+	out, err := s.credits.DeleteSpendingLimits(r.Context(), in)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	s.writeResponse(w, &out)
+}
+
+// GetSpendingUsage is an HTTP handler to call the api.SpendingLimitsV1's GetSpendingUsage method.
+func (s *Server) GetSpendingUsage(w http.ResponseWriter, r *http.Request) {
+	var in api.GetSpendingUsageRequest
+	if err := s.readBodyJSON(w, r, &in); err != nil {
+		return
+	}
+
+	out, err := s.credits.GetSpendingUsage(r.Context(), in)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	s.writeResponse(w, &out)
+}
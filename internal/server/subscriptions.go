@@ -0,0 +1,70 @@
+package server
+
+import (
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/api"
+	"net/http"
+)
+
+// CreateSubscription is an HTTP handler to call the api.SubscriptionsV1's CreateSubscription method.
+func (s *Server) CreateSubscription(w http.ResponseWriter, r *http.Request) {
+	var in api.CreateSubscriptionRequest
+	if err := s.readBodyJSON(w, r, &in); err != nil {
+		return
+	}
+
+	out, err := s.credits.CreateSubscription(r.Context(), in)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	s.writeResponse(w, &out)
+}
+
+// CancelSubscription is an HTTP handler to call the api.SubscriptionsV1's CancelSubscription method.
+func (s *Server) CancelSubscription(w http.ResponseWriter, r *http.Request) {
+	var in api.CancelSubscriptionRequest
+	if err := s.readBodyJSON(w, r, &in); err != nil {
+		return
+	}
+
+	out, err := s.credits.CancelSubscription(r.Context(), in)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	s.writeResponse(w, &out)
+}
+
+// ListSubscriptions is an HTTP handler to call the api.SubscriptionsV1's ListSubscriptions method.
+func (s *Server) ListSubscriptions(w http.ResponseWriter, r *http.Request) {
+	var in api.ListSubscriptionsRequest
+	if err := s.readBodyJSON(w, r, &in); err != nil {
+		return
+	}
+
+	out, err := s.credits.ListSubscriptions(r.Context(), in)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	s.writeResponse(w, &out)
+}
+
+// ListSubscriptionGrants is an HTTP handler to call the api.SubscriptionsV1's ListSubscriptionGrants method.
+func (s *Server) ListSubscriptionGrants(w http.ResponseWriter, r *http.Request) {
+	var in api.ListSubscriptionGrantsRequest
+	if err := s.readBodyJSON(w, r, &in); err != nil {
+		return
+	}
+
+	out, err := s.credits.ListSubscriptionGrants(r.Context(), in)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	s.writeResponse(w, &out)
+}
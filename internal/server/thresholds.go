@@ -0,0 +1,54 @@
+package server
+
+import (
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/api"
+	"net/http"
+)
+
+// SetLowBalanceThreshold is an HTTP handler to call the api.ThresholdsV1's SetLowBalanceThreshold method.
+func (s *Server) SetLowBalanceThreshold(w http.ResponseWriter, r *http.Request) {
+	var in api.SetLowBalanceThresholdRequest
+	if err := s.readBodyJSON(w, r, &in); err != nil {
+		return
+	}
+
+	out, err := s.credits.SetLowBalanceThreshold(r.Context(), in)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	s.writeResponse(w, &out)
+}
+
+// GetLowBalanceThreshold is an HTTP handler to call the api.ThresholdsV1's GetLowBalanceThreshold method.
+func (s *Server) GetLowBalanceThreshold(w http.ResponseWriter, r *http.Request) {
+	var in api.GetLowBalanceThresholdRequest
+	if err := s.readBodyJSON(w, r, &in); err != nil {
+		return
+	}
+
+	out, err := s.credits.GetLowBalanceThreshold(r.Context(), in)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	s.writeResponse(w, &out)
+}
+
+// DeleteLowBalanceThreshold is an HTTP handler to call the api.ThresholdsV1's DeleteLowBalanceThreshold method.
+func (s *Server) DeleteLowBalanceThreshold(w http.ResponseWriter, r *http.Request) {
+	var in api.DeleteLowBalanceThresholdRequest
+	if err := s.readBodyJSON(w, r, &in); err != nil {
+		return
+	}
+
+	out, err := s.credits.DeleteLowBalanceThreshold(r.Context(), in)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	s.writeResponse(w, &out)
+}
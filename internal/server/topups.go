@@ -0,0 +1,70 @@
+package server
+
+import (
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/api"
+	"net/http"
+)
+
+// SetAutoTopUp is an HTTP handler to call the api.TopUpsV1's SetAutoTopUp method.
+func (s *Server) SetAutoTopUp(w http.ResponseWriter, r *http.Request) {
+	var in api.SetAutoTopUpRequest
+	if err := s.readBodyJSON(w, r, &in); err != nil {
+		return
+	}
+
+	out, err := s.credits.SetAutoTopUp(r.Context(), in)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	s.writeResponse(w, &out)
+}
+
+// GetAutoTopUp is an HTTP handler to call the api.TopUpsV1's GetAutoTopUp method.
+func (s *Server) GetAutoTopUp(w http.ResponseWriter, r *http.Request) {
+	var in api.GetAutoTopUpRequest
+	if err := s.readBodyJSON(w, r, &in); err != nil {
+		return
+	}
+
+	out, err := s.credits.GetAutoTopUp(r.Context(), in)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	s.writeResponse(w, &out)
+}
+
+// DisableAutoTopUp is an HTTP handler to call the api.TopUpsV1's DisableAutoTopUp method.
+func (s *Server) DisableAutoTopUp(w http.ResponseWriter, r *http.Request) {
+	var in api.DisableAutoTopUpRequest
+	if err := s.readBodyJSON(w, r, &in); err != nil {
+		return
+	}
+
+	out, err := s.credits.DisableAutoTopUp(r.Context(), in)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	s.writeResponse(w, &out)
+}
+
+// ListTopUps is an HTTP handler to call the api.TopUpsV1's ListTopUps method.
+func (s *Server) ListTopUps(w http.ResponseWriter, r *http.Request) {
+	var in api.ListTopUpsRequest
+	if err := s.readBodyJSON(w, r, &in); err != nil {
+		return
+	}
+
+	out, err := s.credits.ListTopUps(r.Context(), in)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	s.writeResponse(w, &out)
+}
@@ -0,0 +1,86 @@
+package server
+
+import (
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/api"
+	"net/http"
+)
+
+// SetTrialPolicy is an HTTP handler to call the api.TrialsV1's SetTrialPolicy method.
+func (s *Server) SetTrialPolicy(w http.ResponseWriter, r *http.Request) {
+	var in api.SetTrialPolicyRequest
+	if err := s.readBodyJSON(w, r, &in); err != nil {
+		return
+	}
+
+	out, err := s.credits.SetTrialPolicy(r.Context(), in)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	s.writeResponse(w, &out)
+}
+
+// GetTrialPolicy is an HTTP handler to call the api.TrialsV1's GetTrialPolicy method.
+func (s *Server) GetTrialPolicy(w http.ResponseWriter, r *http.Request) {
+	var in api.GetTrialPolicyRequest
+	if err := s.readBodyJSON(w, r, &in); err != nil {
+		return
+	}
+
+	out, err := s.credits.GetTrialPolicy(r.Context(), in)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	s.writeResponse(w, &out)
+}
+
+// DeleteTrialPolicy is an HTTP handler to call the api.TrialsV1's DeleteTrialPolicy method.
+func (s *Server) DeleteTrialPolicy(w http.ResponseWriter, r *http.Request) {
+	var in api.DeleteTrialPolicyRequest
+	if err := s.readBodyJSON(w, r, &in); err != nil {
+		return
+	}
+
+	out, err := s.credits.DeleteTrialPolicy(r.Context(), in)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	s.writeResponse(w, &out)
+}
+
+// GetTrialGrant is an HTTP handler to call the api.TrialsV1's GetTrialGrant method.
+func (s *Server) GetTrialGrant(w http.ResponseWriter, r *http.Request) {
+	var in api.GetTrialGrantRequest
+	if err := s.readBodyJSON(w, r, &in); err != nil {
+		return
+	}
+
+	out, err := s.credits.GetTrialGrant(r.Context(), in)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	s.writeResponse(w, &out)
+}
+
+// RecordHandleChange is an HTTP handler to call the api.TrialsV1's RecordHandleChange method.
+func (s *Server) RecordHandleChange(w http.ResponseWriter, r *http.Request) {
+	var in api.RecordHandleChangeRequest
+	if err := s.readBodyJSON(w, r, &in); err != nil {
+		return
+	}
+
+	out, err := s.credits.RecordHandleChange(r.Context(), in)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	s.writeResponse(w, &out)
+}
@@ -0,0 +1,86 @@
+package server
+
+import (
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/api"
+	"net/http"
+)
+
+// SetWalletMember is an HTTP handler to call the api.WalletsV1's SetWalletMember method.
+func (s *Server) SetWalletMember(w http.ResponseWriter, r *http.Request) {
+	var in api.SetWalletMemberRequest
+	if err := s.readBodyJSON(w, r, &in); err != nil {
+		return
+	}
+
+	out, err := s.credits.SetWalletMember(r.Context(), in)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	s.writeResponse(w, &out)
+}
+
+// RemoveWalletMember is an HTTP handler to call the api.WalletsV1's RemoveWalletMember method.
+func (s *Server) RemoveWalletMember(w http.ResponseWriter, r *http.Request) {
+	var in api.RemoveWalletMemberRequest
+	if err := s.readBodyJSON(w, r, &in); err != nil {
+		return
+	}
+
+	out, err := s.credits.RemoveWalletMember(r.Context(), in)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	s.writeResponse(w, &out)
+}
+
+// ListWalletMembers is an HTTP handler to call the api.WalletsV1's ListWalletMembers method.
+func (s *Server) ListWalletMembers(w http.ResponseWriter, r *http.Request) {
+	var in api.ListWalletMembersRequest
+	if err := s.readBodyJSON(w, r, &in); err != nil {
+		return
+	}
+
+	out, err := s.credits.ListWalletMembers(r.Context(), in)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	s.writeResponse(w, &out)
+}
+
+// SpendFromWallet is an HTTP handler to call the api.WalletsV1's SpendFromWallet method.
+func (s *Server) SpendFromWallet(w http.ResponseWriter, r *http.Request) {
+	var in api.SpendFromWalletRequest
+	if err := s.readBodyJSON(w, r, &in); err != nil {
+		return
+	}
+
+	out, err := s.credits.SpendFromWallet(r.Context(), in)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	s.writeResponse(w, &out)
+}
+
+// ListWalletSpending is an HTTP handler to call the api.WalletsV1's ListWalletSpending method.
+func (s *Server) ListWalletSpending(w http.ResponseWriter, r *http.Request) {
+	var in api.ListWalletSpendingRequest
+	if err := s.readBodyJSON(w, r, &in); err != nil {
+		return
+	}
+
+	out, err := s.credits.ListWalletSpending(r.Context(), in)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	s.writeResponse(w, &out)
+}
@@ -0,0 +1,86 @@
+package server
+
+import (
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/api"
+	"net/http"
+)
+
+// CreateWebhook is an HTTP handler to call the api.WebhooksV1's CreateWebhook method.
+func (s *Server) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	var in api.CreateWebhookRequest
+	if err := s.readBodyJSON(w, r, &in); err != nil {
+		return
+	}
+
+	out, err := s.credits.CreateWebhook(r.Context(), in)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	s.writeResponse(w, &out)
+}
+
+// ListWebhooks is an HTTP handler to call the api.WebhooksV1's ListWebhooks method.
+func (s *Server) ListWebhooks(w http.ResponseWriter, r *http.Request) {
+	var in api.ListWebhooksRequest
+	if err := s.readBodyJSON(w, r, &in); err != nil {
+		return
+	}
+
+	out, err := s.credits.ListWebhooks(r.Context(), in)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	s.writeResponse(w, &out)
+}
+
+// DeleteWebhook is an HTTP handler to call the api.WebhooksV1's DeleteWebhook method.
+func (s *Server) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	var in api.DeleteWebhookRequest
+	if err := s.readBodyJSON(w, r, &in); err != nil {
+		return
+	}
+
+	out, err := s.credits.DeleteWebhook(r.Context(), in)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	s.writeResponse(w, &out)
+}
+
+// ListWebhookDeliveries is an HTTP handler to call the api.WebhooksV1's ListWebhookDeliveries method.
+func (s *Server) ListWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	var in api.ListWebhookDeliveriesRequest
+	if err := s.readBodyJSON(w, r, &in); err != nil {
+		return
+	}
+
+	out, err := s.credits.ListWebhookDeliveries(r.Context(), in)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	s.writeResponse(w, &out)
+}
+
+// ReplayWebhookDelivery is an HTTP handler to call the api.WebhooksV1's ReplayWebhookDelivery method.
+func (s *Server) ReplayWebhookDelivery(w http.ResponseWriter, r *http.Request) {
+	var in api.ReplayWebhookDeliveryRequest
+	if err := s.readBodyJSON(w, r, &in); err != nil {
+		return
+	}
+
+	out, err := s.credits.ReplayWebhookDelivery(r.Context(), in)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	s.writeResponse(w, &out)
+}
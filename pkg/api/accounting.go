@@ -0,0 +1,159 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// AccountingV1 holds the methods that allow auditing the double-entry ledger of each application.
+type AccountingV1 interface {
+	// ListAccounts returns the accounts of a certain application.
+	ListAccounts(ctx context.Context, req ListAccountsRequest) (ListAccountsResponse, error)
+
+	// ListEntries returns the latest entries posted in a certain account.
+	ListEntries(ctx context.Context, req ListEntriesRequest) (ListEntriesResponse, error)
+
+	// CheckLedger verifies that the entries of an application add up to zero, and that the balance of every
+	// account matches its entries.
+	CheckLedger(ctx context.Context, req CheckLedgerRequest) (CheckLedgerResponse, error)
+}
+
+var (
+	// ErrAccountNotFound is returned when an account could not be found.
+	ErrAccountNotFound = errors.New("account not found")
+	// ErrInvalidAccountType is returned when an unknown account type is passed in the request.
+	ErrInvalidAccountType = errors.New("invalid account type")
+)
+
+// AccountType is the type of an account of the ledger.
+type AccountType string
+
+const (
+	// AccountCustomer is the account holding the credits of a single customer.
+	AccountCustomer AccountType = "customer"
+	// AccountIssuance is the account credits are issued from when customers get or buy credits.
+	AccountIssuance AccountType = "issuance"
+	// AccountRevenue is the account receiving the credits spent by customers.
+	AccountRevenue AccountType = "revenue"
+	// AccountPromotions is the account promotional credits are granted from.
+	AccountPromotions AccountType = "promotions"
+	// AccountExpired is the account receiving the credits that expired.
+	AccountExpired AccountType = "expired"
+	// AccountTransfers is the account used to move credits between customers.
+	AccountTransfers AccountType = "transfers"
+)
+
+// Validate validates the current account type is valid.
+func (t AccountType) Validate() error {
+	switch t {
+	case AccountCustomer, AccountIssuance, AccountRevenue, AccountPromotions, AccountExpired, AccountTransfers:
+		return nil
+	default:
+		return ErrInvalidAccountType
+	}
+}
+
+// Account is an account of the double-entry ledger of an application.
+type Account struct {
+	// ID is the unique identifier of the account.
+	ID uint `json:"id"`
+
+	// Application is the application the account belongs to.
+	Application string `json:"application"`
+
+	// Type is the account type.
+	Type AccountType `json:"type"`
+
+	// Handle is the customer that owns the account. It's empty for system accounts.
+	Handle string `json:"handle,omitempty"`
+
+	// Balance is the sum of the entries of the account.
+	Balance int `json:"balance"`
+}
+
+// Entry is a single posting in an account. Positive amounts are debits that increase the account balance, negative
+// amounts are credits that decrease it. The entries of every transaction add up to zero.
+type Entry struct {
+	// ID is the unique identifier of the entry.
+	ID uint `json:"id"`
+
+	// TransactionID is the transaction that posted the entry. It's zero for opening entries.
+	TransactionID uint `json:"transaction_id,omitempty"`
+
+	// AccountID is the account the entry is posted in.
+	AccountID uint `json:"account_id"`
+
+	// Amount is the amount of credits posted.
+	Amount int `json:"amount"`
+
+	// CreatedAt is the time the entry was posted.
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ListAccountsRequest is the input for the AccountingV1.ListAccounts method.
+type ListAccountsRequest struct {
+	// Application is the application the accounts belong to.
+	Application string `json:"application"`
+
+	// Type filters accounts by type. All accounts are returned if empty.
+	Type AccountType `json:"type,omitempty"`
+}
+
+// Validate validates the current request is valid.
+func (r ListAccountsRequest) Validate() error {
+	if len(r.Application) == 0 {
+		return ErrMissingApplication
+	}
+	if len(r.Type) > 0 {
+		return r.Type.Validate()
+	}
+	return nil
+}
+
+// ListAccountsResponse is the output of the AccountingV1.ListAccounts method.
+type ListAccountsResponse struct {
+	// Accounts contains the accounts of the application.
+	Accounts []Account `json:"accounts"`
+}
+
+// ListEntriesRequest is the input for the AccountingV1.ListEntries method.
+type ListEntriesRequest struct {
+	// AccountID is the account identifier.
+	AccountID uint `json:"account_id"`
+
+	// Limit is the maximum amount of entries returned, newest first. Defaults to DefaultPageSize.
+	Limit int `json:"limit,omitempty"`
+}
+
+// ListEntriesResponse is the output of the AccountingV1.ListEntries method.
+type ListEntriesResponse struct {
+	// Entries contains the entries of the account, newest first.
+	Entries []Entry `json:"entries"`
+}
+
+// CheckLedgerRequest is the input for the AccountingV1.CheckLedger method.
+type CheckLedgerRequest struct {
+	// Application is the application whose ledger is checked.
+	Application string `json:"application"`
+}
+
+// AccountMismatch is an account whose balance doesn't match the sum of its entries.
+type AccountMismatch struct {
+	Account
+
+	// EntriesSum is the sum of the entries of the account.
+	EntriesSum int `json:"entries_sum"`
+}
+
+// CheckLedgerResponse is the output of the AccountingV1.CheckLedger method.
+type CheckLedgerResponse struct {
+	// Balanced is true when the entries add up to zero and every account balance matches its entries.
+	Balanced bool `json:"balanced"`
+
+	// Sum is the sum of all the entries of the application. It must be zero.
+	Sum int `json:"sum"`
+
+	// Mismatches contains the accounts whose balance doesn't match their entries.
+	Mismatches []AccountMismatch `json:"mismatches,omitempty"`
+}
@@ -16,11 +16,33 @@ type CreditsV1 interface {
 	// GetBalance returns the current amount of credits of a given user.
 	GetBalance(ctx context.Context, req GetBalanceRequest) (GetBalanceResponse, error)
 
+	// GetBalances returns the current amount of credits of a list of users.
+	GetBalances(ctx context.Context, req GetBalancesRequest) (GetBalancesResponse, error)
+
+	// GetBalanceAt returns the amount of credits a given user had at a certain point in time.
+	GetBalanceAt(ctx context.Context, req GetBalanceAtRequest) (GetBalanceAtResponse, error)
+
+	// GetBalanceHistory returns the balance of a given user over a date range grouped by a certain interval.
+	GetBalanceHistory(ctx context.Context, req GetBalanceHistoryRequest) (GetBalanceHistoryResponse, error)
+
 	// ConvertCurrency converts a certain amount of FIAT currency in USD to credits.
 	ConvertCurrency(ctx context.Context, req ConvertCurrencyRequest) (ConvertCurrencyResponse, error)
 
 	// GetUnitPrice returns the amount of currency needed to buy 1 credit.
 	GetUnitPrice(ctx context.Context, req GetUnitPriceRequest) (GetUnitPriceResponse, error)
+
+	// Charge decreases the credits of a given user by the cost of a certain quantity of a SKU from the pricing catalog.
+	Charge(ctx context.Context, req ChargeRequest) (ChargeResponse, error)
+
+	// ExecuteBatch applies a list of increase, decrease and transfer operations atomically.
+	ExecuteBatch(ctx context.Context, req ExecuteBatchRequest) (ExecuteBatchResponse, error)
+
+	// EstimateCost returns the cost of a certain quantity of a SKU and whether a given user can afford it.
+	// It doesn't modify the user's credits.
+	EstimateCost(ctx context.Context, req EstimateCostRequest) (EstimateCostResponse, error)
+
+	// ReverseTransaction applies the opposite credit delta of a previous transaction, totally or partially.
+	ReverseTransaction(ctx context.Context, req ReverseTransactionRequest) (ReverseTransactionResponse, error)
 }
 
 var (
@@ -32,6 +54,8 @@ var (
 	ErrInvalidCurrencyFormat = errors.New("invalid currency format")
 	// ErrMissingApplication is returned when there's no application defined in a request.
 	ErrMissingApplication = errors.New("missing application")
+	// ErrInsufficientCredits is returned when a customer doesn't have enough credits to perform an operation.
+	ErrInsufficientCredits = errors.New("insufficient credits")
 )
 
 // Transaction is an operation made with credits. It's usually used to increase and decrease the amount of credits of certain models.Customer.
@@ -74,7 +98,13 @@ type IncreaseCreditsRequest struct {
 }
 
 // IncreaseCreditsResponse is the output of the CreditsV1.IncreaseCredits method.
-type IncreaseCreditsResponse struct{}
+type IncreaseCreditsResponse struct {
+	// TransactionID identifies the transaction. It can be used to reverse it with CreditsV1.ReverseTransaction.
+	TransactionID uint `json:"transaction_id"`
+
+	// Balance is the balance of the customer after applying the transaction.
+	Balance int `json:"balance"`
+}
 
 // DecreaseCreditsRequest is the input for the CreditsV1.DecreaseCredits method.
 type DecreaseCreditsRequest struct {
@@ -82,7 +112,13 @@ type DecreaseCreditsRequest struct {
 }
 
 // DecreaseCreditsResponse is the output of the CreditsV1.DecreaseCredits method.
-type DecreaseCreditsResponse struct{}
+type DecreaseCreditsResponse struct {
+	// TransactionID identifies the transaction. It can be used to reverse it with CreditsV1.ReverseTransaction.
+	TransactionID uint `json:"transaction_id"`
+
+	// Balance is the balance of the customer after applying the transaction.
+	Balance int `json:"balance"`
+}
 
 // GetBalanceRequest is the input for the CreditsV1.GetBalance method.
 type GetBalanceRequest struct {
@@ -103,6 +139,56 @@ type GetBalanceResponse struct {
 
 	// Credits is the amount of credits that the customer identified by Handle has.
 	Credits int `json:"credits"`
+
+	// Status is the status of the customer.
+	Status CustomerStatus `json:"status,omitempty"`
+
+	// OverdraftLimit is the amount of credits the customer can go below zero.
+	OverdraftLimit uint `json:"overdraft_limit,omitempty"`
+
+	// Spendable is the amount of credits the customer can still spend, including its overdraft limit.
+	Spendable int `json:"spendable,omitempty"`
+}
+
+// MaxBalancesHandles is the maximum amount of handles that can be requested in a single GetBalances call.
+const MaxBalancesHandles = 500
+
+// ErrTooManyHandles is returned when more than MaxBalancesHandles handles are passed in the request.
+var ErrTooManyHandles = errors.New("too many handles")
+
+// GetBalancesRequest is the input for the CreditsV1.GetBalances method.
+type GetBalancesRequest struct {
+	// Handles contains the usernames of the customers that should receive the balance summary.
+	Handles []string `json:"handles"`
+
+	// Application is the application that credits are tracked for.
+	Application string `json:"application"`
+}
+
+// Validate validates the current request is valid.
+func (r GetBalancesRequest) Validate() error {
+	if len(r.Handles) == 0 {
+		return ErrHandleNotProvided
+	}
+	if len(r.Handles) > MaxBalancesHandles {
+		return ErrTooManyHandles
+	}
+	for _, h := range r.Handles {
+		if len(h) == 0 {
+			return ErrHandleNotProvided
+		}
+	}
+	if len(r.Application) == 0 {
+		return ErrMissingApplication
+	}
+	return nil
+}
+
+// GetBalancesResponse is the output of the CreditsV1.GetBalances method.
+type GetBalancesResponse struct {
+	// Balances contains the balance of each requested handle in the same order they were requested.
+	// Handles without credits have a zero balance.
+	Balances []GetBalanceResponse `json:"balances"`
 }
 
 // ConvertCurrencyRequest is the input for the CreditsV1.ConvertCurrency method.
@@ -113,12 +199,22 @@ type ConvertCurrencyRequest struct {
 
 	// Currency is the ISO 4217 currency code in lowercase format.
 	Currency string `json:"currency"`
+
+	// Application is the application the credits are bought for. The volume tiers and packages of the application
+	// are applied if it's set. Otherwise, only the conversion rate is applied.
+	Application string `json:"application,omitempty"`
 }
 
 // ConvertCurrencyResponse is the output of the CreditsV1.ConvertCurrency method.
 type ConvertCurrencyResponse struct {
 	// Credits contains the result of converting a certain currency value into credits.
 	Credits uint `json:"credits"`
+
+	// Bonus is the amount of credits included in Credits by the volume tiers of the application.
+	Bonus uint `json:"bonus,omitempty"`
+
+	// Package is the code of the package bought with the given amount, if any.
+	Package string `json:"package,omitempty"`
 }
 
 // GetUnitPriceRequest is the input for the CreditsV1.GetUnitPrice method.
@@ -135,3 +231,68 @@ type GetUnitPriceResponse struct {
 	// Currency is the ISO 4217 currency code in lowercase format.
 	Currency string `json:"currency"`
 }
+
+// EstimateCostRequest is the input for the CreditsV1.EstimateCost method.
+type EstimateCostRequest struct {
+	// Handle is the username of the customer whose balance will be checked.
+	Handle string `json:"handle"`
+
+	// Application is the application that credits are tracked for.
+	Application string `json:"application"`
+
+	// SKU is the code of the SKU being estimated.
+	SKU string `json:"sku"`
+
+	// Quantity is the amount of units of the SKU being estimated.
+	Quantity uint `json:"quantity"`
+
+	// Periods is the amount of time units the SKU will be used for (e.g. hours for SKUs billed by the hour).
+	// Defaults to 1.
+	Periods uint `json:"periods,omitempty"`
+
+	// Currency is the ISO 4217 currency code in lowercase format used to express the shortfall in FIAT currency.
+	Currency string `json:"currency"`
+}
+
+// Validate validates the current request is valid.
+func (r EstimateCostRequest) Validate() error {
+	if len(r.Handle) == 0 {
+		return ErrHandleNotProvided
+	}
+	if len(r.Application) == 0 {
+		return ErrMissingApplication
+	}
+	if len(r.SKU) == 0 {
+		return ErrMissingSKU
+	}
+	if r.Quantity == 0 {
+		return ErrInvalidQuantity
+	}
+	// TODO: Add check for valid currency values as well as lowercase.
+	if len(r.Currency) == 0 || len(r.Currency) > 3 {
+		return ErrInvalidCurrencyFormat
+	}
+	return nil
+}
+
+// EstimateCostResponse is the output of the CreditsV1.EstimateCost method.
+type EstimateCostResponse struct {
+	// Cost is the amount of credits the requested usage would cost.
+	Cost uint `json:"cost"`
+
+	// Available is the amount of credits the customer can currently spend. It's the current balance minus the credits
+	// held by open usage sessions that haven't been charged yet.
+	Available int `json:"available"`
+
+	// Affordable is true if the customer has enough available credits to cover the cost.
+	Affordable bool `json:"affordable"`
+
+	// Shortfall is the amount of credits missing to cover the cost.
+	Shortfall uint `json:"shortfall"`
+
+	// ShortfallAmount is the money in the minimum currency value (e.g. cents for USD) needed to buy the Shortfall.
+	ShortfallAmount uint `json:"shortfall_amount"`
+
+	// Currency is the ISO 4217 currency code in lowercase format of ShortfallAmount.
+	Currency string `json:"currency"`
+}
@@ -1 +1,171 @@
 package api
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestIntervalTruncate(t *testing.T) {
+	// Wednesday
+	at := time.Date(2021, time.November, 17, 15, 30, 0, 0, time.UTC)
+
+	assert.Equal(t, time.Date(2021, time.November, 17, 0, 0, 0, 0, time.UTC), IntervalDaily.Truncate(at))
+	assert.Equal(t, time.Date(2021, time.November, 15, 0, 0, 0, 0, time.UTC), IntervalWeekly.Truncate(at))
+	assert.Equal(t, time.Date(2021, time.November, 1, 0, 0, 0, 0, time.UTC), IntervalMonthly.Truncate(at))
+}
+
+func TestIntervalValidate(t *testing.T) {
+	assert.NoError(t, IntervalDaily.Validate())
+	assert.NoError(t, IntervalWeekly.Validate())
+	assert.NoError(t, IntervalMonthly.Validate())
+	assert.Equal(t, ErrInvalidInterval, Interval("hourly").Validate())
+}
+
+func TestGetBalanceHistoryRequestValidate(t *testing.T) {
+	now := time.Now()
+	req := GetBalanceHistoryRequest{
+		Handle:      "test",
+		Application: "cloudsim",
+		From:        now,
+		To:          now.Add(-time.Hour),
+	}
+	assert.Equal(t, ErrInvalidDateRange, req.Validate())
+
+	req.To = now.Add(time.Hour)
+	assert.NoError(t, req.Validate())
+}
+
+func TestVerifySignature(t *testing.T) {
+	payload := []byte(`{"id":"evt_1"}`)
+	now := time.Now()
+
+	header := SignPayload("secret", now, payload)
+	assert.NoError(t, VerifySignature("secret", header, payload, time.Minute))
+
+	assert.Equal(t, ErrInvalidSignature, VerifySignature("other", header, payload, time.Minute))
+	assert.Equal(t, ErrInvalidSignature, VerifySignature("secret", header, []byte(`{"id":"evt_2"}`), time.Minute))
+	assert.Equal(t, ErrInvalidSignature, VerifySignature("secret", "v1=abc", payload, time.Minute))
+
+	old := SignPayload("secret", now.Add(-time.Hour), payload)
+	assert.Equal(t, ErrInvalidSignature, VerifySignature("secret", old, payload, time.Minute))
+	assert.NoError(t, VerifySignature("secret", old, payload, 0))
+}
+
+func TestCustomerStatusError(t *testing.T) {
+	err := error(&CustomerStatusError{Handle: "test", Application: "cloudsim", Status: CustomerFrozen})
+	assert.True(t, errors.Is(err, ErrCustomerFrozen))
+	assert.False(t, errors.Is(err, ErrCustomerClosed))
+
+	var statusErr *CustomerStatusError
+	assert.True(t, errors.As(err, &statusErr))
+	assert.Equal(t, CustomerFrozen, statusErr.Status)
+
+	err = &CustomerStatusError{Handle: "test", Application: "cloudsim", Status: CustomerClosed}
+	assert.True(t, errors.Is(err, ErrCustomerClosed))
+}
+
+func TestSpendingLimitsCaps(t *testing.T) {
+	caps := SpendingLimits{CreditsPerDay: 100, DecreasesPerMinute: 5}.Caps()
+	assert.Equal(t, map[SpendingCap]uint{CapCreditsPerDay: 100, CapDecreasesPerMinute: 5}, caps)
+	assert.Empty(t, SpendingLimits{}.Caps())
+
+	assert.Equal(t, time.Hour, CapCreditsPerHour.Window())
+	assert.Equal(t, 24*time.Hour, CapCreditsPerDay.Window())
+	assert.Equal(t, time.Minute, CapDecreasesPerMinute.Window())
+
+	err := error(&SpendingLimitError{SpendingUsage{Cap: CapCreditsPerHour, Limit: 10, Used: 8}})
+	assert.True(t, errors.Is(err, ErrSpendingLimitExceeded))
+	assert.Contains(t, err.Error(), "credits_per_hour")
+}
+
+func TestSubscriptionSchedulePeriodStart(t *testing.T) {
+	start := time.Date(2022, time.January, 31, 10, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, start, ScheduleMonthly.PeriodStart(start, 0))
+	assert.Equal(t, time.Date(2022, time.February, 28, 10, 0, 0, 0, time.UTC), ScheduleMonthly.PeriodStart(start, 1))
+	assert.Equal(t, time.Date(2022, time.March, 31, 10, 0, 0, 0, time.UTC), ScheduleMonthly.PeriodStart(start, 2))
+	assert.Equal(t, time.Date(2023, time.January, 31, 10, 0, 0, 0, time.UTC), ScheduleMonthly.PeriodStart(start, 12))
+	assert.Equal(t, time.Date(2022, time.February, 14, 10, 0, 0, 0, time.UTC), ScheduleWeekly.PeriodStart(start, 2))
+	assert.Equal(t, time.Date(2022, time.February, 1, 10, 0, 0, 0, time.UTC), ScheduleDaily.PeriodStart(start, 1))
+
+	assert.NoError(t, ScheduleMonthly.Validate())
+	assert.Equal(t, ErrInvalidSchedule, SubscriptionSchedule("yearly").Validate())
+	assert.Equal(t, ErrInvalidRollover, RolloverRule("keep").Validate())
+}
+
+func TestScheduleOperationRequestValidate(t *testing.T) {
+	req := ScheduleOperationRequest{
+		Type: OperationIncrease,
+		Transaction: Transaction{
+			Handle:      "test",
+			Amount:      100,
+			Currency:    "usd",
+			Application: "cloudsim",
+		},
+		RunAt: time.Now(),
+	}
+	assert.NoError(t, req.Validate())
+
+	req.Type = OperationTransfer
+	assert.Equal(t, ErrInvalidOperationType, req.Validate())
+
+	req.Type = OperationDecrease
+	req.RunAt = time.Time{}
+	assert.Equal(t, ErrMissingRunAt, req.Validate())
+}
+
+func TestCreateCouponRequestValidate(t *testing.T) {
+	req := CreateCouponRequest{Application: "cloudsim", Code: "LAUNCH", Credits: 100}
+	assert.NoError(t, req.Validate())
+
+	req.Code = strings.Repeat("A", MaxCouponCodeLength+1)
+	assert.Equal(t, ErrInvalidCode, req.Validate())
+
+	req.Code = ""
+	assert.Equal(t, ErrMissingCode, req.Validate())
+}
+
+func TestSetVolumeTiersRequestValidate(t *testing.T) {
+	req := SetVolumeTiersRequest{Application: "cloudsim"}
+	assert.NoError(t, req.Validate())
+
+	req.Tiers = []VolumeTier{{MinAmount: 10000, BonusPercent: 10}, {MinAmount: 50000, BonusPercent: 20}}
+	assert.NoError(t, req.Validate())
+
+	req.Tiers = append(req.Tiers, VolumeTier{MinAmount: 10000, BonusPercent: 15})
+	assert.Equal(t, ErrInvalidVolumeTier, req.Validate())
+
+	req.Tiers = []VolumeTier{{MinAmount: 10000}}
+	assert.Equal(t, ErrInvalidVolumeTier, req.Validate())
+
+	req.Application = ""
+	assert.Equal(t, ErrMissingApplication, req.Validate())
+}
+
+func TestCreatePackageRequestValidate(t *testing.T) {
+	req := CreatePackageRequest{CreditPackage{Application: "cloudsim", Code: "starter", Credits: 500, Amount: 450}}
+	assert.NoError(t, req.Validate())
+
+	req.Amount = 0
+	assert.Equal(t, ErrInvalidAmount, req.Validate())
+
+	req.Code = ""
+	assert.Equal(t, ErrMissingCode, req.Validate())
+}
+
+func TestSetConversionPolicyRequestValidate(t *testing.T) {
+	req := SetConversionPolicyRequest{ConversionPolicy{Application: "cloudsim", Rounding: RoundingHalfEven}}
+	assert.NoError(t, req.Validate())
+
+	req.Rounding = "round"
+	assert.Equal(t, ErrInvalidRoundingMode, req.Validate())
+
+	req.Rounding = ""
+	assert.Equal(t, ErrInvalidRoundingMode, req.Validate())
+
+	req.Application = ""
+	assert.Equal(t, ErrMissingApplication, req.Validate())
+}
@@ -0,0 +1,135 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrEmptyBatch is returned when a batch without operations is passed in the request.
+	ErrEmptyBatch = errors.New("empty batch")
+	// ErrBatchTooLarge is returned when a batch with more than MaxBatchOperations is passed in the request.
+	ErrBatchTooLarge = errors.New("batch too large")
+	// ErrInvalidOperationType is returned when an unknown operation type is passed in the request.
+	ErrInvalidOperationType = errors.New("invalid operation type")
+	// ErrMissingRecipient is returned when a transfer operation has no recipient.
+	ErrMissingRecipient = errors.New("missing recipient")
+)
+
+// MaxBatchOperations is the maximum amount of operations allowed in a single batch.
+const MaxBatchOperations = 100
+
+// OperationType is the type of operation of a batch.
+type OperationType string
+
+const (
+	// OperationIncrease increases the credits of a customer. See CreditsV1.IncreaseCredits.
+	OperationIncrease OperationType = "increase"
+	// OperationDecrease decreases the credits of a customer. See CreditsV1.DecreaseCredits.
+	OperationDecrease OperationType = "decrease"
+	// OperationTransfer moves credits from one customer to another customer of the same application.
+	OperationTransfer OperationType = "transfer"
+)
+
+// BatchOperation is a single operation of a batch.
+type BatchOperation struct {
+	// Type is the type of operation.
+	Type OperationType `json:"type"`
+
+	// Transaction contains the customer and the amount of the operation. For transfers, Handle is the customer that
+	// sends the credits.
+	Transaction
+
+	// Recipient is the handle of the customer that receives the credits of a transfer.
+	Recipient string `json:"recipient,omitempty"`
+}
+
+// Validate validates the current operation is valid.
+func (op BatchOperation) Validate() error {
+	if err := op.Transaction.Validate(); err != nil {
+		return err
+	}
+	switch op.Type {
+	case OperationIncrease, OperationDecrease:
+		return nil
+	case OperationTransfer:
+		if len(op.Recipient) == 0 || op.Recipient == op.Handle {
+			return ErrMissingRecipient
+		}
+		return nil
+	default:
+		return ErrInvalidOperationType
+	}
+}
+
+// BatchOperationError is returned when a single operation of a batch fails. None of the operations of the batch
+// are applied when this happens.
+type BatchOperationError struct {
+	// Index is the position of the failed operation in the batch.
+	Index int
+
+	// Err is the reason the operation failed.
+	Err error
+}
+
+// Error returns the error message.
+func (e *BatchOperationError) Error() string {
+	return fmt.Sprintf("operation %d: %s", e.Index, e.Err)
+}
+
+// Unwrap returns the reason the operation failed.
+func (e *BatchOperationError) Unwrap() error {
+	return e.Err
+}
+
+// ExecuteBatchRequest is the input for the CreditsV1.ExecuteBatch method.
+type ExecuteBatchRequest struct {
+	// Operations contains the operations to apply, in order.
+	Operations []BatchOperation `json:"operations"`
+}
+
+// Validate validates the current request is valid.
+func (r ExecuteBatchRequest) Validate() error {
+	if len(r.Operations) == 0 {
+		return ErrEmptyBatch
+	}
+	if len(r.Operations) > MaxBatchOperations {
+		return ErrBatchTooLarge
+	}
+	for i, op := range r.Operations {
+		if err := op.Validate(); err != nil {
+			return &BatchOperationError{Index: i, Err: err}
+		}
+	}
+	return nil
+}
+
+// BatchOperationResult is the result of a single operation of a batch.
+type BatchOperationResult struct {
+	// Index is the position of the operation in the batch.
+	Index int `json:"index"`
+
+	// Type is the type of operation.
+	Type OperationType `json:"type"`
+
+	// Handle is the customer the operation was applied to.
+	Handle string `json:"handle"`
+
+	// Credits is the amount of credits added, removed or transferred by the operation.
+	Credits uint `json:"credits"`
+
+	// Balance is the balance of Handle after applying the operation.
+	Balance int `json:"balance"`
+
+	// Recipient is the handle of the customer that received the credits of a transfer.
+	Recipient string `json:"recipient,omitempty"`
+
+	// RecipientBalance is the balance of Recipient after applying a transfer.
+	RecipientBalance int `json:"recipient_balance,omitempty"`
+}
+
+// ExecuteBatchResponse is the output of the CreditsV1.ExecuteBatch method.
+type ExecuteBatchResponse struct {
+	// Results contains the result of each operation, in the same order they were requested.
+	Results []BatchOperationResult `json:"results"`
+}
@@ -0,0 +1,95 @@
+package api
+
+import (
+	"context"
+	"errors"
+)
+
+// ConversionV1 holds the methods that allow configuring how the money paid by customers is converted to credits.
+type ConversionV1 interface {
+	// SetConversionPolicy sets the rounding mode and the precision used to convert money to the credits of an
+	// application.
+	SetConversionPolicy(ctx context.Context, req SetConversionPolicyRequest) (SetConversionPolicyResponse, error)
+
+	// GetConversionPolicy returns the conversion policy of an application.
+	GetConversionPolicy(ctx context.Context, req GetConversionPolicyRequest) (GetConversionPolicyResponse, error)
+}
+
+var (
+	// ErrInvalidRoundingMode is returned when an invalid rounding mode is passed in the request.
+	ErrInvalidRoundingMode = errors.New("invalid rounding mode")
+	// ErrPrecisionLocked is returned when changing the precision of an application that already has customers or
+	// settings expressed in credits.
+	ErrPrecisionLocked = errors.New("precision can't change once the application has customers or credit settings")
+)
+
+// RoundingMode defines how fractions of a credit are rounded when converting money to credits.
+type RoundingMode string
+
+const (
+	// RoundingCeil rounds fractions up to the next credit. It's the default rounding mode.
+	RoundingCeil RoundingMode = "ceil"
+	// RoundingFloor rounds fractions down to the previous credit.
+	RoundingFloor RoundingMode = "floor"
+	// RoundingHalfEven rounds fractions to the nearest credit, and exact halves to the nearest even credit.
+	RoundingHalfEven RoundingMode = "half_even"
+)
+
+// Validate validates the current rounding mode is valid.
+func (m RoundingMode) Validate() error {
+	switch m {
+	case RoundingCeil, RoundingFloor, RoundingHalfEven:
+		return nil
+	}
+	return ErrInvalidRoundingMode
+}
+
+// MilliCreditsPerCredit is the amount of milli-credits in a credit.
+const MilliCreditsPerCredit = 1000
+
+// ConversionPolicy defines how money is converted to the credits of an application. Applications without a policy
+// round up to whole credits.
+type ConversionPolicy struct {
+	// Application is the application the policy applies to.
+	Application string `json:"application"`
+
+	// Rounding is the rounding mode applied to the fractions of the smallest unit tracked by the application.
+	Rounding RoundingMode `json:"rounding"`
+
+	// MilliCredits tracks the balances of the application in milli-credits (1/1000 of a credit) instead of credits.
+	// Every credit value of the application is expressed in milli-credits when it's enabled, including balances,
+	// operation results, prices, packages and grants. It can only change while the application has no customers
+	// and no settings expressed in credits, such as SKU prices, coupons, packages, trial policies, low balance
+	// thresholds, overdraft limits or spending limits.
+	MilliCredits bool `json:"milli_credits"`
+}
+
+// SetConversionPolicyRequest is the input for the ConversionV1.SetConversionPolicy method.
+type SetConversionPolicyRequest struct {
+	ConversionPolicy
+}
+
+// Validate validates the current request is valid.
+func (r SetConversionPolicyRequest) Validate() error {
+	if len(r.Application) == 0 {
+		return ErrMissingApplication
+	}
+	return r.Rounding.Validate()
+}
+
+// SetConversionPolicyResponse is the output of the ConversionV1.SetConversionPolicy method.
+type SetConversionPolicyResponse struct {
+	ConversionPolicy
+}
+
+// GetConversionPolicyRequest is the input for the ConversionV1.GetConversionPolicy method.
+type GetConversionPolicyRequest struct {
+	// Application is the application the policy applies to.
+	Application string `json:"application"`
+}
+
+// GetConversionPolicyResponse is the output of the ConversionV1.GetConversionPolicy method. Applications without
+// a policy return the default one.
+type GetConversionPolicyResponse struct {
+	ConversionPolicy
+}
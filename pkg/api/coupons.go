@@ -0,0 +1,182 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// CouponsV1 holds the methods that allow customers to redeem promotional codes for credits.
+type CouponsV1 interface {
+	// CreateCoupon creates a new coupon for an application.
+	CreateCoupon(ctx context.Context, req CreateCouponRequest) (CreateCouponResponse, error)
+
+	// ListCoupons returns the coupons of an application.
+	ListCoupons(ctx context.Context, req ListCouponsRequest) (ListCouponsResponse, error)
+
+	// RedeemCoupon grants the credits of a coupon to a customer.
+	RedeemCoupon(ctx context.Context, req RedeemCouponRequest) (RedeemCouponResponse, error)
+
+	// GetCouponStats returns the usage statistics of a coupon.
+	GetCouponStats(ctx context.Context, req GetCouponStatsRequest) (GetCouponStatsResponse, error)
+}
+
+var (
+	// ErrMissingCode is returned when no coupon code is passed in the request.
+	ErrMissingCode = errors.New("missing code")
+	// ErrInvalidCode is returned when a coupon code longer than MaxCouponCodeLength is passed in the request.
+	ErrInvalidCode = errors.New("invalid code")
+	// ErrCouponExists is returned when creating a coupon with a code that is already in use by the application.
+	ErrCouponExists = errors.New("coupon already exists")
+	// ErrCouponNotFound is returned when a coupon could not be found.
+	ErrCouponNotFound = errors.New("coupon not found")
+	// ErrCouponExpired is returned when redeeming a coupon after its expiration time.
+	ErrCouponExpired = errors.New("coupon expired")
+	// ErrCouponExhausted is returned when redeeming a coupon that reached its maximum amount of redemptions.
+	ErrCouponExhausted = errors.New("coupon exhausted")
+	// ErrCouponAlreadyRedeemed is returned when a customer redeems the same coupon twice.
+	ErrCouponAlreadyRedeemed = errors.New("coupon already redeemed")
+)
+
+// MaxCouponCodeLength is the maximum length of a coupon code.
+const MaxCouponCodeLength = 64
+
+// Coupon is a promotional code that grants credits to the customers that redeem it. Codes are case-insensitive.
+type Coupon struct {
+	// Application is the application the coupon can be redeemed for.
+	Application string `json:"application"`
+
+	// Code is the code customers use to redeem the coupon. It's always upper case.
+	Code string `json:"code"`
+
+	// Credits is the amount of credits granted on every redemption.
+	Credits uint `json:"credits"`
+
+	// ExpiresAt is the time the coupon can't be redeemed anymore. The coupon never expires if it's nil.
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+
+	// MaxRedemptions is the maximum amount of times the coupon can be redeemed. The coupon can be redeemed
+	// without limit if it's zero.
+	MaxRedemptions uint `json:"max_redemptions,omitempty"`
+
+	// Redemptions is the amount of times the coupon has been redeemed.
+	Redemptions uint `json:"redemptions"`
+
+	// CreatedAt is the time the coupon was created.
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// CreateCouponRequest is the input for the CouponsV1.CreateCoupon method.
+type CreateCouponRequest struct {
+	// Application is the application the coupon can be redeemed for.
+	Application string `json:"application"`
+
+	// Code is the code customers use to redeem the coupon.
+	Code string `json:"code"`
+
+	// Credits is the amount of credits granted on every redemption.
+	Credits uint `json:"credits"`
+
+	// ExpiresAt is the time the coupon can't be redeemed anymore. The coupon never expires if it's nil.
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+
+	// MaxRedemptions is the maximum amount of times the coupon can be redeemed. The coupon can be redeemed
+	// without limit if it's zero.
+	MaxRedemptions uint `json:"max_redemptions,omitempty"`
+}
+
+// Validate validates the current request is valid.
+func (r CreateCouponRequest) Validate() error {
+	if len(r.Application) == 0 {
+		return ErrMissingApplication
+	}
+	if len(r.Code) == 0 {
+		return ErrMissingCode
+	}
+	if len(r.Code) > MaxCouponCodeLength {
+		return ErrInvalidCode
+	}
+	if r.Credits == 0 {
+		return ErrInvalidAmount
+	}
+	return nil
+}
+
+// CreateCouponResponse is the output of the CouponsV1.CreateCoupon method.
+type CreateCouponResponse struct {
+	Coupon
+}
+
+// ListCouponsRequest is the input for the CouponsV1.ListCoupons method.
+type ListCouponsRequest struct {
+	// Application is the application the coupons can be redeemed for.
+	Application string `json:"application"`
+}
+
+// ListCouponsResponse is the output of the CouponsV1.ListCoupons method.
+type ListCouponsResponse struct {
+	// Coupons contains the coupons of the application sorted by code.
+	Coupons []Coupon `json:"coupons"`
+}
+
+// RedeemCouponRequest is the input for the CouponsV1.RedeemCoupon method.
+type RedeemCouponRequest struct {
+	// Handle is the customer redeeming the coupon.
+	Handle string `json:"handle"`
+
+	// Application is the application the coupon is redeemed for.
+	Application string `json:"application"`
+
+	// Code is the coupon code.
+	Code string `json:"code"`
+}
+
+// Validate validates the current request is valid.
+func (r RedeemCouponRequest) Validate() error {
+	if len(r.Handle) == 0 {
+		return ErrHandleNotProvided
+	}
+	if len(r.Application) == 0 {
+		return ErrMissingApplication
+	}
+	if len(r.Code) == 0 {
+		return ErrMissingCode
+	}
+	return nil
+}
+
+// RedeemCouponResponse is the output of the CouponsV1.RedeemCoupon method.
+type RedeemCouponResponse struct {
+	// TransactionID is the ID of the transaction that granted the credits.
+	TransactionID uint `json:"transaction_id"`
+
+	// Credits is the amount of credits granted.
+	Credits uint `json:"credits"`
+
+	// Balance is the balance of the customer after redeeming the coupon.
+	Balance int `json:"balance"`
+}
+
+// GetCouponStatsRequest is the input for the CouponsV1.GetCouponStats method.
+type GetCouponStatsRequest struct {
+	// Application is the application the coupon can be redeemed for.
+	Application string `json:"application"`
+
+	// Code is the coupon code.
+	Code string `json:"code"`
+}
+
+// GetCouponStatsResponse is the output of the CouponsV1.GetCouponStats method.
+type GetCouponStatsResponse struct {
+	// Coupon contains the coupon, including its amount of redemptions.
+	Coupon Coupon `json:"coupon"`
+
+	// CreditsGranted is the total amount of credits granted by the coupon.
+	CreditsGranted uint `json:"credits_granted"`
+
+	// Remaining is the amount of redemptions left. It's nil for coupons without a maximum amount of redemptions.
+	Remaining *uint `json:"remaining,omitempty"`
+
+	// LastRedeemedAt is the time of the latest redemption. It's nil if the coupon has never been redeemed.
+	LastRedeemedAt *time.Time `json:"last_redeemed_at,omitempty"`
+}
@@ -0,0 +1,291 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// CustomersV1 holds the methods that allow managing the customers of each application.
+type CustomersV1 interface {
+	// ListCustomers returns a page of the customers of a certain application.
+	ListCustomers(ctx context.Context, req ListCustomersRequest) (ListCustomersResponse, error)
+
+	// SetCustomerStatus changes the status of a customer, recording the reason of the change.
+	SetCustomerStatus(ctx context.Context, req SetCustomerStatusRequest) (SetCustomerStatusResponse, error)
+
+	// ListCustomerStatusChanges returns the latest status changes of a customer.
+	ListCustomerStatusChanges(ctx context.Context, req ListCustomerStatusChangesRequest) (ListCustomerStatusChangesResponse, error)
+}
+
+var (
+	// ErrInvalidCursor is returned when an invalid pagination cursor is passed in the request.
+	ErrInvalidCursor = errors.New("invalid cursor")
+	// ErrInvalidSort is returned when an invalid sort field or order is passed in the request.
+	ErrInvalidSort = errors.New("invalid sort")
+	// ErrInvalidLimit is returned when an invalid page size is passed in the request.
+	ErrInvalidLimit = errors.New("invalid limit")
+	// ErrInvalidBalanceRange is returned when an invalid balance range is passed in the request.
+	ErrInvalidBalanceRange = errors.New("invalid balance range")
+	// ErrCustomerNotFound is returned when a customer could not be found.
+	ErrCustomerNotFound = errors.New("customer not found")
+	// ErrInvalidCustomerStatus is returned when an unknown customer status is passed in the request.
+	ErrInvalidCustomerStatus = errors.New("invalid customer status")
+	// ErrMissingReason is returned when no reason is given for a customer status change.
+	ErrMissingReason = errors.New("missing reason")
+	// ErrCustomerClosed is returned when operating on a closed customer. Closed customers can't be reopened.
+	ErrCustomerClosed = errors.New("customer closed")
+	// ErrCustomerFrozen is returned when a frozen customer tries to receive or spend credits.
+	ErrCustomerFrozen = errors.New("customer frozen")
+)
+
+// CustomerStatus is the status of a customer.
+type CustomerStatus string
+
+const (
+	// CustomerActive is the status of customers that can receive and spend credits.
+	CustomerActive CustomerStatus = "active"
+	// CustomerFrozen is the status of customers that temporarily can't receive nor spend credits, e.g. while
+	// investigating fraud. Their data and balance are kept.
+	CustomerFrozen CustomerStatus = "frozen"
+	// CustomerClosed is the status of customers whose account has been permanently closed.
+	CustomerClosed CustomerStatus = "closed"
+)
+
+// Validate validates the current customer status is valid.
+func (s CustomerStatus) Validate() error {
+	switch s {
+	case CustomerActive, CustomerFrozen, CustomerClosed:
+		return nil
+	default:
+		return ErrInvalidCustomerStatus
+	}
+}
+
+// CustomerStatusError is returned when a customer can't receive or spend credits because of its status. It wraps
+// ErrCustomerFrozen or ErrCustomerClosed.
+type CustomerStatusError struct {
+	// Handle is the customer handle.
+	Handle string
+
+	// Application is the application that credits are tracked for.
+	Application string
+
+	// Status is the current status of the customer.
+	Status CustomerStatus
+}
+
+// Error returns the error message.
+func (e *CustomerStatusError) Error() string {
+	return fmt.Sprintf("customer %s in %s: %s", e.Handle, e.Application, e.Unwrap())
+}
+
+// Unwrap returns the sentinel error of the customer status.
+func (e *CustomerStatusError) Unwrap() error {
+	if e.Status == CustomerClosed {
+		return ErrCustomerClosed
+	}
+	return ErrCustomerFrozen
+}
+
+// CustomerSort is the field used to sort customers.
+type CustomerSort string
+
+const (
+	// SortByHandle sorts customers by their handle.
+	SortByHandle CustomerSort = "handle"
+	// SortByBalance sorts customers by their amount of credits.
+	SortByBalance CustomerSort = "balance"
+	// SortByLastActivity sorts customers by the last time their balance changed.
+	SortByLastActivity CustomerSort = "last_activity"
+)
+
+// SortOrder is the direction used to sort results.
+type SortOrder string
+
+const (
+	// OrderAscending sorts results from the lowest to the highest value.
+	OrderAscending SortOrder = "asc"
+	// OrderDescending sorts results from the highest to the lowest value.
+	OrderDescending SortOrder = "desc"
+)
+
+const (
+	// DefaultPageSize is the page size used when no limit is passed in the request.
+	DefaultPageSize = 50
+	// MaxPageSize is the maximum page size allowed in a single request.
+	MaxPageSize = 500
+)
+
+// Customer contains the balance summary of a single customer.
+type Customer struct {
+	// Handle is the username of the customer.
+	Handle string `json:"handle"`
+
+	// Application is the application that credits are tracked for.
+	Application string `json:"application"`
+
+	// Credits is the amount of credits the customer has.
+	Credits int `json:"credits"`
+
+	// Status is the status of the customer.
+	Status CustomerStatus `json:"status"`
+
+	// CreatedAt is the time the customer was created.
+	CreatedAt time.Time `json:"created_at"`
+
+	// LastActivityAt is the last time the customer was updated.
+	LastActivityAt time.Time `json:"last_activity_at"`
+}
+
+// ListCustomersRequest is the input for the CustomersV1.ListCustomers method.
+type ListCustomersRequest struct {
+	// Application is the application that credits are tracked for.
+	Application string `json:"application"`
+
+	// HandlePrefix filters customers whose handle starts with the given prefix.
+	HandlePrefix string `json:"handle_prefix,omitempty"`
+
+	// MinCredits filters customers with at least the given amount of credits.
+	MinCredits *int `json:"min_credits,omitempty"`
+
+	// MaxCredits filters customers with at most the given amount of credits.
+	MaxCredits *int `json:"max_credits,omitempty"`
+
+	// SortBy is the field used to sort the customers. Defaults to SortByHandle.
+	SortBy CustomerSort `json:"sort_by,omitempty"`
+
+	// Order is the sort direction. Defaults to OrderAscending.
+	Order SortOrder `json:"order,omitempty"`
+
+	// Limit is the maximum amount of customers returned. Defaults to DefaultPageSize.
+	Limit int `json:"limit,omitempty"`
+
+	// Cursor is the value of ListCustomersResponse.NextCursor from the previous page. The rest of the fields of the
+	// request must not change between pages.
+	Cursor string `json:"cursor,omitempty"`
+}
+
+// Validate validates the current request is valid.
+func (r ListCustomersRequest) Validate() error {
+	if len(r.Application) == 0 {
+		return ErrMissingApplication
+	}
+	switch r.SortBy {
+	case "", SortByHandle, SortByBalance, SortByLastActivity:
+	default:
+		return ErrInvalidSort
+	}
+	switch r.Order {
+	case "", OrderAscending, OrderDescending:
+	default:
+		return ErrInvalidSort
+	}
+	if r.Limit < 0 || r.Limit > MaxPageSize {
+		return ErrInvalidLimit
+	}
+	if r.MinCredits != nil && r.MaxCredits != nil && *r.MinCredits > *r.MaxCredits {
+		return ErrInvalidBalanceRange
+	}
+	return nil
+}
+
+// ListCustomersResponse is the output of the CustomersV1.ListCustomers method.
+type ListCustomersResponse struct {
+	// Customers contains the current page of customers.
+	Customers []Customer `json:"customers"`
+
+	// NextCursor is the cursor used to request the next page. It's empty when there are no more pages.
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// CustomerStatusChange is a change of the status of a customer.
+type CustomerStatusChange struct {
+	// Handle is the customer handle.
+	Handle string `json:"handle"`
+
+	// Application is the application that credits are tracked for.
+	Application string `json:"application"`
+
+	// PreviousStatus is the status of the customer before the change.
+	PreviousStatus CustomerStatus `json:"previous_status"`
+
+	// Status is the status of the customer after the change.
+	Status CustomerStatus `json:"status"`
+
+	// Reason explains why the status was changed.
+	Reason string `json:"reason"`
+
+	// CreatedAt is the time the status was changed.
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// SetCustomerStatusRequest is the input for the CustomersV1.SetCustomerStatus method.
+type SetCustomerStatusRequest struct {
+	// Handle is the customer handle.
+	Handle string `json:"handle"`
+
+	// Application is the application that credits are tracked for.
+	Application string `json:"application"`
+
+	// Status is the new status of the customer.
+	Status CustomerStatus `json:"status"`
+
+	// Reason explains why the status is changed.
+	Reason string `json:"reason"`
+}
+
+// Validate validates the current request is valid.
+func (r SetCustomerStatusRequest) Validate() error {
+	if len(r.Handle) == 0 {
+		return ErrHandleNotProvided
+	}
+	if len(r.Application) == 0 {
+		return ErrMissingApplication
+	}
+	if err := r.Status.Validate(); err != nil {
+		return err
+	}
+	if len(r.Reason) == 0 {
+		return ErrMissingReason
+	}
+	return nil
+}
+
+// SetCustomerStatusResponse is the output of the CustomersV1.SetCustomerStatus method.
+type SetCustomerStatusResponse struct {
+	CustomerStatusChange
+}
+
+// ListCustomerStatusChangesRequest is the input for the CustomersV1.ListCustomerStatusChanges method.
+type ListCustomerStatusChangesRequest struct {
+	// Handle is the customer handle.
+	Handle string `json:"handle"`
+
+	// Application is the application that credits are tracked for.
+	Application string `json:"application"`
+
+	// Limit is the maximum amount of changes returned, newest first. Defaults to DefaultPageSize.
+	Limit int `json:"limit,omitempty"`
+}
+
+// Validate validates the current request is valid.
+func (r ListCustomerStatusChangesRequest) Validate() error {
+	if len(r.Handle) == 0 {
+		return ErrHandleNotProvided
+	}
+	if len(r.Application) == 0 {
+		return ErrMissingApplication
+	}
+	if r.Limit < 0 || r.Limit > MaxPageSize {
+		return ErrInvalidLimit
+	}
+	return nil
+}
+
+// ListCustomerStatusChangesResponse is the output of the CustomersV1.ListCustomerStatusChanges method.
+type ListCustomerStatusChangesResponse struct {
+	// Changes contains the status changes of the customer, newest first.
+	Changes []CustomerStatusChange `json:"changes"`
+}
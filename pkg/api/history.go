@@ -0,0 +1,171 @@
+package api
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	// ErrInvalidDateRange is returned when an invalid date range is passed in the request.
+	ErrInvalidDateRange = errors.New("invalid date range")
+	// ErrInvalidInterval is returned when an invalid history interval is passed in the request.
+	ErrInvalidInterval = errors.New("invalid interval")
+)
+
+// Interval is the size of each bucket of a balance history.
+type Interval string
+
+const (
+	// IntervalDaily groups the balance history by day.
+	IntervalDaily Interval = "daily"
+	// IntervalWeekly groups the balance history by week. Weeks start on Monday.
+	IntervalWeekly Interval = "weekly"
+	// IntervalMonthly groups the balance history by calendar month.
+	IntervalMonthly Interval = "monthly"
+)
+
+// MaxHistoryBuckets is the maximum amount of buckets that can be returned in a single balance history.
+const MaxHistoryBuckets = 1000
+
+// Truncate returns the start of the bucket that contains t. Buckets are computed in UTC.
+func (i Interval) Truncate(t time.Time) time.Time {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	switch i {
+	case IntervalWeekly:
+		offset := (int(day.Weekday()) + 6) % 7
+		return day.AddDate(0, 0, -offset)
+	case IntervalMonthly:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	default:
+		return day
+	}
+}
+
+// Next returns the start of the bucket that follows the bucket starting at start.
+func (i Interval) Next(start time.Time) time.Time {
+	switch i {
+	case IntervalWeekly:
+		return start.AddDate(0, 0, 7)
+	case IntervalMonthly:
+		return start.AddDate(0, 1, 0)
+	default:
+		return start.AddDate(0, 0, 1)
+	}
+}
+
+// Validate validates the current interval is valid.
+func (i Interval) Validate() error {
+	switch i {
+	case IntervalDaily, IntervalWeekly, IntervalMonthly:
+		return nil
+	default:
+		return ErrInvalidInterval
+	}
+}
+
+// GetBalanceAtRequest is the input for the CreditsV1.GetBalanceAt method.
+type GetBalanceAtRequest struct {
+	// Handle is the username of the customer that should receive the balance summary.
+	Handle string `json:"handle"`
+
+	// Application is the application that credits are tracked for.
+	Application string `json:"application"`
+
+	// At is the point in time the balance should be computed at.
+	At time.Time `json:"at"`
+}
+
+// Validate validates the current request is valid.
+func (r GetBalanceAtRequest) Validate() error {
+	if len(r.Handle) == 0 {
+		return ErrHandleNotProvided
+	}
+	if len(r.Application) == 0 {
+		return ErrMissingApplication
+	}
+	if r.At.IsZero() {
+		return ErrInvalidDateRange
+	}
+	return nil
+}
+
+// GetBalanceAtResponse is the output of the CreditsV1.GetBalanceAt method.
+type GetBalanceAtResponse struct {
+	// Handle is the username of the customer that is receiving the balance summary.
+	Handle string `json:"handle"`
+
+	// Application is the application that credits are tracked for.
+	Application string `json:"application"`
+
+	// At is the point in time the balance was computed at.
+	At time.Time `json:"at"`
+
+	// Credits is the amount of credits that the customer identified by Handle had at the given time.
+	Credits int `json:"credits"`
+}
+
+// GetBalanceHistoryRequest is the input for the CreditsV1.GetBalanceHistory method.
+type GetBalanceHistoryRequest struct {
+	// Handle is the username of the customer that should receive the balance history.
+	Handle string `json:"handle"`
+
+	// Application is the application that credits are tracked for.
+	Application string `json:"application"`
+
+	// From is the start of the date range.
+	From time.Time `json:"from"`
+
+	// To is the end of the date range.
+	To time.Time `json:"to"`
+
+	// Interval is the size of each bucket. Defaults to IntervalDaily.
+	Interval Interval `json:"interval,omitempty"`
+}
+
+// Validate validates the current request is valid.
+func (r GetBalanceHistoryRequest) Validate() error {
+	if len(r.Handle) == 0 {
+		return ErrHandleNotProvided
+	}
+	if len(r.Application) == 0 {
+		return ErrMissingApplication
+	}
+	if r.From.IsZero() || r.To.IsZero() || !r.From.Before(r.To) {
+		return ErrInvalidDateRange
+	}
+	if len(r.Interval) == 0 {
+		return nil
+	}
+	return r.Interval.Validate()
+}
+
+// BalancePoint is a single bucket of a balance history.
+type BalancePoint struct {
+	// Start is the start of the bucket.
+	Start time.Time `json:"start"`
+
+	// End is the end of the bucket. It's capped by the end of the requested date range.
+	End time.Time `json:"end"`
+
+	// Credits is the balance of the customer at the end of the bucket.
+	Credits int `json:"credits"`
+
+	// Change is the net change of credits in the bucket.
+	Change int `json:"change"`
+}
+
+// GetBalanceHistoryResponse is the output of the CreditsV1.GetBalanceHistory method.
+type GetBalanceHistoryResponse struct {
+	// Handle is the username of the customer that is receiving the balance history.
+	Handle string `json:"handle"`
+
+	// Application is the application that credits are tracked for.
+	Application string `json:"application"`
+
+	// Interval is the size of each bucket.
+	Interval Interval `json:"interval"`
+
+	// Points contains the balance of each bucket, sorted from the oldest to the newest.
+	Points []BalancePoint `json:"points"`
+}
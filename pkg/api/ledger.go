@@ -0,0 +1,63 @@
+package api
+
+import (
+	"context"
+)
+
+// LedgerV1 holds the methods that allow verifying the tamper-evident ledger of each application.
+type LedgerV1 interface {
+	// VerifyLedger walks the hash chain of an application and reports the first broken link, if any.
+	VerifyLedger(ctx context.Context, req VerifyLedgerRequest) (VerifyLedgerResponse, error)
+}
+
+// BrokenLinkReason describes why a record of the ledger chain is not valid.
+type BrokenLinkReason string
+
+const (
+	// BrokenLinkHash is used when the hash of a record doesn't match its contents.
+	BrokenLinkHash BrokenLinkReason = "hash_mismatch"
+	// BrokenLinkPreviousHash is used when a record doesn't point to the hash of the previous record.
+	BrokenLinkPreviousHash BrokenLinkReason = "previous_hash_mismatch"
+	// BrokenLinkSequence is used when records are missing from the chain.
+	BrokenLinkSequence BrokenLinkReason = "sequence_gap"
+	// BrokenLinkBalanceChange is used when the balance change of a record has been edited or removed.
+	BrokenLinkBalanceChange BrokenLinkReason = "balance_change_mismatch"
+	// BrokenLinkHead is used when the last record doesn't match the head of the chain.
+	BrokenLinkHead BrokenLinkReason = "head_mismatch"
+	// BrokenLinkCustomerBalance is used when the balance of a customer doesn't match the balance of its last record.
+	// The broken link points to that record, or has a zero sequence if the customer has no records.
+	BrokenLinkCustomerBalance BrokenLinkReason = "customer_balance_mismatch"
+)
+
+// BrokenLink is the first invalid record found in a ledger chain.
+type BrokenLink struct {
+	// Sequence is the position in the chain of the invalid record.
+	Sequence uint `json:"sequence"`
+
+	// TransactionID is the balance change recorded by the invalid record. It's zero if the record is missing.
+	TransactionID uint `json:"transaction_id,omitempty"`
+
+	// Reason describes why the record is invalid.
+	Reason BrokenLinkReason `json:"reason"`
+}
+
+// VerifyLedgerRequest is the input for the LedgerV1.VerifyLedger method.
+type VerifyLedgerRequest struct {
+	// Application is the application whose ledger is verified.
+	Application string `json:"application"`
+}
+
+// VerifyLedgerResponse is the output of the LedgerV1.VerifyLedger method.
+type VerifyLedgerResponse struct {
+	// Valid is true when every record of the chain is valid.
+	Valid bool `json:"valid"`
+
+	// Records is the amount of records verified.
+	Records uint `json:"records"`
+
+	// Head is the hash of the last record of the chain.
+	Head string `json:"head,omitempty"`
+
+	// BrokenLink is the first invalid record of the chain. It's nil when the chain is valid.
+	BrokenLink *BrokenLink `json:"broken_link,omitempty"`
+}
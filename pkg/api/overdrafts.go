@@ -0,0 +1,82 @@
+package api
+
+import (
+	"context"
+	"errors"
+)
+
+// OverdraftsV1 holds the methods that allow configuring how far below zero the balance of customers can go.
+type OverdraftsV1 interface {
+	// SetOverdraftLimit sets the overdraft limit of an application, or of a single customer.
+	SetOverdraftLimit(ctx context.Context, req SetOverdraftLimitRequest) (SetOverdraftLimitResponse, error)
+
+	// GetOverdraftLimit returns the overdraft limit that applies to an application or to a single customer.
+	GetOverdraftLimit(ctx context.Context, req GetOverdraftLimitRequest) (GetOverdraftLimitResponse, error)
+
+	// DeleteOverdraftLimit removes the overdraft limit of an application, or of a single customer.
+	DeleteOverdraftLimit(ctx context.Context, req DeleteOverdraftLimitRequest) (DeleteOverdraftLimitResponse, error)
+}
+
+var (
+	// ErrOverdraftLimitNotFound is returned when no overdraft limit has been set.
+	ErrOverdraftLimitNotFound = errors.New("overdraft limit not found")
+)
+
+// OverdraftLimit is the amount of credits a customer can spend beyond its balance. Customers without an overdraft
+// limit can't go below zero.
+type OverdraftLimit struct {
+	// Application is the application the limit applies to.
+	Application string `json:"application"`
+
+	// Handle is the customer the limit applies to. It's empty for the application default.
+	Handle string `json:"handle,omitempty"`
+
+	// Limit is the maximum negative balance allowed, in credits.
+	Limit uint `json:"limit"`
+}
+
+// SetOverdraftLimitRequest is the input for the OverdraftsV1.SetOverdraftLimit method.
+type SetOverdraftLimitRequest struct {
+	OverdraftLimit
+}
+
+// Validate validates the current request is valid.
+func (r SetOverdraftLimitRequest) Validate() error {
+	if len(r.Application) == 0 {
+		return ErrMissingApplication
+	}
+	return nil
+}
+
+// SetOverdraftLimitResponse is the output of the OverdraftsV1.SetOverdraftLimit method.
+type SetOverdraftLimitResponse struct {
+	OverdraftLimit
+}
+
+// GetOverdraftLimitRequest is the input for the OverdraftsV1.GetOverdraftLimit method.
+type GetOverdraftLimitRequest struct {
+	// Application is the application the limit applies to.
+	Application string `json:"application"`
+
+	// Handle is the customer the limit applies to. If empty, the application limit is returned.
+	Handle string `json:"handle,omitempty"`
+}
+
+// GetOverdraftLimitResponse is the output of the OverdraftsV1.GetOverdraftLimit method.
+// When requesting the limit of a customer without an override, the application limit is returned and Handle is
+// empty.
+type GetOverdraftLimitResponse struct {
+	OverdraftLimit
+}
+
+// DeleteOverdraftLimitRequest is the input for the OverdraftsV1.DeleteOverdraftLimit method.
+type DeleteOverdraftLimitRequest struct {
+	// Application is the application the limit applies to.
+	Application string `json:"application"`
+
+	// Handle is the customer the limit applies to. If empty, the application limit is removed.
+	Handle string `json:"handle,omitempty"`
+}
+
+// DeleteOverdraftLimitResponse is the output of the OverdraftsV1.DeleteOverdraftLimit method.
+type DeleteOverdraftLimitResponse struct{}
@@ -0,0 +1,104 @@
+package api
+
+import (
+	"context"
+	"errors"
+)
+
+// PaymentsV1 holds the methods used to process the events sent by the payment provider. They're exposed through
+// a webhook endpoint that verifies the signature of the events, see PaymentSignatureHeader.
+type PaymentsV1 interface {
+	// ProcessPaymentEvent credits completed purchases and debits refunds and chargebacks.
+	ProcessPaymentEvent(ctx context.Context, req ProcessPaymentEventRequest) (ProcessPaymentEventResponse, error)
+}
+
+var (
+	// ErrMissingPaymentID is returned when a payment event without payment ID is passed in the request.
+	ErrMissingPaymentID = errors.New("missing payment id")
+	// ErrPaymentNotFound is returned when a refund or chargeback references an unknown payment.
+	ErrPaymentNotFound = errors.New("payment not found")
+)
+
+// PaymentSignatureHeader is the HTTP header containing the signature of a payment provider event. It uses the same
+// format as SignatureHeader, and is verified with VerifySignature.
+const PaymentSignatureHeader = "Stripe-Signature"
+
+// PaymentEventType is the type of event sent by the payment provider.
+type PaymentEventType string
+
+const (
+	// PaymentCompleted is sent when a purchase has been paid. The customer is credited the paid amount.
+	PaymentCompleted PaymentEventType = "checkout.session.completed"
+	// PaymentRefunded is sent when a purchase is refunded, totally or partially. The refunded amount is debited.
+	PaymentRefunded PaymentEventType = "charge.refunded"
+	// PaymentDisputed is sent when the customer opens a chargeback. The credits of the purchase that haven't been
+	// refunded yet are debited.
+	PaymentDisputed PaymentEventType = "charge.dispute.created"
+)
+
+// PaymentEventData contains the payment details of a payment provider event.
+type PaymentEventData struct {
+	// PaymentID is the identifier of the payment in the payment provider.
+	PaymentID string `json:"payment_id"`
+
+	// Handle is the customer that made the purchase.
+	Handle string `json:"handle"`
+
+	// Application is the application that credits are tracked for.
+	Application string `json:"application"`
+
+	// Amount is the amount paid for purchases, or the amount refunded for refunds.
+	Amount uint `json:"amount"`
+
+	// Currency is the currency of Amount.
+	Currency string `json:"currency"`
+}
+
+// ProcessPaymentEventRequest is the input for the PaymentsV1.ProcessPaymentEvent method. It's the JSON body sent
+// by the payment provider.
+type ProcessPaymentEventRequest struct {
+	// ID is the unique identifier of the event. Events are only processed once.
+	ID string `json:"id"`
+
+	// Type is the event type. Unknown types are ignored.
+	Type PaymentEventType `json:"type"`
+
+	// Data contains the payment details.
+	Data PaymentEventData `json:"data"`
+}
+
+// Validate validates the current request is valid.
+func (r ProcessPaymentEventRequest) Validate() error {
+	if len(r.ID) == 0 || len(r.Data.PaymentID) == 0 {
+		return ErrMissingPaymentID
+	}
+	if r.Type != PaymentCompleted {
+		return nil
+	}
+	if len(r.Data.Handle) == 0 {
+		return ErrHandleNotProvided
+	}
+	if len(r.Data.Application) == 0 {
+		return ErrMissingApplication
+	}
+	if r.Data.Amount == 0 {
+		return ErrInvalidAmount
+	}
+	if len(r.Data.Currency) == 0 || len(r.Data.Currency) > 3 {
+		return ErrInvalidCurrencyFormat
+	}
+	return nil
+}
+
+// ProcessPaymentEventResponse is the output of the PaymentsV1.ProcessPaymentEvent method.
+type ProcessPaymentEventResponse struct {
+	// Processed is false when the event was ignored, either because it was already processed or because its type
+	// is unknown.
+	Processed bool `json:"processed"`
+
+	// Credits is the amount of credits added to (positive) or removed from (negative) the customer.
+	Credits int `json:"credits"`
+
+	// Balance is the balance of the customer after processing the event.
+	Balance int `json:"balance,omitempty"`
+}
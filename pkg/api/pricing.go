@@ -0,0 +1,245 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// PricingV1 holds the methods that allow managing the catalog of billable resources (SKUs) of each application.
+type PricingV1 interface {
+	// CreateSKU adds a new SKU to the catalog of a certain application.
+	CreateSKU(ctx context.Context, req CreateSKURequest) (CreateSKUResponse, error)
+
+	// GetSKU returns a single SKU including all its price versions.
+	GetSKU(ctx context.Context, req GetSKURequest) (GetSKUResponse, error)
+
+	// ListSKUs returns all the SKUs of a certain application.
+	ListSKUs(ctx context.Context, req ListSKUsRequest) (ListSKUsResponse, error)
+
+	// UpdateSKU updates the description and unit of a certain SKU.
+	UpdateSKU(ctx context.Context, req UpdateSKURequest) (UpdateSKUResponse, error)
+
+	// DeleteSKU removes a SKU from the catalog.
+	DeleteSKU(ctx context.Context, req DeleteSKURequest) (DeleteSKUResponse, error)
+
+	// SetSKUPrice adds a new price version to a certain SKU that will be used from the given effective date.
+	SetSKUPrice(ctx context.Context, req SetSKUPriceRequest) (SetSKUPriceResponse, error)
+}
+
+var (
+	// ErrMissingSKU is returned when the SKU code is not provided in the request.
+	ErrMissingSKU = errors.New("missing sku")
+	// ErrSKUNotFound is returned when a SKU could not be found.
+	ErrSKUNotFound = errors.New("sku not found")
+	// ErrSKUAlreadyExists is returned when trying to create a SKU that already exists.
+	ErrSKUAlreadyExists = errors.New("sku already exists")
+	// ErrNoEffectivePrice is returned when a SKU has no price in effect.
+	ErrNoEffectivePrice = errors.New("no effective price")
+	// ErrInvalidEffectiveDate is returned when an effective date in the past is passed in the request.
+	ErrInvalidEffectiveDate = errors.New("invalid effective date")
+	// ErrInvalidQuantity is returned when an invalid quantity is passed in the request, including quantities whose
+	// cost is too large to be charged.
+	ErrInvalidQuantity = errors.New("invalid quantity")
+)
+
+// SKU is a billable resource with a credit cost per unit.
+type SKU struct {
+	// Application is the application that offers this SKU.
+	Application string `json:"application"`
+
+	// Code is the unique identifier of the SKU in the Application (e.g. "gpu-g4dn-xlarge").
+	Code string `json:"code"`
+
+	// Description is a human-readable description of the SKU.
+	Description string `json:"description"`
+
+	// Unit is the unit the SKU is billed by (e.g. "hour", "gb").
+	Unit string `json:"unit"`
+
+	// Price is the amount of credits of each Unit currently in effect. It's zero if no price is in effect yet.
+	Price uint `json:"price"`
+
+	// EffectiveFrom is the date the current Price has been in effect since.
+	EffectiveFrom *time.Time `json:"effective_from,omitempty"`
+
+	// Prices contains all the price versions of this SKU sorted by effective date.
+	Prices []SKUPrice `json:"prices,omitempty"`
+}
+
+// SKUPrice is a price version of a certain SKU.
+type SKUPrice struct {
+	// Price is the amount of credits of each unit.
+	Price uint `json:"price"`
+
+	// EffectiveFrom is the date this price is in effect since.
+	EffectiveFrom time.Time `json:"effective_from"`
+}
+
+// SKUIdentifier identifies a single SKU in a certain application.
+type SKUIdentifier struct {
+	// Application is the application that offers the SKU.
+	Application string `json:"application"`
+
+	// Code is the SKU code.
+	Code string `json:"code"`
+}
+
+// Validate validates the current identifier is valid.
+func (id SKUIdentifier) Validate() error {
+	if len(id.Application) == 0 {
+		return ErrMissingApplication
+	}
+	if len(id.Code) == 0 {
+		return ErrMissingSKU
+	}
+	return nil
+}
+
+// CreateSKURequest is the input for the PricingV1.CreateSKU method.
+type CreateSKURequest struct {
+	SKUIdentifier
+
+	// Description is a human-readable description of the SKU.
+	Description string `json:"description"`
+
+	// Unit is the unit the SKU is billed by (e.g. "hour", "gb").
+	Unit string `json:"unit"`
+
+	// Price is the amount of credits of each Unit.
+	Price uint `json:"price"`
+
+	// EffectiveFrom is the date the price is in effect since. Defaults to the current time.
+	EffectiveFrom *time.Time `json:"effective_from,omitempty"`
+}
+
+// Validate validates the current request is valid.
+func (r CreateSKURequest) Validate() error {
+	if err := r.SKUIdentifier.Validate(); err != nil {
+		return err
+	}
+	if r.Price == 0 {
+		return ErrInvalidPrice
+	}
+	return nil
+}
+
+// CreateSKUResponse is the output of the PricingV1.CreateSKU method.
+type CreateSKUResponse struct {
+	SKU
+}
+
+// GetSKURequest is the input for the PricingV1.GetSKU method.
+type GetSKURequest struct {
+	SKUIdentifier
+}
+
+// GetSKUResponse is the output of the PricingV1.GetSKU method.
+type GetSKUResponse struct {
+	SKU
+}
+
+// ListSKUsRequest is the input for the PricingV1.ListSKUs method.
+type ListSKUsRequest struct {
+	// Application is the application that offers the SKUs.
+	Application string `json:"application"`
+}
+
+// ListSKUsResponse is the output of the PricingV1.ListSKUs method.
+type ListSKUsResponse struct {
+	// SKUs contains the list of SKUs of the application, sorted by code.
+	SKUs []SKU `json:"skus"`
+}
+
+// UpdateSKURequest is the input for the PricingV1.UpdateSKU method.
+type UpdateSKURequest struct {
+	SKUIdentifier
+
+	// Description is a human-readable description of the SKU.
+	Description string `json:"description"`
+
+	// Unit is the unit the SKU is billed by (e.g. "hour", "gb").
+	Unit string `json:"unit"`
+}
+
+// UpdateSKUResponse is the output of the PricingV1.UpdateSKU method.
+type UpdateSKUResponse struct {
+	SKU
+}
+
+// DeleteSKURequest is the input for the PricingV1.DeleteSKU method.
+type DeleteSKURequest struct {
+	SKUIdentifier
+}
+
+// DeleteSKUResponse is the output of the PricingV1.DeleteSKU method.
+type DeleteSKUResponse struct{}
+
+// SetSKUPriceRequest is the input for the PricingV1.SetSKUPrice method.
+type SetSKUPriceRequest struct {
+	SKUIdentifier
+
+	// Price is the amount of credits of each unit.
+	Price uint `json:"price"`
+
+	// EffectiveFrom is the date the price will be in effect since. It cannot be in the past, prices that have
+	// already been used to charge customers cannot be changed. Defaults to the current time.
+	EffectiveFrom *time.Time `json:"effective_from,omitempty"`
+}
+
+// Validate validates the current request is valid.
+func (r SetSKUPriceRequest) Validate() error {
+	if err := r.SKUIdentifier.Validate(); err != nil {
+		return err
+	}
+	if r.Price == 0 {
+		return ErrInvalidPrice
+	}
+	return nil
+}
+
+// SetSKUPriceResponse is the output of the PricingV1.SetSKUPrice method.
+type SetSKUPriceResponse struct {
+	SKU
+}
+
+// ChargeRequest is the input for the CreditsV1.Charge method.
+type ChargeRequest struct {
+	// Handle is the username of the customer that will be charged.
+	Handle string `json:"handle"`
+
+	// Application is the application that credits are tracked for.
+	Application string `json:"application"`
+
+	// SKU is the code of the SKU being charged.
+	SKU string `json:"sku"`
+
+	// Quantity is the amount of units of the SKU being charged.
+	Quantity uint `json:"quantity"`
+}
+
+// Validate validates the current request is valid.
+func (r ChargeRequest) Validate() error {
+	if len(r.Handle) == 0 {
+		return ErrHandleNotProvided
+	}
+	if len(r.Application) == 0 {
+		return ErrMissingApplication
+	}
+	if len(r.SKU) == 0 {
+		return ErrMissingSKU
+	}
+	if r.Quantity == 0 {
+		return ErrInvalidQuantity
+	}
+	return nil
+}
+
+// ChargeResponse is the output of the CreditsV1.Charge method.
+type ChargeResponse struct {
+	// Price is the SKU price per unit used to compute the charge.
+	Price uint `json:"price"`
+
+	// Credits is the amount of credits that have been debited.
+	Credits uint `json:"credits"`
+}
@@ -0,0 +1,164 @@
+package api
+
+import (
+	"context"
+	"errors"
+)
+
+// PurchasePricingV1 holds the methods that allow configuring how many credits customers receive for the money they
+// pay. Applications without volume tiers nor packages convert money to credits with the flat conversion rate.
+type PurchasePricingV1 interface {
+	// SetVolumeTiers replaces the volume tiers of an application.
+	SetVolumeTiers(ctx context.Context, req SetVolumeTiersRequest) (SetVolumeTiersResponse, error)
+
+	// ListVolumeTiers returns the volume tiers of an application.
+	ListVolumeTiers(ctx context.Context, req ListVolumeTiersRequest) (ListVolumeTiersResponse, error)
+
+	// CreatePackage adds a new credit package to an application.
+	CreatePackage(ctx context.Context, req CreatePackageRequest) (CreatePackageResponse, error)
+
+	// DeletePackage removes a credit package from an application.
+	DeletePackage(ctx context.Context, req DeletePackageRequest) (DeletePackageResponse, error)
+
+	// ListPackages returns the credit packages available for an application.
+	ListPackages(ctx context.Context, req ListPackagesRequest) (ListPackagesResponse, error)
+}
+
+var (
+	// ErrInvalidVolumeTier is returned when a volume tier without minimum amount or bonus, or with the same minimum
+	// amount as another tier, is passed in the request.
+	ErrInvalidVolumeTier = errors.New("invalid volume tier")
+	// ErrPackageExists is returned when creating a package with a code or an amount already used by another
+	// package of the application.
+	ErrPackageExists = errors.New("package already exists")
+	// ErrPackageNotFound is returned when a package could not be found.
+	ErrPackageNotFound = errors.New("package not found")
+)
+
+// MaxPackageCodeLength is the maximum length of a package code.
+const MaxPackageCodeLength = 64
+
+// VolumeTier gives bonus credits to purchases of at least a certain amount. Purchases get the bonus of the highest
+// tier they reach.
+type VolumeTier struct {
+	// MinAmount is the minimum amount in the minimum currency value (e.g. cents for USD) a purchase must have to
+	// reach this tier. It's inclusive: a tier with 10000 applies to purchases of exactly $100, so a bonus for
+	// purchases of more than $100 needs 10001.
+	MinAmount uint `json:"min_amount"`
+
+	// BonusPercent is the percentage of extra credits added to the credits given by the conversion rate
+	// (e.g. 10 gives 10% more credits).
+	BonusPercent uint `json:"bonus_percent"`
+}
+
+// CreditPackage is a fixed amount of credits sold for a fixed price. Purchases of exactly the package amount receive
+// the package credits instead of the credits given by the conversion rate and the volume tiers.
+type CreditPackage struct {
+	// Application is the application that sells the package.
+	Application string `json:"application"`
+
+	// Code is the unique identifier of the package in the Application (e.g. "starter").
+	Code string `json:"code"`
+
+	// Name is a human-readable name of the package (e.g. "Starter").
+	Name string `json:"name,omitempty"`
+
+	// Credits is the amount of credits the package contains.
+	Credits uint `json:"credits"`
+
+	// Amount is the price of the package in the minimum currency value (e.g. cents for USD).
+	Amount uint `json:"amount"`
+}
+
+// SetVolumeTiersRequest is the input for the PurchasePricingV1.SetVolumeTiers method.
+type SetVolumeTiersRequest struct {
+	// Application is the application the tiers apply to.
+	Application string `json:"application"`
+
+	// Tiers contains the new volume tiers of the application. An empty list removes every tier.
+	Tiers []VolumeTier `json:"tiers"`
+}
+
+// Validate validates the current request is valid.
+func (r SetVolumeTiersRequest) Validate() error {
+	if len(r.Application) == 0 {
+		return ErrMissingApplication
+	}
+	amounts := make(map[uint]bool, len(r.Tiers))
+	for _, tier := range r.Tiers {
+		if tier.MinAmount == 0 || tier.BonusPercent == 0 || amounts[tier.MinAmount] {
+			return ErrInvalidVolumeTier
+		}
+		amounts[tier.MinAmount] = true
+	}
+	return nil
+}
+
+// SetVolumeTiersResponse is the output of the PurchasePricingV1.SetVolumeTiers method.
+type SetVolumeTiersResponse struct {
+	// Tiers contains the volume tiers of the application sorted by minimum amount.
+	Tiers []VolumeTier `json:"tiers"`
+}
+
+// ListVolumeTiersRequest is the input for the PurchasePricingV1.ListVolumeTiers method.
+type ListVolumeTiersRequest struct {
+	// Application is the application the tiers apply to.
+	Application string `json:"application"`
+}
+
+// ListVolumeTiersResponse is the output of the PurchasePricingV1.ListVolumeTiers method.
+type ListVolumeTiersResponse struct {
+	// Tiers contains the volume tiers of the application sorted by minimum amount.
+	Tiers []VolumeTier `json:"tiers"`
+}
+
+// CreatePackageRequest is the input for the PurchasePricingV1.CreatePackage method.
+type CreatePackageRequest struct {
+	CreditPackage
+}
+
+// Validate validates the current request is valid.
+func (r CreatePackageRequest) Validate() error {
+	if len(r.Application) == 0 {
+		return ErrMissingApplication
+	}
+	if len(r.Code) == 0 {
+		return ErrMissingCode
+	}
+	if len(r.Code) > MaxPackageCodeLength {
+		return ErrInvalidCode
+	}
+	if r.Credits == 0 || r.Amount == 0 {
+		return ErrInvalidAmount
+	}
+	return nil
+}
+
+// CreatePackageResponse is the output of the PurchasePricingV1.CreatePackage method.
+type CreatePackageResponse struct {
+	CreditPackage
+}
+
+// DeletePackageRequest is the input for the PurchasePricingV1.DeletePackage method.
+type DeletePackageRequest struct {
+	// Application is the application that sells the package.
+	Application string `json:"application"`
+
+	// Code is the package code.
+	Code string `json:"code"`
+}
+
+// DeletePackageResponse is the output of the PurchasePricingV1.DeletePackage method.
+type DeletePackageResponse struct{}
+
+// ListPackagesRequest is the input for the PurchasePricingV1.ListPackages method.
+type ListPackagesRequest struct {
+	// Application is the application that sells the packages.
+	Application string `json:"application"`
+}
+
+// ListPackagesResponse is the output of the PurchasePricingV1.ListPackages method.
+type ListPackagesResponse struct {
+	// Packages contains the packages of the application sorted by amount.
+	Packages []CreditPackage `json:"packages"`
+}
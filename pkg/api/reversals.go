@@ -0,0 +1,54 @@
+package api
+
+import "errors"
+
+var (
+	// ErrTransactionNotFound is returned when a transaction could not be found.
+	ErrTransactionNotFound = errors.New("transaction not found")
+	// ErrTransactionAlreadyReversed is returned when reversing a transaction that has already been fully reversed.
+	ErrTransactionAlreadyReversed = errors.New("transaction already reversed")
+	// ErrInvalidReversal is returned when reversing a transaction that cannot be reversed, such as a reversal, a
+	// transfer, a refund or a trial grant, or when reversing more credits than the ones left to reverse.
+	ErrInvalidReversal = errors.New("invalid reversal")
+)
+
+// ReverseTransactionRequest is the input for the CreditsV1.ReverseTransaction method.
+type ReverseTransactionRequest struct {
+	// TransactionID identifies the transaction to reverse.
+	TransactionID uint `json:"transaction_id"`
+
+	// Credits is the amount of credits to reverse. If zero, the credits that haven't been reversed yet are reversed.
+	Credits uint `json:"credits,omitempty"`
+}
+
+// Validate validates the current request is valid.
+func (r ReverseTransactionRequest) Validate() error {
+	if r.TransactionID == 0 {
+		return ErrTransactionNotFound
+	}
+	return nil
+}
+
+// ReverseTransactionResponse is the output of the CreditsV1.ReverseTransaction method.
+type ReverseTransactionResponse struct {
+	// TransactionID identifies the reversal transaction.
+	TransactionID uint `json:"transaction_id"`
+
+	// OriginalTransactionID identifies the reversed transaction.
+	OriginalTransactionID uint `json:"original_transaction_id"`
+
+	// Handle is the customer whose credits changed.
+	Handle string `json:"handle"`
+
+	// Application is the application that credits are tracked for.
+	Application string `json:"application"`
+
+	// Value is the amount of credits added (positive) or removed (negative) by the reversal.
+	Value int `json:"value"`
+
+	// Balance is the balance of the customer after applying the reversal.
+	Balance int `json:"balance"`
+
+	// Remaining is the amount of credits of the original transaction that can still be reversed.
+	Remaining uint `json:"remaining"`
+}
@@ -0,0 +1,151 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ScheduledOperationsV1 holds the methods that allow increasing or decreasing the credits of a customer at a future
+// time.
+type ScheduledOperationsV1 interface {
+	// ScheduleOperation schedules an operation to run at a future time.
+	ScheduleOperation(ctx context.Context, req ScheduleOperationRequest) (ScheduleOperationResponse, error)
+
+	// CancelScheduledOperation cancels an operation that hasn't run yet.
+	CancelScheduledOperation(ctx context.Context, req CancelScheduledOperationRequest) (CancelScheduledOperationResponse, error)
+
+	// ListScheduledOperations returns the scheduled operations of an application.
+	ListScheduledOperations(ctx context.Context, req ListScheduledOperationsRequest) (ListScheduledOperationsResponse, error)
+}
+
+var (
+	// ErrScheduledOperationNotFound is returned when a scheduled operation could not be found.
+	ErrScheduledOperationNotFound = errors.New("scheduled operation not found")
+	// ErrScheduledOperationNotPending is returned when canceling a scheduled operation that already ran or was
+	// canceled.
+	ErrScheduledOperationNotPending = errors.New("scheduled operation not pending")
+	// ErrMissingRunAt is returned when no run time is passed in the request.
+	ErrMissingRunAt = errors.New("missing run at")
+)
+
+// ScheduledOperationStatus is the status of a scheduled operation.
+type ScheduledOperationStatus string
+
+const (
+	// ScheduledOperationPending is used for operations that haven't run yet.
+	ScheduledOperationPending ScheduledOperationStatus = "pending"
+	// ScheduledOperationSucceeded is used for operations that changed the credits of the customer.
+	ScheduledOperationSucceeded ScheduledOperationStatus = "succeeded"
+	// ScheduledOperationFailed is used for operations that were rejected when they ran, e.g. because the customer
+	// didn't have enough credits.
+	ScheduledOperationFailed ScheduledOperationStatus = "failed"
+	// ScheduledOperationCanceled is used for operations canceled before running.
+	ScheduledOperationCanceled ScheduledOperationStatus = "canceled"
+)
+
+// ScheduledOperation is an operation that increases or decreases the credits of a customer at a certain time.
+type ScheduledOperation struct {
+	// ID is the unique identifier of the scheduled operation.
+	ID uint `json:"id"`
+
+	// Type is the type of operation. Only OperationIncrease and OperationDecrease can be scheduled.
+	Type OperationType `json:"type"`
+
+	// Transaction contains the customer and the amount of the operation.
+	Transaction
+
+	// RunAt is the time the operation runs at.
+	RunAt time.Time `json:"run_at"`
+
+	// Status is the status of the operation.
+	Status ScheduledOperationStatus `json:"status"`
+
+	// TransactionID is the ID of the transaction made by the operation. It's only set if the operation succeeded.
+	TransactionID uint `json:"transaction_id,omitempty"`
+
+	// Error is the reason the operation failed.
+	Error string `json:"error,omitempty"`
+
+	// ExecutedAt is the time the operation actually ran. It's later than RunAt if the scheduler wasn't running at
+	// RunAt.
+	ExecutedAt *time.Time `json:"executed_at,omitempty"`
+
+	// CreatedAt is the time the operation was scheduled.
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ScheduleOperationRequest is the input for the ScheduledOperationsV1.ScheduleOperation method.
+type ScheduleOperationRequest struct {
+	// Type is the type of operation. Only OperationIncrease and OperationDecrease can be scheduled.
+	Type OperationType `json:"type"`
+
+	// Transaction contains the customer and the amount of the operation. The amount is converted to credits when
+	// the operation runs.
+	Transaction
+
+	// RunAt is the time the operation runs at.
+	RunAt time.Time `json:"run_at"`
+}
+
+// Validate validates the current request is valid.
+func (r ScheduleOperationRequest) Validate() error {
+	if err := r.Transaction.Validate(); err != nil {
+		return err
+	}
+	if r.Type != OperationIncrease && r.Type != OperationDecrease {
+		return ErrInvalidOperationType
+	}
+	if r.RunAt.IsZero() {
+		return ErrMissingRunAt
+	}
+	return nil
+}
+
+// ScheduleOperationResponse is the output of the ScheduledOperationsV1.ScheduleOperation method.
+type ScheduleOperationResponse struct {
+	ScheduledOperation
+}
+
+// CancelScheduledOperationRequest is the input for the ScheduledOperationsV1.CancelScheduledOperation method.
+type CancelScheduledOperationRequest struct {
+	// ID is the scheduled operation identifier.
+	ID uint `json:"id"`
+}
+
+// CancelScheduledOperationResponse is the output of the ScheduledOperationsV1.CancelScheduledOperation method.
+type CancelScheduledOperationResponse struct {
+	ScheduledOperation
+}
+
+// ListScheduledOperationsRequest is the input for the ScheduledOperationsV1.ListScheduledOperations method.
+type ListScheduledOperationsRequest struct {
+	// Application is the application that credits are tracked for.
+	Application string `json:"application"`
+
+	// Handle filters the operations of a single customer. The operations of all the customers are returned if empty.
+	Handle string `json:"handle,omitempty"`
+
+	// Status filters the operations with the given status. Operations with any status are returned if empty.
+	Status ScheduledOperationStatus `json:"status,omitempty"`
+
+	// Limit is the maximum amount of operations returned, sorted by run time. Defaults to DefaultPageSize.
+	Limit int `json:"limit,omitempty"`
+}
+
+// Validate validates the current request is valid.
+func (r ListScheduledOperationsRequest) Validate() error {
+	if len(r.Application) == 0 {
+		return ErrMissingApplication
+	}
+	if r.Limit < 0 || r.Limit > MaxPageSize {
+		return ErrInvalidLimit
+	}
+	return nil
+}
+
+// ListScheduledOperationsResponse is the output of the ScheduledOperationsV1.ListScheduledOperations method.
+type ListScheduledOperationsResponse struct {
+	// Operations contains the scheduled operations, sorted by run time.
+	Operations []ScheduledOperation `json:"operations"`
+}
@@ -0,0 +1,176 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// SessionsV1 holds the methods that allow metering the usage of a certain resource billed by time.
+type SessionsV1 interface {
+	// OpenSession opens a new usage session for a given user. The user will be charged periodically
+	// while the session is open.
+	OpenSession(ctx context.Context, req OpenSessionRequest) (OpenSessionResponse, error)
+
+	// SendHeartbeat notifies that the resource metered by a certain session is still in use.
+	SendHeartbeat(ctx context.Context, req SendHeartbeatRequest) (SendHeartbeatResponse, error)
+
+	// CloseSession closes an open session, charging the remaining usage.
+	CloseSession(ctx context.Context, req CloseSessionRequest) (CloseSessionResponse, error)
+
+	// GetSession returns the current state of a certain session.
+	GetSession(ctx context.Context, req GetSessionRequest) (GetSessionResponse, error)
+}
+
+var (
+	// ErrInvalidPrice is returned when an invalid price is passed in the request.
+	ErrInvalidPrice = errors.New("invalid price")
+	// ErrInvalidTimeUnit is returned when an invalid time unit is passed in the request.
+	ErrInvalidTimeUnit = errors.New("invalid time unit")
+	// ErrSessionNotFound is returned when a session could not be found.
+	ErrSessionNotFound = errors.New("session not found")
+	// ErrSessionClosed is returned when trying to operate on a session that has already been closed.
+	ErrSessionClosed = errors.New("session closed")
+)
+
+// SessionStatus represents the status of a usage session.
+type SessionStatus string
+
+const (
+	// SessionOpen is used for sessions that are currently being metered.
+	SessionOpen SessionStatus = "open"
+	// SessionClosed is used for sessions that are no longer being metered.
+	SessionClosed SessionStatus = "closed"
+)
+
+const (
+	// CloseReasonRequested is used when a session was closed by the client.
+	CloseReasonRequested = "requested"
+	// CloseReasonInsufficientCredits is used when a session was closed because the customer ran out of spendable
+	// credits, or couldn't pay for the usage within its overdraft and spending limits.
+	CloseReasonInsufficientCredits = "insufficient_credits"
+	// CloseReasonCustomerInactive is used when a session was closed because the customer was frozen or closed.
+	CloseReasonCustomerInactive = "customer_inactive"
+	// CloseReasonHeartbeatTimeout is used when a session was closed because no heartbeats were received.
+	CloseReasonHeartbeatTimeout = "heartbeat_timeout"
+)
+
+// Session contains the state of a usage session.
+type Session struct {
+	// ID is the unique identifier of the session.
+	ID uint `json:"id"`
+
+	// Handle is the username of the customer being charged for this session.
+	Handle string `json:"handle"`
+
+	// Application is the application that credits are tracked for.
+	Application string `json:"application"`
+
+	// Price is the amount of credits charged for each Unit of time.
+	Price uint `json:"price"`
+
+	// Unit is the time unit used to charge the session (e.g. "1m", "1h").
+	Unit string `json:"unit"`
+
+	// Status is the current status of the session.
+	Status SessionStatus `json:"status"`
+
+	// CloseReason contains the reason why the session was closed. It's empty for open sessions.
+	CloseReason string `json:"close_reason,omitempty"`
+
+	// Charged is the total amount of credits charged so far.
+	Charged uint `json:"charged"`
+
+	// StartedAt is the time the session was opened.
+	StartedAt time.Time `json:"started_at"`
+
+	// LastHeartbeatAt is the time the last heartbeat was received.
+	LastHeartbeatAt time.Time `json:"last_heartbeat_at"`
+
+	// ClosedAt is the time the session was closed. It's nil for open sessions.
+	ClosedAt *time.Time `json:"closed_at,omitempty"`
+}
+
+// OpenSessionRequest is the input for the SessionsV1.OpenSession method.
+type OpenSessionRequest struct {
+	// Handle is the username of the customer that will be charged.
+	Handle string `json:"handle"`
+
+	// Application is the application that credits are tracked for.
+	Application string `json:"application"`
+
+	// Price is the amount of credits charged for each Unit of time.
+	Price uint `json:"price"`
+
+	// Unit is the time unit used to charge the session. It should be a valid duration string (e.g. "1m", "1h").
+	Unit string `json:"unit"`
+
+	// NotifyURL is an optional URL that will receive a POST request with the Session as JSON body when the
+	// session is closed by the credits service.
+	NotifyURL string `json:"notify_url,omitempty"`
+}
+
+// Validate validates the current request is valid.
+func (r OpenSessionRequest) Validate() error {
+	if len(r.Handle) == 0 {
+		return ErrHandleNotProvided
+	}
+	if len(r.Application) == 0 {
+		return ErrMissingApplication
+	}
+	if r.Price == 0 {
+		return ErrInvalidPrice
+	}
+	if _, err := ParseTimeUnit(r.Unit); err != nil {
+		return err
+	}
+	return nil
+}
+
+// ParseTimeUnit parses the given time unit. It returns ErrInvalidTimeUnit if the unit is not a positive duration.
+func ParseTimeUnit(unit string) (time.Duration, error) {
+	d, err := time.ParseDuration(unit)
+	if err != nil || d <= 0 {
+		return 0, ErrInvalidTimeUnit
+	}
+	return d, nil
+}
+
+// OpenSessionResponse is the output of the SessionsV1.OpenSession method.
+type OpenSessionResponse struct {
+	Session
+}
+
+// SendHeartbeatRequest is the input for the SessionsV1.SendHeartbeat method.
+type SendHeartbeatRequest struct {
+	// ID is the session identifier.
+	ID uint `json:"id"`
+}
+
+// SendHeartbeatResponse is the output of the SessionsV1.SendHeartbeat method.
+// Clients should stop using the metered resource if the returned session is closed.
+type SendHeartbeatResponse struct {
+	Session
+}
+
+// CloseSessionRequest is the input for the SessionsV1.CloseSession method.
+type CloseSessionRequest struct {
+	// ID is the session identifier.
+	ID uint `json:"id"`
+}
+
+// CloseSessionResponse is the output of the SessionsV1.CloseSession method.
+type CloseSessionResponse struct {
+	Session
+}
+
+// GetSessionRequest is the input for the SessionsV1.GetSession method.
+type GetSessionRequest struct {
+	// ID is the session identifier.
+	ID uint `json:"id"`
+}
+
+// GetSessionResponse is the output of the SessionsV1.GetSession method.
+type GetSessionResponse struct {
+	Session
+}
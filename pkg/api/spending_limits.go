@@ -0,0 +1,187 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// SpendingLimitsV1 holds the methods that allow capping how fast customers can spend their credits.
+type SpendingLimitsV1 interface {
+	// SetSpendingLimits sets the spending limits of an application, or of a single customer.
+	SetSpendingLimits(ctx context.Context, req SetSpendingLimitsRequest) (SetSpendingLimitsResponse, error)
+
+	// GetSpendingLimits returns the spending limits that apply to an application or to a single customer.
+	GetSpendingLimits(ctx context.Context, req GetSpendingLimitsRequest) (GetSpendingLimitsResponse, error)
+
+	// DeleteSpendingLimits removes the spending limits of an application, or of a single customer.
+	DeleteSpendingLimits(ctx context.Context, req DeleteSpendingLimitsRequest) (DeleteSpendingLimitsResponse, error)
+
+	// GetSpendingUsage returns the current usage of a customer against each of its spending caps.
+	GetSpendingUsage(ctx context.Context, req GetSpendingUsageRequest) (GetSpendingUsageResponse, error)
+}
+
+var (
+	// ErrSpendingLimitsNotFound is returned when no spending limits have been set.
+	ErrSpendingLimitsNotFound = errors.New("spending limits not found")
+	// ErrSpendingLimitExceeded is returned when a decrease would exceed one of the spending caps of a customer.
+	ErrSpendingLimitExceeded = errors.New("spending limit exceeded")
+)
+
+// SpendingCap identifies a single cap of the spending limits.
+type SpendingCap string
+
+const (
+	// CapCreditsPerHour caps the credits spent in the last hour. Decreases, charges, session charges and outgoing
+	// transfers spend credits.
+	CapCreditsPerHour SpendingCap = "credits_per_hour"
+	// CapCreditsPerDay caps the credits spent in the last 24 hours.
+	CapCreditsPerDay SpendingCap = "credits_per_day"
+	// CapDecreasesPerMinute caps the amount of debits that spent credits in the last minute.
+	CapDecreasesPerMinute SpendingCap = "decreases_per_minute"
+)
+
+// Window returns the sliding window the cap is computed over.
+func (c SpendingCap) Window() time.Duration {
+	switch c {
+	case CapCreditsPerHour:
+		return time.Hour
+	case CapCreditsPerDay:
+		return 24 * time.Hour
+	default:
+		return time.Minute
+	}
+}
+
+// SpendingLimits contains the caps applied by CreditsV1.DecreaseCredits. Zero values disable the cap.
+type SpendingLimits struct {
+	// Application is the application the limits apply to.
+	Application string `json:"application"`
+
+	// Handle is the customer the limits apply to. It's empty for the application default.
+	Handle string `json:"handle,omitempty"`
+
+	// CreditsPerHour is the maximum amount of credits decreased in the last hour.
+	CreditsPerHour uint `json:"credits_per_hour,omitempty"`
+
+	// CreditsPerDay is the maximum amount of credits decreased in the last 24 hours.
+	CreditsPerDay uint `json:"credits_per_day,omitempty"`
+
+	// DecreasesPerMinute is the maximum amount of decreases made in the last minute.
+	DecreasesPerMinute uint `json:"decreases_per_minute,omitempty"`
+}
+
+// Caps returns the enabled caps with their limit.
+func (l SpendingLimits) Caps() map[SpendingCap]uint {
+	out := make(map[SpendingCap]uint)
+	if l.CreditsPerHour > 0 {
+		out[CapCreditsPerHour] = l.CreditsPerHour
+	}
+	if l.CreditsPerDay > 0 {
+		out[CapCreditsPerDay] = l.CreditsPerDay
+	}
+	if l.DecreasesPerMinute > 0 {
+		out[CapDecreasesPerMinute] = l.DecreasesPerMinute
+	}
+	return out
+}
+
+// SpendingUsage is the usage of a customer against a single spending cap.
+type SpendingUsage struct {
+	// Cap is the spending cap.
+	Cap SpendingCap `json:"cap"`
+
+	// Limit is the maximum value allowed by the cap.
+	Limit uint `json:"limit"`
+
+	// Used is the current value, computed over the window of the cap.
+	Used uint `json:"used"`
+}
+
+// SpendingLimitError is returned when a decrease would exceed a spending cap. It wraps ErrSpendingLimitExceeded.
+type SpendingLimitError struct {
+	SpendingUsage
+}
+
+// Error returns the error message.
+func (e *SpendingLimitError) Error() string {
+	return fmt.Sprintf("%s: %s (used %d of %d)", ErrSpendingLimitExceeded, e.Cap, e.Used, e.Limit)
+}
+
+// Unwrap returns ErrSpendingLimitExceeded.
+func (e *SpendingLimitError) Unwrap() error {
+	return ErrSpendingLimitExceeded
+}
+
+// SetSpendingLimitsRequest is the input for the SpendingLimitsV1.SetSpendingLimits method.
+type SetSpendingLimitsRequest struct {
+	SpendingLimits
+}
+
+// Validate validates the current request is valid.
+func (r SetSpendingLimitsRequest) Validate() error {
+	if len(r.Application) == 0 {
+		return ErrMissingApplication
+	}
+	return nil
+}
+
+// SetSpendingLimitsResponse is the output of the SpendingLimitsV1.SetSpendingLimits method.
+type SetSpendingLimitsResponse struct {
+	SpendingLimits
+}
+
+// GetSpendingLimitsRequest is the input for the SpendingLimitsV1.GetSpendingLimits method.
+type GetSpendingLimitsRequest struct {
+	// Application is the application the limits apply to.
+	Application string `json:"application"`
+
+	// Handle is the customer the limits apply to. If empty, the application limits are returned.
+	Handle string `json:"handle,omitempty"`
+}
+
+// GetSpendingLimitsResponse is the output of the SpendingLimitsV1.GetSpendingLimits method.
+// When requesting the limits of a customer without an override, the application limits are returned and Handle is
+// empty.
+type GetSpendingLimitsResponse struct {
+	SpendingLimits
+}
+
+// DeleteSpendingLimitsRequest is the input for the SpendingLimitsV1.DeleteSpendingLimits method.
+type DeleteSpendingLimitsRequest struct {
+	// Application is the application the limits apply to.
+	Application string `json:"application"`
+
+	// Handle is the customer the limits apply to. If empty, the application limits are removed.
+	Handle string `json:"handle,omitempty"`
+}
+
+// DeleteSpendingLimitsResponse is the output of the SpendingLimitsV1.DeleteSpendingLimits method.
+type DeleteSpendingLimitsResponse struct{}
+
+// GetSpendingUsageRequest is the input for the SpendingLimitsV1.GetSpendingUsage method.
+type GetSpendingUsageRequest struct {
+	// Handle is the customer handle.
+	Handle string `json:"handle"`
+
+	// Application is the application that credits are tracked for.
+	Application string `json:"application"`
+}
+
+// Validate validates the current request is valid.
+func (r GetSpendingUsageRequest) Validate() error {
+	if len(r.Handle) == 0 {
+		return ErrHandleNotProvided
+	}
+	if len(r.Application) == 0 {
+		return ErrMissingApplication
+	}
+	return nil
+}
+
+// GetSpendingUsageResponse is the output of the SpendingLimitsV1.GetSpendingUsage method.
+type GetSpendingUsageResponse struct {
+	// Usage contains the usage against each enabled cap of the customer. It's empty if the customer has no caps.
+	Usage []SpendingUsage `json:"usage"`
+}
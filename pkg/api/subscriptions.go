@@ -0,0 +1,265 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// SubscriptionsV1 holds the methods that allow granting recurring credit allowances to customers.
+type SubscriptionsV1 interface {
+	// CreateSubscription creates a new subscription that grants credits to a customer every period.
+	CreateSubscription(ctx context.Context, req CreateSubscriptionRequest) (CreateSubscriptionResponse, error)
+
+	// CancelSubscription cancels a subscription. No more credits are granted after canceling it.
+	CancelSubscription(ctx context.Context, req CancelSubscriptionRequest) (CancelSubscriptionResponse, error)
+
+	// ListSubscriptions returns the subscriptions of a customer.
+	ListSubscriptions(ctx context.Context, req ListSubscriptionsRequest) (ListSubscriptionsResponse, error)
+
+	// ListSubscriptionGrants returns the latest grants of a subscription.
+	ListSubscriptionGrants(ctx context.Context, req ListSubscriptionGrantsRequest) (ListSubscriptionGrantsResponse, error)
+}
+
+var (
+	// ErrSubscriptionNotFound is returned when a subscription could not be found.
+	ErrSubscriptionNotFound = errors.New("subscription not found")
+	// ErrSubscriptionCanceled is returned when trying to cancel a subscription that has already been canceled.
+	ErrSubscriptionCanceled = errors.New("subscription canceled")
+	// ErrInvalidSchedule is returned when an unknown subscription schedule is passed in the request.
+	ErrInvalidSchedule = errors.New("invalid schedule")
+	// ErrInvalidRollover is returned when an unknown rollover rule is passed in the request.
+	ErrInvalidRollover = errors.New("invalid rollover")
+)
+
+// SubscriptionSchedule is how often a subscription grants credits.
+type SubscriptionSchedule string
+
+const (
+	// ScheduleDaily grants credits every day.
+	ScheduleDaily SubscriptionSchedule = "daily"
+	// ScheduleWeekly grants credits every week.
+	ScheduleWeekly SubscriptionSchedule = "weekly"
+	// ScheduleMonthly grants credits every month, on the same day of the month as the start of the subscription. The
+	// last day of the month is used for months that are too short.
+	ScheduleMonthly SubscriptionSchedule = "monthly"
+)
+
+// Validate validates the current schedule is valid.
+func (s SubscriptionSchedule) Validate() error {
+	switch s {
+	case ScheduleDaily, ScheduleWeekly, ScheduleMonthly:
+		return nil
+	default:
+		return ErrInvalidSchedule
+	}
+}
+
+// PeriodStart returns the start of the period n of a subscription that started at the given time. Periods are
+// computed from the start of the subscription, so they don't drift over time.
+func (s SubscriptionSchedule) PeriodStart(start time.Time, n uint) time.Time {
+	switch s {
+	case ScheduleDaily:
+		return start.AddDate(0, 0, int(n))
+	case ScheduleWeekly:
+		return start.AddDate(0, 0, 7*int(n))
+	default:
+		y, m, d := start.Date()
+		first := time.Date(y, m+time.Month(n), 1, start.Hour(), start.Minute(), start.Second(), start.Nanosecond(), start.Location())
+		if last := first.AddDate(0, 1, -1).Day(); d > last {
+			d = last
+		}
+		return first.AddDate(0, 0, d-1)
+	}
+}
+
+// RolloverRule defines what happens to the unused credits of a period when a new period starts.
+type RolloverRule string
+
+const (
+	// RolloverReset expires the unused credits of the previous period.
+	RolloverReset RolloverRule = "reset"
+	// RolloverCapped carries the unused credits of the previous period over, up to the rollover cap. The rest of
+	// them expire.
+	RolloverCapped RolloverRule = "rollover"
+)
+
+// Validate validates the current rollover rule is valid.
+func (r RolloverRule) Validate() error {
+	switch r {
+	case RolloverReset, RolloverCapped:
+		return nil
+	default:
+		return ErrInvalidRollover
+	}
+}
+
+// SubscriptionStatus is the status of a subscription.
+type SubscriptionStatus string
+
+const (
+	// SubscriptionActive is used for subscriptions that grant credits every period.
+	SubscriptionActive SubscriptionStatus = "active"
+	// SubscriptionCanceled is used for subscriptions that don't grant credits anymore.
+	SubscriptionCanceled SubscriptionStatus = "canceled"
+)
+
+// Subscription grants a fixed amount of credits to a customer every period. Credits granted by a subscription are
+// considered spent before any other credits of the customer.
+type Subscription struct {
+	// ID is the unique identifier of the subscription.
+	ID uint `json:"id"`
+
+	// Handle is the customer that receives the credits.
+	Handle string `json:"handle"`
+
+	// Application is the application that credits are tracked for.
+	Application string `json:"application"`
+
+	// Credits is the amount of credits granted every period.
+	Credits uint `json:"credits"`
+
+	// Schedule is how often credits are granted.
+	Schedule SubscriptionSchedule `json:"schedule"`
+
+	// Rollover is what happens to the unused credits when a new period starts.
+	Rollover RolloverRule `json:"rollover"`
+
+	// RolloverCap is the maximum amount of unused credits carried over to the next period. Only used with
+	// RolloverCapped.
+	RolloverCap uint `json:"rollover_cap,omitempty"`
+
+	// Status is the status of the subscription.
+	Status SubscriptionStatus `json:"status"`
+
+	// StartAt is the time the first period starts.
+	StartAt time.Time `json:"start_at"`
+
+	// Periods is the amount of periods granted so far.
+	Periods uint `json:"periods"`
+
+	// NextGrantAt is the time the next period starts.
+	NextGrantAt time.Time `json:"next_grant_at"`
+
+	// Allowance is the amount of credits granted by the subscription that the customer had after the last grant,
+	// including the credits carried over.
+	Allowance uint `json:"allowance"`
+}
+
+// SubscriptionGrant is the grant of a single period of a subscription.
+type SubscriptionGrant struct {
+	// Period is the index of the period, starting at 0.
+	Period uint `json:"period"`
+
+	// PeriodStart is the time the period started.
+	PeriodStart time.Time `json:"period_start"`
+
+	// Granted is the amount of credits granted. It's zero if the customer couldn't receive credits when the period
+	// started.
+	Granted uint `json:"granted"`
+
+	// Carried is the amount of unused credits carried over from the previous period.
+	Carried uint `json:"carried"`
+
+	// Expired is the amount of unused credits of the previous period that expired.
+	Expired uint `json:"expired"`
+
+	// CreatedAt is the time the grant was made.
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// CreateSubscriptionRequest is the input for the SubscriptionsV1.CreateSubscription method.
+type CreateSubscriptionRequest struct {
+	// Handle is the customer that receives the credits.
+	Handle string `json:"handle"`
+
+	// Application is the application that credits are tracked for.
+	Application string `json:"application"`
+
+	// Credits is the amount of credits granted every period.
+	Credits uint `json:"credits"`
+
+	// Schedule is how often credits are granted.
+	Schedule SubscriptionSchedule `json:"schedule"`
+
+	// Rollover is what happens to the unused credits when a new period starts.
+	Rollover RolloverRule `json:"rollover"`
+
+	// RolloverCap is the maximum amount of unused credits carried over to the next period. Only used with
+	// RolloverCapped.
+	RolloverCap uint `json:"rollover_cap,omitempty"`
+
+	// StartAt is the time the first period starts. Defaults to now.
+	StartAt *time.Time `json:"start_at,omitempty"`
+}
+
+// Validate validates the current request is valid.
+func (r CreateSubscriptionRequest) Validate() error {
+	if len(r.Handle) == 0 {
+		return ErrHandleNotProvided
+	}
+	if len(r.Application) == 0 {
+		return ErrMissingApplication
+	}
+	if r.Credits == 0 {
+		return ErrInvalidAmount
+	}
+	if err := r.Schedule.Validate(); err != nil {
+		return err
+	}
+	return r.Rollover.Validate()
+}
+
+// CreateSubscriptionResponse is the output of the SubscriptionsV1.CreateSubscription method.
+type CreateSubscriptionResponse struct {
+	Subscription
+}
+
+// CancelSubscriptionRequest is the input for the SubscriptionsV1.CancelSubscription method.
+type CancelSubscriptionRequest struct {
+	// ID is the subscription identifier.
+	ID uint `json:"id"`
+}
+
+// CancelSubscriptionResponse is the output of the SubscriptionsV1.CancelSubscription method.
+type CancelSubscriptionResponse struct {
+	Subscription
+}
+
+// ListSubscriptionsRequest is the input for the SubscriptionsV1.ListSubscriptions method.
+type ListSubscriptionsRequest struct {
+	// Handle is the customer handle.
+	Handle string `json:"handle"`
+
+	// Application is the application that credits are tracked for.
+	Application string `json:"application"`
+}
+
+// ListSubscriptionsResponse is the output of the SubscriptionsV1.ListSubscriptions method.
+type ListSubscriptionsResponse struct {
+	// Subscriptions contains the subscriptions of the customer.
+	Subscriptions []Subscription `json:"subscriptions"`
+}
+
+// ListSubscriptionGrantsRequest is the input for the SubscriptionsV1.ListSubscriptionGrants method.
+type ListSubscriptionGrantsRequest struct {
+	// ID is the subscription identifier.
+	ID uint `json:"id"`
+
+	// Limit is the maximum amount of grants returned, newest first. Defaults to DefaultPageSize.
+	Limit int `json:"limit,omitempty"`
+}
+
+// Validate validates the current request is valid.
+func (r ListSubscriptionGrantsRequest) Validate() error {
+	if r.Limit < 0 || r.Limit > MaxPageSize {
+		return ErrInvalidLimit
+	}
+	return nil
+}
+
+// ListSubscriptionGrantsResponse is the output of the SubscriptionsV1.ListSubscriptionGrants method.
+type ListSubscriptionGrantsResponse struct {
+	// Grants contains the grants of the subscription, newest first.
+	Grants []SubscriptionGrant `json:"grants"`
+}
@@ -0,0 +1,101 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ThresholdsV1 holds the methods that allow configuring the low balance alerts of each application.
+type ThresholdsV1 interface {
+	// SetLowBalanceThreshold sets the low balance threshold of an application, or of a single customer.
+	SetLowBalanceThreshold(ctx context.Context, req SetLowBalanceThresholdRequest) (SetLowBalanceThresholdResponse, error)
+
+	// GetLowBalanceThreshold returns the low balance threshold that applies to an application or to a single customer.
+	GetLowBalanceThreshold(ctx context.Context, req GetLowBalanceThresholdRequest) (GetLowBalanceThresholdResponse, error)
+
+	// DeleteLowBalanceThreshold removes the low balance threshold of an application, or of a single customer.
+	DeleteLowBalanceThreshold(ctx context.Context, req DeleteLowBalanceThresholdRequest) (DeleteLowBalanceThresholdResponse, error)
+}
+
+var (
+	// ErrThresholdNotFound is returned when no low balance threshold has been set.
+	ErrThresholdNotFound = errors.New("threshold not found")
+)
+
+// LowBalanceThreshold is the balance below which customers are alerted.
+type LowBalanceThreshold struct {
+	// Application is the application the threshold applies to.
+	Application string `json:"application"`
+
+	// Handle is the customer the threshold applies to. It's empty for the application default.
+	Handle string `json:"handle,omitempty"`
+
+	// Threshold is the balance below which customers are alerted.
+	Threshold int `json:"threshold"`
+}
+
+// LowBalanceAlert is sent when the balance of a customer drops below its low balance threshold. A single alert is
+// sent per crossing, customers are not alerted again until their balance recovers to the threshold or above.
+type LowBalanceAlert struct {
+	// Handle is the customer whose balance crossed the threshold.
+	Handle string `json:"handle"`
+
+	// Application is the application that credits are tracked for.
+	Application string `json:"application"`
+
+	// Threshold is the threshold that was crossed.
+	Threshold int `json:"threshold"`
+
+	// Balance is the balance of the customer after crossing the threshold.
+	Balance int `json:"balance"`
+
+	// CreatedAt is the time the threshold was crossed.
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// SetLowBalanceThresholdRequest is the input for the ThresholdsV1.SetLowBalanceThreshold method.
+type SetLowBalanceThresholdRequest struct {
+	LowBalanceThreshold
+}
+
+// Validate validates the current request is valid.
+func (r SetLowBalanceThresholdRequest) Validate() error {
+	if len(r.Application) == 0 {
+		return ErrMissingApplication
+	}
+	return nil
+}
+
+// SetLowBalanceThresholdResponse is the output of the ThresholdsV1.SetLowBalanceThreshold method.
+type SetLowBalanceThresholdResponse struct {
+	LowBalanceThreshold
+}
+
+// GetLowBalanceThresholdRequest is the input for the ThresholdsV1.GetLowBalanceThreshold method.
+type GetLowBalanceThresholdRequest struct {
+	// Application is the application the threshold applies to.
+	Application string `json:"application"`
+
+	// Handle is the customer the threshold applies to. If empty, the application threshold is returned.
+	Handle string `json:"handle,omitempty"`
+}
+
+// GetLowBalanceThresholdResponse is the output of the ThresholdsV1.GetLowBalanceThreshold method.
+// When requesting the threshold of a customer without an override, the application threshold is returned and
+// Handle is empty.
+type GetLowBalanceThresholdResponse struct {
+	LowBalanceThreshold
+}
+
+// DeleteLowBalanceThresholdRequest is the input for the ThresholdsV1.DeleteLowBalanceThreshold method.
+type DeleteLowBalanceThresholdRequest struct {
+	// Application is the application the threshold applies to.
+	Application string `json:"application"`
+
+	// Handle is the customer the threshold applies to. If empty, the application threshold is removed.
+	Handle string `json:"handle,omitempty"`
+}
+
+// DeleteLowBalanceThresholdResponse is the output of the ThresholdsV1.DeleteLowBalanceThreshold method.
+type DeleteLowBalanceThresholdResponse struct{}
@@ -0,0 +1,173 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// TopUpsV1 holds the methods that allow configuring the automatic top-ups of customers.
+type TopUpsV1 interface {
+	// SetAutoTopUp enables or updates the automatic top-ups of a customer.
+	SetAutoTopUp(ctx context.Context, req SetAutoTopUpRequest) (SetAutoTopUpResponse, error)
+
+	// GetAutoTopUp returns the automatic top-up settings of a customer.
+	GetAutoTopUp(ctx context.Context, req GetAutoTopUpRequest) (GetAutoTopUpResponse, error)
+
+	// DisableAutoTopUp disables the automatic top-ups of a customer.
+	DisableAutoTopUp(ctx context.Context, req DisableAutoTopUpRequest) (DisableAutoTopUpResponse, error)
+
+	// ListTopUps returns the latest top-ups of a customer.
+	ListTopUps(ctx context.Context, req ListTopUpsRequest) (ListTopUpsResponse, error)
+}
+
+var (
+	// ErrAutoTopUpNotFound is returned when a customer doesn't have automatic top-ups enabled.
+	ErrAutoTopUpNotFound = errors.New("auto top-up not found")
+	// ErrInvalidDailyLimit is returned when an invalid amount of top-ups per day is passed in the request.
+	ErrInvalidDailyLimit = errors.New("invalid daily limit")
+)
+
+// TopUpStatus is the status of an automatic top-up.
+type TopUpStatus string
+
+const (
+	// TopUpPending is used for top-ups whose payment hasn't been completed yet. Payments that fail without being
+	// declined (e.g. timeouts) keep their top-up pending until they are retried.
+	TopUpPending TopUpStatus = "pending"
+	// TopUpSucceeded is used for top-ups that have been paid and credited.
+	TopUpSucceeded TopUpStatus = "succeeded"
+	// TopUpFailed is used for top-ups whose payment has been rejected by the payment provider.
+	TopUpFailed TopUpStatus = "failed"
+)
+
+// AutoTopUp contains the automatic top-up settings of a customer.
+type AutoTopUp struct {
+	// Handle is the customer that is topped up.
+	Handle string `json:"handle"`
+
+	// Application is the application that credits are tracked for.
+	Application string `json:"application"`
+
+	// Threshold is the balance below which the customer is topped up.
+	Threshold int `json:"threshold"`
+
+	// Amount is the amount of money charged on every top-up. Credits are added using the current conversion rate.
+	Amount uint `json:"amount"`
+
+	// Currency is the currency of Amount.
+	Currency string `json:"currency"`
+
+	// MaxPerDay is the maximum amount of top-ups in a 24 hours period, including failed top-ups.
+	MaxPerDay int `json:"max_per_day"`
+
+	// PaymentMethod identifies the payment method charged in the payment provider.
+	PaymentMethod string `json:"payment_method"`
+}
+
+// TopUp is a single automatic top-up.
+type TopUp struct {
+	// ID is the unique identifier of the top-up.
+	ID uint `json:"id"`
+
+	// Handle is the customer that was topped up.
+	Handle string `json:"handle"`
+
+	// Application is the application that credits are tracked for.
+	Application string `json:"application"`
+
+	// Amount is the amount of money charged.
+	Amount uint `json:"amount"`
+
+	// Currency is the currency of Amount.
+	Currency string `json:"currency"`
+
+	// Credits is the amount of credits added to the customer.
+	Credits uint `json:"credits"`
+
+	// Status is the top-up status.
+	Status TopUpStatus `json:"status"`
+
+	// PaymentID is the identifier of the payment in the payment provider.
+	PaymentID string `json:"payment_id,omitempty"`
+
+	// Error contains the reason a failed top-up failed, or the last error of a pending top-up being retried.
+	Error string `json:"error,omitempty"`
+
+	// CreatedAt is the time the top-up was started.
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// SetAutoTopUpRequest is the input for the TopUpsV1.SetAutoTopUp method.
+type SetAutoTopUpRequest struct {
+	AutoTopUp
+}
+
+// Validate validates the current request is valid.
+func (r SetAutoTopUpRequest) Validate() error {
+	if len(r.Handle) == 0 {
+		return ErrHandleNotProvided
+	}
+	if len(r.Application) == 0 {
+		return ErrMissingApplication
+	}
+	if r.Amount == 0 {
+		return ErrInvalidAmount
+	}
+	if len(r.Currency) == 0 || len(r.Currency) > 3 {
+		return ErrInvalidCurrencyFormat
+	}
+	if r.MaxPerDay <= 0 {
+		return ErrInvalidDailyLimit
+	}
+	return nil
+}
+
+// SetAutoTopUpResponse is the output of the TopUpsV1.SetAutoTopUp method.
+type SetAutoTopUpResponse struct {
+	AutoTopUp
+}
+
+// GetAutoTopUpRequest is the input for the TopUpsV1.GetAutoTopUp method.
+type GetAutoTopUpRequest struct {
+	// Handle is the customer handle.
+	Handle string `json:"handle"`
+
+	// Application is the application that credits are tracked for.
+	Application string `json:"application"`
+}
+
+// GetAutoTopUpResponse is the output of the TopUpsV1.GetAutoTopUp method.
+type GetAutoTopUpResponse struct {
+	AutoTopUp
+}
+
+// DisableAutoTopUpRequest is the input for the TopUpsV1.DisableAutoTopUp method.
+type DisableAutoTopUpRequest struct {
+	// Handle is the customer handle.
+	Handle string `json:"handle"`
+
+	// Application is the application that credits are tracked for.
+	Application string `json:"application"`
+}
+
+// DisableAutoTopUpResponse is the output of the TopUpsV1.DisableAutoTopUp method.
+type DisableAutoTopUpResponse struct{}
+
+// ListTopUpsRequest is the input for the TopUpsV1.ListTopUps method.
+type ListTopUpsRequest struct {
+	// Handle is the customer handle.
+	Handle string `json:"handle"`
+
+	// Application is the application that credits are tracked for.
+	Application string `json:"application"`
+
+	// Limit is the maximum amount of top-ups returned, newest first. Defaults to DefaultPageSize.
+	Limit int `json:"limit,omitempty"`
+}
+
+// ListTopUpsResponse is the output of the TopUpsV1.ListTopUps method.
+type ListTopUpsResponse struct {
+	// TopUps contains the top-ups of the customer, newest first.
+	TopUps []TopUp `json:"top_ups"`
+}
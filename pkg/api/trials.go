@@ -0,0 +1,162 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// TrialsV1 holds the methods that allow granting welcome credits to new customers.
+type TrialsV1 interface {
+	// SetTrialPolicy sets the welcome credits granted to the new customers of an application.
+	SetTrialPolicy(ctx context.Context, req SetTrialPolicyRequest) (SetTrialPolicyResponse, error)
+
+	// GetTrialPolicy returns the trial policy of an application.
+	GetTrialPolicy(ctx context.Context, req GetTrialPolicyRequest) (GetTrialPolicyResponse, error)
+
+	// DeleteTrialPolicy removes the trial policy of an application. New customers don't receive welcome credits
+	// after removing it.
+	DeleteTrialPolicy(ctx context.Context, req DeleteTrialPolicyRequest) (DeleteTrialPolicyResponse, error)
+
+	// GetTrialGrant returns the welcome credits granted to a customer.
+	GetTrialGrant(ctx context.Context, req GetTrialGrantRequest) (GetTrialGrantResponse, error)
+
+	// RecordHandleChange records that a customer changed its handle, so the new handle doesn't receive the welcome
+	// credits again.
+	RecordHandleChange(ctx context.Context, req RecordHandleChangeRequest) (RecordHandleChangeResponse, error)
+}
+
+var (
+	// ErrTrialPolicyNotFound is returned when an application has no trial policy.
+	ErrTrialPolicyNotFound = errors.New("trial policy not found")
+	// ErrTrialGrantNotFound is returned when a customer never received welcome credits.
+	ErrTrialGrantNotFound = errors.New("trial grant not found")
+	// ErrMissingPreviousHandle is returned when no previous handle is passed in the request.
+	ErrMissingPreviousHandle = errors.New("missing previous handle")
+)
+
+// TrialPolicy defines the welcome credits granted to a customer when it's created by its first credit operation.
+type TrialPolicy struct {
+	// Application is the application the policy applies to.
+	Application string `json:"application"`
+
+	// Credits is the amount of credits granted to new customers.
+	Credits uint `json:"credits"`
+
+	// ExpiresIn is the amount of seconds the welcome credits last. The unused welcome credits are removed after
+	// that time. Welcome credits never expire if it's zero.
+	ExpiresIn uint `json:"expires_in,omitempty"`
+}
+
+// TrialGrant contains the welcome credits granted to a customer. Grants are kept after the customer is gone, so
+// a handle can only receive the welcome credits once.
+type TrialGrant struct {
+	// Handle is the customer that received the credits.
+	Handle string `json:"handle"`
+
+	// Application is the application that credits are tracked for.
+	Application string `json:"application"`
+
+	// PreviousHandle is the handle the customer had when it received the credits. It's only set for handle changes,
+	// in which case Credits is zero.
+	PreviousHandle string `json:"previous_handle,omitempty"`
+
+	// Credits is the amount of credits granted.
+	Credits uint `json:"credits"`
+
+	// ExpiresAt is the time the unused credits expire. It's nil if the credits never expire.
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+
+	// ExpiredAt is the time the unused credits were removed. It's nil until then.
+	ExpiredAt *time.Time `json:"expired_at,omitempty"`
+
+	// Expired is the amount of unused credits removed when the credits expired.
+	Expired uint `json:"expired,omitempty"`
+
+	// CreatedAt is the time the credits were granted.
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// SetTrialPolicyRequest is the input for the TrialsV1.SetTrialPolicy method.
+type SetTrialPolicyRequest struct {
+	TrialPolicy
+}
+
+// Validate validates the current request is valid.
+func (r SetTrialPolicyRequest) Validate() error {
+	if len(r.Application) == 0 {
+		return ErrMissingApplication
+	}
+	if r.Credits == 0 {
+		return ErrInvalidAmount
+	}
+	return nil
+}
+
+// SetTrialPolicyResponse is the output of the TrialsV1.SetTrialPolicy method.
+type SetTrialPolicyResponse struct {
+	TrialPolicy
+}
+
+// GetTrialPolicyRequest is the input for the TrialsV1.GetTrialPolicy method.
+type GetTrialPolicyRequest struct {
+	// Application is the application the policy applies to.
+	Application string `json:"application"`
+}
+
+// GetTrialPolicyResponse is the output of the TrialsV1.GetTrialPolicy method.
+type GetTrialPolicyResponse struct {
+	TrialPolicy
+}
+
+// DeleteTrialPolicyRequest is the input for the TrialsV1.DeleteTrialPolicy method.
+type DeleteTrialPolicyRequest struct {
+	// Application is the application the policy applies to.
+	Application string `json:"application"`
+}
+
+// DeleteTrialPolicyResponse is the output of the TrialsV1.DeleteTrialPolicy method.
+type DeleteTrialPolicyResponse struct{}
+
+// GetTrialGrantRequest is the input for the TrialsV1.GetTrialGrant method.
+type GetTrialGrantRequest struct {
+	// Handle is the customer handle.
+	Handle string `json:"handle"`
+
+	// Application is the application that credits are tracked for.
+	Application string `json:"application"`
+}
+
+// GetTrialGrantResponse is the output of the TrialsV1.GetTrialGrant method.
+type GetTrialGrantResponse struct {
+	TrialGrant
+}
+
+// RecordHandleChangeRequest is the input for the TrialsV1.RecordHandleChange method.
+type RecordHandleChangeRequest struct {
+	// Application is the application that credits are tracked for.
+	Application string `json:"application"`
+
+	// PreviousHandle is the handle the customer had before the change.
+	PreviousHandle string `json:"previous_handle"`
+
+	// Handle is the new handle of the customer.
+	Handle string `json:"handle"`
+}
+
+// Validate validates the current request is valid.
+func (r RecordHandleChangeRequest) Validate() error {
+	if len(r.Application) == 0 {
+		return ErrMissingApplication
+	}
+	if len(r.PreviousHandle) == 0 {
+		return ErrMissingPreviousHandle
+	}
+	if len(r.Handle) == 0 {
+		return ErrHandleNotProvided
+	}
+	return nil
+}
+
+// RecordHandleChangeResponse is the output of the TrialsV1.RecordHandleChange method.
+type RecordHandleChangeResponse struct{}
@@ -0,0 +1,212 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// WalletsV1 holds the methods that allow several customers to share the credits of a group wallet. A wallet is a
+// regular customer whose credits can be spent by its members.
+type WalletsV1 interface {
+	// SetWalletMember adds a member to a wallet, or updates its allowance if it's already a member.
+	SetWalletMember(ctx context.Context, req SetWalletMemberRequest) (SetWalletMemberResponse, error)
+
+	// RemoveWalletMember removes a member from a wallet.
+	RemoveWalletMember(ctx context.Context, req RemoveWalletMemberRequest) (RemoveWalletMemberResponse, error)
+
+	// ListWalletMembers returns the members of a wallet.
+	ListWalletMembers(ctx context.Context, req ListWalletMembersRequest) (ListWalletMembersResponse, error)
+
+	// SpendFromWallet decreases the credits of a wallet on behalf of one of its members.
+	SpendFromWallet(ctx context.Context, req SpendFromWalletRequest) (SpendFromWalletResponse, error)
+
+	// ListWalletSpending returns the latest spending of a wallet, attributed to the members that made it.
+	ListWalletSpending(ctx context.Context, req ListWalletSpendingRequest) (ListWalletSpendingResponse, error)
+}
+
+var (
+	// ErrMissingWallet is returned when no wallet handle is passed in the request.
+	ErrMissingWallet = errors.New("missing wallet")
+	// ErrInvalidMember is returned when a wallet is added as a member of itself.
+	ErrInvalidMember = errors.New("invalid member")
+	// ErrNotWalletMember is returned when a handle is not a member of the wallet.
+	ErrNotWalletMember = errors.New("not a wallet member")
+	// ErrAllowanceExceeded is returned when spending would exceed the allowance of a wallet member.
+	ErrAllowanceExceeded = errors.New("allowance exceeded")
+)
+
+// WalletMember is a customer allowed to spend the credits of a wallet.
+type WalletMember struct {
+	// Application is the application that credits are tracked for.
+	Application string `json:"application"`
+
+	// Wallet is the handle of the wallet.
+	Wallet string `json:"wallet"`
+
+	// Member is the handle of the member.
+	Member string `json:"member"`
+
+	// Allowance is the maximum amount of credits the member can spend from the wallet. The member can spend
+	// without limit if it's nil.
+	Allowance *uint `json:"allowance,omitempty"`
+
+	// Spent is the amount of credits the member has spent from the wallet.
+	Spent uint `json:"spent"`
+}
+
+// WalletSpend is a single decrease of the credits of a wallet made by one of its members.
+type WalletSpend struct {
+	// TransactionID is the ID of the transaction that decreased the credits of the wallet.
+	TransactionID uint `json:"transaction_id"`
+
+	// Member is the handle of the member that spent the credits.
+	Member string `json:"member"`
+
+	// Credits is the amount of credits spent.
+	Credits uint `json:"credits"`
+
+	// ReversedCredits is the amount of the spent credits returned to the wallet by reversals.
+	ReversedCredits uint `json:"reversed_credits,omitempty"`
+
+	// CreatedAt is the time the credits were spent.
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// SetWalletMemberRequest is the input for the WalletsV1.SetWalletMember method.
+type SetWalletMemberRequest struct {
+	// Application is the application that credits are tracked for.
+	Application string `json:"application"`
+
+	// Wallet is the handle of the wallet.
+	Wallet string `json:"wallet"`
+
+	// Member is the handle of the member.
+	Member string `json:"member"`
+
+	// Allowance is the maximum amount of credits the member can spend from the wallet. The member can spend
+	// without limit if it's nil.
+	Allowance *uint `json:"allowance,omitempty"`
+}
+
+// Validate validates the current request is valid.
+func (r SetWalletMemberRequest) Validate() error {
+	if len(r.Application) == 0 {
+		return ErrMissingApplication
+	}
+	if len(r.Wallet) == 0 {
+		return ErrMissingWallet
+	}
+	if len(r.Member) == 0 {
+		return ErrHandleNotProvided
+	}
+	if r.Member == r.Wallet {
+		return ErrInvalidMember
+	}
+	return nil
+}
+
+// SetWalletMemberResponse is the output of the WalletsV1.SetWalletMember method.
+type SetWalletMemberResponse struct {
+	WalletMember
+}
+
+// RemoveWalletMemberRequest is the input for the WalletsV1.RemoveWalletMember method.
+type RemoveWalletMemberRequest struct {
+	// Application is the application that credits are tracked for.
+	Application string `json:"application"`
+
+	// Wallet is the handle of the wallet.
+	Wallet string `json:"wallet"`
+
+	// Member is the handle of the member.
+	Member string `json:"member"`
+}
+
+// RemoveWalletMemberResponse is the output of the WalletsV1.RemoveWalletMember method.
+type RemoveWalletMemberResponse struct{}
+
+// ListWalletMembersRequest is the input for the WalletsV1.ListWalletMembers method.
+type ListWalletMembersRequest struct {
+	// Application is the application that credits are tracked for.
+	Application string `json:"application"`
+
+	// Wallet is the handle of the wallet.
+	Wallet string `json:"wallet"`
+}
+
+// ListWalletMembersResponse is the output of the WalletsV1.ListWalletMembers method.
+type ListWalletMembersResponse struct {
+	// Members contains the members of the wallet.
+	Members []WalletMember `json:"members"`
+}
+
+// SpendFromWalletRequest is the input for the WalletsV1.SpendFromWallet method.
+type SpendFromWalletRequest struct {
+	// Transaction contains the member spending the credits and the amount spent.
+	Transaction
+
+	// Wallet is the handle of the wallet the credits are spent from.
+	Wallet string `json:"wallet"`
+}
+
+// Validate validates the current request is valid.
+func (r SpendFromWalletRequest) Validate() error {
+	if err := r.Transaction.Validate(); err != nil {
+		return err
+	}
+	if len(r.Wallet) == 0 {
+		return ErrMissingWallet
+	}
+	return nil
+}
+
+// SpendFromWalletResponse is the output of the WalletsV1.SpendFromWallet method.
+type SpendFromWalletResponse struct {
+	// TransactionID is the ID of the transaction that decreased the credits of the wallet.
+	TransactionID uint `json:"transaction_id"`
+
+	// Balance is the balance of the wallet after spending the credits.
+	Balance int `json:"balance"`
+
+	// Credits is the amount of credits spent.
+	Credits uint `json:"credits"`
+
+	// Member contains the member after spending the credits.
+	Member WalletMember `json:"member"`
+}
+
+// ListWalletSpendingRequest is the input for the WalletsV1.ListWalletSpending method.
+type ListWalletSpendingRequest struct {
+	// Application is the application that credits are tracked for.
+	Application string `json:"application"`
+
+	// Wallet is the handle of the wallet.
+	Wallet string `json:"wallet"`
+
+	// Member filters the spending of a single member. The spending of all the members is returned if empty.
+	Member string `json:"member,omitempty"`
+
+	// Limit is the maximum amount of spends returned, newest first. Defaults to DefaultPageSize.
+	Limit int `json:"limit,omitempty"`
+}
+
+// Validate validates the current request is valid.
+func (r ListWalletSpendingRequest) Validate() error {
+	if len(r.Application) == 0 {
+		return ErrMissingApplication
+	}
+	if len(r.Wallet) == 0 {
+		return ErrMissingWallet
+	}
+	if r.Limit < 0 || r.Limit > MaxPageSize {
+		return ErrInvalidLimit
+	}
+	return nil
+}
+
+// ListWalletSpendingResponse is the output of the WalletsV1.ListWalletSpending method.
+type ListWalletSpendingResponse struct {
+	// Spending contains the spending of the wallet, newest first.
+	Spending []WalletSpend `json:"spending"`
+}
@@ -0,0 +1,314 @@
+package api
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// WebhooksV1 holds the methods that allow applications to receive notifications about balance events.
+type WebhooksV1 interface {
+	// CreateWebhook registers a new URL that will receive the events of a certain application.
+	CreateWebhook(ctx context.Context, req CreateWebhookRequest) (CreateWebhookResponse, error)
+
+	// ListWebhooks returns the webhooks registered for a certain application.
+	ListWebhooks(ctx context.Context, req ListWebhooksRequest) (ListWebhooksResponse, error)
+
+	// DeleteWebhook removes a webhook. Pending deliveries of the webhook are discarded.
+	DeleteWebhook(ctx context.Context, req DeleteWebhookRequest) (DeleteWebhookResponse, error)
+
+	// ListWebhookDeliveries returns the delivery log of a certain webhook.
+	ListWebhookDeliveries(ctx context.Context, req ListWebhookDeliveriesRequest) (ListWebhookDeliveriesResponse, error)
+
+	// ReplayWebhookDelivery schedules a delivery to be sent again.
+	ReplayWebhookDelivery(ctx context.Context, req ReplayWebhookDeliveryRequest) (ReplayWebhookDeliveryResponse, error)
+}
+
+var (
+	// ErrInvalidURL is returned when an invalid URL is passed in the request.
+	ErrInvalidURL = errors.New("invalid url")
+	// ErrInvalidEventType is returned when an unknown event type is passed in the request.
+	ErrInvalidEventType = errors.New("invalid event type")
+	// ErrWebhookNotFound is returned when a webhook could not be found.
+	ErrWebhookNotFound = errors.New("webhook not found")
+	// ErrDeliveryNotFound is returned when a webhook delivery could not be found.
+	ErrDeliveryNotFound = errors.New("delivery not found")
+	// ErrInvalidSignature is returned when the signature of a webhook payload cannot be verified.
+	ErrInvalidSignature = errors.New("invalid signature")
+)
+
+// EventType is the type of event sent to webhooks.
+type EventType string
+
+const (
+	// EventCreditsIncreased is sent when credits are added to a customer.
+	EventCreditsIncreased EventType = "credits.increased"
+	// EventCreditsDecreased is sent when credits are removed from a customer.
+	EventCreditsDecreased EventType = "credits.decreased"
+	// EventBalanceLow is sent when the balance of a customer drops below its low balance threshold. See ThresholdsV1.
+	EventBalanceLow EventType = "balance.low"
+	// EventBalanceDepleted is sent when the balance of a customer drops to zero or below.
+	EventBalanceDepleted EventType = "balance.depleted"
+	// EventCreditsUpdated is published to the event sink for every balance change. It's not sent to webhooks.
+	EventCreditsUpdated EventType = "credits.updated"
+)
+
+// Validate validates the current event type is valid.
+func (e EventType) Validate() error {
+	switch e {
+	case EventCreditsIncreased, EventCreditsDecreased, EventBalanceLow, EventBalanceDepleted:
+		return nil
+	default:
+		return ErrInvalidEventType
+	}
+}
+
+// DeliveryStatus is the status of a webhook delivery.
+type DeliveryStatus string
+
+const (
+	// DeliveryPending is used for deliveries that haven't been delivered yet but will be attempted again.
+	DeliveryPending DeliveryStatus = "pending"
+	// DeliverySucceeded is used for deliveries that have been accepted by the receiver.
+	DeliverySucceeded DeliveryStatus = "succeeded"
+	// DeliveryFailed is used for deliveries that exhausted all their attempts. They can be replayed.
+	DeliveryFailed DeliveryStatus = "failed"
+)
+
+const (
+	// SignatureHeader is the HTTP header containing the signature of a webhook payload.
+	// Its value has the following format: t=<unix timestamp>,v1=<hex encoded HMAC-SHA256>.
+	SignatureHeader = "X-Credits-Signature"
+	// EventHeader is the HTTP header containing the EventType of a webhook payload.
+	EventHeader = "X-Credits-Event"
+	// DeliveryHeader is the HTTP header containing the ID of a webhook delivery.
+	DeliveryHeader = "X-Credits-Delivery"
+)
+
+// SignPayload returns the signature header value of the given payload signed at the given time.
+// The HMAC-SHA256 is computed over "<unix timestamp>.<payload>" using secret as key.
+func SignPayload(secret string, timestamp time.Time, payload []byte) string {
+	t := strconv.FormatInt(timestamp.Unix(), 10)
+	return fmt.Sprintf("t=%s,v1=%s", t, computeSignature(secret, t, payload))
+}
+
+// VerifySignature verifies that the given signature header has been generated for payload using secret.
+// Signatures older than tolerance are rejected, a zero tolerance disables this check.
+func VerifySignature(secret, header string, payload []byte, tolerance time.Duration) error {
+	var t, v1 string
+	for _, part := range strings.Split(header, ",") {
+		kv := strings.SplitN(strings.TrimSpace(part), "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		switch kv[0] {
+		case "t":
+			t = kv[1]
+		case "v1":
+			v1 = kv[1]
+		}
+	}
+	if len(t) == 0 || len(v1) == 0 {
+		return ErrInvalidSignature
+	}
+
+	if tolerance > 0 {
+		unix, err := strconv.ParseInt(t, 10, 64)
+		if err != nil {
+			return ErrInvalidSignature
+		}
+		if age := time.Since(time.Unix(unix, 0)); age > tolerance || age < -tolerance {
+			return ErrInvalidSignature
+		}
+	}
+
+	expected, err := hex.DecodeString(computeSignature(secret, t, payload))
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	actual, err := hex.DecodeString(v1)
+	if err != nil || !hmac.Equal(expected, actual) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// computeSignature returns the hex encoded HMAC-SHA256 of "<timestamp>.<payload>".
+func computeSignature(secret, timestamp string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Event is the JSON body sent to webhooks and event sinks.
+type Event struct {
+	// ID is the unique identifier of the event.
+	ID string `json:"id"`
+
+	// Type is the event type.
+	Type EventType `json:"type"`
+
+	// CreatedAt is the time the event happened.
+	CreatedAt time.Time `json:"created_at"`
+
+	// Data contains the balance change that triggered the event.
+	Data EventData `json:"data"`
+}
+
+// EventData contains the details of a balance change.
+type EventData struct {
+	// Handle is the username of the customer whose balance changed.
+	Handle string `json:"handle"`
+
+	// Application is the application that credits are tracked for.
+	Application string `json:"application"`
+
+	// Operation is the operation that changed the balance (e.g. "increase", "charge").
+	Operation string `json:"operation"`
+
+	// Value is the amount of credits added (positive) or removed (negative).
+	Value int `json:"value"`
+
+	// Balance is the balance of the customer after the change.
+	Balance int `json:"balance"`
+}
+
+// Webhook is a URL that receives the events of a certain application.
+type Webhook struct {
+	// ID is the unique identifier of the webhook.
+	ID uint `json:"id"`
+
+	// Application is the application whose events are sent to the webhook.
+	Application string `json:"application"`
+
+	// URL is the URL that receives the events.
+	URL string `json:"url"`
+
+	// Secret is the key used to sign the events sent to this webhook. See VerifySignature. It's only returned when
+	// creating the webhook.
+	Secret string `json:"secret,omitempty"`
+
+	// Events contains the event types sent to the webhook. All events are sent if empty.
+	Events []EventType `json:"events"`
+}
+
+// CreateWebhookRequest is the input for the WebhooksV1.CreateWebhook method.
+type CreateWebhookRequest struct {
+	// Application is the application whose events are sent to the webhook.
+	Application string `json:"application"`
+
+	// URL is the URL that receives the events. It must be an absolute HTTP(S) URL.
+	URL string `json:"url"`
+
+	// Events contains the event types sent to the webhook. All events are sent if empty.
+	Events []EventType `json:"events,omitempty"`
+}
+
+// Validate validates the current request is valid.
+func (r CreateWebhookRequest) Validate() error {
+	if len(r.Application) == 0 {
+		return ErrMissingApplication
+	}
+	if !strings.HasPrefix(r.URL, "http://") && !strings.HasPrefix(r.URL, "https://") {
+		return ErrInvalidURL
+	}
+	for _, e := range r.Events {
+		if err := e.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CreateWebhookResponse is the output of the WebhooksV1.CreateWebhook method.
+type CreateWebhookResponse struct {
+	Webhook
+}
+
+// ListWebhooksRequest is the input for the WebhooksV1.ListWebhooks method.
+type ListWebhooksRequest struct {
+	// Application is the application whose webhooks are returned.
+	Application string `json:"application"`
+}
+
+// ListWebhooksResponse is the output of the WebhooksV1.ListWebhooks method.
+type ListWebhooksResponse struct {
+	// Webhooks contains the webhooks of the application, without their secrets.
+	Webhooks []Webhook `json:"webhooks"`
+}
+
+// DeleteWebhookRequest is the input for the WebhooksV1.DeleteWebhook method.
+type DeleteWebhookRequest struct {
+	// ID is the webhook identifier.
+	ID uint `json:"id"`
+}
+
+// DeleteWebhookResponse is the output of the WebhooksV1.DeleteWebhook method.
+type DeleteWebhookResponse struct{}
+
+// WebhookDelivery is a single attempt to send an event to a webhook.
+type WebhookDelivery struct {
+	// ID is the unique identifier of the delivery.
+	ID uint `json:"id"`
+
+	// WebhookID is the webhook the event is sent to.
+	WebhookID uint `json:"webhook_id"`
+
+	// Event contains the event sent to the webhook.
+	Event Event `json:"event"`
+
+	// Status is the delivery status.
+	Status DeliveryStatus `json:"status"`
+
+	// Attempts is the amount of times the delivery has been attempted.
+	Attempts int `json:"attempts"`
+
+	// NextAttemptAt is the time the next attempt will be made for pending deliveries.
+	NextAttemptAt time.Time `json:"next_attempt_at"`
+
+	// ResponseCode is the HTTP status code returned by the receiver in the last attempt.
+	ResponseCode int `json:"response_code,omitempty"`
+
+	// LastError contains the error of the last failed attempt.
+	LastError string `json:"last_error,omitempty"`
+
+	// DeliveredAt is the time the event was accepted by the receiver.
+	DeliveredAt *time.Time `json:"delivered_at,omitempty"`
+}
+
+// ListWebhookDeliveriesRequest is the input for the WebhooksV1.ListWebhookDeliveries method.
+type ListWebhookDeliveriesRequest struct {
+	// WebhookID is the webhook identifier.
+	WebhookID uint `json:"webhook_id"`
+
+	// Status filters deliveries by status. All deliveries are returned if empty.
+	Status DeliveryStatus `json:"status,omitempty"`
+
+	// Limit is the maximum amount of deliveries returned, newest first. Defaults to DefaultPageSize.
+	Limit int `json:"limit,omitempty"`
+}
+
+// ListWebhookDeliveriesResponse is the output of the WebhooksV1.ListWebhookDeliveries method.
+type ListWebhookDeliveriesResponse struct {
+	// Deliveries contains the deliveries of the webhook, newest first.
+	Deliveries []WebhookDelivery `json:"deliveries"`
+}
+
+// ReplayWebhookDeliveryRequest is the input for the WebhooksV1.ReplayWebhookDelivery method.
+type ReplayWebhookDeliveryRequest struct {
+	// ID is the delivery identifier.
+	ID uint `json:"id"`
+}
+
+// ReplayWebhookDeliveryResponse is the output of the WebhooksV1.ReplayWebhookDelivery method.
+type ReplayWebhookDeliveryResponse struct {
+	WebhookDelivery
+}
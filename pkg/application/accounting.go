@@ -0,0 +1,202 @@
+package application
+
+import (
+	"context"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/api"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/domain/models"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/domain/persistence"
+	"gorm.io/gorm"
+)
+
+// ListAccounts returns the accounts of an application.
+func (s *service) ListAccounts(ctx context.Context, req api.ListAccountsRequest) (api.ListAccountsResponse, error) {
+	if err := req.Validate(); err != nil {
+		return api.ListAccountsResponse{}, err
+	}
+
+	list, err := persistence.GetAccounts(s.db, req.Application, string(req.Type))
+	if err != nil {
+		return api.ListAccountsResponse{}, err
+	}
+
+	out := api.ListAccountsResponse{Accounts: make([]api.Account, len(list))}
+	for i, a := range list {
+		out.Accounts[i] = toAccountAPI(a)
+	}
+	return out, nil
+}
+
+// ListEntries returns the latest entries posted in an account.
+func (s *service) ListEntries(ctx context.Context, req api.ListEntriesRequest) (api.ListEntriesResponse, error) {
+	if req.Limit < 0 || req.Limit > api.MaxPageSize {
+		return api.ListEntriesResponse{}, api.ErrInvalidLimit
+	}
+	limit := req.Limit
+	if limit == 0 {
+		limit = api.DefaultPageSize
+	}
+
+	if _, err := persistence.GetAccount(s.db, req.AccountID); err == gorm.ErrRecordNotFound {
+		return api.ListEntriesResponse{}, api.ErrAccountNotFound
+	} else if err != nil {
+		return api.ListEntriesResponse{}, err
+	}
+
+	list, err := persistence.GetEntries(s.db, req.AccountID, limit)
+	if err != nil {
+		return api.ListEntriesResponse{}, err
+	}
+
+	out := api.ListEntriesResponse{Entries: make([]api.Entry, len(list))}
+	for i, e := range list {
+		out.Entries[i] = toEntryAPI(e)
+	}
+	return out, nil
+}
+
+// CheckLedger verifies that the entries of an application add up to zero and that the balance of every account
+// matches the sum of its entries.
+func (s *service) CheckLedger(ctx context.Context, req api.CheckLedgerRequest) (api.CheckLedgerResponse, error) {
+	if len(req.Application) == 0 {
+		return api.CheckLedgerResponse{}, api.ErrMissingApplication
+	}
+
+	accounts, err := persistence.GetAccounts(s.db, req.Application, "")
+	if err != nil {
+		return api.CheckLedgerResponse{}, err
+	}
+
+	sums, err := persistence.SumEntriesByAccount(s.db, req.Application)
+	if err != nil {
+		return api.CheckLedgerResponse{}, err
+	}
+
+	entries := make(map[uint]int, len(sums))
+	var out api.CheckLedgerResponse
+	for _, sum := range sums {
+		entries[sum.AccountID] = sum.Sum
+		out.Sum += sum.Sum
+	}
+
+	for _, a := range accounts {
+		if a.Balance != entries[a.ID] {
+			out.Mismatches = append(out.Mismatches, api.AccountMismatch{
+				Account:    toAccountAPI(a),
+				EntriesSum: entries[a.ID],
+			})
+		}
+	}
+
+	out.Balanced = out.Sum == 0 && len(out.Mismatches) == 0
+	if !out.Balanced {
+		s.logger.Println("Unbalanced ledger:", req.Application, "Sum:", out.Sum, "Mismatches:", len(out.Mismatches))
+	}
+	return out, nil
+}
+
+// counterpartAccount returns the type of the system account used as counterpart of a customer account for a
+// balance change of the given operation and value.
+func counterpartAccount(operation string, value int) string {
+	switch operation {
+	case models.OperationTransfer:
+		return models.AccountTransfers
+	case models.OperationPromotion, models.OperationAllowance, models.OperationTrial:
+		return models.AccountPromotions
+	case models.OperationExpiration:
+		return models.AccountExpired
+	case models.OperationDecrease, models.OperationCharge, models.OperationSession:
+		return models.AccountRevenue
+	case models.OperationOpening, models.OperationIncrease, models.OperationPurchase, models.OperationRefund,
+		models.OperationChargeback:
+		return models.AccountIssuance
+	}
+	if value < 0 {
+		return models.AccountRevenue
+	}
+	return models.AccountIssuance
+}
+
+// postEntries posts the balanced entries of the given balance change: one in the customer account and the
+// opposite one in the given counterpart account. Customer accounts are created on their first change, with an
+// opening entry for the credits the customer already had.
+func postEntries(tx *gorm.DB, change models.BalanceChange, counterpart string) error {
+	customer, created, err := persistence.FirstOrCreateAccount(tx, models.Account{
+		Application: change.Application,
+		Type:        models.AccountCustomer,
+		Handle:      change.Handle,
+	})
+	if err != nil {
+		return err
+	}
+
+	if created {
+		if opening := change.Balance - change.Value; opening != 0 {
+			if err = postPair(tx, nil, customer, models.AccountIssuance, opening); err != nil {
+				return err
+			}
+		}
+	}
+
+	return postPair(tx, &change.ID, customer, counterpart, change.Value)
+}
+
+// postPair posts value in the given customer account and its opposite in the counterpart system account of the
+// same application.
+func postPair(tx *gorm.DB, transactionID *uint, customer models.Account, counterpart string, value int) error {
+	system, _, err := persistence.FirstOrCreateAccount(tx, models.Account{
+		Application: customer.Application,
+		Type:        counterpart,
+	})
+	if err != nil {
+		return err
+	}
+
+	return persistence.PostEntries(tx, []models.Entry{
+		{TransactionID: transactionID, AccountID: customer.ID, Amount: value},
+		{TransactionID: transactionID, AccountID: system.ID, Amount: -value},
+	})
+}
+
+// transactionCounterpart returns the type of the counterpart account used by a previous transaction. It falls back
+// to counterpartAccount for transactions posted before the ledger existed.
+func transactionCounterpart(tx *gorm.DB, change models.BalanceChange) (string, error) {
+	entries, err := persistence.GetTransactionEntries(tx, change.ID)
+	if err != nil {
+		return "", err
+	}
+	for _, e := range entries {
+		account, err := persistence.GetAccount(tx, e.AccountID)
+		if err != nil {
+			return "", err
+		}
+		if account.Type != models.AccountCustomer {
+			return account.Type, nil
+		}
+	}
+	return counterpartAccount(change.Operation, change.Value), nil
+}
+
+// toAccountAPI converts the given account model into its API representation.
+func toAccountAPI(a models.Account) api.Account {
+	return api.Account{
+		ID:          a.ID,
+		Application: a.Application,
+		Type:        api.AccountType(a.Type),
+		Handle:      a.Handle,
+		Balance:     a.Balance,
+	}
+}
+
+// toEntryAPI converts the given entry model into its API representation.
+func toEntryAPI(e models.Entry) api.Entry {
+	out := api.Entry{
+		ID:        e.ID,
+		AccountID: e.AccountID,
+		Amount:    e.Amount,
+		CreatedAt: e.CreatedAt,
+	}
+	if e.TransactionID != nil {
+		out.TransactionID = *e.TransactionID
+	}
+	return out
+}
@@ -0,0 +1,173 @@
+package application
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"gitlab.com/ignitionrobotics/billing/credits/internal/conf"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/api"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/domain/models"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/domain/persistence"
+	"gorm.io/gorm"
+	"log"
+	"os"
+	"testing"
+)
+
+func TestCounterpartAccount(t *testing.T) {
+	assert.Equal(t, models.AccountIssuance, counterpartAccount(models.OperationIncrease, 10))
+	assert.Equal(t, models.AccountIssuance, counterpartAccount(models.OperationChargeback, -10))
+	assert.Equal(t, models.AccountRevenue, counterpartAccount(models.OperationDecrease, -10))
+	assert.Equal(t, models.AccountRevenue, counterpartAccount(models.OperationSession, -10))
+	assert.Equal(t, models.AccountTransfers, counterpartAccount(models.OperationTransfer, 10))
+	assert.Equal(t, models.AccountPromotions, counterpartAccount(models.OperationPromotion, 10))
+	assert.Equal(t, models.AccountExpired, counterpartAccount(models.OperationExpiration, -10))
+	assert.Equal(t, models.AccountRevenue, counterpartAccount(models.OperationReversal, -10))
+	assert.Equal(t, models.AccountIssuance, counterpartAccount(models.OperationReversal, 10))
+}
+
+type testAccountingSuite struct {
+	suite.Suite
+	DB      *gorm.DB
+	Logger  *log.Logger
+	Service Service
+}
+
+func TestAccounting(t *testing.T) {
+	suite.Run(t, new(testAccountingSuite))
+}
+
+func (s *testAccountingSuite) SetupSuite() {
+	s.Logger = log.New(os.Stdout, "[TestAccounting] ", log.LstdFlags|log.Lshortfile|log.Lmsgprefix)
+
+	var c conf.Config
+	s.Require().NoError(c.Parse())
+
+	var err error
+	s.DB, err = persistence.OpenConn(c.Database)
+	s.Require().NoError(err)
+
+	s.Require().NoError(persistence.DropTables(s.DB))
+}
+
+func (s *testAccountingSuite) SetupTest() {
+	s.Require().NoError(persistence.MigrateTables(s.DB))
+	s.Service = NewCreditsService(s.DB, s.Logger, 1)
+
+	_, err := persistence.CreateCustomer(s.DB, models.Customer{
+		Handle:      "test1",
+		Application: "cloudsim",
+		Credits:     100,
+	})
+	s.Require().NoError(err)
+}
+
+func (s *testAccountingSuite) TearDownTest() {
+	s.Require().NoError(persistence.DropTables(s.DB))
+}
+
+func (s *testAccountingSuite) transaction(handle string, amount uint) api.Transaction {
+	return api.Transaction{
+		Handle:      handle,
+		Amount:      amount,
+		Currency:    "usd",
+		Application: "cloudsim",
+	}
+}
+
+func (s *testAccountingSuite) balances() map[string]int {
+	res, err := s.Service.ListAccounts(context.Background(), api.ListAccountsRequest{Application: "cloudsim"})
+	s.Require().NoError(err)
+
+	out := make(map[string]int)
+	for _, a := range res.Accounts {
+		out[string(a.Type)+":"+a.Handle] = a.Balance
+	}
+	return out
+}
+
+func (s *testAccountingSuite) TestMovementsPostBalancedEntries() {
+	ctx := context.Background()
+
+	_, err := s.Service.IncreaseCredits(ctx, api.IncreaseCreditsRequest{Transaction: s.transaction("test1", 50)})
+	s.Require().NoError(err)
+
+	res, err := s.Service.DecreaseCredits(ctx, api.DecreaseCreditsRequest{Transaction: s.transaction("test1", 30)})
+	s.Require().NoError(err)
+
+	_, err = s.Service.ExecuteBatch(ctx, api.ExecuteBatchRequest{Operations: []api.BatchOperation{{
+		Type:        api.OperationTransfer,
+		Transaction: s.transaction("test1", 20),
+		Recipient:   "test2",
+	}}})
+	s.Require().NoError(err)
+
+	_, err = s.Service.ReverseTransaction(ctx, api.ReverseTransactionRequest{TransactionID: res.TransactionID, Credits: 10})
+	s.Require().NoError(err)
+
+	// The opening balance of test1 is posted against issuance when its account is created.
+	balances := s.balances()
+	s.Assert().Equal(110, balances["customer:test1"])
+	s.Assert().Equal(20, balances["customer:test2"])
+	s.Assert().Equal(-150, balances["issuance:"])
+	s.Assert().Equal(20, balances["revenue:"])
+	s.Assert().Equal(0, balances["transfers:"])
+
+	c, err := persistence.GetCustomer(s.DB, "test1", "cloudsim")
+	s.Require().NoError(err)
+	s.Assert().Equal(c.Credits, balances["customer:test1"])
+
+	check, err := s.Service.CheckLedger(ctx, api.CheckLedgerRequest{Application: "cloudsim"})
+	s.Require().NoError(err)
+	s.Assert().True(check.Balanced)
+	s.Assert().Zero(check.Sum)
+	s.Assert().Empty(check.Mismatches)
+}
+
+func (s *testAccountingSuite) TestListEntries() {
+	ctx := context.Background()
+
+	res, err := s.Service.DecreaseCredits(ctx, api.DecreaseCreditsRequest{Transaction: s.transaction("test1", 30)})
+	s.Require().NoError(err)
+
+	accounts, err := s.Service.ListAccounts(ctx, api.ListAccountsRequest{
+		Application: "cloudsim",
+		Type:        api.AccountCustomer,
+	})
+	s.Require().NoError(err)
+	s.Require().Len(accounts.Accounts, 1)
+
+	entries, err := s.Service.ListEntries(ctx, api.ListEntriesRequest{AccountID: accounts.Accounts[0].ID})
+	s.Require().NoError(err)
+	s.Require().Len(entries.Entries, 2)
+	s.Assert().Equal(res.TransactionID, entries.Entries[0].TransactionID)
+	s.Assert().Equal(-30, entries.Entries[0].Amount)
+	s.Assert().Zero(entries.Entries[1].TransactionID)
+	s.Assert().Equal(100, entries.Entries[1].Amount)
+
+	_, err = s.Service.ListEntries(ctx, api.ListEntriesRequest{AccountID: 1000})
+	s.Assert().Equal(api.ErrAccountNotFound, err)
+
+	_, err = s.Service.ListAccounts(ctx, api.ListAccountsRequest{Application: "cloudsim", Type: "invalid"})
+	s.Assert().Equal(api.ErrInvalidAccountType, err)
+}
+
+func (s *testAccountingSuite) TestCheckLedgerDetectsMismatches() {
+	ctx := context.Background()
+
+	_, err := s.Service.IncreaseCredits(ctx, api.IncreaseCreditsRequest{Transaction: s.transaction("test1", 50)})
+	s.Require().NoError(err)
+
+	s.Require().NoError(s.DB.Model(&models.Account{}).
+		Where("type = ?", models.AccountRevenue).
+		Or("type = ?", models.AccountIssuance).
+		Update("balance", gorm.Expr("balance + 1")).Error)
+
+	check, err := s.Service.CheckLedger(ctx, api.CheckLedgerRequest{Application: "cloudsim"})
+	s.Require().NoError(err)
+	s.Assert().False(check.Balanced)
+	s.Require().Len(check.Mismatches, 1)
+	s.Assert().Equal(api.AccountIssuance, check.Mismatches[0].Type)
+	s.Assert().Equal(-150, check.Mismatches[0].EntriesSum)
+	s.Assert().Equal(-149, check.Mismatches[0].Balance)
+}
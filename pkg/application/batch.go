@@ -0,0 +1,149 @@
+package application
+
+import (
+	"context"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/api"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/domain/models"
+	"gorm.io/gorm"
+	"sort"
+)
+
+// ExecuteBatch applies a list of credit operations in a single database transaction. Either all the operations are
+// applied, or none of them is.
+func (s *service) ExecuteBatch(ctx context.Context, req api.ExecuteBatchRequest) (api.ExecuteBatchResponse, error) {
+	if err := req.Validate(); err != nil {
+		return api.ExecuteBatchResponse{}, err
+	}
+
+	results := make([]api.BatchOperationResult, len(req.Operations))
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockBatchCustomers(tx, req.Operations); err != nil {
+			return err
+		}
+		for i, op := range req.Operations {
+			res, err := s.applyBatchOperation(tx, op)
+			if err != nil {
+				s.logger.Println("Batch operation failed:", i, "Error:", err)
+				return &api.BatchOperationError{Index: i, Err: err}
+			}
+			res.Index = i
+			results[i] = res
+		}
+		return nil
+	})
+	if err != nil {
+		return api.ExecuteBatchResponse{}, err
+	}
+
+	return api.ExecuteBatchResponse{Results: results}, nil
+}
+
+// lockBatchCustomers locks the customers involved in the given operations, including the recipients of transfers,
+// sorted by application and handle. Customers are always locked in the same order, so concurrent batches, such as
+// transfers in opposite directions, can't deadlock.
+func lockBatchCustomers(tx *gorm.DB, ops []api.BatchOperation) error {
+	type customer struct{ application, handle string }
+	seen := make(map[customer]bool)
+	var list []customer
+	add := func(application, handle string) {
+		c := customer{application: application, handle: handle}
+		if len(handle) == 0 || seen[c] {
+			return
+		}
+		seen[c] = true
+		list = append(list, c)
+	}
+	for _, op := range ops {
+		add(op.Application, op.Handle)
+		if op.Type == api.OperationTransfer {
+			add(op.Application, op.Recipient)
+		}
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].application != list[j].application {
+			return list[i].application < list[j].application
+		}
+		return list[i].handle < list[j].handle
+	})
+	for _, c := range list {
+		if _, err := lockCustomer(tx, c.handle, c.application); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyBatchOperation applies a single batch operation using the given transaction. Increases are calculated with
+// the volume tiers and packages of the application, like IncreaseCredits. Operations involving frozen or closed
+// customers fail, and decreases and transfers can't take the customer below its overdraft limit nor exceed its
+// spending limits. The customers must have been locked with lockBatchCustomers.
+func (s *service) applyBatchOperation(tx *gorm.DB, op api.BatchOperation) (api.BatchOperationResult, error) {
+	var value uint
+	if op.Type == api.OperationIncrease {
+		p, err := s.purchaseCredits(tx, op.Application, op.Amount, op.Currency)
+		if err != nil {
+			return api.BatchOperationResult{}, err
+		}
+		value = p.Credits
+	} else {
+		credits, err := s.calculateCredits(tx, op.Application, op.Amount, op.Currency)
+		if err != nil {
+			return api.BatchOperationResult{}, err
+		}
+		value = credits
+	}
+	res := api.BatchOperationResult{
+		Type:    op.Type,
+		Handle:  op.Handle,
+		Credits: value,
+	}
+
+	c, err := lockActiveCustomer(tx, op.Handle, op.Application)
+	if err != nil {
+		return api.BatchOperationResult{}, err
+	}
+	if op.Type == api.OperationDecrease || op.Type == api.OperationTransfer {
+		if err = checkOverdraft(tx, c, value); err != nil {
+			return api.BatchOperationResult{}, err
+		}
+		if err = checkSpendingLimits(tx, op.Handle, op.Application, value); err != nil {
+			return api.BatchOperationResult{}, err
+		}
+	}
+	if op.Type == api.OperationTransfer {
+		if _, err := lockActiveCustomer(tx, op.Recipient, op.Application); err != nil {
+			return api.BatchOperationResult{}, err
+		}
+	}
+
+	switch op.Type {
+	case api.OperationIncrease:
+		change, err := updateCredits(tx, op.Handle, op.Application, int(value), models.OperationIncrease)
+		if err != nil {
+			return api.BatchOperationResult{}, err
+		}
+		res.Balance = change.Balance
+	case api.OperationDecrease:
+		change, err := updateCredits(tx, op.Handle, op.Application, -1*int(value), models.OperationDecrease)
+		if err != nil {
+			return api.BatchOperationResult{}, err
+		}
+		res.Balance = change.Balance
+	case api.OperationTransfer:
+		from, err := updateCredits(tx, op.Handle, op.Application, -1*int(value), models.OperationTransfer)
+		if err != nil {
+			return api.BatchOperationResult{}, err
+		}
+		to, err := updateCredits(tx, op.Recipient, op.Application, int(value), models.OperationTransfer)
+		if err != nil {
+			return api.BatchOperationResult{}, err
+		}
+		res.Balance = from.Balance
+		res.Recipient = op.Recipient
+		res.RecipientBalance = to.Balance
+	default:
+		return api.BatchOperationResult{}, api.ErrInvalidOperationType
+	}
+	return res, nil
+}
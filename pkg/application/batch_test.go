@@ -0,0 +1,216 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/suite"
+	"gitlab.com/ignitionrobotics/billing/credits/internal/conf"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/api"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/domain/models"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/domain/persistence"
+	"gorm.io/gorm"
+	"log"
+	"os"
+	"sync"
+	"testing"
+)
+
+type testBatchSuite struct {
+	suite.Suite
+	DB      *gorm.DB
+	Logger  *log.Logger
+	Service Service
+}
+
+func TestBatch(t *testing.T) {
+	suite.Run(t, new(testBatchSuite))
+}
+
+func (s *testBatchSuite) SetupSuite() {
+	s.Logger = log.New(os.Stdout, "[TestBatch] ", log.LstdFlags|log.Lshortfile|log.Lmsgprefix)
+
+	var c conf.Config
+	s.Require().NoError(c.Parse())
+
+	var err error
+	s.DB, err = persistence.OpenConn(c.Database)
+	s.Require().NoError(err)
+
+	s.Require().NoError(persistence.DropTables(s.DB))
+}
+
+func (s *testBatchSuite) SetupTest() {
+	s.Require().NoError(persistence.MigrateTables(s.DB))
+	s.Service = NewCreditsService(s.DB, s.Logger, 1)
+
+	_, err := persistence.CreateCustomer(s.DB, models.Customer{
+		Handle:      "test1",
+		Application: "cloudsim",
+		Credits:     100,
+	})
+	s.Require().NoError(err)
+}
+
+func (s *testBatchSuite) TearDownTest() {
+	s.Require().NoError(persistence.DropTables(s.DB))
+}
+
+func (s *testBatchSuite) operation(t api.OperationType, handle string, amount uint, recipient string) api.BatchOperation {
+	return api.BatchOperation{
+		Type: t,
+		Transaction: api.Transaction{
+			Handle:      handle,
+			Amount:      amount,
+			Currency:    "usd",
+			Application: "cloudsim",
+		},
+		Recipient: recipient,
+	}
+}
+
+func (s *testBatchSuite) TestExecuteBatch() {
+	res, err := s.Service.ExecuteBatch(context.Background(), api.ExecuteBatchRequest{
+		Operations: []api.BatchOperation{
+			s.operation(api.OperationIncrease, "test1", 50, ""),
+			s.operation(api.OperationDecrease, "test1", 20, ""),
+			s.operation(api.OperationTransfer, "test1", 30, "test2"),
+		},
+	})
+	s.Require().NoError(err)
+	s.Require().Len(res.Results, 3)
+
+	s.Assert().Equal(150, res.Results[0].Balance)
+	s.Assert().Equal(130, res.Results[1].Balance)
+	s.Assert().Equal(2, res.Results[2].Index)
+	s.Assert().Equal(uint(30), res.Results[2].Credits)
+	s.Assert().Equal(100, res.Results[2].Balance)
+	s.Assert().Equal("test2", res.Results[2].Recipient)
+	s.Assert().Equal(30, res.Results[2].RecipientBalance)
+
+	c, err := persistence.GetCustomer(s.DB, "test2", "cloudsim")
+	s.Require().NoError(err)
+	s.Assert().Equal(30, c.Credits)
+}
+
+func (s *testBatchSuite) TestExecuteBatchAppliesNothingOnFailure() {
+	_, err := s.Service.ExecuteBatch(context.Background(), api.ExecuteBatchRequest{
+		Operations: []api.BatchOperation{
+			s.operation(api.OperationIncrease, "test1", 50, ""),
+			s.operation(api.OperationTransfer, "test1", 30, ""),
+		},
+	})
+	s.Require().Error(err)
+
+	var opErr *api.BatchOperationError
+	s.Require().True(errors.As(err, &opErr))
+	s.Assert().Equal(1, opErr.Index)
+	s.Assert().True(errors.Is(err, api.ErrMissingRecipient))
+
+	c, err := persistence.GetCustomer(s.DB, "test1", "cloudsim")
+	s.Require().NoError(err)
+	s.Assert().Equal(100, c.Credits)
+}
+
+func (s *testBatchSuite) TestExecuteBatchValidation() {
+	_, err := s.Service.ExecuteBatch(context.Background(), api.ExecuteBatchRequest{})
+	s.Assert().Equal(api.ErrEmptyBatch, err)
+
+	_, err = s.Service.ExecuteBatch(context.Background(), api.ExecuteBatchRequest{
+		Operations: []api.BatchOperation{
+			s.operation("refund", "test1", 50, ""),
+		},
+	})
+	s.Assert().True(errors.Is(err, api.ErrInvalidOperationType))
+}
+
+func (s *testBatchSuite) TestExecuteBatchChecksOverdraft() {
+	_, err := s.Service.ExecuteBatch(context.Background(), api.ExecuteBatchRequest{
+		Operations: []api.BatchOperation{
+			s.operation(api.OperationDecrease, "test1", 101, ""),
+		},
+	})
+	s.Assert().True(errors.Is(err, api.ErrInsufficientCredits))
+
+	_, err = s.Service.ExecuteBatch(context.Background(), api.ExecuteBatchRequest{
+		Operations: []api.BatchOperation{
+			s.operation(api.OperationDecrease, "test1", 60, ""),
+			s.operation(api.OperationTransfer, "test1", 60, "test2"),
+		},
+	})
+	var opErr *api.BatchOperationError
+	s.Require().True(errors.As(err, &opErr))
+	s.Assert().Equal(1, opErr.Index)
+	s.Assert().True(errors.Is(err, api.ErrInsufficientCredits))
+
+	c, err := persistence.GetCustomer(s.DB, "test1", "cloudsim")
+	s.Require().NoError(err)
+	s.Assert().Equal(100, c.Credits)
+}
+
+func (s *testBatchSuite) TestExecuteBatchUsesPurchasePricing() {
+	_, err := s.Service.SetVolumeTiers(context.Background(), api.SetVolumeTiersRequest{
+		Application: "cloudsim",
+		Tiers:       []api.VolumeTier{{MinAmount: 50, BonusPercent: 10}},
+	})
+	s.Require().NoError(err)
+
+	_, err = s.Service.CreatePackage(context.Background(), api.CreatePackageRequest{
+		CreditPackage: api.CreditPackage{
+			Application: "cloudsim",
+			Code:        "starter",
+			Credits:     60,
+			Amount:      40,
+		},
+	})
+	s.Require().NoError(err)
+
+	res, err := s.Service.ExecuteBatch(context.Background(), api.ExecuteBatchRequest{
+		Operations: []api.BatchOperation{
+			s.operation(api.OperationIncrease, "test1", 50, ""),
+			s.operation(api.OperationIncrease, "test1", 40, ""),
+			s.operation(api.OperationDecrease, "test1", 50, ""),
+		},
+	})
+	s.Require().NoError(err)
+	s.Require().Len(res.Results, 3)
+
+	s.Assert().Equal(uint(55), res.Results[0].Credits)
+	s.Assert().Equal(155, res.Results[0].Balance)
+	s.Assert().Equal(uint(60), res.Results[1].Credits)
+	s.Assert().Equal(215, res.Results[1].Balance)
+	s.Assert().Equal(uint(50), res.Results[2].Credits)
+	s.Assert().Equal(165, res.Results[2].Balance)
+}
+
+func (s *testBatchSuite) TestExecuteBatchOppositeTransfers() {
+	_, err := persistence.CreateCustomer(s.DB, models.Customer{
+		Handle:      "test2",
+		Application: "cloudsim",
+		Credits:     100,
+	})
+	s.Require().NoError(err)
+
+	// Transfers in opposite directions lock both customers in the same order, so they don't deadlock.
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		from, to := "test1", "test2"
+		if i%2 == 1 {
+			from, to = to, from
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := s.Service.ExecuteBatch(context.Background(), api.ExecuteBatchRequest{
+				Operations: []api.BatchOperation{s.operation(api.OperationTransfer, from, 10, to)},
+			})
+			s.Assert().NoError(err)
+		}()
+	}
+	wg.Wait()
+
+	for _, handle := range []string{"test1", "test2"} {
+		c, err := persistence.GetCustomer(s.DB, handle, "cloudsim")
+		s.Require().NoError(err)
+		s.Assert().Equal(100, c.Credits)
+	}
+}
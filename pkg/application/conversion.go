@@ -0,0 +1,174 @@
+package application
+
+import (
+	"context"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/api"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/domain/models"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/domain/persistence"
+	"gorm.io/gorm"
+	"math"
+)
+
+// SetConversionPolicy sets the conversion policy of an application. The rounding mode can change at any time, but
+// the precision can't change once the application has customers or settings expressed in credits, since their
+// values would be misread. The policy is locked while checking them, so customers can't be created meanwhile.
+func (s *service) SetConversionPolicy(ctx context.Context, req api.SetConversionPolicyRequest) (api.SetConversionPolicyResponse, error) {
+	if err := req.Validate(); err != nil {
+		return api.SetConversionPolicyResponse{}, err
+	}
+
+	var out api.SetConversionPolicyResponse
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		current, err := lockConversionPolicy(tx, req.Application)
+		if err != nil {
+			return err
+		}
+		if current.MilliCredits != req.MilliCredits {
+			count, err := persistence.CountCustomers(tx, req.Application)
+			if err != nil {
+				return err
+			}
+			if count > 0 {
+				return api.ErrPrecisionLocked
+			}
+			configured, err := persistence.HasCreditSettings(tx, req.Application)
+			if err != nil {
+				return err
+			}
+			if configured {
+				return api.ErrPrecisionLocked
+			}
+		}
+
+		policy, err := persistence.SetConversionPolicy(tx, models.ConversionPolicy{
+			Application:  req.Application,
+			Rounding:     string(req.Rounding),
+			MilliCredits: req.MilliCredits,
+		})
+		if err != nil {
+			return err
+		}
+		out.ConversionPolicy = toConversionPolicyAPI(policy)
+		return nil
+	})
+	if err != nil {
+		return api.SetConversionPolicyResponse{}, err
+	}
+	return out, nil
+}
+
+// GetConversionPolicy returns the conversion policy of an application, or the default one if it has none.
+func (s *service) GetConversionPolicy(ctx context.Context, req api.GetConversionPolicyRequest) (api.GetConversionPolicyResponse, error) {
+	if len(req.Application) == 0 {
+		return api.GetConversionPolicyResponse{}, api.ErrMissingApplication
+	}
+
+	policy, err := getConversionPolicy(s.db, req.Application)
+	if err != nil {
+		return api.GetConversionPolicyResponse{}, err
+	}
+	return api.GetConversionPolicyResponse{ConversionPolicy: toConversionPolicyAPI(policy)}, nil
+}
+
+// getConversionPolicy returns the conversion policy of an application. Applications without a policy round up to
+// whole credits.
+func getConversionPolicy(db *gorm.DB, application string) (models.ConversionPolicy, error) {
+	policy, err := persistence.GetConversionPolicy(db, application)
+	if err == gorm.ErrRecordNotFound {
+		return defaultConversionPolicy(application), nil
+	}
+	if err != nil {
+		return models.ConversionPolicy{}, err
+	}
+	return policy, nil
+}
+
+// lockConversionPolicy works like getConversionPolicy, but locks the policy until the end of the given transaction.
+func lockConversionPolicy(tx *gorm.DB, application string) (models.ConversionPolicy, error) {
+	policy, err := persistence.GetConversionPolicyForUpdate(tx, application)
+	if err == gorm.ErrRecordNotFound {
+		return defaultConversionPolicy(application), nil
+	}
+	if err != nil {
+		return models.ConversionPolicy{}, err
+	}
+	return policy, nil
+}
+
+// shareConversionPolicy locks the conversion policy of an application in share mode until the end of the given
+// transaction, so its precision can't change while values expressed in credits are being stored.
+func shareConversionPolicy(tx *gorm.DB, application string) error {
+	_, err := persistence.GetConversionPolicyForShare(tx, application)
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return err
+	}
+	return nil
+}
+
+// defaultConversionPolicy returns the conversion policy of an application without one.
+func defaultConversionPolicy(application string) models.ConversionPolicy {
+	return models.ConversionPolicy{Application: application, Rounding: string(api.RoundingCeil)}
+}
+
+// creditScale returns the amount of balance units in a credit for the given policy.
+func creditScale(policy models.ConversionPolicy) uint64 {
+	if policy.MilliCredits {
+		return api.MilliCreditsPerCredit
+	}
+	return 1
+}
+
+// convertToCredits converts the given amount to the balance units of the given policy with the given conversion
+// rate, using integer math only. It returns false if the credits don't fit in a balance.
+func convertToCredits(policy models.ConversionPolicy, amount, rate uint) (uint, bool) {
+	scale := creditScale(policy)
+	if uint64(amount) > math.MaxUint64/scale {
+		return 0, false
+	}
+	return toBalance(divide(uint64(amount)*scale, uint64(rate), api.RoundingMode(policy.Rounding)))
+}
+
+// convertToAmount returns the minimum amount needed to buy the given balance units of the given policy with the
+// given conversion rate. It returns false if the amount doesn't fit in an int.
+func convertToAmount(policy models.ConversionPolicy, credits, rate uint) (uint, bool) {
+	if rate != 0 && uint64(credits) > math.MaxUint64/uint64(rate) {
+		return 0, false
+	}
+	return toBalance(divide(uint64(credits)*uint64(rate), creditScale(policy), api.RoundingCeil))
+}
+
+// toBalance converts the given value to uint, returning false if it doesn't fit in an int like balances do.
+func toBalance(value uint64) (uint, bool) {
+	if value > math.MaxInt {
+		return 0, false
+	}
+	return uint(value), true
+}
+
+// divide divides n by d rounding the result with the given rounding mode.
+func divide(n, d uint64, mode api.RoundingMode) uint64 {
+	q, r := n/d, n%d
+	if r == 0 {
+		return q
+	}
+	switch mode {
+	case api.RoundingFloor:
+		return q
+	case api.RoundingHalfEven:
+		if r*2 > d || (r*2 == d && q%2 == 1) {
+			return q + 1
+		}
+		return q
+	default:
+		return q + 1
+	}
+}
+
+// toConversionPolicyAPI converts the given conversion policy model into its API representation.
+func toConversionPolicyAPI(policy models.ConversionPolicy) api.ConversionPolicy {
+	return api.ConversionPolicy{
+		Application:  policy.Application,
+		Rounding:     api.RoundingMode(policy.Rounding),
+		MilliCredits: policy.MilliCredits,
+	}
+}
@@ -0,0 +1,222 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"gitlab.com/ignitionrobotics/billing/credits/internal/conf"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/api"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/domain/models"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/domain/persistence"
+	"gorm.io/gorm"
+	"log"
+	"math"
+	"os"
+	"testing"
+)
+
+func TestDivide(t *testing.T) {
+	assert.Equal(t, uint64(2), divide(10, 5, api.RoundingCeil))
+	assert.Equal(t, uint64(2), divide(10, 5, api.RoundingFloor))
+	assert.Equal(t, uint64(2), divide(10, 5, api.RoundingHalfEven))
+
+	assert.Equal(t, uint64(1), divide(1, 100, api.RoundingCeil))
+	assert.Equal(t, uint64(0), divide(1, 100, api.RoundingFloor))
+	assert.Equal(t, uint64(0), divide(1, 100, api.RoundingHalfEven))
+
+	assert.Equal(t, uint64(2), divide(250, 100, api.RoundingHalfEven))
+	assert.Equal(t, uint64(4), divide(350, 100, api.RoundingHalfEven))
+	assert.Equal(t, uint64(3), divide(251, 100, api.RoundingHalfEven))
+	assert.Equal(t, uint64(2), divide(249, 100, api.RoundingHalfEven))
+}
+
+func TestConvertToCredits(t *testing.T) {
+	convert := func(policy models.ConversionPolicy, amount, rate uint) uint {
+		credits, ok := convertToCredits(policy, amount, rate)
+		require.True(t, ok)
+		return credits
+	}
+
+	ceil := models.ConversionPolicy{Rounding: string(api.RoundingCeil)}
+	assert.Equal(t, uint(1), convert(ceil, 1, 100))
+	assert.Equal(t, uint(2), convert(ceil, 1000, 500))
+
+	milli := models.ConversionPolicy{Rounding: string(api.RoundingFloor), MilliCredits: true}
+	assert.Equal(t, uint(10), convert(milli, 1, 100))
+	assert.Equal(t, uint(333), convert(milli, 1, 3))
+
+	// Large amounts don't lose precision.
+	assert.Equal(t, uint(math.MaxUint32), convert(ceil, math.MaxUint32, 1))
+
+	// Credits that don't fit in a balance are rejected.
+	_, ok := convertToCredits(ceil, math.MaxInt+1, 1)
+	assert.False(t, ok)
+	_, ok = convertToCredits(milli, math.MaxUint, 1)
+	assert.False(t, ok)
+}
+
+func TestConvertToAmount(t *testing.T) {
+	convert := func(policy models.ConversionPolicy, credits, rate uint) uint {
+		amount, ok := convertToAmount(policy, credits, rate)
+		require.True(t, ok)
+		return amount
+	}
+
+	policy := models.ConversionPolicy{Rounding: string(api.RoundingCeil)}
+	assert.Equal(t, uint(500), convert(policy, 5, 100))
+
+	// Amounts that don't fit in an int are rejected.
+	_, ok := convertToAmount(policy, math.MaxInt, 100)
+	assert.False(t, ok)
+
+	policy.MilliCredits = true
+	assert.Equal(t, uint(1), convert(policy, 10, 100))
+	assert.Equal(t, uint(1), convert(policy, 1, 100))
+}
+
+type testConversionSuite struct {
+	suite.Suite
+	DB      *gorm.DB
+	Logger  *log.Logger
+	Service Service
+}
+
+func TestConversion(t *testing.T) {
+	suite.Run(t, new(testConversionSuite))
+}
+
+func (s *testConversionSuite) SetupSuite() {
+	s.Logger = log.New(os.Stdout, "[TestConversion] ", log.LstdFlags|log.Lshortfile|log.Lmsgprefix)
+
+	var c conf.Config
+	s.Require().NoError(c.Parse())
+
+	var err error
+	s.DB, err = persistence.OpenConn(c.Database)
+	s.Require().NoError(err)
+
+	s.Require().NoError(persistence.DropTables(s.DB))
+}
+
+func (s *testConversionSuite) SetupTest() {
+	s.Require().NoError(persistence.MigrateTables(s.DB))
+	s.Service = NewCreditsService(s.DB, s.Logger, 100)
+}
+
+func (s *testConversionSuite) TearDownTest() {
+	s.Require().NoError(persistence.DropTables(s.DB))
+}
+
+func (s *testConversionSuite) setPolicy(rounding api.RoundingMode, milli bool) error {
+	_, err := s.Service.SetConversionPolicy(context.Background(), api.SetConversionPolicyRequest{
+		ConversionPolicy: api.ConversionPolicy{Application: "cloudsim", Rounding: rounding, MilliCredits: milli},
+	})
+	return err
+}
+
+func (s *testConversionSuite) TestDefaultPolicy() {
+	res, err := s.Service.GetConversionPolicy(context.Background(), api.GetConversionPolicyRequest{Application: "cloudsim"})
+	s.Require().NoError(err)
+	s.Assert().Equal(api.RoundingCeil, res.Rounding)
+	s.Assert().False(res.MilliCredits)
+
+	conv, err := s.Service.ConvertCurrency(context.Background(), api.ConvertCurrencyRequest{
+		Amount: 1, Currency: "usd", Application: "cloudsim",
+	})
+	s.Require().NoError(err)
+	s.Assert().Equal(uint(1), conv.Credits)
+}
+
+func (s *testConversionSuite) TestRounding() {
+	s.Require().NoError(s.setPolicy(api.RoundingHalfEven, false))
+
+	convert := func(amount uint) uint {
+		res, err := s.Service.ConvertCurrency(context.Background(), api.ConvertCurrencyRequest{
+			Amount: amount, Currency: "usd", Application: "cloudsim",
+		})
+		s.Require().NoError(err)
+		return res.Credits
+	}
+	s.Assert().Equal(uint(0), convert(1))
+	s.Assert().Equal(uint(2), convert(250))
+	s.Assert().Equal(uint(3), convert(251))
+
+	s.Require().NoError(s.setPolicy(api.RoundingFloor, false))
+	s.Assert().Equal(uint(2), convert(299))
+}
+
+func (s *testConversionSuite) TestMilliCredits() {
+	s.Require().NoError(s.setPolicy(api.RoundingCeil, true))
+
+	_, err := s.Service.IncreaseCredits(context.Background(), api.IncreaseCreditsRequest{
+		Transaction: api.Transaction{Handle: "test", Amount: 100, Currency: "usd", Application: "cloudsim"},
+	})
+	s.Require().NoError(err)
+
+	// A hundred charges of a cent spend exactly one credit.
+	for i := 0; i < 100; i++ {
+		_, err = s.Service.DecreaseCredits(context.Background(), api.DecreaseCreditsRequest{
+			Transaction: api.Transaction{Handle: "test", Amount: 1, Currency: "usd", Application: "cloudsim"},
+		})
+		s.Require().NoError(err)
+	}
+
+	res, err := s.Service.GetBalance(context.Background(), api.GetBalanceRequest{Handle: "test", Application: "cloudsim"})
+	s.Require().NoError(err)
+	s.Assert().Equal(0, res.Credits)
+
+	// The precision can't change once the application has customers, but the rounding mode can.
+	err = s.setPolicy(api.RoundingCeil, false)
+	s.Assert().True(errors.Is(err, api.ErrPrecisionLocked))
+	s.Assert().NoError(s.setPolicy(api.RoundingHalfEven, true))
+}
+
+func (s *testConversionSuite) TestPrecisionLockedByCreditSettings() {
+	_, err := s.Service.SetOverdraftLimit(context.Background(), api.SetOverdraftLimitRequest{
+		OverdraftLimit: api.OverdraftLimit{Application: "cloudsim", Limit: 10},
+	})
+	s.Require().NoError(err)
+
+	// The overdraft limit is expressed in credits, so the precision can't change while it exists.
+	err = s.setPolicy(api.RoundingCeil, true)
+	s.Assert().True(errors.Is(err, api.ErrPrecisionLocked))
+	s.Assert().NoError(s.setPolicy(api.RoundingFloor, false))
+
+	_, err = s.Service.DeleteOverdraftLimit(context.Background(), api.DeleteOverdraftLimitRequest{Application: "cloudsim"})
+	s.Require().NoError(err)
+	s.Assert().NoError(s.setPolicy(api.RoundingCeil, true))
+}
+
+func (s *testConversionSuite) TestPrecisionLockedByCustomerSettings() {
+	setPolicy := func(application string) error {
+		_, err := s.Service.SetConversionPolicy(context.Background(), api.SetConversionPolicyRequest{
+			ConversionPolicy: api.ConversionPolicy{Application: application, Rounding: api.RoundingCeil, MilliCredits: true},
+		})
+		return err
+	}
+
+	// Subscriptions, automatic top-ups and wallet allowances are expressed in credits before the customer exists.
+	_, err := s.Service.CreateSubscription(context.Background(), api.CreateSubscriptionRequest{
+		Handle: "test", Application: "subscriptions", Credits: 50,
+		Schedule: api.ScheduleMonthly, Rollover: api.RolloverReset,
+	})
+	s.Require().NoError(err)
+	s.Assert().True(errors.Is(setPolicy("subscriptions"), api.ErrPrecisionLocked))
+
+	_, err = s.Service.SetAutoTopUp(context.Background(), api.SetAutoTopUpRequest{AutoTopUp: api.AutoTopUp{
+		Handle: "test", Application: "topups", Threshold: 10, Amount: 100, Currency: "usd", MaxPerDay: 1,
+	}})
+	s.Require().NoError(err)
+	s.Assert().True(errors.Is(setPolicy("topups"), api.ErrPrecisionLocked))
+
+	allowance := uint(10)
+	_, err = s.Service.SetWalletMember(context.Background(), api.SetWalletMemberRequest{
+		Application: "wallets", Wallet: "team", Member: "test", Allowance: &allowance,
+	})
+	s.Require().NoError(err)
+	s.Assert().True(errors.Is(setPolicy("wallets"), api.ErrPrecisionLocked))
+
+	s.Assert().NoError(setPolicy("cloudsim"))
+}
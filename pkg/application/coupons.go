@@ -0,0 +1,187 @@
+package application
+
+import (
+	"context"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/api"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/domain/models"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/domain/persistence"
+	"gorm.io/gorm"
+	"strings"
+	"time"
+)
+
+// CreateCoupon creates a new coupon. Codes are stored in upper case, so they can be redeemed regardless of their
+// case.
+func (s *service) CreateCoupon(ctx context.Context, req api.CreateCouponRequest) (api.CreateCouponResponse, error) {
+	if err := req.Validate(); err != nil {
+		return api.CreateCouponResponse{}, err
+	}
+
+	code := normalizeCouponCode(req.Code)
+	_, err := persistence.GetCoupon(s.db, req.Application, code)
+	if err == nil {
+		return api.CreateCouponResponse{}, api.ErrCouponExists
+	}
+	if err != gorm.ErrRecordNotFound {
+		return api.CreateCouponResponse{}, err
+	}
+
+	var coupon models.Coupon
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := shareConversionPolicy(tx, req.Application); err != nil {
+			return err
+		}
+		var err error
+		coupon, err = persistence.CreateCoupon(tx, models.Coupon{
+			Application:    req.Application,
+			Code:           code,
+			Credits:        req.Credits,
+			ExpiresAt:      req.ExpiresAt,
+			MaxRedemptions: req.MaxRedemptions,
+		})
+		return err
+	})
+	if err != nil {
+		return api.CreateCouponResponse{}, err
+	}
+
+	return api.CreateCouponResponse{Coupon: toCouponAPI(coupon)}, nil
+}
+
+// ListCoupons returns the coupons of an application.
+func (s *service) ListCoupons(ctx context.Context, req api.ListCouponsRequest) (api.ListCouponsResponse, error) {
+	if len(req.Application) == 0 {
+		return api.ListCouponsResponse{}, api.ErrMissingApplication
+	}
+
+	list, err := persistence.GetCoupons(s.db, req.Application)
+	if err != nil {
+		return api.ListCouponsResponse{}, err
+	}
+
+	out := api.ListCouponsResponse{Coupons: make([]api.Coupon, len(list))}
+	for i, coupon := range list {
+		out.Coupons[i] = toCouponAPI(coupon)
+	}
+	return out, nil
+}
+
+// RedeemCoupon grants the credits of a coupon to a customer. The coupon is locked while checking its expiration,
+// its redemptions and whether the customer already redeemed it, and the credits are granted in the same
+// transaction, so coupons can't be redeemed more times than allowed. Frozen and closed customers can't redeem
+// coupons.
+func (s *service) RedeemCoupon(ctx context.Context, req api.RedeemCouponRequest) (api.RedeemCouponResponse, error) {
+	if err := req.Validate(); err != nil {
+		return api.RedeemCouponResponse{}, err
+	}
+
+	var out api.RedeemCouponResponse
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		coupon, err := persistence.GetCouponForUpdate(tx, req.Application, normalizeCouponCode(req.Code))
+		if err == gorm.ErrRecordNotFound {
+			return api.ErrCouponNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		if coupon.ExpiresAt != nil && !time.Now().Before(*coupon.ExpiresAt) {
+			return api.ErrCouponExpired
+		}
+		if coupon.MaxRedemptions > 0 && coupon.Redemptions >= coupon.MaxRedemptions {
+			return api.ErrCouponExhausted
+		}
+
+		redeemed, err := persistence.CountCouponRedemptions(tx, coupon.ID, req.Handle)
+		if err != nil {
+			return err
+		}
+		if redeemed > 0 {
+			return api.ErrCouponAlreadyRedeemed
+		}
+
+		if _, err = lockActiveCustomer(tx, req.Handle, req.Application); err != nil {
+			return err
+		}
+
+		change, err := updateCredits(tx, req.Handle, req.Application, int(coupon.Credits), models.OperationPromotion)
+		if err != nil {
+			return err
+		}
+
+		_, err = persistence.CreateCouponRedemption(tx, models.CouponRedemption{
+			CouponID:      coupon.ID,
+			Handle:        req.Handle,
+			Application:   req.Application,
+			TransactionID: change.ID,
+		})
+		if err != nil {
+			return err
+		}
+		if err = persistence.IncrementCouponRedemptions(tx, coupon); err != nil {
+			return err
+		}
+
+		out = api.RedeemCouponResponse{
+			TransactionID: change.ID,
+			Credits:       coupon.Credits,
+			Balance:       change.Balance,
+		}
+		return nil
+	})
+	if err != nil {
+		return api.RedeemCouponResponse{}, err
+	}
+	return out, nil
+}
+
+// GetCouponStats returns the usage statistics of a coupon.
+func (s *service) GetCouponStats(ctx context.Context, req api.GetCouponStatsRequest) (api.GetCouponStatsResponse, error) {
+	if len(req.Application) == 0 {
+		return api.GetCouponStatsResponse{}, api.ErrMissingApplication
+	}
+
+	coupon, err := persistence.GetCoupon(s.db, req.Application, normalizeCouponCode(req.Code))
+	if err == gorm.ErrRecordNotFound {
+		return api.GetCouponStatsResponse{}, api.ErrCouponNotFound
+	}
+	if err != nil {
+		return api.GetCouponStatsResponse{}, err
+	}
+
+	out := api.GetCouponStatsResponse{
+		Coupon:         toCouponAPI(coupon),
+		CreditsGranted: coupon.Credits * coupon.Redemptions,
+	}
+	if coupon.MaxRedemptions > 0 {
+		remaining := coupon.MaxRedemptions - coupon.Redemptions
+		out.Remaining = &remaining
+	}
+
+	latest, err := persistence.GetLatestCouponRedemption(s.db, coupon.ID)
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return api.GetCouponStatsResponse{}, err
+	}
+	if err == nil {
+		out.LastRedeemedAt = &latest.CreatedAt
+	}
+	return out, nil
+}
+
+// normalizeCouponCode returns the code used to store and look up the given coupon code.
+func normalizeCouponCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// toCouponAPI converts the given coupon model into its API representation.
+func toCouponAPI(coupon models.Coupon) api.Coupon {
+	return api.Coupon{
+		Application:    coupon.Application,
+		Code:           coupon.Code,
+		Credits:        coupon.Credits,
+		ExpiresAt:      coupon.ExpiresAt,
+		MaxRedemptions: coupon.MaxRedemptions,
+		Redemptions:    coupon.Redemptions,
+		CreatedAt:      coupon.CreatedAt,
+	}
+}
@@ -0,0 +1,172 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"gitlab.com/ignitionrobotics/billing/credits/internal/conf"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/api"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/domain/models"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/domain/persistence"
+	"gorm.io/gorm"
+	"log"
+	"os"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestNormalizeCouponCode(t *testing.T) {
+	assert.Equal(t, "LAUNCH2022", normalizeCouponCode(" launch2022 "))
+}
+
+type testCouponsSuite struct {
+	suite.Suite
+	DB      *gorm.DB
+	Logger  *log.Logger
+	Service Service
+}
+
+func TestCoupons(t *testing.T) {
+	suite.Run(t, new(testCouponsSuite))
+}
+
+func (s *testCouponsSuite) SetupSuite() {
+	s.Logger = log.New(os.Stdout, "[TestCoupons] ", log.LstdFlags|log.Lshortfile|log.Lmsgprefix)
+
+	var c conf.Config
+	s.Require().NoError(c.Parse())
+
+	var err error
+	s.DB, err = persistence.OpenConn(c.Database)
+	s.Require().NoError(err)
+
+	s.Require().NoError(persistence.DropTables(s.DB))
+}
+
+func (s *testCouponsSuite) SetupTest() {
+	s.Require().NoError(persistence.MigrateTables(s.DB))
+	s.Service = NewCreditsService(s.DB, s.Logger, 1)
+
+	_, err := persistence.CreateCustomer(s.DB, models.Customer{
+		Handle:      "test1",
+		Application: "cloudsim",
+		Credits:     100,
+	})
+	s.Require().NoError(err)
+}
+
+func (s *testCouponsSuite) TearDownTest() {
+	s.Require().NoError(persistence.DropTables(s.DB))
+}
+
+func (s *testCouponsSuite) create(code string, max uint, expiresAt *time.Time) {
+	_, err := s.Service.CreateCoupon(context.Background(), api.CreateCouponRequest{
+		Application:    "cloudsim",
+		Code:           code,
+		Credits:        25,
+		ExpiresAt:      expiresAt,
+		MaxRedemptions: max,
+	})
+	s.Require().NoError(err)
+}
+
+func (s *testCouponsSuite) redeem(handle, code string) (api.RedeemCouponResponse, error) {
+	return s.Service.RedeemCoupon(context.Background(), api.RedeemCouponRequest{
+		Handle:      handle,
+		Application: "cloudsim",
+		Code:        code,
+	})
+}
+
+func (s *testCouponsSuite) TestRedeemOncePerCustomer() {
+	s.create("Launch", 0, nil)
+
+	_, err := s.Service.CreateCoupon(context.Background(), api.CreateCouponRequest{
+		Application: "cloudsim",
+		Code:        "LAUNCH",
+		Credits:     10,
+	})
+	s.Assert().Equal(api.ErrCouponExists, err)
+
+	res, err := s.redeem("test1", "launch")
+	s.Require().NoError(err)
+	s.Assert().Equal(uint(25), res.Credits)
+	s.Assert().Equal(125, res.Balance)
+
+	_, err = s.redeem("test1", "LAUNCH")
+	s.Assert().Equal(api.ErrCouponAlreadyRedeemed, err)
+
+	_, err = s.redeem("test2", "LAUNCH")
+	s.Require().NoError(err)
+
+	stats, err := s.Service.GetCouponStats(context.Background(), api.GetCouponStatsRequest{Application: "cloudsim", Code: "launch"})
+	s.Require().NoError(err)
+	s.Assert().Equal(uint(2), stats.Coupon.Redemptions)
+	s.Assert().Equal(uint(50), stats.CreditsGranted)
+	s.Assert().Nil(stats.Remaining)
+	s.Assert().NotNil(stats.LastRedeemedAt)
+}
+
+func (s *testCouponsSuite) TestExpiredAndUnknownCoupons() {
+	expiresAt := time.Now().Add(-time.Minute)
+	s.create("OLD", 0, &expiresAt)
+
+	_, err := s.redeem("test1", "OLD")
+	s.Assert().Equal(api.ErrCouponExpired, err)
+
+	_, err = s.redeem("test1", "MISSING")
+	s.Assert().Equal(api.ErrCouponNotFound, err)
+}
+
+func (s *testCouponsSuite) TestConcurrentRedemptions() {
+	s.create("LIMITED", 3, nil)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 10)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, err := s.redeem(string(rune('a'+i)), "LIMITED")
+			errs <- err
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+
+	var succeeded int
+	for err := range errs {
+		if err == nil {
+			succeeded++
+		} else {
+			s.Assert().Equal(api.ErrCouponExhausted, err)
+		}
+	}
+	s.Assert().Equal(3, succeeded)
+
+	stats, err := s.Service.GetCouponStats(context.Background(), api.GetCouponStatsRequest{Application: "cloudsim", Code: "LIMITED"})
+	s.Require().NoError(err)
+	s.Require().NotNil(stats.Remaining)
+	s.Assert().Zero(*stats.Remaining)
+}
+
+func (s *testCouponsSuite) TestFrozenCustomerCantRedeem() {
+	s.create("LAUNCH", 0, nil)
+
+	_, err := s.Service.SetCustomerStatus(context.Background(), api.SetCustomerStatusRequest{
+		Handle:      "test1",
+		Application: "cloudsim",
+		Status:      api.CustomerFrozen,
+		Reason:      "fraud review",
+	})
+	s.Require().NoError(err)
+
+	_, err = s.redeem("test1", "LAUNCH")
+	s.Assert().True(errors.Is(err, api.ErrCustomerFrozen))
+
+	stats, err := s.Service.GetCouponStats(context.Background(), api.GetCouponStatsRequest{Application: "cloudsim", Code: "LAUNCH"})
+	s.Require().NoError(err)
+	s.Assert().Zero(stats.Coupon.Redemptions)
+}
@@ -0,0 +1,256 @@
+package application
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/api"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/domain/models"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/domain/persistence"
+	"gorm.io/gorm"
+	"time"
+)
+
+// customerSortColumns maps each api.CustomerSort to the column used to sort customers.
+var customerSortColumns = map[api.CustomerSort]string{
+	api.SortByHandle:       "handle",
+	api.SortByBalance:      "credits",
+	api.SortByLastActivity: "updated_at",
+}
+
+// customerCursor is the decoded representation of a customers pagination cursor. It contains the values of the last
+// customer of a page.
+type customerCursor struct {
+	SortBy    api.CustomerSort `json:"s"`
+	ID        uint             `json:"id"`
+	Handle    string           `json:"h,omitempty"`
+	Credits   int              `json:"c,omitempty"`
+	UpdatedAt time.Time        `json:"u,omitempty"`
+}
+
+// value returns the value of the sort column stored in the cursor.
+func (c customerCursor) value() interface{} {
+	switch c.SortBy {
+	case api.SortByBalance:
+		return c.Credits
+	case api.SortByLastActivity:
+		return c.UpdatedAt
+	default:
+		return c.Handle
+	}
+}
+
+// encodeCustomerCursor returns an opaque cursor pointing after the given customer.
+func encodeCustomerCursor(sortBy api.CustomerSort, c models.Customer) (string, error) {
+	b, err := json.Marshal(customerCursor{
+		SortBy:    sortBy,
+		ID:        c.ID,
+		Handle:    c.Handle,
+		Credits:   c.Credits,
+		UpdatedAt: c.UpdatedAt,
+	})
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// decodeCustomerCursor decodes the given cursor. It returns api.ErrInvalidCursor if the cursor is malformed or it
+// was generated for a different sort field.
+func decodeCustomerCursor(cursor string, sortBy api.CustomerSort) (customerCursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return customerCursor{}, api.ErrInvalidCursor
+	}
+	var out customerCursor
+	if err = json.Unmarshal(b, &out); err != nil || out.ID == 0 || out.SortBy != sortBy {
+		return customerCursor{}, api.ErrInvalidCursor
+	}
+	return out, nil
+}
+
+// ListCustomers returns a page of the customers of an application matching the given filters.
+func (s *service) ListCustomers(ctx context.Context, req api.ListCustomersRequest) (api.ListCustomersResponse, error) {
+	if err := req.Validate(); err != nil {
+		return api.ListCustomersResponse{}, err
+	}
+
+	sortBy := req.SortBy
+	if len(sortBy) == 0 {
+		sortBy = api.SortByHandle
+	}
+
+	limit := req.Limit
+	if limit == 0 {
+		limit = api.DefaultPageSize
+	}
+
+	opts := persistence.CustomerListOptions{
+		Application:  req.Application,
+		HandlePrefix: req.HandlePrefix,
+		MinCredits:   req.MinCredits,
+		MaxCredits:   req.MaxCredits,
+		SortColumn:   customerSortColumns[sortBy],
+		Descending:   req.Order == api.OrderDescending,
+		// Request an additional customer to know if there's a next page.
+		Limit: limit + 1,
+	}
+
+	if len(req.Cursor) > 0 {
+		cursor, err := decodeCustomerCursor(req.Cursor, sortBy)
+		if err != nil {
+			return api.ListCustomersResponse{}, err
+		}
+		opts.After = cursor.value()
+		opts.AfterID = cursor.ID
+	}
+
+	list, err := persistence.ListCustomers(s.db, opts)
+	if err != nil {
+		return api.ListCustomersResponse{}, err
+	}
+
+	var out api.ListCustomersResponse
+	if len(list) > limit {
+		list = list[:limit]
+		out.NextCursor, err = encodeCustomerCursor(sortBy, list[limit-1])
+		if err != nil {
+			return api.ListCustomersResponse{}, err
+		}
+	}
+
+	out.Customers = make([]api.Customer, len(list))
+	for i, c := range list {
+		out.Customers[i] = toCustomerAPI(c)
+	}
+	return out, nil
+}
+
+// toCustomerAPI converts the given customer model into its API representation.
+func toCustomerAPI(c models.Customer) api.Customer {
+	return api.Customer{
+		Handle:         c.Handle,
+		Application:    c.Application,
+		Credits:        c.Credits,
+		Status:         api.CustomerStatus(c.Status),
+		CreatedAt:      c.CreatedAt,
+		LastActivityAt: c.UpdatedAt,
+	}
+}
+
+// SetCustomerStatus changes the status of a customer and records the change with its reason. Closed customers can't
+// be reopened.
+func (s *service) SetCustomerStatus(ctx context.Context, req api.SetCustomerStatusRequest) (api.SetCustomerStatusResponse, error) {
+	if err := req.Validate(); err != nil {
+		return api.SetCustomerStatusResponse{}, err
+	}
+
+	var change models.CustomerStatusChange
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		c, err := persistence.GetCustomerForUpdate(tx, req.Handle, req.Application)
+		if err == gorm.ErrRecordNotFound {
+			return api.ErrCustomerNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		if api.CustomerStatus(c.Status) == api.CustomerClosed && req.Status != api.CustomerClosed {
+			return api.ErrCustomerClosed
+		}
+
+		if err = persistence.SetCustomerStatus(tx, c, string(req.Status)); err != nil {
+			return err
+		}
+
+		change, err = persistence.CreateCustomerStatusChange(tx, models.CustomerStatusChange{
+			Handle:         c.Handle,
+			Application:    c.Application,
+			PreviousStatus: c.Status,
+			Status:         string(req.Status),
+			Reason:         req.Reason,
+		})
+		return err
+	})
+	if err != nil {
+		return api.SetCustomerStatusResponse{}, err
+	}
+
+	s.logger.Println("Customer status changed:", change.Handle, change.Application, "Status:", change.Status, "Reason:", change.Reason)
+	return api.SetCustomerStatusResponse{CustomerStatusChange: toCustomerStatusChangeAPI(change)}, nil
+}
+
+// ListCustomerStatusChanges returns the latest status changes of a customer.
+func (s *service) ListCustomerStatusChanges(ctx context.Context, req api.ListCustomerStatusChangesRequest) (api.ListCustomerStatusChangesResponse, error) {
+	if err := req.Validate(); err != nil {
+		return api.ListCustomerStatusChangesResponse{}, err
+	}
+
+	limit := req.Limit
+	if limit == 0 {
+		limit = api.DefaultPageSize
+	}
+
+	list, err := persistence.GetCustomerStatusChanges(s.db, req.Handle, req.Application, limit)
+	if err != nil {
+		return api.ListCustomerStatusChangesResponse{}, err
+	}
+
+	out := api.ListCustomerStatusChangesResponse{Changes: make([]api.CustomerStatusChange, len(list))}
+	for i, c := range list {
+		out.Changes[i] = toCustomerStatusChangeAPI(c)
+	}
+	return out, nil
+}
+
+// lockCustomer returns the given customer, locking it until the end of the current transaction. Customers that
+// don't exist yet are created with createCustomer, so they start with the welcome credits of their application.
+// Missing customers are created before taking the lock, since locking a missing row would lock the gap where it
+// goes and make concurrent first operations on the same handle deadlock. The ledger of the application is locked
+// first with lockLedger.
+func lockCustomer(tx *gorm.DB, handle, application string) (models.Customer, error) {
+	if err := lockLedger(tx, application); err != nil {
+		return models.Customer{}, err
+	}
+	if err := ensureCustomer(tx, handle, application); err != nil {
+		return models.Customer{}, err
+	}
+	return persistence.GetCustomerForUpdate(tx, handle, application)
+}
+
+// checkCustomerStatus returns an api.CustomerStatusError if the given customer can't receive or spend credits.
+func checkCustomerStatus(c models.Customer) error {
+	status := api.CustomerStatus(c.Status)
+	if status == api.CustomerActive || len(status) == 0 {
+		return nil
+	}
+	return &api.CustomerStatusError{
+		Handle:      c.Handle,
+		Application: c.Application,
+		Status:      status,
+	}
+}
+
+// lockActiveCustomer locks the given customer like lockCustomer, and checks it can receive or spend credits.
+func lockActiveCustomer(tx *gorm.DB, handle, application string) (models.Customer, error) {
+	c, err := lockCustomer(tx, handle, application)
+	if err != nil {
+		return models.Customer{}, err
+	}
+	if err = checkCustomerStatus(c); err != nil {
+		return models.Customer{}, err
+	}
+	return c, nil
+}
+
+// toCustomerStatusChangeAPI converts the given customer status change model into its API representation.
+func toCustomerStatusChangeAPI(c models.CustomerStatusChange) api.CustomerStatusChange {
+	return api.CustomerStatusChange{
+		Handle:         c.Handle,
+		Application:    c.Application,
+		PreviousStatus: api.CustomerStatus(c.PreviousStatus),
+		Status:         api.CustomerStatus(c.Status),
+		Reason:         c.Reason,
+		CreatedAt:      c.CreatedAt,
+	}
+}
@@ -0,0 +1,261 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"gitlab.com/ignitionrobotics/billing/credits/internal/conf"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/api"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/domain/models"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/domain/persistence"
+	"gorm.io/gorm"
+	"log"
+	"os"
+	"testing"
+)
+
+func TestCustomerCursor(t *testing.T) {
+	c := models.Customer{Model: gorm.Model{ID: 5}, Handle: "test", Credits: 10}
+
+	cursor, err := encodeCustomerCursor(api.SortByBalance, c)
+	assert.NoError(t, err)
+
+	decoded, err := decodeCustomerCursor(cursor, api.SortByBalance)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(5), decoded.ID)
+	assert.Equal(t, 10, decoded.value())
+
+	_, err = decodeCustomerCursor(cursor, api.SortByHandle)
+	assert.Equal(t, api.ErrInvalidCursor, err)
+
+	_, err = decodeCustomerCursor("not a cursor", api.SortByBalance)
+	assert.Equal(t, api.ErrInvalidCursor, err)
+}
+
+type testCustomersSuite struct {
+	suite.Suite
+	DB      *gorm.DB
+	Logger  *log.Logger
+	Service Service
+}
+
+func TestCustomers(t *testing.T) {
+	suite.Run(t, new(testCustomersSuite))
+}
+
+func (s *testCustomersSuite) SetupSuite() {
+	s.Logger = log.New(os.Stdout, "[TestCustomers] ", log.LstdFlags|log.Lshortfile|log.Lmsgprefix)
+
+	var c conf.Config
+	s.Require().NoError(c.Parse())
+
+	var err error
+	s.DB, err = persistence.OpenConn(c.Database)
+	s.Require().NoError(err)
+
+	s.Require().NoError(persistence.DropTables(s.DB))
+}
+
+func (s *testCustomersSuite) SetupTest() {
+	s.Require().NoError(persistence.MigrateTables(s.DB))
+	s.Service = NewCreditsService(s.DB, s.Logger, 1)
+
+	for i := 0; i < 10; i++ {
+		_, err := persistence.CreateCustomer(s.DB, models.Customer{
+			Handle:      fmt.Sprintf("user%d", i),
+			Application: "cloudsim",
+			Credits:     (i % 5) * 10,
+		})
+		s.Require().NoError(err)
+	}
+
+	_, err := persistence.CreateCustomer(s.DB, models.Customer{
+		Handle:      "user_fuel",
+		Application: "fuel",
+		Credits:     100,
+	})
+	s.Require().NoError(err)
+}
+
+func (s *testCustomersSuite) TearDownTest() {
+	s.Require().NoError(persistence.DropTables(s.DB))
+}
+
+func (s *testCustomersSuite) TestListCustomersPagination() {
+	var handles []string
+	req := api.ListCustomersRequest{
+		Application: "cloudsim",
+		SortBy:      api.SortByBalance,
+		Order:       api.OrderDescending,
+		Limit:       3,
+	}
+	for {
+		res, err := s.Service.ListCustomers(context.Background(), req)
+		s.Require().NoError(err)
+		s.Require().LessOrEqual(len(res.Customers), 3)
+		for _, c := range res.Customers {
+			handles = append(handles, c.Handle)
+		}
+		if len(res.NextCursor) == 0 {
+			break
+		}
+		req.Cursor = res.NextCursor
+	}
+
+	s.Assert().Equal([]string{
+		"user9", "user4", "user8", "user3", "user7", "user2", "user6", "user1", "user5", "user0",
+	}, handles)
+}
+
+func (s *testCustomersSuite) TestListCustomersFilters() {
+	min, max := 10, 20
+	res, err := s.Service.ListCustomers(context.Background(), api.ListCustomersRequest{
+		Application: "cloudsim",
+		MinCredits:  &min,
+		MaxCredits:  &max,
+	})
+	s.Require().NoError(err)
+	s.Assert().Len(res.Customers, 4)
+	s.Assert().Empty(res.NextCursor)
+
+	res, err = s.Service.ListCustomers(context.Background(), api.ListCustomersRequest{
+		Application:  "fuel",
+		HandlePrefix: "user_",
+	})
+	s.Require().NoError(err)
+	s.Require().Len(res.Customers, 1)
+	s.Assert().Equal("user_fuel", res.Customers[0].Handle)
+
+	res, err = s.Service.ListCustomers(context.Background(), api.ListCustomersRequest{
+		Application:  "cloudsim",
+		HandlePrefix: "user_",
+	})
+	s.Require().NoError(err)
+	s.Assert().Empty(res.Customers)
+}
+
+func (s *testCustomersSuite) TestListCustomersValidation() {
+	_, err := s.Service.ListCustomers(context.Background(), api.ListCustomersRequest{})
+	s.Assert().Equal(api.ErrMissingApplication, err)
+
+	_, err = s.Service.ListCustomers(context.Background(), api.ListCustomersRequest{
+		Application: "cloudsim",
+		SortBy:      "credits",
+	})
+	s.Assert().Equal(api.ErrInvalidSort, err)
+
+	_, err = s.Service.ListCustomers(context.Background(), api.ListCustomersRequest{
+		Application: "cloudsim",
+		Limit:       api.MaxPageSize + 1,
+	})
+	s.Assert().Equal(api.ErrInvalidLimit, err)
+}
+
+func (s *testCustomersSuite) transaction(handle string, amount uint) api.Transaction {
+	return api.Transaction{
+		Handle:      handle,
+		Amount:      amount,
+		Currency:    "usd",
+		Application: "cloudsim",
+	}
+}
+
+func (s *testCustomersSuite) TestFrozenCustomer() {
+	ctx := context.Background()
+
+	res, err := s.Service.SetCustomerStatus(ctx, api.SetCustomerStatusRequest{
+		Handle:      "user1",
+		Application: "cloudsim",
+		Status:      api.CustomerFrozen,
+		Reason:      "fraud investigation",
+	})
+	s.Require().NoError(err)
+	s.Assert().Equal(api.CustomerActive, res.PreviousStatus)
+	s.Assert().Equal(api.CustomerFrozen, res.Status)
+
+	_, err = s.Service.IncreaseCredits(ctx, api.IncreaseCreditsRequest{Transaction: s.transaction("user1", 10)})
+	s.Assert().True(errors.Is(err, api.ErrCustomerFrozen))
+
+	_, err = s.Service.DecreaseCredits(ctx, api.DecreaseCreditsRequest{Transaction: s.transaction("user1", 5)})
+	var statusErr *api.CustomerStatusError
+	s.Require().True(errors.As(err, &statusErr))
+	s.Assert().Equal(api.CustomerFrozen, statusErr.Status)
+
+	balance, err := s.Service.GetBalance(ctx, api.GetBalanceRequest{Handle: "user1", Application: "cloudsim"})
+	s.Require().NoError(err)
+	s.Assert().Equal(10, balance.Credits)
+	s.Assert().Equal(api.CustomerFrozen, balance.Status)
+
+	_, err = s.Service.SetCustomerStatus(ctx, api.SetCustomerStatusRequest{
+		Handle:      "user1",
+		Application: "cloudsim",
+		Status:      api.CustomerActive,
+		Reason:      "investigation closed",
+	})
+	s.Require().NoError(err)
+
+	_, err = s.Service.DecreaseCredits(ctx, api.DecreaseCreditsRequest{Transaction: s.transaction("user1", 5)})
+	s.Assert().NoError(err)
+
+	changes, err := s.Service.ListCustomerStatusChanges(ctx, api.ListCustomerStatusChangesRequest{
+		Handle:      "user1",
+		Application: "cloudsim",
+	})
+	s.Require().NoError(err)
+	s.Require().Len(changes.Changes, 2)
+	s.Assert().Equal("investigation closed", changes.Changes[0].Reason)
+	s.Assert().Equal(api.CustomerActive, changes.Changes[0].Status)
+	s.Assert().Equal("fraud investigation", changes.Changes[1].Reason)
+}
+
+func (s *testCustomersSuite) TestClosedCustomer() {
+	ctx := context.Background()
+
+	_, err := s.Service.SetCustomerStatus(ctx, api.SetCustomerStatusRequest{
+		Handle:      "user2",
+		Application: "cloudsim",
+		Status:      api.CustomerClosed,
+		Reason:      "requested by the customer",
+	})
+	s.Require().NoError(err)
+
+	_, err = s.Service.IncreaseCredits(ctx, api.IncreaseCreditsRequest{Transaction: s.transaction("user2", 10)})
+	s.Assert().True(errors.Is(err, api.ErrCustomerClosed))
+
+	_, err = s.Service.SetCustomerStatus(ctx, api.SetCustomerStatusRequest{
+		Handle:      "user2",
+		Application: "cloudsim",
+		Status:      api.CustomerActive,
+		Reason:      "reopen",
+	})
+	s.Assert().Equal(api.ErrCustomerClosed, err)
+}
+
+func (s *testCustomersSuite) TestSetCustomerStatusValidation() {
+	ctx := context.Background()
+
+	_, err := s.Service.SetCustomerStatus(ctx, api.SetCustomerStatusRequest{
+		Handle:      "user1",
+		Application: "cloudsim",
+		Status:      api.CustomerFrozen,
+	})
+	s.Assert().Equal(api.ErrMissingReason, err)
+
+	_, err = s.Service.SetCustomerStatus(ctx, api.SetCustomerStatusRequest{
+		Handle:      "user1",
+		Application: "cloudsim",
+		Status:      "deleted",
+		Reason:      "test",
+	})
+	s.Assert().Equal(api.ErrInvalidCustomerStatus, err)
+
+	_, err = s.Service.SetCustomerStatus(ctx, api.SetCustomerStatusRequest{
+		Handle:      "unknown",
+		Application: "cloudsim",
+		Status:      api.CustomerFrozen,
+		Reason:      "test",
+	})
+	s.Assert().Equal(api.ErrCustomerNotFound, err)
+}
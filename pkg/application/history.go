@@ -0,0 +1,103 @@
+package application
+
+import (
+	"context"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/api"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/domain/models"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/domain/persistence"
+	"time"
+)
+
+// GetBalanceAt returns the balance a customer had at a certain point in time. The balance is computed by reverting
+// the balance changes recorded after that time from the current balance.
+func (s *service) GetBalanceAt(ctx context.Context, req api.GetBalanceAtRequest) (api.GetBalanceAtResponse, error) {
+	if err := req.Validate(); err != nil {
+		return api.GetBalanceAtResponse{}, err
+	}
+
+	c, err := persistence.GetCustomer(s.db, req.Handle, req.Application)
+	if err != nil {
+		return api.GetBalanceAtResponse{}, err
+	}
+
+	sum, err := persistence.SumBalanceChangesSince(s.db, req.Handle, req.Application, req.At)
+	if err != nil {
+		return api.GetBalanceAtResponse{}, err
+	}
+
+	return api.GetBalanceAtResponse{
+		Handle:      c.Handle,
+		Application: c.Application,
+		At:          req.At,
+		Credits:     c.Credits - sum,
+	}, nil
+}
+
+// GetBalanceHistory returns the balance of a customer at the end of each bucket of the given date range.
+func (s *service) GetBalanceHistory(ctx context.Context, req api.GetBalanceHistoryRequest) (api.GetBalanceHistoryResponse, error) {
+	if err := req.Validate(); err != nil {
+		return api.GetBalanceHistoryResponse{}, err
+	}
+
+	interval := req.Interval
+	if len(interval) == 0 {
+		interval = api.IntervalDaily
+	}
+
+	start := interval.Truncate(req.From)
+	var buckets int
+	for t := start; t.Before(req.To); t = interval.Next(t) {
+		buckets++
+		if buckets > api.MaxHistoryBuckets {
+			return api.GetBalanceHistoryResponse{}, api.ErrInvalidDateRange
+		}
+	}
+
+	c, err := persistence.GetCustomer(s.db, req.Handle, req.Application)
+	if err != nil {
+		return api.GetBalanceHistoryResponse{}, err
+	}
+
+	changes, err := persistence.GetBalanceChangesSince(s.db, req.Handle, req.Application, start)
+	if err != nil {
+		return api.GetBalanceHistoryResponse{}, err
+	}
+
+	return api.GetBalanceHistoryResponse{
+		Handle:      c.Handle,
+		Application: c.Application,
+		Interval:    interval,
+		Points:      buildBalanceHistory(c.Credits, changes, interval, start, req.To, buckets),
+	}, nil
+}
+
+// buildBalanceHistory groups the given changes into buckets of the given interval between start and end.
+// The changes must be sorted from the oldest to the newest and contain every change recorded after start.
+// balance is the current balance of the customer.
+func buildBalanceHistory(balance int, changes []models.BalanceChange, interval api.Interval, start, end time.Time, buckets int) []api.BalancePoint {
+	for _, change := range changes {
+		balance -= change.Value
+	}
+
+	points := make([]api.BalancePoint, 0, buckets)
+	var i int
+	for t := start; t.Before(end); t = interval.Next(t) {
+		next := interval.Next(t)
+		if next.After(end) {
+			next = end
+		}
+
+		point := api.BalancePoint{
+			Start: t,
+			End:   next,
+		}
+		for ; i < len(changes) && !changes[i].CreatedAt.After(next); i++ {
+			point.Change += changes[i].Value
+		}
+		balance += point.Change
+		point.Credits = balance
+
+		points = append(points, point)
+	}
+	return points
+}
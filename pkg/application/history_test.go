@@ -0,0 +1,148 @@
+package application
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"gitlab.com/ignitionrobotics/billing/credits/internal/conf"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/api"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/domain/models"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/domain/persistence"
+	"gorm.io/gorm"
+	"log"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestBuildBalanceHistory(t *testing.T) {
+	start := time.Date(2021, time.November, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 0, 3)
+
+	changes := []models.BalanceChange{
+		{Model: gorm.Model{CreatedAt: start.Add(2 * time.Hour)}, Value: 10},
+		{Model: gorm.Model{CreatedAt: start.Add(3 * time.Hour)}, Value: -5},
+		{Model: gorm.Model{CreatedAt: start.Add(50 * time.Hour)}, Value: 20},
+		{Model: gorm.Model{CreatedAt: end.Add(time.Hour)}, Value: 100},
+	}
+
+	points := buildBalanceHistory(225, changes, api.IntervalDaily, start, end, 3)
+	assert.Len(t, points, 3)
+
+	assert.Equal(t, start, points[0].Start)
+	assert.Equal(t, 5, points[0].Change)
+	assert.Equal(t, 105, points[0].Credits)
+
+	assert.Equal(t, 0, points[1].Change)
+	assert.Equal(t, 105, points[1].Credits)
+
+	assert.Equal(t, end, points[2].End)
+	assert.Equal(t, 20, points[2].Change)
+	assert.Equal(t, 125, points[2].Credits)
+}
+
+type testHistorySuite struct {
+	suite.Suite
+	DB      *gorm.DB
+	Logger  *log.Logger
+	Service Service
+}
+
+func TestHistory(t *testing.T) {
+	suite.Run(t, new(testHistorySuite))
+}
+
+func (s *testHistorySuite) SetupSuite() {
+	s.Logger = log.New(os.Stdout, "[TestHistory] ", log.LstdFlags|log.Lshortfile|log.Lmsgprefix)
+
+	var c conf.Config
+	s.Require().NoError(c.Parse())
+
+	var err error
+	s.DB, err = persistence.OpenConn(c.Database)
+	s.Require().NoError(err)
+
+	s.Require().NoError(persistence.DropTables(s.DB))
+}
+
+func (s *testHistorySuite) SetupTest() {
+	s.Require().NoError(persistence.MigrateTables(s.DB))
+	s.Service = NewCreditsService(s.DB, s.Logger, 1)
+}
+
+func (s *testHistorySuite) TearDownTest() {
+	s.Require().NoError(persistence.DropTables(s.DB))
+}
+
+func (s *testHistorySuite) TestGetBalanceAt() {
+	_, err := s.Service.IncreaseCredits(context.Background(), api.IncreaseCreditsRequest{Transaction: api.Transaction{
+		Handle:      "test1",
+		Amount:      100,
+		Currency:    "usd",
+		Application: "cloudsim",
+	}})
+	s.Require().NoError(err)
+
+	time.Sleep(10 * time.Millisecond)
+	middle := time.Now()
+	time.Sleep(10 * time.Millisecond)
+
+	_, err = s.Service.DecreaseCredits(context.Background(), api.DecreaseCreditsRequest{Transaction: api.Transaction{
+		Handle:      "test1",
+		Amount:      30,
+		Currency:    "usd",
+		Application: "cloudsim",
+	}})
+	s.Require().NoError(err)
+
+	res, err := s.Service.GetBalanceAt(context.Background(), api.GetBalanceAtRequest{
+		Handle:      "test1",
+		Application: "cloudsim",
+		At:          middle,
+	})
+	s.Require().NoError(err)
+	s.Assert().Equal(100, res.Credits)
+
+	res, err = s.Service.GetBalanceAt(context.Background(), api.GetBalanceAtRequest{
+		Handle:      "test1",
+		Application: "cloudsim",
+		At:          time.Now(),
+	})
+	s.Require().NoError(err)
+	s.Assert().Equal(70, res.Credits)
+}
+
+func (s *testHistorySuite) TestGetBalanceHistory() {
+	_, err := s.Service.IncreaseCredits(context.Background(), api.IncreaseCreditsRequest{Transaction: api.Transaction{
+		Handle:      "test1",
+		Amount:      100,
+		Currency:    "usd",
+		Application: "cloudsim",
+	}})
+	s.Require().NoError(err)
+
+	now := time.Now()
+	res, err := s.Service.GetBalanceHistory(context.Background(), api.GetBalanceHistoryRequest{
+		Handle:      "test1",
+		Application: "cloudsim",
+		From:        now.AddDate(0, 0, -6),
+		To:          now.Add(time.Minute),
+	})
+	s.Require().NoError(err)
+	s.Assert().Equal(api.IntervalDaily, res.Interval)
+	s.Require().Len(res.Points, 7)
+	s.Assert().Equal(0, res.Points[0].Credits)
+	s.Assert().Equal(100, res.Points[6].Credits)
+	s.Assert().Equal(100, res.Points[6].Change)
+}
+
+func (s *testHistorySuite) TestGetBalanceHistoryTooManyBuckets() {
+	now := time.Now()
+	_, err := s.Service.GetBalanceHistory(context.Background(), api.GetBalanceHistoryRequest{
+		Handle:      "test1",
+		Application: "cloudsim",
+		From:        now.AddDate(-10, 0, 0),
+		To:          now,
+	})
+	s.Assert().Equal(api.ErrInvalidDateRange, err)
+}
@@ -0,0 +1,235 @@
+package application
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/api"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/domain/models"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/domain/persistence"
+	"gorm.io/gorm"
+	"time"
+)
+
+// ledgerBatchSize is the amount of ledger records verified at once.
+const ledgerBatchSize = 500
+
+// VerifyLedger walks the hash chain of an application from the first record and reports the first broken link.
+// Records are also compared with the balance changes they recorded, to detect edits made to the credit history, and
+// the last record of every customer is compared with its current balance, to detect edits made to the balances.
+// Everything is read from the same snapshot, so concurrent changes aren't reported as broken links.
+func (s *service) VerifyLedger(ctx context.Context, req api.VerifyLedgerRequest) (api.VerifyLedgerResponse, error) {
+	if len(req.Application) == 0 {
+		return api.VerifyLedgerResponse{}, api.ErrMissingApplication
+	}
+
+	var out api.VerifyLedgerResponse
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		out, err = s.verifyLedger(tx, req.Application)
+		return err
+	})
+	if err != nil {
+		return api.VerifyLedgerResponse{}, err
+	}
+	if out.BrokenLink != nil {
+		s.logger.Println("Broken ledger chain:", req.Application, "Sequence:", out.BrokenLink.Sequence, "Reason:", out.BrokenLink.Reason)
+	}
+	return out, nil
+}
+
+// verifyLedger verifies the ledger chain of an application, see VerifyLedger.
+func (s *service) verifyLedger(tx *gorm.DB, application string) (api.VerifyLedgerResponse, error) {
+	head, err := persistence.GetLedgerHead(tx, application)
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return api.VerifyLedgerResponse{}, err
+	}
+
+	var out api.VerifyLedgerResponse
+	var previous models.LedgerRecord
+	latest := make(map[string]models.LedgerRecord)
+	for {
+		list, err := persistence.GetLedgerRecords(tx, application, previous.Sequence, ledgerBatchSize)
+		if err != nil {
+			return api.VerifyLedgerResponse{}, err
+		}
+		if len(list) == 0 {
+			break
+		}
+
+		link, err := verifyLedgerRecords(tx, previous, list)
+		if err != nil {
+			return api.VerifyLedgerResponse{}, err
+		}
+		if link != nil {
+			out.BrokenLink = link
+			return out, nil
+		}
+
+		for _, r := range list {
+			latest[r.Handle] = r
+		}
+		out.Records += uint(len(list))
+		previous = list[len(list)-1]
+	}
+
+	if previous.Sequence != head.Sequence || previous.Hash != head.Hash {
+		out.BrokenLink = &api.BrokenLink{
+			Sequence: previous.Sequence + 1,
+			Reason:   api.BrokenLinkHead,
+		}
+		return out, nil
+	}
+
+	link, err := verifyLedgerBalances(tx, application, latest)
+	if err != nil {
+		return api.VerifyLedgerResponse{}, err
+	}
+	if link != nil {
+		out.BrokenLink = link
+		return out, nil
+	}
+
+	out.Valid = true
+	out.Head = previous.Hash
+	return out, nil
+}
+
+// verifyLedgerRecords verifies a batch of consecutive ledger records that follow the given previous record. It
+// returns the first broken link, or nil if all the records are valid.
+func verifyLedgerRecords(tx *gorm.DB, previous models.LedgerRecord, list []models.LedgerRecord) (*api.BrokenLink, error) {
+	ids := make([]uint, len(list))
+	for i, r := range list {
+		ids[i] = r.TransactionID
+	}
+	changes, err := persistence.GetBalanceChanges(tx, ids)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]models.BalanceChange, len(changes))
+	for _, c := range changes {
+		byID[c.ID] = c
+	}
+
+	for _, r := range list {
+		link := &api.BrokenLink{Sequence: r.Sequence, TransactionID: r.TransactionID}
+		switch {
+		case r.Sequence != previous.Sequence+1:
+			link.Sequence = previous.Sequence + 1
+			link.TransactionID = 0
+			link.Reason = api.BrokenLinkSequence
+		case r.PreviousHash != previous.Hash:
+			link.Reason = api.BrokenLinkPreviousHash
+		case r.Hash != ledgerHash(r):
+			link.Reason = api.BrokenLinkHash
+		case !matchesBalanceChange(r, byID[r.TransactionID]):
+			link.Reason = api.BrokenLinkBalanceChange
+		default:
+			previous = r
+			continue
+		}
+		return link, nil
+	}
+	return nil, nil
+}
+
+// verifyLedgerBalances compares the balance of every customer of an application with the balance of its last
+// ledger record. Customers without records must have no credits. It returns the first broken link, or nil if all
+// the balances match.
+func verifyLedgerBalances(tx *gorm.DB, application string, latest map[string]models.LedgerRecord) (*api.BrokenLink, error) {
+	opts := persistence.CustomerListOptions{
+		Application: application,
+		SortColumn:  "id",
+		Limit:       ledgerBatchSize,
+	}
+	for {
+		list, err := persistence.ListCustomers(tx, opts)
+		if err != nil {
+			return nil, err
+		}
+		if len(list) == 0 {
+			break
+		}
+
+		for _, c := range list {
+			r := latest[c.Handle]
+			if c.Credits != r.Balance {
+				return &api.BrokenLink{
+					Sequence:      r.Sequence,
+					TransactionID: r.TransactionID,
+					Reason:        api.BrokenLinkCustomerBalance,
+				}, nil
+			}
+		}
+
+		last := list[len(list)-1]
+		opts.After, opts.AfterID = last.ID, last.ID
+	}
+	return nil, nil
+}
+
+// lockLedger locks the head of the ledger chain of an application until the end of the current transaction.
+// Transactions that change credits take it before locking any customer, so the head and the customers are always
+// locked in the same order and can't deadlock with each other.
+func lockLedger(tx *gorm.DB, application string) error {
+	_, err := persistence.GetLedgerHeadForUpdate(tx, application)
+	return err
+}
+
+// appendLedgerRecord appends the given balance change to the ledger chain of its application. The chain head is
+// locked until the end of the transaction, so records are appended one at a time. Callers must have locked it with
+// lockLedger before locking the customer.
+func appendLedgerRecord(tx *gorm.DB, change models.BalanceChange) error {
+	head, err := persistence.GetLedgerHeadForUpdate(tx, change.Application)
+	if err != nil {
+		return err
+	}
+
+	record := models.LedgerRecord{
+		Application:   change.Application,
+		Sequence:      head.Sequence + 1,
+		TransactionID: change.ID,
+		Handle:        change.Handle,
+		Operation:     change.Operation,
+		Value:         change.Value,
+		Balance:       change.Balance,
+		Timestamp:     time.Now().UnixNano(),
+		PreviousHash:  head.Hash,
+	}
+	record.Hash = ledgerHash(record)
+
+	_, err = persistence.AppendLedgerRecord(tx, head, record)
+	return err
+}
+
+// ledgerHash returns the hex-encoded SHA-256 hash of the contents of a ledger record, including the hash of the
+// previous record.
+func ledgerHash(r models.LedgerRecord) string {
+	// Fields are encoded as a JSON array to avoid ambiguities between the values of consecutive fields.
+	b, _ := json.Marshal([]interface{}{
+		r.Application,
+		r.Sequence,
+		r.TransactionID,
+		r.Handle,
+		r.Operation,
+		r.Value,
+		r.Balance,
+		r.Timestamp,
+		r.PreviousHash,
+	})
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+// matchesBalanceChange returns true if the given ledger record contains the current values of the given balance
+// change, and the balance change hasn't been deleted.
+func matchesBalanceChange(r models.LedgerRecord, change models.BalanceChange) bool {
+	return change.ID == r.TransactionID &&
+		!change.DeletedAt.Valid &&
+		change.Application == r.Application &&
+		change.Handle == r.Handle &&
+		change.Operation == r.Operation &&
+		change.Value == r.Value &&
+		change.Balance == r.Balance
+}
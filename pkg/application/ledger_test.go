@@ -0,0 +1,169 @@
+package application
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"gitlab.com/ignitionrobotics/billing/credits/internal/conf"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/api"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/domain/models"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/domain/persistence"
+	"gorm.io/gorm"
+	"log"
+	"os"
+	"testing"
+)
+
+func TestLedgerHash(t *testing.T) {
+	r := models.LedgerRecord{
+		Application:   "cloudsim",
+		Sequence:      1,
+		TransactionID: 10,
+		Handle:        "test1",
+		Operation:     models.OperationIncrease,
+		Value:         50,
+		Balance:       150,
+		Timestamp:     1,
+	}
+	hash := ledgerHash(r)
+	assert.Len(t, hash, 64)
+	assert.Equal(t, hash, ledgerHash(r))
+
+	edited := r
+	edited.Value = 500
+	assert.NotEqual(t, hash, ledgerHash(edited))
+
+	chained := r
+	chained.PreviousHash = hash
+	assert.NotEqual(t, hash, ledgerHash(chained))
+
+	// Moving characters between fields changes the hash.
+	moved := r
+	moved.Application, moved.Handle = "cloudsimt", "est1"
+	assert.NotEqual(t, hash, ledgerHash(moved))
+}
+
+type testLedgerSuite struct {
+	suite.Suite
+	DB      *gorm.DB
+	Logger  *log.Logger
+	Service Service
+}
+
+func TestLedger(t *testing.T) {
+	suite.Run(t, new(testLedgerSuite))
+}
+
+func (s *testLedgerSuite) SetupSuite() {
+	s.Logger = log.New(os.Stdout, "[TestLedger] ", log.LstdFlags|log.Lshortfile|log.Lmsgprefix)
+
+	var c conf.Config
+	s.Require().NoError(c.Parse())
+
+	var err error
+	s.DB, err = persistence.OpenConn(c.Database)
+	s.Require().NoError(err)
+
+	s.Require().NoError(persistence.DropTables(s.DB))
+}
+
+func (s *testLedgerSuite) SetupTest() {
+	s.Require().NoError(persistence.MigrateTables(s.DB))
+	s.Service = NewCreditsService(s.DB, s.Logger, 1)
+
+	for _, amount := range []uint{100, 50, 25} {
+		_, err := s.Service.IncreaseCredits(context.Background(), api.IncreaseCreditsRequest{
+			Transaction: api.Transaction{
+				Handle:      "test1",
+				Amount:      amount,
+				Currency:    "usd",
+				Application: "cloudsim",
+			},
+		})
+		s.Require().NoError(err)
+	}
+}
+
+func (s *testLedgerSuite) TearDownTest() {
+	s.Require().NoError(persistence.DropTables(s.DB))
+}
+
+func (s *testLedgerSuite) verify() api.VerifyLedgerResponse {
+	res, err := s.Service.VerifyLedger(context.Background(), api.VerifyLedgerRequest{Application: "cloudsim"})
+	s.Require().NoError(err)
+	return res
+}
+
+func (s *testLedgerSuite) TestValidChain() {
+	res := s.verify()
+	s.Assert().True(res.Valid)
+	s.Assert().Equal(uint(3), res.Records)
+	s.Assert().NotEmpty(res.Head)
+	s.Assert().Nil(res.BrokenLink)
+
+	// Applications are chained independently
+	res, err := s.Service.VerifyLedger(context.Background(), api.VerifyLedgerRequest{Application: "other"})
+	s.Require().NoError(err)
+	s.Assert().True(res.Valid)
+	s.Assert().Zero(res.Records)
+}
+
+func (s *testLedgerSuite) TestEditedBalanceChange() {
+	s.Require().NoError(s.DB.Model(&models.BalanceChange{}).Where("id = ?", 2).Update("value", 500).Error)
+
+	res := s.verify()
+	s.Assert().False(res.Valid)
+	s.Require().NotNil(res.BrokenLink)
+	s.Assert().Equal(uint(2), res.BrokenLink.Sequence)
+	s.Assert().Equal(uint(2), res.BrokenLink.TransactionID)
+	s.Assert().Equal(api.BrokenLinkBalanceChange, res.BrokenLink.Reason)
+}
+
+func (s *testLedgerSuite) TestEditedRecord() {
+	s.Require().NoError(s.DB.Model(&models.LedgerRecord{}).Where("sequence = ?", 2).Update("balance", 1000).Error)
+
+	res := s.verify()
+	s.Assert().False(res.Valid)
+	s.Require().NotNil(res.BrokenLink)
+	s.Assert().Equal(uint(2), res.BrokenLink.Sequence)
+	s.Assert().Equal(api.BrokenLinkHash, res.BrokenLink.Reason)
+}
+
+func (s *testLedgerSuite) TestRemovedRecords() {
+	s.Require().NoError(s.DB.Unscoped().Where("sequence = ?", 3).Delete(&models.LedgerRecord{}).Error)
+
+	res := s.verify()
+	s.Assert().False(res.Valid)
+	s.Require().NotNil(res.BrokenLink)
+	s.Assert().Equal(uint(3), res.BrokenLink.Sequence)
+	s.Assert().Equal(api.BrokenLinkHead, res.BrokenLink.Reason)
+
+	s.Require().NoError(s.DB.Unscoped().Where("sequence = ?", 1).Delete(&models.LedgerRecord{}).Error)
+
+	res = s.verify()
+	s.Assert().False(res.Valid)
+	s.Require().NotNil(res.BrokenLink)
+	s.Assert().Equal(uint(1), res.BrokenLink.Sequence)
+	s.Assert().Equal(api.BrokenLinkSequence, res.BrokenLink.Reason)
+}
+
+func (s *testLedgerSuite) TestEditedCustomerBalance() {
+	s.Require().NoError(s.DB.Model(&models.Customer{}).Where("handle = ?", "test1").Update("credits", 1000).Error)
+
+	res := s.verify()
+	s.Assert().False(res.Valid)
+	s.Require().NotNil(res.BrokenLink)
+	s.Assert().Equal(uint(3), res.BrokenLink.Sequence)
+	s.Assert().Equal(uint(3), res.BrokenLink.TransactionID)
+	s.Assert().Equal(api.BrokenLinkCustomerBalance, res.BrokenLink.Reason)
+
+	// Customers without records can't have credits either.
+	s.Require().NoError(s.DB.Model(&models.Customer{}).Where("handle = ?", "test1").Update("credits", 175).Error)
+	s.Require().NoError(s.DB.Create(&models.Customer{Handle: "test2", Application: "cloudsim", Credits: 10}).Error)
+
+	res = s.verify()
+	s.Assert().False(res.Valid)
+	s.Require().NotNil(res.BrokenLink)
+	s.Assert().Zero(res.BrokenLink.Sequence)
+	s.Assert().Equal(api.BrokenLinkCustomerBalance, res.BrokenLink.Reason)
+}
@@ -0,0 +1,188 @@
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/api"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/domain/models"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/domain/persistence"
+	"gorm.io/gorm"
+	"io"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// EventSink receives the domain events published by the outbox relay.
+type EventSink interface {
+	// Publish publishes the given events, in order. Events may be published more than once if the relay fails
+	// after publishing them, so consumers should deduplicate them by ID.
+	Publish(ctx context.Context, events []api.Event) error
+}
+
+// writerEventSink is an EventSink that writes events as JSON lines.
+type writerEventSink struct {
+	lock    sync.Mutex
+	encoder *json.Encoder
+}
+
+// Publish writes each event as a single JSON line.
+func (s *writerEventSink) Publish(ctx context.Context, events []api.Event) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for _, e := range events {
+		if err := s.encoder.Encode(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// NewWriterEventSink initializes a new EventSink that writes events to w as JSON lines.
+func NewWriterEventSink(w io.Writer) EventSink {
+	return &writerEventSink{encoder: json.NewEncoder(w)}
+}
+
+// NewStdoutEventSink initializes a new EventSink that writes events to the standard output as JSON lines.
+func NewStdoutEventSink() EventSink {
+	return NewWriterEventSink(os.Stdout)
+}
+
+// NewFileEventSink initializes a new EventSink that appends events to the file at the given path as JSON lines.
+// The file is created if it doesn't exist.
+func NewFileEventSink(path string) (EventSink, error) {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return NewWriterEventSink(f), nil
+}
+
+// MemoryEventSink is an EventSink that keeps the published events in memory. It's intended to be used in tests.
+type MemoryEventSink struct {
+	lock   sync.Mutex
+	events []api.Event
+}
+
+// Publish appends the given events to the list of published events.
+func (s *MemoryEventSink) Publish(ctx context.Context, events []api.Event) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.events = append(s.events, events...)
+	return nil
+}
+
+// Events returns the events published so far.
+func (s *MemoryEventSink) Events() []api.Event {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	out := make([]api.Event, len(s.events))
+	copy(out, s.events)
+	return out
+}
+
+// NewMemoryEventSink initializes a new MemoryEventSink.
+func NewMemoryEventSink() *MemoryEventSink {
+	return &MemoryEventSink{}
+}
+
+// newBalanceEvent creates a new event of the given type for a balance change. It returns the event and its JSON
+// representation.
+func newBalanceEvent(t api.EventType, change models.BalanceChange) (api.Event, []byte, error) {
+	id, err := randomToken(16)
+	if err != nil {
+		return api.Event{}, nil, err
+	}
+	event := api.Event{
+		ID:        "evt_" + id,
+		Type:      t,
+		CreatedAt: change.CreatedAt,
+		Data: api.EventData{
+			Handle:      change.Handle,
+			Application: change.Application,
+			Operation:   change.Operation,
+			Value:       change.Value,
+			Balance:     change.Balance,
+		},
+	}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return api.Event{}, nil, err
+	}
+	return event, payload, nil
+}
+
+// enqueueOutboxEvent writes the api.EventCreditsUpdated event of the given balance change to the outbox.
+// It must be called in the same transaction that recorded the change.
+func enqueueOutboxEvent(tx *gorm.DB, change models.BalanceChange) error {
+	event, payload, err := newBalanceEvent(api.EventCreditsUpdated, change)
+	if err != nil {
+		return err
+	}
+	_, err = persistence.CreateOutboxEvent(tx, models.OutboxEvent{
+		EventID:   event.ID,
+		EventType: string(event.Type),
+		Payload:   string(payload),
+	})
+	return err
+}
+
+// outboxBatchSize is the maximum amount of events published in a single run of the outbox relay.
+const outboxBatchSize = 500
+
+// outboxRelay is a Worker that publishes the outbox events to an EventSink.
+type outboxRelay struct {
+	db     *gorm.DB
+	logger *log.Logger
+	sink   EventSink
+}
+
+// Run publishes the outbox events every interval until ctx is done.
+func (r *outboxRelay) Run(ctx context.Context, interval time.Duration) {
+	runPeriodically(ctx, interval, r.logger, "Outbox relay", r.RunOnce)
+}
+
+// RunOnce publishes the events that haven't been published yet, in the order they were written. Events are
+// marked as published only after the sink accepts them.
+func (r *outboxRelay) RunOnce(ctx context.Context) error {
+	for {
+		list, err := persistence.GetUnpublishedOutboxEvents(r.db, outboxBatchSize)
+		if err != nil || len(list) == 0 {
+			return err
+		}
+
+		events := make([]api.Event, len(list))
+		ids := make([]uint, len(list))
+		for i, e := range list {
+			if err = json.Unmarshal([]byte(e.Payload), &events[i]); err != nil {
+				return err
+			}
+			ids[i] = e.ID
+		}
+
+		if err = r.sink.Publish(ctx, events); err != nil {
+			return err
+		}
+
+		if err = persistence.MarkOutboxEventsPublished(r.db, ids, time.Now()); err != nil {
+			return err
+		}
+
+		if len(list) < outboxBatchSize {
+			return nil
+		}
+	}
+}
+
+// NewOutboxRelay initializes a new Worker that publishes the outbox events to the given sink.
+func NewOutboxRelay(db *gorm.DB, logger *log.Logger, sink EventSink) Worker {
+	if logger == nil {
+		logger = log.New(io.Discard, "", log.LstdFlags)
+	}
+	return &outboxRelay{
+		db:     db,
+		logger: logger,
+		sink:   sink,
+	}
+}
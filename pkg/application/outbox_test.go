@@ -0,0 +1,146 @@
+package application
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"gitlab.com/ignitionrobotics/billing/credits/internal/conf"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/api"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/domain/models"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/domain/persistence"
+	"gorm.io/gorm"
+	"log"
+	"os"
+	"testing"
+)
+
+func TestWriterEventSink(t *testing.T) {
+	var buf bytes.Buffer
+	sink := NewWriterEventSink(&buf)
+
+	events := []api.Event{
+		{ID: "evt_1", Type: api.EventCreditsUpdated, Data: api.EventData{Handle: "test1", Value: 10}},
+		{ID: "evt_2", Type: api.EventCreditsUpdated, Data: api.EventData{Handle: "test1", Value: -5}},
+	}
+	require.NoError(t, sink.Publish(context.Background(), events))
+
+	dec := json.NewDecoder(&buf)
+	for _, expected := range events {
+		var e api.Event
+		require.NoError(t, dec.Decode(&e))
+		assert.Equal(t, expected.ID, e.ID)
+		assert.Equal(t, expected.Data.Value, e.Data.Value)
+	}
+	assert.False(t, dec.More())
+}
+
+// failingEventSink is an EventSink that always fails.
+type failingEventSink struct{}
+
+func (failingEventSink) Publish(ctx context.Context, events []api.Event) error {
+	return errors.New("sink unavailable")
+}
+
+type testOutboxSuite struct {
+	suite.Suite
+	DB      *gorm.DB
+	Logger  *log.Logger
+	Service Service
+	Sink    *MemoryEventSink
+	Relay   Worker
+}
+
+func TestOutbox(t *testing.T) {
+	suite.Run(t, new(testOutboxSuite))
+}
+
+func (s *testOutboxSuite) SetupSuite() {
+	s.Logger = log.New(os.Stdout, "[TestOutbox] ", log.LstdFlags|log.Lshortfile|log.Lmsgprefix)
+
+	var c conf.Config
+	s.Require().NoError(c.Parse())
+
+	var err error
+	s.DB, err = persistence.OpenConn(c.Database)
+	s.Require().NoError(err)
+
+	s.Require().NoError(persistence.DropTables(s.DB))
+}
+
+func (s *testOutboxSuite) SetupTest() {
+	s.Require().NoError(persistence.MigrateTables(s.DB))
+	s.Service = NewCreditsService(s.DB, s.Logger, 1)
+	s.Sink = NewMemoryEventSink()
+	s.Relay = NewOutboxRelay(s.DB, s.Logger, s.Sink)
+
+	_, err := persistence.CreateCustomer(s.DB, models.Customer{
+		Handle:      "test1",
+		Application: "cloudsim",
+		Credits:     100,
+	})
+	s.Require().NoError(err)
+}
+
+func (s *testOutboxSuite) TearDownTest() {
+	s.Require().NoError(persistence.DropTables(s.DB))
+}
+
+func (s *testOutboxSuite) transaction(amount uint) api.Transaction {
+	return api.Transaction{
+		Handle:      "test1",
+		Amount:      amount,
+		Currency:    "usd",
+		Application: "cloudsim",
+	}
+}
+
+func (s *testOutboxSuite) TestPublishEvents() {
+	_, err := s.Service.IncreaseCredits(context.Background(), api.IncreaseCreditsRequest{Transaction: s.transaction(50)})
+	s.Require().NoError(err)
+	_, err = s.Service.DecreaseCredits(context.Background(), api.DecreaseCreditsRequest{Transaction: s.transaction(30)})
+	s.Require().NoError(err)
+
+	s.Require().NoError(s.Relay.RunOnce(context.Background()))
+
+	events := s.Sink.Events()
+	s.Require().Len(events, 2)
+	s.Assert().Equal(api.EventCreditsUpdated, events[0].Type)
+	s.Assert().Equal(50, events[0].Data.Value)
+	s.Assert().Equal(150, events[0].Data.Balance)
+	s.Assert().Equal(models.OperationIncrease, events[0].Data.Operation)
+	s.Assert().Equal(-30, events[1].Data.Value)
+	s.Assert().Equal(120, events[1].Data.Balance)
+	s.Assert().NotEqual(events[0].ID, events[1].ID)
+
+	// Published events are not published again
+	s.Require().NoError(s.Relay.RunOnce(context.Background()))
+	s.Assert().Len(s.Sink.Events(), 2)
+}
+
+func (s *testOutboxSuite) TestRolledBackUpdateDoesNotEmitEvents() {
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		if _, err := updateCredits(tx, "test1", "cloudsim", 10, models.OperationIncrease); err != nil {
+			return err
+		}
+		return errors.New("rollback")
+	})
+	s.Require().Error(err)
+
+	s.Require().NoError(s.Relay.RunOnce(context.Background()))
+	s.Assert().Empty(s.Sink.Events())
+}
+
+func (s *testOutboxSuite) TestSinkFailure() {
+	_, err := s.Service.IncreaseCredits(context.Background(), api.IncreaseCreditsRequest{Transaction: s.transaction(50)})
+	s.Require().NoError(err)
+
+	s.Assert().Error(NewOutboxRelay(s.DB, s.Logger, failingEventSink{}).RunOnce(context.Background()))
+
+	// Events that failed to be published are kept in the outbox
+	s.Require().NoError(s.Relay.RunOnce(context.Background()))
+	s.Assert().Len(s.Sink.Events(), 1)
+}
@@ -0,0 +1,118 @@
+package application
+
+import (
+	"context"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/api"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/domain/models"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/domain/persistence"
+	"gorm.io/gorm"
+)
+
+// SetOverdraftLimit sets the overdraft limit of an application, or of a single customer if a handle is given.
+func (s *service) SetOverdraftLimit(ctx context.Context, req api.SetOverdraftLimitRequest) (api.SetOverdraftLimitResponse, error) {
+	if err := req.Validate(); err != nil {
+		return api.SetOverdraftLimitResponse{}, err
+	}
+
+	var limit models.OverdraftLimit
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := shareConversionPolicy(tx, req.Application); err != nil {
+			return err
+		}
+		var err error
+		limit, err = persistence.SetOverdraftLimit(tx, models.OverdraftLimit{
+			Application: req.Application,
+			Handle:      req.Handle,
+			Amount:      req.Limit,
+		})
+		return err
+	})
+	if err != nil {
+		return api.SetOverdraftLimitResponse{}, err
+	}
+
+	return api.SetOverdraftLimitResponse{OverdraftLimit: toOverdraftLimitAPI(limit)}, nil
+}
+
+// GetOverdraftLimit returns the overdraft limit that applies to an application or to a single customer.
+func (s *service) GetOverdraftLimit(ctx context.Context, req api.GetOverdraftLimitRequest) (api.GetOverdraftLimitResponse, error) {
+	if len(req.Application) == 0 {
+		return api.GetOverdraftLimitResponse{}, api.ErrMissingApplication
+	}
+
+	limit, err := persistence.GetEffectiveOverdraftLimit(s.db, req.Application, req.Handle)
+	if err == gorm.ErrRecordNotFound {
+		return api.GetOverdraftLimitResponse{}, api.ErrOverdraftLimitNotFound
+	}
+	if err != nil {
+		return api.GetOverdraftLimitResponse{}, err
+	}
+
+	return api.GetOverdraftLimitResponse{OverdraftLimit: toOverdraftLimitAPI(limit)}, nil
+}
+
+// DeleteOverdraftLimit removes the overdraft limit of an application, or of a single customer if a handle is
+// given. Customers without their own limit fall back to the application limit.
+func (s *service) DeleteOverdraftLimit(ctx context.Context, req api.DeleteOverdraftLimitRequest) (api.DeleteOverdraftLimitResponse, error) {
+	if len(req.Application) == 0 {
+		return api.DeleteOverdraftLimitResponse{}, api.ErrMissingApplication
+	}
+
+	limit, err := persistence.GetOverdraftLimit(s.db, req.Application, req.Handle)
+	if err == gorm.ErrRecordNotFound {
+		return api.DeleteOverdraftLimitResponse{}, api.ErrOverdraftLimitNotFound
+	}
+	if err != nil {
+		return api.DeleteOverdraftLimitResponse{}, err
+	}
+
+	if err = persistence.DeleteOverdraftLimit(s.db, limit); err != nil {
+		return api.DeleteOverdraftLimitResponse{}, err
+	}
+	return api.DeleteOverdraftLimitResponse{}, nil
+}
+
+// getOverdraftLimit returns the amount of credits a customer is allowed to go below zero. Customers without an
+// overdraft limit can't go below zero.
+func getOverdraftLimit(db *gorm.DB, handle, application string) (uint, error) {
+	limit, err := persistence.GetEffectiveOverdraftLimit(db, application, handle)
+	if err == gorm.ErrRecordNotFound {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return limit.Amount, nil
+}
+
+// checkOverdraft checks that removing value credits from the given customer doesn't leave its balance below its
+// overdraft limit. The customer must have been locked in the same transaction that removes the credits.
+func checkOverdraft(tx *gorm.DB, c models.Customer, value uint) error {
+	limit, err := getOverdraftLimit(tx, c.Handle, c.Application)
+	if err != nil {
+		return err
+	}
+
+	if c.Credits-int(value) < -int(limit) {
+		return api.ErrInsufficientCredits
+	}
+	return nil
+}
+
+// spendableCredits returns the amount of credits that can be spent given a balance and an overdraft limit.
+func spendableCredits(balance int, limit uint) int {
+	spendable := balance + int(limit)
+	if spendable < 0 {
+		return 0
+	}
+	return spendable
+}
+
+// toOverdraftLimitAPI converts the given overdraft limit model into its API representation.
+func toOverdraftLimitAPI(limit models.OverdraftLimit) api.OverdraftLimit {
+	return api.OverdraftLimit{
+		Application: limit.Application,
+		Handle:      limit.Handle,
+		Limit:       limit.Amount,
+	}
+}
@@ -0,0 +1,175 @@
+package application
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"gitlab.com/ignitionrobotics/billing/credits/internal/conf"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/api"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/domain/models"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/domain/persistence"
+	"gorm.io/gorm"
+	"log"
+	"os"
+	"sync"
+	"testing"
+)
+
+func TestSpendableCredits(t *testing.T) {
+	assert.Equal(t, 100, spendableCredits(100, 0))
+	assert.Equal(t, 150, spendableCredits(100, 50))
+	assert.Equal(t, 20, spendableCredits(-30, 50))
+	assert.Equal(t, 0, spendableCredits(-60, 50))
+}
+
+type testOverdraftsSuite struct {
+	suite.Suite
+	DB      *gorm.DB
+	Logger  *log.Logger
+	Service Service
+}
+
+func TestOverdrafts(t *testing.T) {
+	suite.Run(t, new(testOverdraftsSuite))
+}
+
+func (s *testOverdraftsSuite) SetupSuite() {
+	s.Logger = log.New(os.Stdout, "[TestOverdrafts] ", log.LstdFlags|log.Lshortfile|log.Lmsgprefix)
+
+	var c conf.Config
+	s.Require().NoError(c.Parse())
+
+	var err error
+	s.DB, err = persistence.OpenConn(c.Database)
+	s.Require().NoError(err)
+
+	s.Require().NoError(persistence.DropTables(s.DB))
+}
+
+func (s *testOverdraftsSuite) SetupTest() {
+	s.Require().NoError(persistence.MigrateTables(s.DB))
+	s.Service = NewCreditsService(s.DB, s.Logger, 1)
+
+	for _, handle := range []string{"test1", "test2"} {
+		_, err := persistence.CreateCustomer(s.DB, models.Customer{
+			Handle:      handle,
+			Application: "cloudsim",
+			Credits:     100,
+		})
+		s.Require().NoError(err)
+	}
+}
+
+func (s *testOverdraftsSuite) TearDownTest() {
+	s.Require().NoError(persistence.DropTables(s.DB))
+}
+
+func (s *testOverdraftsSuite) decrease(handle string, amount uint) (api.DecreaseCreditsResponse, error) {
+	return s.Service.DecreaseCredits(context.Background(), api.DecreaseCreditsRequest{
+		Transaction: api.Transaction{
+			Handle:      handle,
+			Amount:      amount,
+			Currency:    "usd",
+			Application: "cloudsim",
+		},
+	})
+}
+
+func (s *testOverdraftsSuite) setLimit(handle string, limit uint) {
+	_, err := s.Service.SetOverdraftLimit(context.Background(), api.SetOverdraftLimitRequest{
+		OverdraftLimit: api.OverdraftLimit{Application: "cloudsim", Handle: handle, Limit: limit},
+	})
+	s.Require().NoError(err)
+}
+
+func (s *testOverdraftsSuite) TestNoOverdraftByDefault() {
+	_, err := s.decrease("test1", 101)
+	s.Assert().Equal(api.ErrInsufficientCredits, err)
+
+	res, err := s.decrease("test1", 100)
+	s.Require().NoError(err)
+	s.Assert().Zero(res.Balance)
+
+	// Customers that don't exist yet have no credits
+	_, err = s.decrease("test3", 1)
+	s.Assert().Equal(api.ErrInsufficientCredits, err)
+}
+
+func (s *testOverdraftsSuite) TestCustomerOverride() {
+	s.setLimit("", 20)
+	s.setLimit("test2", 500)
+
+	_, err := s.decrease("test1", 130)
+	s.Assert().Equal(api.ErrInsufficientCredits, err)
+
+	res, err := s.decrease("test1", 120)
+	s.Require().NoError(err)
+	s.Assert().Equal(-20, res.Balance)
+
+	res, err = s.decrease("test2", 600)
+	s.Require().NoError(err)
+	s.Assert().Equal(-500, res.Balance)
+
+	balance, err := s.Service.GetBalance(context.Background(), api.GetBalanceRequest{Handle: "test2", Application: "cloudsim"})
+	s.Require().NoError(err)
+	s.Assert().Equal(-500, balance.Credits)
+	s.Assert().Equal(uint(500), balance.OverdraftLimit)
+	s.Assert().Zero(balance.Spendable)
+
+	// Removing the override falls back to the application limit
+	_, err = s.Service.DeleteOverdraftLimit(context.Background(), api.DeleteOverdraftLimitRequest{
+		Application: "cloudsim",
+		Handle:      "test2",
+	})
+	s.Require().NoError(err)
+
+	limit, err := s.Service.GetOverdraftLimit(context.Background(), api.GetOverdraftLimitRequest{
+		Application: "cloudsim",
+		Handle:      "test2",
+	})
+	s.Require().NoError(err)
+	s.Assert().Empty(limit.Handle)
+	s.Assert().Equal(uint(20), limit.Limit)
+
+	_, err = s.Service.DeleteOverdraftLimit(context.Background(), api.DeleteOverdraftLimitRequest{
+		Application: "cloudsim",
+		Handle:      "test2",
+	})
+	s.Assert().Equal(api.ErrOverdraftLimitNotFound, err)
+}
+
+func (s *testOverdraftsSuite) TestGetBalanceSpendable() {
+	s.setLimit("test1", 50)
+
+	balance, err := s.Service.GetBalance(context.Background(), api.GetBalanceRequest{Handle: "test1", Application: "cloudsim"})
+	s.Require().NoError(err)
+	s.Assert().Equal(100, balance.Credits)
+	s.Assert().Equal(150, balance.Spendable)
+}
+
+func (s *testOverdraftsSuite) TestConcurrentDecreases() {
+	var wg sync.WaitGroup
+	errs := make(chan error, 10)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := s.decrease("test1", 30)
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	var succeeded int
+	for err := range errs {
+		if err == nil {
+			succeeded++
+		}
+	}
+	s.Assert().Equal(3, succeeded)
+
+	c, err := persistence.GetCustomer(s.DB, "test1", "cloudsim")
+	s.Require().NoError(err)
+	s.Assert().Equal(10, c.Credits)
+}
@@ -0,0 +1,155 @@
+package application
+
+import (
+	"context"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/api"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/domain/models"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/domain/persistence"
+	"gorm.io/gorm"
+)
+
+// ProcessPaymentEvent processes an event sent by the payment provider. Completed purchases are credited once per
+// payment, refunds debit the refunded amount and chargebacks debit the credits of the payment that haven't been
+// refunded yet. Each event is processed only once.
+func (s *service) ProcessPaymentEvent(ctx context.Context, req api.ProcessPaymentEventRequest) (api.ProcessPaymentEventResponse, error) {
+	if err := req.Validate(); err != nil {
+		return api.ProcessPaymentEventResponse{}, err
+	}
+
+	switch req.Type {
+	case api.PaymentCompleted, api.PaymentRefunded, api.PaymentDisputed:
+	default:
+		s.logger.Println("Ignoring payment event:", req.ID, "Type:", req.Type)
+		return api.ProcessPaymentEventResponse{}, nil
+	}
+
+	var out api.ProcessPaymentEventResponse
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		_, err := persistence.GetPaymentEvent(tx, req.ID)
+		if err == nil {
+			return nil
+		}
+		if err != gorm.ErrRecordNotFound {
+			return err
+		}
+
+		var change models.BalanceChange
+		if req.Type == api.PaymentCompleted {
+			change, err = s.creditPayment(tx, req.Data)
+		} else {
+			change, err = s.debitPayment(tx, req.Type, req.Data)
+		}
+		if err != nil {
+			return err
+		}
+
+		_, err = persistence.CreatePaymentEvent(tx, models.PaymentEvent{
+			EventID:   req.ID,
+			PaymentID: req.Data.PaymentID,
+			Type:      string(req.Type),
+			Credits:   change.Value,
+		})
+		if err != nil {
+			return err
+		}
+
+		out = api.ProcessPaymentEventResponse{
+			Processed: change.ID != 0,
+			Credits:   change.Value,
+			Balance:   change.Balance,
+		}
+		return nil
+	})
+	if err != nil {
+		return api.ProcessPaymentEventResponse{}, err
+	}
+
+	return out, nil
+}
+
+// creditPayment records a completed payment and credits the customer. Payments that have already been credited
+// are ignored, in which case an empty balance change is returned. Frozen and closed customers can't receive credits,
+// so their payments fail with an api.CustomerStatusError and must be sent again once the customer is active.
+func (s *service) creditPayment(tx *gorm.DB, data api.PaymentEventData) (models.BalanceChange, error) {
+	_, err := persistence.GetPaymentForUpdate(tx, data.PaymentID)
+	if err == nil {
+		s.logger.Println("Payment already credited:", data.PaymentID)
+		return models.BalanceChange{}, nil
+	}
+	if err != gorm.ErrRecordNotFound {
+		return models.BalanceChange{}, err
+	}
+
+	if _, err = lockActiveCustomer(tx, data.Handle, data.Application); err != nil {
+		return models.BalanceChange{}, err
+	}
+
+	p, err := s.purchaseCredits(tx, data.Application, data.Amount, data.Currency)
+	if err != nil {
+		return models.BalanceChange{}, err
+	}
+	change, err := updateCredits(tx, data.Handle, data.Application, int(p.Credits), models.OperationPurchase)
+	if err != nil {
+		return models.BalanceChange{}, err
+	}
+
+	_, err = persistence.CreatePayment(tx, models.Payment{
+		PaymentID:     data.PaymentID,
+		Handle:        data.Handle,
+		Application:   data.Application,
+		Amount:        data.Amount,
+		Currency:      data.Currency,
+		Credits:       p.Credits,
+		TransactionID: &change.ID,
+	})
+	if err != nil {
+		return models.BalanceChange{}, err
+	}
+	return change, nil
+}
+
+// debitPayment debits the credits of a refunded or disputed payment. The debited credits never exceed the credits
+// added by the payment. A refund without amount refunds the whole payment, and partial refunds debit the share of
+// the payment credits bought with the refunded amount. The money has already been returned, so the credits are
+// debited even if the customer is frozen or closed.
+func (s *service) debitPayment(tx *gorm.DB, t api.PaymentEventType, data api.PaymentEventData) (models.BalanceChange, error) {
+	payment, err := persistence.GetPaymentForUpdate(tx, data.PaymentID)
+	if err == gorm.ErrRecordNotFound {
+		return models.BalanceChange{}, api.ErrPaymentNotFound
+	}
+	if err != nil {
+		return models.BalanceChange{}, err
+	}
+
+	remaining := payment.Credits - payment.DebitedCredits
+	credits := remaining
+	if t == api.PaymentRefunded && data.Amount > 0 && payment.Amount > 0 {
+		if c := refundedCredits(payment, data.Amount); c < remaining {
+			credits = c
+		}
+	}
+	if credits == 0 {
+		s.logger.Println("Payment already debited:", data.PaymentID)
+		return models.BalanceChange{}, nil
+	}
+
+	if _, err = lockCustomer(tx, payment.Handle, payment.Application); err != nil {
+		return models.BalanceChange{}, err
+	}
+
+	if err = persistence.SetPaymentDebitedCredits(tx, payment, payment.DebitedCredits+credits); err != nil {
+		return models.BalanceChange{}, err
+	}
+
+	operation := models.OperationRefund
+	if t == api.PaymentDisputed {
+		operation = models.OperationChargeback
+	}
+	return updateCredits(tx, payment.Handle, payment.Application, -1*int(credits), operation)
+}
+
+// refundedCredits returns the credits bought with the given amount of a payment. Payments may include bonus credits
+// or be packages, so the credits are proportional to the credits of the payment, rounded up.
+func refundedCredits(payment models.Payment, amount uint) uint {
+	return uint((uint64(payment.Credits)*uint64(amount) + uint64(payment.Amount) - 1) / uint64(payment.Amount))
+}
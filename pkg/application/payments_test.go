@@ -0,0 +1,191 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/suite"
+	"gitlab.com/ignitionrobotics/billing/credits/internal/conf"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/api"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/domain/models"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/domain/persistence"
+	"gorm.io/gorm"
+	"log"
+	"os"
+	"testing"
+)
+
+type testPaymentsSuite struct {
+	suite.Suite
+	DB      *gorm.DB
+	Logger  *log.Logger
+	Service Service
+}
+
+func TestPayments(t *testing.T) {
+	suite.Run(t, new(testPaymentsSuite))
+}
+
+func (s *testPaymentsSuite) SetupSuite() {
+	s.Logger = log.New(os.Stdout, "[TestPayments] ", log.LstdFlags|log.Lshortfile|log.Lmsgprefix)
+
+	var c conf.Config
+	s.Require().NoError(c.Parse())
+
+	var err error
+	s.DB, err = persistence.OpenConn(c.Database)
+	s.Require().NoError(err)
+
+	s.Require().NoError(persistence.DropTables(s.DB))
+}
+
+func (s *testPaymentsSuite) SetupTest() {
+	s.Require().NoError(persistence.MigrateTables(s.DB))
+	s.Service = NewCreditsService(s.DB, s.Logger, 10)
+}
+
+func (s *testPaymentsSuite) TearDownTest() {
+	s.Require().NoError(persistence.DropTables(s.DB))
+}
+
+func (s *testPaymentsSuite) event(id string, t api.PaymentEventType, amount uint) api.ProcessPaymentEventRequest {
+	return api.ProcessPaymentEventRequest{
+		ID:   id,
+		Type: t,
+		Data: api.PaymentEventData{
+			PaymentID:   "pi_1",
+			Handle:      "test1",
+			Application: "cloudsim",
+			Amount:      amount,
+			Currency:    "usd",
+		},
+	}
+}
+
+func (s *testPaymentsSuite) balance() int {
+	c, err := persistence.GetCustomer(s.DB, "test1", "cloudsim")
+	s.Require().NoError(err)
+	return c.Credits
+}
+
+func (s *testPaymentsSuite) setStatus(status api.CustomerStatus) {
+	_, err := s.Service.SetCustomerStatus(context.Background(), api.SetCustomerStatusRequest{
+		Handle:      "test1",
+		Application: "cloudsim",
+		Status:      status,
+		Reason:      "test",
+	})
+	s.Require().NoError(err)
+}
+
+func (s *testPaymentsSuite) TestCreditPaymentOnce() {
+	res, err := s.Service.ProcessPaymentEvent(context.Background(), s.event("evt_1", api.PaymentCompleted, 1000))
+	s.Require().NoError(err)
+	s.Assert().True(res.Processed)
+	s.Assert().Equal(100, res.Credits)
+	s.Assert().Equal(100, res.Balance)
+
+	// Same event delivered twice
+	res, err = s.Service.ProcessPaymentEvent(context.Background(), s.event("evt_1", api.PaymentCompleted, 1000))
+	s.Require().NoError(err)
+	s.Assert().False(res.Processed)
+
+	// Different event for the same payment
+	res, err = s.Service.ProcessPaymentEvent(context.Background(), s.event("evt_2", api.PaymentCompleted, 1000))
+	s.Require().NoError(err)
+	s.Assert().False(res.Processed)
+
+	s.Assert().Equal(100, s.balance())
+}
+
+func (s *testPaymentsSuite) TestRefunds() {
+	_, err := s.Service.ProcessPaymentEvent(context.Background(), s.event("evt_1", api.PaymentCompleted, 1000))
+	s.Require().NoError(err)
+
+	res, err := s.Service.ProcessPaymentEvent(context.Background(), s.event("evt_2", api.PaymentRefunded, 300))
+	s.Require().NoError(err)
+	s.Assert().True(res.Processed)
+	s.Assert().Equal(-30, res.Credits)
+	s.Assert().Equal(70, s.balance())
+
+	// Chargebacks debit what hasn't been refunded yet
+	res, err = s.Service.ProcessPaymentEvent(context.Background(), s.event("evt_3", api.PaymentDisputed, 0))
+	s.Require().NoError(err)
+	s.Assert().Equal(-70, res.Credits)
+	s.Assert().Equal(0, s.balance())
+
+	// Nothing left to debit
+	res, err = s.Service.ProcessPaymentEvent(context.Background(), s.event("evt_4", api.PaymentRefunded, 1000))
+	s.Require().NoError(err)
+	s.Assert().False(res.Processed)
+	s.Assert().Equal(0, s.balance())
+
+	changes, err := persistence.GetBalanceChangesSince(s.DB, "test1", "cloudsim", models.BalanceChange{}.CreatedAt)
+	s.Require().NoError(err)
+	s.Require().Len(changes, 3)
+	s.Assert().Equal(models.OperationPurchase, changes[0].Operation)
+	s.Assert().Equal(models.OperationRefund, changes[1].Operation)
+	s.Assert().Equal(models.OperationChargeback, changes[2].Operation)
+}
+
+func (s *testPaymentsSuite) TestReversePurchase() {
+	_, err := s.Service.ProcessPaymentEvent(context.Background(), s.event("evt_1", api.PaymentCompleted, 1000))
+	s.Require().NoError(err)
+	_, err = s.Service.ProcessPaymentEvent(context.Background(), s.event("evt_2", api.PaymentRefunded, 300))
+	s.Require().NoError(err)
+
+	changes, err := persistence.GetBalanceChangesSince(s.DB, "test1", "cloudsim", models.BalanceChange{}.CreatedAt)
+	s.Require().NoError(err)
+	s.Require().Equal(models.OperationPurchase, changes[0].Operation)
+
+	// Credits already refunded are not reversed again
+	reversal, err := s.Service.ReverseTransaction(context.Background(), api.ReverseTransactionRequest{
+		TransactionID: changes[0].ID,
+	})
+	s.Require().NoError(err)
+	s.Assert().Equal(-70, reversal.Value)
+	s.Assert().Equal(0, s.balance())
+
+	// Reversed credits are not debited again
+	res, err := s.Service.ProcessPaymentEvent(context.Background(), s.event("evt_3", api.PaymentDisputed, 0))
+	s.Require().NoError(err)
+	s.Assert().False(res.Processed)
+	s.Assert().Equal(0, s.balance())
+}
+
+func (s *testPaymentsSuite) TestFrozenCustomer() {
+	_, err := s.Service.ProcessPaymentEvent(context.Background(), s.event("evt_1", api.PaymentCompleted, 1000))
+	s.Require().NoError(err)
+
+	s.setStatus(api.CustomerFrozen)
+
+	// Frozen customers still lose the credits of refunded payments.
+	res, err := s.Service.ProcessPaymentEvent(context.Background(), s.event("evt_2", api.PaymentRefunded, 300))
+	s.Require().NoError(err)
+	s.Assert().Equal(-30, res.Credits)
+	s.Assert().Equal(70, s.balance())
+
+	// But they can't be credited new payments until they are active again.
+	completed := s.event("evt_3", api.PaymentCompleted, 1000)
+	completed.Data.PaymentID = "pi_2"
+	_, err = s.Service.ProcessPaymentEvent(context.Background(), completed)
+	s.Assert().True(errors.Is(err, api.ErrCustomerFrozen))
+	s.Assert().Equal(70, s.balance())
+
+	s.setStatus(api.CustomerActive)
+
+	res, err = s.Service.ProcessPaymentEvent(context.Background(), completed)
+	s.Require().NoError(err)
+	s.Assert().True(res.Processed)
+	s.Assert().Equal(170, s.balance())
+}
+
+func (s *testPaymentsSuite) TestRefundUnknownPayment() {
+	_, err := s.Service.ProcessPaymentEvent(context.Background(), s.event("evt_1", api.PaymentRefunded, 300))
+	s.Assert().Equal(api.ErrPaymentNotFound, err)
+}
+
+func (s *testPaymentsSuite) TestIgnoreUnknownEvents() {
+	res, err := s.Service.ProcessPaymentEvent(context.Background(), s.event("evt_1", "customer.created", 1000))
+	s.Require().NoError(err)
+	s.Assert().False(res.Processed)
+}
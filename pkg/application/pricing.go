@@ -0,0 +1,264 @@
+package application
+
+import (
+	"context"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/api"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/domain/models"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/domain/persistence"
+	"gorm.io/gorm"
+	"math"
+	"time"
+)
+
+// CreateSKU adds a new SKU with an initial price to the catalog of an application.
+func (s *service) CreateSKU(ctx context.Context, req api.CreateSKURequest) (api.CreateSKUResponse, error) {
+	if err := req.Validate(); err != nil {
+		return api.CreateSKUResponse{}, err
+	}
+
+	_, err := persistence.GetSKU(s.db, req.Application, req.Code)
+	if err == nil {
+		return api.CreateSKUResponse{}, api.ErrSKUAlreadyExists
+	}
+	if err != gorm.ErrRecordNotFound {
+		return api.CreateSKUResponse{}, err
+	}
+
+	effectiveFrom := time.Now()
+	if req.EffectiveFrom != nil {
+		effectiveFrom = *req.EffectiveFrom
+	}
+
+	var sku models.SKU
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := shareConversionPolicy(tx, req.Application); err != nil {
+			return err
+		}
+		var err error
+		sku, err = persistence.CreateSKU(tx, models.SKU{
+			Application: req.Application,
+			Code:        req.Code,
+			Description: req.Description,
+			Unit:        req.Unit,
+			Prices: []models.SKUPrice{
+				{
+					Price:         req.Price,
+					EffectiveFrom: effectiveFrom,
+				},
+			},
+		})
+		return err
+	})
+	if err != nil {
+		return api.CreateSKUResponse{}, err
+	}
+
+	return api.CreateSKUResponse{SKU: toSKUAPI(sku, time.Now())}, nil
+}
+
+// GetSKU returns the SKU identified by the given application and code.
+func (s *service) GetSKU(ctx context.Context, req api.GetSKURequest) (api.GetSKUResponse, error) {
+	if err := req.Validate(); err != nil {
+		return api.GetSKUResponse{}, err
+	}
+
+	sku, err := s.getSKU(req.SKUIdentifier)
+	if err != nil {
+		return api.GetSKUResponse{}, err
+	}
+
+	return api.GetSKUResponse{SKU: toSKUAPI(sku, time.Now())}, nil
+}
+
+// ListSKUs returns all the SKUs of the given application.
+func (s *service) ListSKUs(ctx context.Context, req api.ListSKUsRequest) (api.ListSKUsResponse, error) {
+	if len(req.Application) == 0 {
+		return api.ListSKUsResponse{}, api.ErrMissingApplication
+	}
+
+	list, err := persistence.ListSKUs(s.db, req.Application)
+	if err != nil {
+		return api.ListSKUsResponse{}, err
+	}
+
+	now := time.Now()
+	out := make([]api.SKU, len(list))
+	for i, sku := range list {
+		out[i] = toSKUAPI(sku, now)
+	}
+
+	return api.ListSKUsResponse{SKUs: out}, nil
+}
+
+// UpdateSKU updates the description and unit of a SKU. Prices are changed through SetSKUPrice.
+func (s *service) UpdateSKU(ctx context.Context, req api.UpdateSKURequest) (api.UpdateSKUResponse, error) {
+	if err := req.Validate(); err != nil {
+		return api.UpdateSKUResponse{}, err
+	}
+
+	sku, err := s.getSKU(req.SKUIdentifier)
+	if err != nil {
+		return api.UpdateSKUResponse{}, err
+	}
+
+	sku, err = persistence.UpdateSKU(s.db, sku, req.Description, req.Unit)
+	if err != nil {
+		return api.UpdateSKUResponse{}, err
+	}
+
+	return api.UpdateSKUResponse{SKU: toSKUAPI(sku, time.Now())}, nil
+}
+
+// DeleteSKU removes a SKU and its prices from the catalog.
+func (s *service) DeleteSKU(ctx context.Context, req api.DeleteSKURequest) (api.DeleteSKUResponse, error) {
+	if err := req.Validate(); err != nil {
+		return api.DeleteSKUResponse{}, err
+	}
+
+	sku, err := s.getSKU(req.SKUIdentifier)
+	if err != nil {
+		return api.DeleteSKUResponse{}, err
+	}
+
+	if err = persistence.DeleteSKU(s.db, sku); err != nil {
+		return api.DeleteSKUResponse{}, err
+	}
+
+	return api.DeleteSKUResponse{}, nil
+}
+
+// SetSKUPrice adds a new price version to a SKU. Effective dates cannot be in the past.
+func (s *service) SetSKUPrice(ctx context.Context, req api.SetSKUPriceRequest) (api.SetSKUPriceResponse, error) {
+	if err := req.Validate(); err != nil {
+		return api.SetSKUPriceResponse{}, err
+	}
+
+	now := time.Now()
+	effectiveFrom := now
+	if req.EffectiveFrom != nil {
+		effectiveFrom = *req.EffectiveFrom
+	}
+	if effectiveFrom.Before(now) {
+		return api.SetSKUPriceResponse{}, api.ErrInvalidEffectiveDate
+	}
+
+	sku, err := s.getSKU(req.SKUIdentifier)
+	if err != nil {
+		return api.SetSKUPriceResponse{}, err
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := shareConversionPolicy(tx, sku.Application); err != nil {
+			return err
+		}
+		_, err := persistence.CreateSKUPrice(tx, models.SKUPrice{
+			SKUID:         sku.ID,
+			Price:         req.Price,
+			EffectiveFrom: effectiveFrom,
+		})
+		return err
+	})
+	if err != nil {
+		return api.SetSKUPriceResponse{}, err
+	}
+
+	sku, err = s.getSKU(req.SKUIdentifier)
+	if err != nil {
+		return api.SetSKUPriceResponse{}, err
+	}
+
+	return api.SetSKUPriceResponse{SKU: toSKUAPI(sku, now)}, nil
+}
+
+// Charge decreases the credits of a customer by the cost of the given quantity of a SKU, using the SKU price in
+// effect at the moment of the charge. Customers can't go below their overdraft limit nor exceed their spending limits.
+// Quantities whose cost doesn't fit in a balance are rejected with api.ErrInvalidQuantity.
+func (s *service) Charge(ctx context.Context, req api.ChargeRequest) (api.ChargeResponse, error) {
+	if err := req.Validate(); err != nil {
+		return api.ChargeResponse{}, err
+	}
+
+	sku, err := s.getSKU(api.SKUIdentifier{Application: req.Application, Code: req.SKU})
+	if err != nil {
+		return api.ChargeResponse{}, err
+	}
+
+	price, err := persistence.GetEffectiveSKUPrice(s.db, sku.ID, time.Now())
+	if err == gorm.ErrRecordNotFound {
+		return api.ChargeResponse{}, api.ErrNoEffectivePrice
+	}
+	if err != nil {
+		return api.ChargeResponse{}, err
+	}
+
+	value, ok := multiplyCredits(req.Quantity, price.Price)
+	if !ok {
+		return api.ChargeResponse{}, api.ErrInvalidQuantity
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		c, err := lockActiveCustomer(tx, req.Handle, req.Application)
+		if err != nil {
+			return err
+		}
+		if err = checkOverdraft(tx, c, value); err != nil {
+			return err
+		}
+		if err = checkSpendingLimits(tx, req.Handle, req.Application, value); err != nil {
+			return err
+		}
+		_, err = updateCredits(tx, req.Handle, req.Application, -1*int(value), models.OperationCharge)
+		return err
+	})
+	if err != nil {
+		return api.ChargeResponse{}, err
+	}
+
+	return api.ChargeResponse{
+		Price:   price.Price,
+		Credits: value,
+	}, nil
+}
+
+// multiplyCredits returns the product of a and b. It returns false if the product doesn't fit in a balance.
+func multiplyCredits(a, b uint) (uint, bool) {
+	if b != 0 && a > uint(math.MaxInt)/b {
+		return 0, false
+	}
+	return a * b, true
+}
+
+// getSKU returns the SKU identified by id. It returns api.ErrSKUNotFound if the SKU doesn't exist.
+func (s *service) getSKU(id api.SKUIdentifier) (models.SKU, error) {
+	sku, err := persistence.GetSKU(s.db, id.Application, id.Code)
+	if err == gorm.ErrRecordNotFound {
+		return models.SKU{}, api.ErrSKUNotFound
+	}
+	if err != nil {
+		return models.SKU{}, err
+	}
+	return sku, nil
+}
+
+// toSKUAPI converts the given SKU model into its API representation, using the price in effect at the given time.
+func toSKUAPI(sku models.SKU, at time.Time) api.SKU {
+	out := api.SKU{
+		Application: sku.Application,
+		Code:        sku.Code,
+		Description: sku.Description,
+		Unit:        sku.Unit,
+		Prices:      make([]api.SKUPrice, len(sku.Prices)),
+	}
+	for i, p := range sku.Prices {
+		out.Prices[i] = api.SKUPrice{
+			Price:         p.Price,
+			EffectiveFrom: p.EffectiveFrom,
+		}
+		if !p.EffectiveFrom.After(at) && (out.EffectiveFrom == nil || !p.EffectiveFrom.Before(*out.EffectiveFrom)) {
+			effectiveFrom := p.EffectiveFrom
+			out.Price = p.Price
+			out.EffectiveFrom = &effectiveFrom
+		}
+	}
+	return out
+}
@@ -0,0 +1,361 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"gitlab.com/ignitionrobotics/billing/credits/internal/conf"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/api"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/domain/models"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/domain/persistence"
+	"gorm.io/gorm"
+	"log"
+	"math"
+	"os"
+	"testing"
+	"time"
+)
+
+type testPricingSuite struct {
+	suite.Suite
+	DB      *gorm.DB
+	Logger  *log.Logger
+	Service Service
+}
+
+func TestPricing(t *testing.T) {
+	suite.Run(t, new(testPricingSuite))
+}
+
+func (s *testPricingSuite) SetupSuite() {
+	s.Logger = log.New(os.Stdout, "[TestPricing] ", log.LstdFlags|log.Lshortfile|log.Lmsgprefix)
+
+	var c conf.Config
+	s.Require().NoError(c.Parse())
+
+	var err error
+	s.DB, err = persistence.OpenConn(c.Database)
+	s.Require().NoError(err)
+
+	s.Require().NoError(persistence.DropTables(s.DB))
+}
+
+func (s *testPricingSuite) SetupTest() {
+	s.Require().NoError(persistence.MigrateTables(s.DB))
+
+	s.Service = NewCreditsService(s.DB, s.Logger, 100)
+
+	_, err := persistence.CreateCustomer(s.DB, models.Customer{
+		Handle:      "test1",
+		Application: "cloudsim",
+		Credits:     100,
+	})
+	s.Require().NoError(err)
+
+	_, err = s.Service.CreateSKU(context.Background(), api.CreateSKURequest{
+		SKUIdentifier: api.SKUIdentifier{
+			Application: "cloudsim",
+			Code:        "gpu",
+		},
+		Description: "GPU machine",
+		Unit:        "hour",
+		Price:       5,
+	})
+	s.Require().NoError(err)
+}
+
+func (s *testPricingSuite) TearDownTest() {
+	s.Require().NoError(persistence.DropTables(s.DB))
+}
+
+func (s *testPricingSuite) TestCreateSKUAlreadyExists() {
+	_, err := s.Service.CreateSKU(context.Background(), api.CreateSKURequest{
+		SKUIdentifier: api.SKUIdentifier{
+			Application: "cloudsim",
+			Code:        "gpu",
+		},
+		Price: 10,
+	})
+	s.Assert().Equal(api.ErrSKUAlreadyExists, err)
+}
+
+func (s *testPricingSuite) TestCreateSKUValidation() {
+	_, err := s.Service.CreateSKU(context.Background(), api.CreateSKURequest{
+		SKUIdentifier: api.SKUIdentifier{
+			Application: "cloudsim",
+		},
+		Price: 10,
+	})
+	s.Assert().Equal(api.ErrMissingSKU, err)
+
+	_, err = s.Service.CreateSKU(context.Background(), api.CreateSKURequest{
+		SKUIdentifier: api.SKUIdentifier{
+			Application: "cloudsim",
+			Code:        "cpu",
+		},
+	})
+	s.Assert().Equal(api.ErrInvalidPrice, err)
+}
+
+func (s *testPricingSuite) TestUpdateAndDeleteSKU() {
+	res, err := s.Service.UpdateSKU(context.Background(), api.UpdateSKURequest{
+		SKUIdentifier: api.SKUIdentifier{
+			Application: "cloudsim",
+			Code:        "gpu",
+		},
+		Description: "GPU instance",
+		Unit:        "minute",
+	})
+	s.Require().NoError(err)
+	s.Assert().Equal("GPU instance", res.Description)
+	s.Assert().Equal("minute", res.Unit)
+
+	list, err := s.Service.ListSKUs(context.Background(), api.ListSKUsRequest{Application: "cloudsim"})
+	s.Require().NoError(err)
+	s.Require().Len(list.SKUs, 1)
+	s.Assert().Equal(uint(5), list.SKUs[0].Price)
+
+	_, err = s.Service.DeleteSKU(context.Background(), api.DeleteSKURequest{SKUIdentifier: api.SKUIdentifier{
+		Application: "cloudsim",
+		Code:        "gpu",
+	}})
+	s.Require().NoError(err)
+
+	_, err = s.Service.GetSKU(context.Background(), api.GetSKURequest{SKUIdentifier: api.SKUIdentifier{
+		Application: "cloudsim",
+		Code:        "gpu",
+	}})
+	s.Assert().Equal(api.ErrSKUNotFound, err)
+}
+
+func (s *testPricingSuite) TestSetSKUPriceInThePast() {
+	past := time.Now().Add(-time.Hour)
+	_, err := s.Service.SetSKUPrice(context.Background(), api.SetSKUPriceRequest{
+		SKUIdentifier: api.SKUIdentifier{
+			Application: "cloudsim",
+			Code:        "gpu",
+		},
+		Price:         10,
+		EffectiveFrom: &past,
+	})
+	s.Assert().Equal(api.ErrInvalidEffectiveDate, err)
+}
+
+func (s *testPricingSuite) TestSetSKUPriceInTheFuture() {
+	future := time.Now().Add(time.Hour)
+	res, err := s.Service.SetSKUPrice(context.Background(), api.SetSKUPriceRequest{
+		SKUIdentifier: api.SKUIdentifier{
+			Application: "cloudsim",
+			Code:        "gpu",
+		},
+		Price:         10,
+		EffectiveFrom: &future,
+	})
+	s.Require().NoError(err)
+	s.Assert().Len(res.Prices, 2)
+	s.Assert().Equal(uint(5), res.Price)
+
+	charge, err := s.Service.Charge(context.Background(), api.ChargeRequest{
+		Handle:      "test1",
+		Application: "cloudsim",
+		SKU:         "gpu",
+		Quantity:    3,
+	})
+	s.Require().NoError(err)
+	s.Assert().Equal(uint(5), charge.Price)
+	s.Assert().Equal(uint(15), charge.Credits)
+}
+
+func (s *testPricingSuite) TestCharge() {
+	_, err := s.Service.Charge(context.Background(), api.ChargeRequest{
+		Handle:      "test1",
+		Application: "cloudsim",
+		SKU:         "gpu",
+		Quantity:    4,
+	})
+	s.Require().NoError(err)
+
+	c, err := persistence.GetCustomer(s.DB, "test1", "cloudsim")
+	s.Require().NoError(err)
+	s.Assert().Equal(80, c.Credits)
+}
+
+func (s *testPricingSuite) TestChargeFails() {
+	_, err := s.Service.Charge(context.Background(), api.ChargeRequest{
+		Handle:      "test1",
+		Application: "cloudsim",
+		SKU:         "gpu",
+		Quantity:    0,
+	})
+	s.Assert().Equal(api.ErrInvalidQuantity, err)
+
+	_, err = s.Service.Charge(context.Background(), api.ChargeRequest{
+		Handle:      "test1",
+		Application: "cloudsim",
+		SKU:         "storage",
+		Quantity:    1,
+	})
+	s.Assert().Equal(api.ErrSKUNotFound, err)
+}
+
+func (s *testPricingSuite) TestChargeChecksOverdraft() {
+	_, err := s.Service.Charge(context.Background(), api.ChargeRequest{
+		Handle:      "test1",
+		Application: "cloudsim",
+		SKU:         "gpu",
+		Quantity:    21,
+	})
+	s.Assert().True(errors.Is(err, api.ErrInsufficientCredits))
+
+	c, err := persistence.GetCustomer(s.DB, "test1", "cloudsim")
+	s.Require().NoError(err)
+	s.Assert().Equal(100, c.Credits)
+}
+
+func (s *testPricingSuite) TestChargeRejectsOverflow() {
+	_, err := s.Service.Charge(context.Background(), api.ChargeRequest{
+		Handle:      "test1",
+		Application: "cloudsim",
+		SKU:         "gpu",
+		Quantity:    math.MaxUint,
+	})
+	s.Assert().True(errors.Is(err, api.ErrInvalidQuantity))
+
+	c, err := persistence.GetCustomer(s.DB, "test1", "cloudsim")
+	s.Require().NoError(err)
+	s.Assert().Equal(100, c.Credits)
+}
+
+func (s *testPricingSuite) TestEstimateCostRejectsOverflow() {
+	_, err := s.Service.EstimateCost(context.Background(), api.EstimateCostRequest{
+		Handle:      "test1",
+		Application: "cloudsim",
+		SKU:         "gpu",
+		Quantity:    2,
+		Periods:     math.MaxUint / 2,
+		Currency:    "usd",
+	})
+	s.Assert().True(errors.Is(err, api.ErrInvalidQuantity))
+}
+
+func (s *testPricingSuite) TestEstimateCostAffordable() {
+	res, err := s.Service.EstimateCost(context.Background(), api.EstimateCostRequest{
+		Handle:      "test1",
+		Application: "cloudsim",
+		SKU:         "gpu",
+		Quantity:    2,
+		Periods:     10,
+		Currency:    "usd",
+	})
+	s.Require().NoError(err)
+	s.Assert().Equal(uint(100), res.Cost)
+	s.Assert().Equal(100, res.Available)
+	s.Assert().True(res.Affordable)
+	s.Assert().Zero(res.Shortfall)
+	s.Assert().Zero(res.ShortfallAmount)
+
+	c, err := persistence.GetCustomer(s.DB, "test1", "cloudsim")
+	s.Require().NoError(err)
+	s.Assert().Equal(100, c.Credits)
+}
+
+func (s *testPricingSuite) TestEstimateCostShortfall() {
+	res, err := s.Service.EstimateCost(context.Background(), api.EstimateCostRequest{
+		Handle:      "test1",
+		Application: "cloudsim",
+		SKU:         "gpu",
+		Quantity:    3,
+		Periods:     10,
+		Currency:    "usd",
+	})
+	s.Require().NoError(err)
+	s.Assert().Equal(uint(150), res.Cost)
+	s.Assert().False(res.Affordable)
+	s.Assert().Equal(uint(50), res.Shortfall)
+	s.Assert().Equal(uint(5000), res.ShortfallAmount)
+	s.Assert().Equal("usd", res.Currency)
+
+	converted, err := s.Service.ConvertCurrency(context.Background(), api.ConvertCurrencyRequest{
+		Amount:   res.ShortfallAmount,
+		Currency: res.Currency,
+	})
+	s.Require().NoError(err)
+	s.Assert().Equal(res.Shortfall, converted.Credits)
+}
+
+func (s *testPricingSuite) TestEstimateCostIncludesHolds() {
+	session, err := s.Service.OpenSession(context.Background(), api.OpenSessionRequest{
+		Handle:      "test1",
+		Application: "cloudsim",
+		Price:       1,
+		Unit:        "1m",
+	})
+	s.Require().NoError(err)
+
+	m, err := persistence.GetSession(s.DB, session.ID)
+	s.Require().NoError(err)
+	m.ChargedUntil = m.ChargedUntil.Add(-30 * time.Minute)
+	_, err = persistence.SaveSession(s.DB, m)
+	s.Require().NoError(err)
+
+	res, err := s.Service.EstimateCost(context.Background(), api.EstimateCostRequest{
+		Handle:      "test1",
+		Application: "cloudsim",
+		SKU:         "gpu",
+		Quantity:    1,
+		Currency:    "usd",
+	})
+	s.Require().NoError(err)
+	s.Assert().InDelta(70, res.Available, 1)
+}
+
+func (s *testPricingSuite) TestEstimateCostUnknownCustomer() {
+	res, err := s.Service.EstimateCost(context.Background(), api.EstimateCostRequest{
+		Handle:      "test2",
+		Application: "cloudsim",
+		SKU:         "gpu",
+		Quantity:    1,
+		Currency:    "usd",
+	})
+	s.Require().NoError(err)
+	s.Assert().Equal(0, res.Available)
+	s.Assert().Equal(uint(5), res.Shortfall)
+}
+
+func TestToSKUAPIUsesPriceInEffect(t *testing.T) {
+	now := time.Now()
+	sku := models.SKU{
+		Application: "cloudsim",
+		Code:        "gpu",
+		Prices: []models.SKUPrice{
+			{Price: 1, EffectiveFrom: now.Add(-2 * time.Hour)},
+			{Price: 2, EffectiveFrom: now.Add(-time.Hour)},
+			{Price: 3, EffectiveFrom: now.Add(time.Hour)},
+		},
+	}
+
+	out := toSKUAPI(sku, now)
+	assert.Equal(t, uint(2), out.Price)
+	assert.Len(t, out.Prices, 3)
+
+	out = toSKUAPI(sku, now.Add(-3*time.Hour))
+	assert.Equal(t, uint(0), out.Price)
+	assert.Nil(t, out.EffectiveFrom)
+}
+
+func TestMultiplyCredits(t *testing.T) {
+	value, ok := multiplyCredits(3, 4)
+	assert.True(t, ok)
+	assert.Equal(t, uint(12), value)
+
+	value, ok = multiplyCredits(uint(math.MaxInt), 1)
+	assert.True(t, ok)
+	assert.Equal(t, uint(math.MaxInt), value)
+
+	_, ok = multiplyCredits(uint(math.MaxInt)/2+1, 2)
+	assert.False(t, ok)
+
+	_, ok = multiplyCredits(math.MaxUint, math.MaxUint)
+	assert.False(t, ok)
+}
@@ -0,0 +1,215 @@
+package application
+
+import (
+	"context"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/api"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/domain/models"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/domain/persistence"
+	"gorm.io/gorm"
+)
+
+// SetVolumeTiers replaces the volume tiers of an application. Purchases made before the change are not affected.
+func (s *service) SetVolumeTiers(ctx context.Context, req api.SetVolumeTiersRequest) (api.SetVolumeTiersResponse, error) {
+	if err := req.Validate(); err != nil {
+		return api.SetVolumeTiersResponse{}, err
+	}
+
+	tiers := make([]models.VolumeTier, len(req.Tiers))
+	for i, tier := range req.Tiers {
+		tiers[i] = models.VolumeTier{
+			Application:  req.Application,
+			MinAmount:    tier.MinAmount,
+			BonusPercent: tier.BonusPercent,
+		}
+	}
+
+	var out api.SetVolumeTiersResponse
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := persistence.SetVolumeTiers(tx, req.Application, tiers); err != nil {
+			return err
+		}
+		list, err := persistence.GetVolumeTiers(tx, req.Application)
+		if err != nil {
+			return err
+		}
+		out.Tiers = toVolumeTiersAPI(list)
+		return nil
+	})
+	if err != nil {
+		return api.SetVolumeTiersResponse{}, err
+	}
+	return out, nil
+}
+
+// ListVolumeTiers returns the volume tiers of an application.
+func (s *service) ListVolumeTiers(ctx context.Context, req api.ListVolumeTiersRequest) (api.ListVolumeTiersResponse, error) {
+	if len(req.Application) == 0 {
+		return api.ListVolumeTiersResponse{}, api.ErrMissingApplication
+	}
+
+	list, err := persistence.GetVolumeTiers(s.db, req.Application)
+	if err != nil {
+		return api.ListVolumeTiersResponse{}, err
+	}
+	return api.ListVolumeTiersResponse{Tiers: toVolumeTiersAPI(list)}, nil
+}
+
+// CreatePackage adds a new credit package to an application. Packages are matched by amount when buying credits, so
+// two packages of the same application can't have the same amount.
+func (s *service) CreatePackage(ctx context.Context, req api.CreatePackageRequest) (api.CreatePackageResponse, error) {
+	if err := req.Validate(); err != nil {
+		return api.CreatePackageResponse{}, err
+	}
+
+	_, err := persistence.GetCreditPackage(s.db, req.Application, req.Code)
+	if err == nil {
+		return api.CreatePackageResponse{}, api.ErrPackageExists
+	}
+	if err != gorm.ErrRecordNotFound {
+		return api.CreatePackageResponse{}, err
+	}
+
+	_, err = persistence.GetCreditPackageByAmount(s.db, req.Application, req.Amount)
+	if err == nil {
+		return api.CreatePackageResponse{}, api.ErrPackageExists
+	}
+	if err != gorm.ErrRecordNotFound {
+		return api.CreatePackageResponse{}, err
+	}
+
+	var creditPackage models.CreditPackage
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := shareConversionPolicy(tx, req.Application); err != nil {
+			return err
+		}
+		var err error
+		creditPackage, err = persistence.CreateCreditPackage(tx, models.CreditPackage{
+			Application: req.Application,
+			Code:        req.Code,
+			Name:        req.Name,
+			Credits:     req.Credits,
+			Amount:      req.Amount,
+		})
+		return err
+	})
+	if err != nil {
+		return api.CreatePackageResponse{}, err
+	}
+
+	return api.CreatePackageResponse{CreditPackage: toCreditPackageAPI(creditPackage)}, nil
+}
+
+// DeletePackage removes a credit package from an application. The credits already bought with it are kept.
+func (s *service) DeletePackage(ctx context.Context, req api.DeletePackageRequest) (api.DeletePackageResponse, error) {
+	if len(req.Application) == 0 {
+		return api.DeletePackageResponse{}, api.ErrMissingApplication
+	}
+	if len(req.Code) == 0 {
+		return api.DeletePackageResponse{}, api.ErrMissingCode
+	}
+
+	creditPackage, err := persistence.GetCreditPackage(s.db, req.Application, req.Code)
+	if err == gorm.ErrRecordNotFound {
+		return api.DeletePackageResponse{}, api.ErrPackageNotFound
+	}
+	if err != nil {
+		return api.DeletePackageResponse{}, err
+	}
+
+	if err = persistence.DeleteCreditPackage(s.db, creditPackage); err != nil {
+		return api.DeletePackageResponse{}, err
+	}
+	return api.DeletePackageResponse{}, nil
+}
+
+// ListPackages returns the credit packages available for an application.
+func (s *service) ListPackages(ctx context.Context, req api.ListPackagesRequest) (api.ListPackagesResponse, error) {
+	if len(req.Application) == 0 {
+		return api.ListPackagesResponse{}, api.ErrMissingApplication
+	}
+
+	list, err := persistence.GetCreditPackages(s.db, req.Application)
+	if err != nil {
+		return api.ListPackagesResponse{}, err
+	}
+
+	out := api.ListPackagesResponse{Packages: make([]api.CreditPackage, len(list))}
+	for i, creditPackage := range list {
+		out.Packages[i] = toCreditPackageAPI(creditPackage)
+	}
+	return out, nil
+}
+
+// purchase contains the credits bought with a certain amount.
+type purchase struct {
+	// Credits is the total amount of credits bought, including the bonus.
+	Credits uint
+
+	// Bonus is the amount of credits given by the volume tiers.
+	Bonus uint
+
+	// Package is the code of the package bought, if any.
+	Package string
+}
+
+// purchaseCredits returns the credits a customer receives for paying the given amount to an application. Amounts
+// that match a package buy the package credits. Any other amount is converted with the conversion rate, and receives
+// the bonus of the highest volume tier it reaches.
+func (s *service) purchaseCredits(db *gorm.DB, application string, amount uint, currency string) (purchase, error) {
+	creditPackage, err := persistence.GetCreditPackageByAmount(db, application, amount)
+	if err == nil {
+		return purchase{Credits: creditPackage.Credits, Package: creditPackage.Code}, nil
+	}
+	if err != gorm.ErrRecordNotFound {
+		return purchase{}, err
+	}
+
+	tiers, err := persistence.GetVolumeTiers(db, application)
+	if err != nil {
+		return purchase{}, err
+	}
+
+	credits, err := s.calculateCredits(db, application, amount, currency)
+	if err != nil {
+		return purchase{}, err
+	}
+	bonus := volumeBonus(tiers, amount, credits)
+	return purchase{Credits: credits + bonus, Bonus: bonus}, nil
+}
+
+// volumeBonus returns the bonus credits of the highest tier reached by the given amount. An amount equal to the
+// minimum amount of a tier reaches it. The bonus is a percentage of the given credits rounded down. The tiers must be
+// sorted by minimum amount.
+func volumeBonus(tiers []models.VolumeTier, amount, credits uint) uint {
+	var percent uint
+	for _, tier := range tiers {
+		if tier.MinAmount > amount {
+			break
+		}
+		percent = tier.BonusPercent
+	}
+	return credits * percent / 100
+}
+
+// toVolumeTiersAPI converts the given volume tier models into their API representation.
+func toVolumeTiersAPI(tiers []models.VolumeTier) []api.VolumeTier {
+	out := make([]api.VolumeTier, len(tiers))
+	for i, tier := range tiers {
+		out[i] = api.VolumeTier{
+			MinAmount:    tier.MinAmount,
+			BonusPercent: tier.BonusPercent,
+		}
+	}
+	return out
+}
+
+// toCreditPackageAPI converts the given credit package model into its API representation.
+func toCreditPackageAPI(creditPackage models.CreditPackage) api.CreditPackage {
+	return api.CreditPackage{
+		Application: creditPackage.Application,
+		Code:        creditPackage.Code,
+		Name:        creditPackage.Name,
+		Credits:     creditPackage.Credits,
+		Amount:      creditPackage.Amount,
+	}
+}
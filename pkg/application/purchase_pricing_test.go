@@ -0,0 +1,166 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"gitlab.com/ignitionrobotics/billing/credits/internal/conf"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/api"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/domain/models"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/domain/persistence"
+	"gorm.io/gorm"
+	"log"
+	"os"
+	"testing"
+)
+
+func TestVolumeBonus(t *testing.T) {
+	tiers := []models.VolumeTier{
+		{MinAmount: 10000, BonusPercent: 10},
+		{MinAmount: 50000, BonusPercent: 20},
+	}
+
+	assert.Equal(t, uint(0), volumeBonus(tiers, 9999, 9999))
+	assert.Equal(t, uint(1000), volumeBonus(tiers, 10000, 10000))
+	assert.Equal(t, uint(1000), volumeBonus(tiers, 10001, 10001))
+	assert.Equal(t, uint(4999), volumeBonus(tiers, 49999, 49999))
+	assert.Equal(t, uint(10000), volumeBonus(tiers, 50000, 50000))
+	assert.Equal(t, uint(12000), volumeBonus(tiers, 60000, 60000))
+	assert.Equal(t, uint(0), volumeBonus(nil, 60000, 60000))
+}
+
+func TestRefundedCredits(t *testing.T) {
+	payment := models.Payment{Amount: 10000, Credits: 11000}
+	assert.Equal(t, uint(11000), refundedCredits(payment, 10000))
+	assert.Equal(t, uint(5500), refundedCredits(payment, 5000))
+	assert.Equal(t, uint(2), refundedCredits(payment, 1))
+}
+
+type testPurchasePricingSuite struct {
+	suite.Suite
+	DB      *gorm.DB
+	Logger  *log.Logger
+	Service Service
+}
+
+func TestPurchasePricing(t *testing.T) {
+	suite.Run(t, new(testPurchasePricingSuite))
+}
+
+func (s *testPurchasePricingSuite) SetupSuite() {
+	s.Logger = log.New(os.Stdout, "[TestPurchasePricing] ", log.LstdFlags|log.Lshortfile|log.Lmsgprefix)
+
+	var c conf.Config
+	s.Require().NoError(c.Parse())
+
+	var err error
+	s.DB, err = persistence.OpenConn(c.Database)
+	s.Require().NoError(err)
+
+	s.Require().NoError(persistence.DropTables(s.DB))
+}
+
+func (s *testPurchasePricingSuite) SetupTest() {
+	s.Require().NoError(persistence.MigrateTables(s.DB))
+	s.Service = NewCreditsService(s.DB, s.Logger, 1)
+
+	_, err := s.Service.SetVolumeTiers(context.Background(), api.SetVolumeTiersRequest{
+		Application: "cloudsim",
+		Tiers: []api.VolumeTier{
+			{MinAmount: 50000, BonusPercent: 20},
+			{MinAmount: 10000, BonusPercent: 10},
+		},
+	})
+	s.Require().NoError(err)
+
+	_, err = s.Service.CreatePackage(context.Background(), api.CreatePackageRequest{
+		CreditPackage: api.CreditPackage{Application: "cloudsim", Code: "starter", Name: "Starter", Credits: 500, Amount: 450},
+	})
+	s.Require().NoError(err)
+}
+
+func (s *testPurchasePricingSuite) TearDownTest() {
+	s.Require().NoError(persistence.DropTables(s.DB))
+}
+
+func (s *testPurchasePricingSuite) convert(application string, amount uint) api.ConvertCurrencyResponse {
+	res, err := s.Service.ConvertCurrency(context.Background(), api.ConvertCurrencyRequest{
+		Amount:      amount,
+		Currency:    "usd",
+		Application: application,
+	})
+	s.Require().NoError(err)
+	return res
+}
+
+func (s *testPurchasePricingSuite) TestListVolumeTiers() {
+	res, err := s.Service.ListVolumeTiers(context.Background(), api.ListVolumeTiersRequest{Application: "cloudsim"})
+	s.Require().NoError(err)
+	s.Require().Len(res.Tiers, 2)
+	s.Assert().Equal(uint(10000), res.Tiers[0].MinAmount)
+	s.Assert().Equal(uint(50000), res.Tiers[1].MinAmount)
+
+	_, err = s.Service.SetVolumeTiers(context.Background(), api.SetVolumeTiersRequest{Application: "cloudsim"})
+	s.Require().NoError(err)
+
+	res, err = s.Service.ListVolumeTiers(context.Background(), api.ListVolumeTiersRequest{Application: "cloudsim"})
+	s.Require().NoError(err)
+	s.Assert().Empty(res.Tiers)
+}
+
+func (s *testPurchasePricingSuite) TestConvertCurrency() {
+	s.Assert().Equal(api.ConvertCurrencyResponse{Credits: 9999}, s.convert("cloudsim", 9999))
+	s.Assert().Equal(api.ConvertCurrencyResponse{Credits: 11000, Bonus: 1000}, s.convert("cloudsim", 10000))
+	s.Assert().Equal(api.ConvertCurrencyResponse{Credits: 72000, Bonus: 12000}, s.convert("cloudsim", 60000))
+	s.Assert().Equal(api.ConvertCurrencyResponse{Credits: 500, Package: "starter"}, s.convert("cloudsim", 450))
+
+	// Without application, only the conversion rate is applied.
+	s.Assert().Equal(api.ConvertCurrencyResponse{Credits: 10000}, s.convert("", 10000))
+	s.Assert().Equal(api.ConvertCurrencyResponse{Credits: 10000}, s.convert("other", 10000))
+}
+
+func (s *testPurchasePricingSuite) TestIncreaseCredits() {
+	res, err := s.Service.IncreaseCredits(context.Background(), api.IncreaseCreditsRequest{
+		Transaction: api.Transaction{Handle: "test", Amount: 10000, Currency: "usd", Application: "cloudsim"},
+	})
+	s.Require().NoError(err)
+	s.Assert().Equal(11000, res.Balance)
+
+	res, err = s.Service.IncreaseCredits(context.Background(), api.IncreaseCreditsRequest{
+		Transaction: api.Transaction{Handle: "test", Amount: 450, Currency: "usd", Application: "cloudsim"},
+	})
+	s.Require().NoError(err)
+	s.Assert().Equal(11500, res.Balance)
+}
+
+func (s *testPurchasePricingSuite) TestPackages() {
+	_, err := s.Service.CreatePackage(context.Background(), api.CreatePackageRequest{
+		CreditPackage: api.CreditPackage{Application: "cloudsim", Code: "starter", Credits: 600, Amount: 500},
+	})
+	s.Assert().True(errors.Is(err, api.ErrPackageExists))
+
+	_, err = s.Service.CreatePackage(context.Background(), api.CreatePackageRequest{
+		CreditPackage: api.CreditPackage{Application: "cloudsim", Code: "other", Credits: 600, Amount: 450},
+	})
+	s.Assert().True(errors.Is(err, api.ErrPackageExists))
+
+	_, err = s.Service.CreatePackage(context.Background(), api.CreatePackageRequest{
+		CreditPackage: api.CreditPackage{Application: "cloudsim", Code: "pro", Name: "Pro", Credits: 2500, Amount: 2000},
+	})
+	s.Require().NoError(err)
+
+	res, err := s.Service.ListPackages(context.Background(), api.ListPackagesRequest{Application: "cloudsim"})
+	s.Require().NoError(err)
+	s.Require().Len(res.Packages, 2)
+	s.Assert().Equal("starter", res.Packages[0].Code)
+	s.Assert().Equal("pro", res.Packages[1].Code)
+
+	_, err = s.Service.DeletePackage(context.Background(), api.DeletePackageRequest{Application: "cloudsim", Code: "starter"})
+	s.Require().NoError(err)
+
+	_, err = s.Service.DeletePackage(context.Background(), api.DeletePackageRequest{Application: "cloudsim", Code: "starter"})
+	s.Assert().True(errors.Is(err, api.ErrPackageNotFound))
+
+	s.Assert().Equal(api.ConvertCurrencyResponse{Credits: 450}, s.convert("cloudsim", 450))
+}
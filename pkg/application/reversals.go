@@ -0,0 +1,159 @@
+package application
+
+import (
+	"context"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/api"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/domain/models"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/domain/persistence"
+	"gorm.io/gorm"
+)
+
+// ReverseTransaction applies the opposite credit delta of a previous transaction. Transactions can be reversed in
+// several partial reversals, but the reversed credits never exceed the credits of the original transaction.
+// Reversals and opening balances cannot be reversed, and neither can transfers, since reversing a single leg of a
+// transfer would create or destroy credits. Refunds, chargebacks, trial and allowance grants and expirations cannot
+// be reversed either, since their payments, trials and subscriptions would still account for them. Reversing a
+// purchase debits its payment, so refunds and chargebacks of the payment never debit the reversed credits again, and
+// reversing a wallet spend returns the credits to the allowance of the member that spent them. Frozen and closed
+// customers can't have their transactions reversed.
+func (s *service) ReverseTransaction(ctx context.Context, req api.ReverseTransactionRequest) (api.ReverseTransactionResponse, error) {
+	if err := req.Validate(); err != nil {
+		return api.ReverseTransactionResponse{}, err
+	}
+
+	var out api.ReverseTransactionResponse
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		original, err := persistence.GetBalanceChangeForUpdate(tx, req.TransactionID)
+		if err == gorm.ErrRecordNotFound {
+			return api.ErrTransactionNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		if original.ReversalOf != nil || original.Value == 0 {
+			return api.ErrInvalidReversal
+		}
+		switch original.Operation {
+		case models.OperationOpening, models.OperationTransfer, models.OperationRefund, models.OperationChargeback,
+			models.OperationTrial, models.OperationAllowance, models.OperationExpiration:
+			return api.ErrInvalidReversal
+		}
+
+		if _, err = lockActiveCustomer(tx, original.Handle, original.Application); err != nil {
+			return err
+		}
+
+		remaining := uint(abs(original.Value)) - original.ReversedCredits
+
+		var payment models.Payment
+		if original.Operation == models.OperationPurchase {
+			payment, err = persistence.GetPaymentByTransactionForUpdate(tx, original.ID)
+			if err == gorm.ErrRecordNotFound {
+				return api.ErrInvalidReversal
+			}
+			if err != nil {
+				return err
+			}
+			if debitable := payment.Credits - payment.DebitedCredits; debitable < remaining {
+				remaining = debitable
+			}
+		}
+
+		if remaining == 0 {
+			return api.ErrTransactionAlreadyReversed
+		}
+
+		credits := req.Credits
+		if credits == 0 {
+			credits = remaining
+		}
+		if credits > remaining {
+			return api.ErrInvalidReversal
+		}
+
+		value := int(credits)
+		if original.Value > 0 {
+			value = -value
+		}
+
+		counterpart, err := transactionCounterpart(tx, original)
+		if err != nil {
+			return err
+		}
+
+		reversal, err := updateCreditsAgainst(tx, original.Handle, original.Application, value, models.OperationReversal, counterpart)
+		if err != nil {
+			return err
+		}
+
+		if err = persistence.SetBalanceChangeReversal(tx, original, reversal, credits); err != nil {
+			return err
+		}
+
+		if payment.ID != 0 {
+			if err = persistence.SetPaymentDebitedCredits(tx, payment, payment.DebitedCredits+credits); err != nil {
+				return err
+			}
+		}
+
+		if original.Value < 0 {
+			if err = reverseWalletSpend(tx, original.ID, credits); err != nil {
+				return err
+			}
+		}
+
+		out = api.ReverseTransactionResponse{
+			TransactionID:         reversal.ID,
+			OriginalTransactionID: original.ID,
+			Handle:                reversal.Handle,
+			Application:           reversal.Application,
+			Value:                 reversal.Value,
+			Balance:               reversal.Balance,
+			Remaining:             remaining - credits,
+		}
+		return nil
+	})
+	if err != nil {
+		return api.ReverseTransactionResponse{}, err
+	}
+
+	return out, nil
+}
+
+// reverseWalletSpend returns the given credits to the allowance of the wallet member that spent them in the given
+// transaction. Transactions that aren't wallet spends, and members that left the wallet, are ignored.
+func reverseWalletSpend(tx *gorm.DB, transactionID, credits uint) error {
+	spend, err := persistence.GetWalletSpendByTransaction(tx, transactionID)
+	if err == gorm.ErrRecordNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if err = persistence.AddWalletSpendReversedCredits(tx, spend, credits); err != nil {
+		return err
+	}
+
+	member, err := persistence.GetWalletMemberForUpdate(tx, spend.Application, spend.Wallet, spend.Member)
+	if err == gorm.ErrRecordNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	spent := uint(0)
+	if member.Spent > credits {
+		spent = member.Spent - credits
+	}
+	return persistence.SetWalletMemberSpent(tx, member, spent)
+}
+
+// abs returns the absolute value of x.
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
@@ -0,0 +1,182 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/suite"
+	"gitlab.com/ignitionrobotics/billing/credits/internal/conf"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/api"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/domain/models"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/domain/persistence"
+	"gorm.io/gorm"
+	"log"
+	"os"
+	"testing"
+)
+
+type testReversalsSuite struct {
+	suite.Suite
+	DB      *gorm.DB
+	Logger  *log.Logger
+	Service Service
+}
+
+func TestReversals(t *testing.T) {
+	suite.Run(t, new(testReversalsSuite))
+}
+
+func (s *testReversalsSuite) SetupSuite() {
+	s.Logger = log.New(os.Stdout, "[TestReversals] ", log.LstdFlags|log.Lshortfile|log.Lmsgprefix)
+
+	var c conf.Config
+	s.Require().NoError(c.Parse())
+
+	var err error
+	s.DB, err = persistence.OpenConn(c.Database)
+	s.Require().NoError(err)
+
+	s.Require().NoError(persistence.DropTables(s.DB))
+}
+
+func (s *testReversalsSuite) SetupTest() {
+	s.Require().NoError(persistence.MigrateTables(s.DB))
+	s.Service = NewCreditsService(s.DB, s.Logger, 1)
+
+	_, err := persistence.CreateCustomer(s.DB, models.Customer{
+		Handle:      "test1",
+		Application: "cloudsim",
+		Credits:     100,
+	})
+	s.Require().NoError(err)
+}
+
+func (s *testReversalsSuite) TearDownTest() {
+	s.Require().NoError(persistence.DropTables(s.DB))
+}
+
+func (s *testReversalsSuite) transaction(amount uint) api.Transaction {
+	return api.Transaction{
+		Handle:      "test1",
+		Amount:      amount,
+		Currency:    "usd",
+		Application: "cloudsim",
+	}
+}
+
+func (s *testReversalsSuite) TestReverseDecrease() {
+	res, err := s.Service.DecreaseCredits(context.Background(), api.DecreaseCreditsRequest{Transaction: s.transaction(30)})
+	s.Require().NoError(err)
+	s.Require().NotZero(res.TransactionID)
+	s.Assert().Equal(70, res.Balance)
+
+	reversal, err := s.Service.ReverseTransaction(context.Background(), api.ReverseTransactionRequest{
+		TransactionID: res.TransactionID,
+	})
+	s.Require().NoError(err)
+	s.Assert().Equal(res.TransactionID, reversal.OriginalTransactionID)
+	s.Assert().NotEqual(res.TransactionID, reversal.TransactionID)
+	s.Assert().Equal(30, reversal.Value)
+	s.Assert().Equal(100, reversal.Balance)
+	s.Assert().Zero(reversal.Remaining)
+
+	_, err = s.Service.ReverseTransaction(context.Background(), api.ReverseTransactionRequest{
+		TransactionID: res.TransactionID,
+	})
+	s.Assert().Equal(api.ErrTransactionAlreadyReversed, err)
+
+	// Reversals cannot be reversed
+	_, err = s.Service.ReverseTransaction(context.Background(), api.ReverseTransactionRequest{
+		TransactionID: reversal.TransactionID,
+	})
+	s.Assert().Equal(api.ErrInvalidReversal, err)
+}
+
+func (s *testReversalsSuite) TestPartialReversals() {
+	res, err := s.Service.IncreaseCredits(context.Background(), api.IncreaseCreditsRequest{Transaction: s.transaction(50)})
+	s.Require().NoError(err)
+
+	reversal, err := s.Service.ReverseTransaction(context.Background(), api.ReverseTransactionRequest{
+		TransactionID: res.TransactionID,
+		Credits:       20,
+	})
+	s.Require().NoError(err)
+	s.Assert().Equal(-20, reversal.Value)
+	s.Assert().Equal(130, reversal.Balance)
+	s.Assert().Equal(uint(30), reversal.Remaining)
+
+	_, err = s.Service.ReverseTransaction(context.Background(), api.ReverseTransactionRequest{
+		TransactionID: res.TransactionID,
+		Credits:       40,
+	})
+	s.Assert().Equal(api.ErrInvalidReversal, err)
+
+	reversal, err = s.Service.ReverseTransaction(context.Background(), api.ReverseTransactionRequest{
+		TransactionID: res.TransactionID,
+	})
+	s.Require().NoError(err)
+	s.Assert().Equal(-30, reversal.Value)
+	s.Assert().Equal(100, reversal.Balance)
+	s.Assert().Zero(reversal.Remaining)
+}
+
+func (s *testReversalsSuite) TestReverseTransfer() {
+	res, err := s.Service.ExecuteBatch(context.Background(), api.ExecuteBatchRequest{
+		Operations: []api.BatchOperation{{
+			Type:        api.OperationTransfer,
+			Transaction: s.transaction(30),
+			Recipient:   "test2",
+		}},
+	})
+	s.Require().NoError(err)
+
+	changes, err := persistence.GetBalanceChangesSince(s.DB, "test1", "cloudsim", models.BalanceChange{}.CreatedAt)
+	s.Require().NoError(err)
+	s.Require().Len(changes, 1)
+	s.Require().Equal(models.OperationTransfer, changes[0].Operation)
+
+	_, err = s.Service.ReverseTransaction(context.Background(), api.ReverseTransactionRequest{
+		TransactionID: changes[0].ID,
+	})
+	s.Assert().Equal(api.ErrInvalidReversal, err)
+
+	c, err := persistence.GetCustomer(s.DB, "test2", "cloudsim")
+	s.Require().NoError(err)
+	s.Assert().Equal(res.Results[0].RecipientBalance, c.Credits)
+}
+
+func (s *testReversalsSuite) TestReverseLinkedOperations() {
+	for _, operation := range []string{
+		models.OperationRefund,
+		models.OperationChargeback,
+		models.OperationTrial,
+		models.OperationAllowance,
+		models.OperationExpiration,
+	} {
+		change, err := updateCredits(s.DB, "test1", "cloudsim", 10, operation)
+		s.Require().NoError(err)
+
+		_, err = s.Service.ReverseTransaction(context.Background(), api.ReverseTransactionRequest{TransactionID: change.ID})
+		s.Assert().Equal(api.ErrInvalidReversal, err, operation)
+	}
+}
+
+func (s *testReversalsSuite) TestReverseFrozenCustomer() {
+	res, err := s.Service.DecreaseCredits(context.Background(), api.DecreaseCreditsRequest{Transaction: s.transaction(10)})
+	s.Require().NoError(err)
+
+	_, err = s.Service.SetCustomerStatus(context.Background(), api.SetCustomerStatusRequest{
+		Handle:      "test1",
+		Application: "cloudsim",
+		Status:      api.CustomerFrozen,
+		Reason:      "test",
+	})
+	s.Require().NoError(err)
+
+	_, err = s.Service.ReverseTransaction(context.Background(), api.ReverseTransactionRequest{TransactionID: res.TransactionID})
+	s.Assert().True(errors.Is(err, api.ErrCustomerFrozen))
+}
+
+func (s *testReversalsSuite) TestReverseUnknownTransaction() {
+	_, err := s.Service.ReverseTransaction(context.Background(), api.ReverseTransactionRequest{TransactionID: 1000})
+	s.Assert().Equal(api.ErrTransactionNotFound, err)
+}
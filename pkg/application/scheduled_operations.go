@@ -0,0 +1,223 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/api"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/domain/models"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/domain/persistence"
+	"gorm.io/gorm"
+	"io"
+	"log"
+	"time"
+)
+
+// ScheduleOperation schedules an operation to run at the given time. Operations scheduled in the past run on the
+// next run of the operation scheduler.
+func (s *service) ScheduleOperation(ctx context.Context, req api.ScheduleOperationRequest) (api.ScheduleOperationResponse, error) {
+	if err := req.Validate(); err != nil {
+		return api.ScheduleOperationResponse{}, err
+	}
+
+	op, err := persistence.CreateScheduledOperation(s.db, models.ScheduledOperation{
+		Handle:      req.Handle,
+		Application: req.Application,
+		Type:        string(req.Type),
+		Amount:      req.Amount,
+		Currency:    req.Currency,
+		RunAt:       req.RunAt.UTC(),
+		Status:      string(api.ScheduledOperationPending),
+	})
+	if err != nil {
+		return api.ScheduleOperationResponse{}, err
+	}
+
+	return api.ScheduleOperationResponse{ScheduledOperation: toScheduledOperationAPI(op)}, nil
+}
+
+// CancelScheduledOperation cancels a pending scheduled operation. The operation is locked like the scheduler does
+// when running it, so an operation can't be canceled while it runs.
+func (s *service) CancelScheduledOperation(ctx context.Context, req api.CancelScheduledOperationRequest) (api.CancelScheduledOperationResponse, error) {
+	var out api.CancelScheduledOperationResponse
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		op, err := persistence.GetScheduledOperationForUpdate(tx, req.ID)
+		if err == gorm.ErrRecordNotFound {
+			return api.ErrScheduledOperationNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if op.Status != string(api.ScheduledOperationPending) {
+			return api.ErrScheduledOperationNotPending
+		}
+
+		op.Status = string(api.ScheduledOperationCanceled)
+		op, err = persistence.FinishScheduledOperation(tx, op)
+		if err != nil {
+			return err
+		}
+		out.ScheduledOperation = toScheduledOperationAPI(op)
+		return nil
+	})
+	if err != nil {
+		return api.CancelScheduledOperationResponse{}, err
+	}
+	return out, nil
+}
+
+// ListScheduledOperations returns the scheduled operations of an application.
+func (s *service) ListScheduledOperations(ctx context.Context, req api.ListScheduledOperationsRequest) (api.ListScheduledOperationsResponse, error) {
+	if err := req.Validate(); err != nil {
+		return api.ListScheduledOperationsResponse{}, err
+	}
+
+	limit := req.Limit
+	if limit == 0 {
+		limit = api.DefaultPageSize
+	}
+
+	list, err := persistence.GetScheduledOperations(s.db, req.Application, req.Handle, string(req.Status), limit)
+	if err != nil {
+		return api.ListScheduledOperationsResponse{}, err
+	}
+
+	out := api.ListScheduledOperationsResponse{Operations: make([]api.ScheduledOperation, len(list))}
+	for i, op := range list {
+		out.Operations[i] = toScheduledOperationAPI(op)
+	}
+	return out, nil
+}
+
+// toScheduledOperationAPI converts the given scheduled operation model into its API representation.
+func toScheduledOperationAPI(op models.ScheduledOperation) api.ScheduledOperation {
+	out := api.ScheduledOperation{
+		ID:   op.ID,
+		Type: api.OperationType(op.Type),
+		Transaction: api.Transaction{
+			Handle:      op.Handle,
+			Amount:      op.Amount,
+			Currency:    op.Currency,
+			Application: op.Application,
+		},
+		RunAt:      op.RunAt,
+		Status:     api.ScheduledOperationStatus(op.Status),
+		Error:      op.Error,
+		ExecutedAt: op.ExecutedAt,
+		CreatedAt:  op.CreatedAt,
+	}
+	if op.TransactionID != nil {
+		out.TransactionID = *op.TransactionID
+	}
+	return out
+}
+
+// scheduledOperationBatchSize is the maximum amount of operations run on every run of the operation scheduler.
+const scheduledOperationBatchSize = 100
+
+// errScheduledOperationSkipped is returned when a scheduled operation shouldn't run.
+var errScheduledOperationSkipped = errors.New("scheduled operation skipped")
+
+// rejectsOperation returns true if the given error is a rejection of the operation by the credits service, and not
+// a failure that could succeed if the operation is retried. Spending limits are not rejections, since they're
+// computed over periods of time and the operation can succeed once the period passes.
+func rejectsOperation(err error) bool {
+	return errors.Is(err, api.ErrInsufficientCredits) ||
+		errors.Is(err, api.ErrCustomerFrozen) ||
+		errors.Is(err, api.ErrCustomerClosed) ||
+		errors.Is(err, api.ErrInvalidAmount)
+}
+
+// operationScheduler is a Worker that runs the scheduled operations that are due.
+type operationScheduler struct {
+	db             *gorm.DB
+	logger         *log.Logger
+	conversionRate uint
+}
+
+// Run runs the due operations every interval until ctx is done.
+func (w *operationScheduler) Run(ctx context.Context, interval time.Duration) {
+	runPeriodically(ctx, interval, w.logger, "Operation scheduler", w.RunOnce)
+}
+
+// RunOnce runs the pending operations whose run time has passed, oldest first. Operations that should have run while
+// the scheduler wasn't running are run as soon as it starts again.
+func (w *operationScheduler) RunOnce(ctx context.Context) error {
+	now := time.Now().UTC()
+	due, err := persistence.GetDueScheduledOperations(w.db, string(api.ScheduledOperationPending), now, scheduledOperationBatchSize)
+	if err != nil {
+		return err
+	}
+
+	for _, op := range due {
+		err = w.run(ctx, op.ID, now)
+		if err != nil && err != errScheduledOperationSkipped {
+			w.logger.Println("Failed to run scheduled operation:", op.ID, "Error:", err)
+		}
+	}
+	return nil
+}
+
+// run runs the given scheduled operation. The operation is locked and finished in the same transaction that changes
+// the credits, so it never runs twice. Operations rejected by the credits service are marked as failed, any other
+// error leaves the operation pending to retry it on the next run.
+func (w *operationScheduler) run(ctx context.Context, id uint, now time.Time) error {
+	return w.db.Transaction(func(tx *gorm.DB) error {
+		op, err := persistence.GetScheduledOperationForUpdate(tx, id)
+		if err != nil {
+			return err
+		}
+		if op.Status != string(api.ScheduledOperationPending) || op.RunAt.After(now) {
+			return errScheduledOperationSkipped
+		}
+
+		credits := &service{db: tx, logger: w.logger, conversionRate: w.conversionRate}
+		transaction := api.Transaction{
+			Handle:      op.Handle,
+			Amount:      op.Amount,
+			Currency:    op.Currency,
+			Application: op.Application,
+		}
+
+		var transactionID uint
+		switch api.OperationType(op.Type) {
+		case api.OperationIncrease:
+			var res api.IncreaseCreditsResponse
+			res, err = credits.IncreaseCredits(ctx, api.IncreaseCreditsRequest{Transaction: transaction})
+			transactionID = res.TransactionID
+		case api.OperationDecrease:
+			var res api.DecreaseCreditsResponse
+			res, err = credits.DecreaseCredits(ctx, api.DecreaseCreditsRequest{Transaction: transaction})
+			transactionID = res.TransactionID
+		default:
+			err = api.ErrInvalidOperationType
+		}
+
+		switch {
+		case err == nil:
+			op.Status = string(api.ScheduledOperationSucceeded)
+			op.TransactionID = &transactionID
+		case rejectsOperation(err) || err == api.ErrInvalidOperationType:
+			op.Status = string(api.ScheduledOperationFailed)
+			op.Error = err.Error()
+		default:
+			return err
+		}
+
+		op.ExecutedAt = &now
+		_, err = persistence.FinishScheduledOperation(tx, op)
+		return err
+	})
+}
+
+// NewOperationScheduler initializes a new Worker that runs the scheduled operations. The conversion rate is used to
+// calculate the credits of every operation.
+func NewOperationScheduler(db *gorm.DB, logger *log.Logger, rate uint) Worker {
+	if logger == nil {
+		logger = log.New(io.Discard, "", log.LstdFlags)
+	}
+	return &operationScheduler{
+		db:             db,
+		logger:         logger,
+		conversionRate: rate,
+	}
+}
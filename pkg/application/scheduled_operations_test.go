@@ -0,0 +1,178 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"gitlab.com/ignitionrobotics/billing/credits/internal/conf"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/api"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/domain/models"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/domain/persistence"
+	"gorm.io/gorm"
+	"log"
+	"os"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRejectsOperation(t *testing.T) {
+	assert.True(t, rejectsOperation(api.ErrInsufficientCredits))
+	assert.True(t, rejectsOperation(&api.CustomerStatusError{Status: api.CustomerFrozen}))
+	assert.True(t, rejectsOperation(api.ErrInvalidAmount))
+	assert.False(t, rejectsOperation(&api.SpendingLimitError{}))
+	assert.False(t, rejectsOperation(errors.New("connection refused")))
+}
+
+type testScheduledOperationsSuite struct {
+	suite.Suite
+	DB      *gorm.DB
+	Logger  *log.Logger
+	Service Service
+}
+
+func TestScheduledOperations(t *testing.T) {
+	suite.Run(t, new(testScheduledOperationsSuite))
+}
+
+func (s *testScheduledOperationsSuite) SetupSuite() {
+	s.Logger = log.New(os.Stdout, "[TestScheduledOperations] ", log.LstdFlags|log.Lshortfile|log.Lmsgprefix)
+
+	var c conf.Config
+	s.Require().NoError(c.Parse())
+
+	var err error
+	s.DB, err = persistence.OpenConn(c.Database)
+	s.Require().NoError(err)
+
+	s.Require().NoError(persistence.DropTables(s.DB))
+}
+
+func (s *testScheduledOperationsSuite) SetupTest() {
+	s.Require().NoError(persistence.MigrateTables(s.DB))
+	s.Service = NewCreditsService(s.DB, s.Logger, 1)
+
+	_, err := persistence.CreateCustomer(s.DB, models.Customer{
+		Handle:      "test1",
+		Application: "cloudsim",
+		Credits:     100,
+	})
+	s.Require().NoError(err)
+}
+
+func (s *testScheduledOperationsSuite) TearDownTest() {
+	s.Require().NoError(persistence.DropTables(s.DB))
+}
+
+func (s *testScheduledOperationsSuite) schedule(typ api.OperationType, amount uint, runAt time.Time) api.ScheduledOperation {
+	res, err := s.Service.ScheduleOperation(context.Background(), api.ScheduleOperationRequest{
+		Type: typ,
+		Transaction: api.Transaction{
+			Handle:      "test1",
+			Amount:      amount,
+			Currency:    "usd",
+			Application: "cloudsim",
+		},
+		RunAt: runAt,
+	})
+	s.Require().NoError(err)
+	return res.ScheduledOperation
+}
+
+func (s *testScheduledOperationsSuite) list(status api.ScheduledOperationStatus) []api.ScheduledOperation {
+	res, err := s.Service.ListScheduledOperations(context.Background(), api.ListScheduledOperationsRequest{
+		Application: "cloudsim",
+		Status:      status,
+	})
+	s.Require().NoError(err)
+	return res.Operations
+}
+
+func (s *testScheduledOperationsSuite) balance() int {
+	c, err := persistence.GetCustomer(s.DB, "test1", "cloudsim")
+	s.Require().NoError(err)
+	return c.Credits
+}
+
+func (s *testScheduledOperationsSuite) TestRunDueOperations() {
+	s.schedule(api.OperationIncrease, 50, time.Now().Add(-time.Hour))
+	s.schedule(api.OperationDecrease, 30, time.Now().Add(-time.Minute))
+	future := s.schedule(api.OperationIncrease, 1000, time.Now().Add(time.Hour))
+
+	scheduler := NewOperationScheduler(s.DB, s.Logger, 1)
+	s.Require().NoError(scheduler.RunOnce(context.Background()))
+	s.Require().NoError(scheduler.RunOnce(context.Background()))
+	s.Assert().Equal(120, s.balance())
+
+	succeeded := s.list(api.ScheduledOperationSucceeded)
+	s.Require().Len(succeeded, 2)
+	s.Assert().NotZero(succeeded[0].TransactionID)
+	s.Assert().NotNil(succeeded[0].ExecutedAt)
+
+	pending := s.list(api.ScheduledOperationPending)
+	s.Require().Len(pending, 1)
+	s.Assert().Equal(future.ID, pending[0].ID)
+}
+
+func (s *testScheduledOperationsSuite) TestRejectedOperationFails() {
+	op := s.schedule(api.OperationDecrease, 500, time.Now().Add(-time.Minute))
+
+	s.Require().NoError(NewOperationScheduler(s.DB, s.Logger, 1).RunOnce(context.Background()))
+	s.Assert().Equal(100, s.balance())
+
+	failed := s.list(api.ScheduledOperationFailed)
+	s.Require().Len(failed, 1)
+	s.Assert().Equal(op.ID, failed[0].ID)
+	s.Assert().Equal(api.ErrInsufficientCredits.Error(), failed[0].Error)
+}
+
+func (s *testScheduledOperationsSuite) TestSpendingLimitIsRetried() {
+	_, err := s.Service.SetSpendingLimits(context.Background(), api.SetSpendingLimitsRequest{
+		SpendingLimits: api.SpendingLimits{Application: "cloudsim", Handle: "test1", CreditsPerHour: 10},
+	})
+	s.Require().NoError(err)
+
+	op := s.schedule(api.OperationDecrease, 30, time.Now().Add(-time.Minute))
+	s.Require().NoError(NewOperationScheduler(s.DB, s.Logger, 1).RunOnce(context.Background()))
+	s.Assert().Equal(100, s.balance())
+
+	// The operation stays pending to run once the spending period has passed.
+	pending := s.list(api.ScheduledOperationPending)
+	s.Require().Len(pending, 1)
+	s.Assert().Equal(op.ID, pending[0].ID)
+	s.Assert().Empty(s.list(api.ScheduledOperationFailed))
+}
+
+func (s *testScheduledOperationsSuite) TestCancel() {
+	op := s.schedule(api.OperationIncrease, 50, time.Now().Add(-time.Minute))
+
+	res, err := s.Service.CancelScheduledOperation(context.Background(), api.CancelScheduledOperationRequest{ID: op.ID})
+	s.Require().NoError(err)
+	s.Assert().Equal(api.ScheduledOperationCanceled, res.Status)
+
+	_, err = s.Service.CancelScheduledOperation(context.Background(), api.CancelScheduledOperationRequest{ID: op.ID})
+	s.Assert().Equal(api.ErrScheduledOperationNotPending, err)
+
+	s.Require().NoError(NewOperationScheduler(s.DB, s.Logger, 1).RunOnce(context.Background()))
+	s.Assert().Equal(100, s.balance())
+}
+
+func (s *testScheduledOperationsSuite) TestConcurrentSchedulers() {
+	for i := 0; i < 5; i++ {
+		s.schedule(api.OperationIncrease, 10, time.Now().Add(-time.Minute))
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.Assert().NoError(NewOperationScheduler(s.DB, s.Logger, 1).RunOnce(context.Background()))
+		}()
+	}
+	wg.Wait()
+
+	s.Assert().Equal(150, s.balance())
+	s.Assert().Len(s.list(api.ScheduledOperationSucceeded), 5)
+}
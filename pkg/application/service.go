@@ -3,11 +3,12 @@ package application
 import (
 	"context"
 	"gitlab.com/ignitionrobotics/billing/credits/pkg/api"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/domain/models"
 	"gitlab.com/ignitionrobotics/billing/credits/pkg/domain/persistence"
 	"gorm.io/gorm"
 	"io"
 	"log"
-	"math"
+	"time"
 )
 
 // service contains the business logic to manage credits.
@@ -29,34 +30,70 @@ func (s *service) GetUnitPrice(ctx context.Context, req api.GetUnitPriceRequest)
 	}, nil
 }
 
-// IncreaseCredits increases the amount of service for a given user.
+// IncreaseCredits increases the amount of service for a given user. The credits are calculated with the volume tiers
+// and packages of the application. Frozen and closed customers can't receive credits.
 func (s *service) IncreaseCredits(ctx context.Context, req api.IncreaseCreditsRequest) (api.IncreaseCreditsResponse, error) {
 	if err := req.Validate(); err != nil {
 		return api.IncreaseCreditsResponse{}, err
 	}
 
-	value := s.calculateCredits(req.Amount, req.Currency)
-
-	if err := persistence.UpdateCredits(s.db, req.Handle, req.Application, int(value)); err != nil {
+	var change models.BalanceChange
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if _, err := lockActiveCustomer(tx, req.Handle, req.Application); err != nil {
+			return err
+		}
+		p, err := s.purchaseCredits(tx, req.Application, req.Amount, req.Currency)
+		if err != nil {
+			return err
+		}
+		change, err = updateCredits(tx, req.Handle, req.Application, int(p.Credits), models.OperationIncrease)
+		return err
+	})
+	if err != nil {
 		return api.IncreaseCreditsResponse{}, err
 	}
 
-	return api.IncreaseCreditsResponse{}, nil
+	return api.IncreaseCreditsResponse{
+		TransactionID: change.ID,
+		Balance:       change.Balance,
+	}, nil
 }
 
-// DecreaseCredits decreases the amount of service for a given user.
+// DecreaseCredits decreases the amount of service for a given user. Customers can't go below their overdraft
+// limit, which is zero unless configured otherwise, nor exceed their spending limits. Frozen and closed customers
+// can't spend credits.
 func (s *service) DecreaseCredits(ctx context.Context, req api.DecreaseCreditsRequest) (api.DecreaseCreditsResponse, error) {
 	if err := req.Validate(); err != nil {
 		return api.DecreaseCreditsResponse{}, err
 	}
 
-	value := s.calculateCredits(req.Amount, req.Currency)
-
-	if err := persistence.UpdateCredits(s.db, req.Handle, req.Application, -1*int(value)); err != nil {
+	var change models.BalanceChange
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		c, err := lockActiveCustomer(tx, req.Handle, req.Application)
+		if err != nil {
+			return err
+		}
+		value, err := s.calculateCredits(tx, req.Application, req.Amount, req.Currency)
+		if err != nil {
+			return err
+		}
+		if err = checkOverdraft(tx, c, value); err != nil {
+			return err
+		}
+		if err = checkSpendingLimits(tx, req.Handle, req.Application, value); err != nil {
+			return err
+		}
+		change, err = updateCredits(tx, req.Handle, req.Application, -1*int(value), models.OperationDecrease)
+		return err
+	})
+	if err != nil {
 		return api.DecreaseCreditsResponse{}, err
 	}
 
-	return api.DecreaseCreditsResponse{}, nil
+	return api.DecreaseCreditsResponse{
+		TransactionID: change.ID,
+		Balance:       change.Balance,
+	}, nil
 }
 
 // GetBalance returns the current amount of service of a given user.
@@ -74,33 +111,266 @@ func (s *service) GetBalance(ctx context.Context, req api.GetBalanceRequest) (ap
 		return api.GetBalanceResponse{}, err
 	}
 
+	limit, err := getOverdraftLimit(s.db, req.Handle, req.Application)
+	if err != nil {
+		return api.GetBalanceResponse{}, err
+	}
+
 	return api.GetBalanceResponse{
-		Handle:      c.Handle,
-		Application: c.Application,
-		Credits:     c.Credits,
+		Handle:         c.Handle,
+		Application:    c.Application,
+		Credits:        c.Credits,
+		Status:         api.CustomerStatus(c.Status),
+		OverdraftLimit: limit,
+		Spendable:      spendableCredits(c.Credits, limit),
 	}, nil
 }
 
-// ConvertCurrency converts a certain amount of FIAT currency in USD to service.
+// GetBalances returns the current balance, status and overdraft limit of each of the given customers using a
+// single query for the customers and another one for the overdraft limits. Handles without a customer have a zero
+// balance and are active, like new customers.
+func (s *service) GetBalances(ctx context.Context, req api.GetBalancesRequest) (api.GetBalancesResponse, error) {
+	if err := req.Validate(); err != nil {
+		return api.GetBalancesResponse{}, err
+	}
+
+	list, err := persistence.GetCustomers(s.db, req.Handles, req.Application)
+	if err != nil {
+		return api.GetBalancesResponse{}, err
+	}
+
+	customers := make(map[string]models.Customer, len(list))
+	for _, c := range list {
+		customers[c.Handle] = c
+	}
+
+	limits, err := persistence.GetEffectiveOverdraftLimits(s.db, req.Application, req.Handles)
+	if err != nil {
+		return api.GetBalancesResponse{}, err
+	}
+	overdrafts := make(map[string]uint, len(limits))
+	for _, l := range limits {
+		overdrafts[l.Handle] = l.Amount
+	}
+
+	out := api.GetBalancesResponse{
+		Balances: make([]api.GetBalanceResponse, len(req.Handles)),
+	}
+	for i, h := range req.Handles {
+		c, ok := customers[h]
+		if !ok {
+			c.Status = string(api.CustomerActive)
+		}
+		limit, ok := overdrafts[h]
+		if !ok {
+			limit = overdrafts[""]
+		}
+		out.Balances[i] = api.GetBalanceResponse{
+			Handle:         h,
+			Application:    req.Application,
+			Credits:        c.Credits,
+			Status:         api.CustomerStatus(c.Status),
+			OverdraftLimit: limit,
+			Spendable:      spendableCredits(c.Credits, limit),
+		}
+	}
+	return out, nil
+}
+
+// ConvertCurrency converts a certain amount of FIAT currency in USD to service. The volume tiers and packages of the
+// application are applied if an application is given.
 func (s *service) ConvertCurrency(ctx context.Context, req api.ConvertCurrencyRequest) (api.ConvertCurrencyResponse, error) {
 	if len(req.Currency) == 0 || len(req.Currency) > 3 {
 		s.logger.Println("Invalid currency format")
 		return api.ConvertCurrencyResponse{}, api.ErrInvalidCurrencyFormat
 	}
+	if len(req.Application) == 0 {
+		credits, err := s.calculateCredits(s.db, req.Application, req.Amount, req.Currency)
+		if err != nil {
+			return api.ConvertCurrencyResponse{}, err
+		}
+		return api.ConvertCurrencyResponse{Credits: credits}, nil
+	}
+
+	p, err := s.purchaseCredits(s.db, req.Application, req.Amount, req.Currency)
+	if err != nil {
+		return api.ConvertCurrencyResponse{}, err
+	}
 	return api.ConvertCurrencyResponse{
-		Credits: s.calculateCredits(req.Amount, req.Currency),
+		Credits: p.Credits,
+		Bonus:   p.Bonus,
+		Package: p.Package,
 	}, nil
 }
 
-// calculateCredits applies the conversion rate to amount in a certain currency and returns a credits value.
-// It rounds up the output value to the closest integer.
-func (s *service) calculateCredits(amount uint, currency string) uint {
-	return uint(math.Ceil(float64(amount) / float64(s.conversionRate)))
+// EstimateCost returns the cost of a certain quantity of a SKU and whether the given customer can afford it.
+// The available balance takes into account the credits held by open usage sessions. If the customer cannot afford
+// the cost, the shortfall is also returned in the requested currency. Usages whose cost doesn't fit in a balance are
+// rejected with api.ErrInvalidQuantity.
+func (s *service) EstimateCost(ctx context.Context, req api.EstimateCostRequest) (api.EstimateCostResponse, error) {
+	if err := req.Validate(); err != nil {
+		return api.EstimateCostResponse{}, err
+	}
+
+	sku, err := s.getSKU(api.SKUIdentifier{Application: req.Application, Code: req.SKU})
+	if err != nil {
+		return api.EstimateCostResponse{}, err
+	}
+
+	now := time.Now()
+	price, err := persistence.GetEffectiveSKUPrice(s.db, sku.ID, now)
+	if err == gorm.ErrRecordNotFound {
+		return api.EstimateCostResponse{}, api.ErrNoEffectivePrice
+	}
+	if err != nil {
+		return api.EstimateCostResponse{}, err
+	}
+
+	periods := req.Periods
+	if periods == 0 {
+		periods = 1
+	}
+	cost, ok := multiplyCredits(price.Price, req.Quantity)
+	if ok {
+		cost, ok = multiplyCredits(cost, periods)
+	}
+	if !ok {
+		return api.EstimateCostResponse{}, api.ErrInvalidQuantity
+	}
+
+	available, err := s.getAvailableCredits(req.Handle, req.Application, now)
+	if err != nil {
+		return api.EstimateCostResponse{}, err
+	}
+
+	out := api.EstimateCostResponse{
+		Cost:       cost,
+		Available:  available,
+		Affordable: available >= int(cost),
+		Currency:   req.Currency,
+	}
+	if !out.Affordable {
+		out.Shortfall = uint(int(cost) - available)
+		out.ShortfallAmount, err = s.calculateAmount(s.db, req.Application, out.Shortfall, req.Currency)
+		if err != nil {
+			return api.EstimateCostResponse{}, err
+		}
+	}
+	return out, nil
+}
+
+// getAvailableCredits returns the amount of credits a customer can spend at the given time. Customers that don't
+// exist yet have no credits available.
+func (s *service) getAvailableCredits(handle, application string, at time.Time) (int, error) {
+	c, err := persistence.GetCustomer(s.db, handle, application)
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return 0, err
+	}
+
+	held, err := heldCredits(s.db, handle, application, at)
+	if err != nil {
+		return 0, err
+	}
+
+	return c.Credits - int(held), nil
+}
+
+// calculateAmount returns the amount in a certain currency needed to buy the given credits of an application. It's
+// the inverse of calculateCredits. Amounts too large to be represented are rejected with api.ErrInvalidAmount.
+func (s *service) calculateAmount(db *gorm.DB, application string, credits uint, currency string) (uint, error) {
+	policy, err := getConversionPolicy(db, application)
+	if err != nil {
+		return 0, err
+	}
+	amount, ok := convertToAmount(policy, credits, s.conversionRate)
+	if !ok {
+		return 0, api.ErrInvalidAmount
+	}
+	return amount, nil
+}
+
+// updateCredits increases or decreases the credits of a customer, recording the change with the given operation.
+// Customers that don't exist yet are created first with createCustomer. The ledger of the application is locked
+// before the customer, see lockLedger.
+// The ledger entries, the ledger record, the outbox event, the webhook events and the low balance alert triggered
+// by the change are written in the same transaction.
+func updateCredits(db *gorm.DB, handle, application string, value int, operation string) (models.BalanceChange, error) {
+	return updateCreditsAgainst(db, handle, application, value, operation, counterpartAccount(operation, value))
+}
+
+// updateCreditsAgainst works like updateCredits, but posts the opposite ledger entry in the given counterpart
+// account type instead of the default one of the operation.
+func updateCreditsAgainst(db *gorm.DB, handle, application string, value int, operation, counterpart string) (models.BalanceChange, error) {
+	var change models.BalanceChange
+	err := db.Transaction(func(tx *gorm.DB) error {
+		err := lockLedger(tx, application)
+		if err != nil {
+			return err
+		}
+		if err = ensureCustomer(tx, handle, application); err != nil {
+			return err
+		}
+		change, err = persistence.UpdateCredits(tx, handle, application, value, operation)
+		if err != nil {
+			return err
+		}
+		if err = postEntries(tx, change, counterpart); err != nil {
+			return err
+		}
+		if err = appendLedgerRecord(tx, change); err != nil {
+			return err
+		}
+		if err = enqueueOutboxEvent(tx, change); err != nil {
+			return err
+		}
+		low, err := checkLowBalance(tx, change)
+		if err != nil {
+			return err
+		}
+		return enqueueWebhookEvents(tx, change, low)
+	})
+	if err != nil {
+		return models.BalanceChange{}, err
+	}
+	return change, nil
+}
+
+// calculateCredits applies the conversion rate to amount in a certain currency and returns a credits value in the
+// balance units of the given application. It's rounded with the conversion policy of the application. Amounts whose
+// credits don't fit in a balance are rejected with api.ErrInvalidAmount.
+func (s *service) calculateCredits(db *gorm.DB, application string, amount uint, currency string) (uint, error) {
+	policy, err := getConversionPolicy(db, application)
+	if err != nil {
+		return 0, err
+	}
+	credits, ok := convertToCredits(policy, amount, s.conversionRate)
+	if !ok {
+		return 0, api.ErrInvalidAmount
+	}
+	return credits, nil
 }
 
 // Service holds the methods of the service in charge of managing user credits.
 type Service interface {
 	api.CreditsV1
+	api.SessionsV1
+	api.PricingV1
+	api.CustomersV1
+	api.WebhooksV1
+	api.ThresholdsV1
+	api.TopUpsV1
+	api.PaymentsV1
+	api.AccountingV1
+	api.LedgerV1
+	api.OverdraftsV1
+	api.SpendingLimitsV1
+	api.WalletsV1
+	api.SubscriptionsV1
+	api.ScheduledOperationsV1
+	api.CouponsV1
+	api.TrialsV1
+	api.PurchasePricingV1
+	api.ConversionV1
 }
 
 // NewCreditsService initializes a new api.CreditsV1 service implementation.
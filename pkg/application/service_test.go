@@ -10,7 +10,9 @@ import (
 	"gorm.io/gorm"
 	"log"
 	"os"
+	"sync"
 	"testing"
+	"time"
 )
 
 type testManageCreditsSuite struct {
@@ -291,6 +293,80 @@ func (s *testManageCreditsSuite) TestGetBalance() {
 	s.Assert().Equal(balance, res.Credits)
 }
 
+func (s *testManageCreditsSuite) TestGetBalances() {
+	res, err := s.Service.GetBalances(context.Background(), api.GetBalancesRequest{
+		Handles:     []string{"test2", "test1", "test3", "unknown"},
+		Application: "cloudsim",
+	})
+	s.Require().NoError(err)
+	s.Require().Len(res.Balances, 4)
+
+	s.Assert().Equal("test2", res.Balances[0].Handle)
+	s.Assert().Equal(-100, res.Balances[0].Credits)
+	s.Assert().Equal("test1", res.Balances[1].Handle)
+	s.Assert().Equal(0, res.Balances[1].Credits)
+	s.Assert().Equal("test3", res.Balances[2].Handle)
+	s.Assert().Equal(0, res.Balances[2].Credits)
+	s.Assert().Equal("unknown", res.Balances[3].Handle)
+	s.Assert().Equal(0, res.Balances[3].Credits)
+	s.Assert().Equal("cloudsim", res.Balances[3].Application)
+}
+
+func (s *testManageCreditsSuite) TestGetBalancesIncludesStatusAndOverdraft() {
+	_, err := s.Service.SetOverdraftLimit(context.Background(), api.SetOverdraftLimitRequest{
+		OverdraftLimit: api.OverdraftLimit{Application: "cloudsim", Limit: 150},
+	})
+	s.Require().NoError(err)
+	_, err = s.Service.SetOverdraftLimit(context.Background(), api.SetOverdraftLimitRequest{
+		OverdraftLimit: api.OverdraftLimit{Application: "cloudsim", Handle: "test3", Limit: 20},
+	})
+	s.Require().NoError(err)
+	_, err = s.Service.SetCustomerStatus(context.Background(), api.SetCustomerStatusRequest{
+		Handle:      "test3",
+		Application: "cloudsim",
+		Status:      api.CustomerFrozen,
+		Reason:      "fraud review",
+	})
+	s.Require().NoError(err)
+
+	res, err := s.Service.GetBalances(context.Background(), api.GetBalancesRequest{
+		Handles:     []string{"test2", "test3", "unknown"},
+		Application: "cloudsim",
+	})
+	s.Require().NoError(err)
+	s.Require().Len(res.Balances, 3)
+
+	s.Assert().Equal(api.CustomerActive, res.Balances[0].Status)
+	s.Assert().Equal(uint(150), res.Balances[0].OverdraftLimit)
+	s.Assert().Equal(50, res.Balances[0].Spendable)
+
+	s.Assert().Equal(api.CustomerFrozen, res.Balances[1].Status)
+	s.Assert().Equal(uint(20), res.Balances[1].OverdraftLimit)
+	s.Assert().Equal(20, res.Balances[1].Spendable)
+
+	s.Assert().Equal(api.CustomerActive, res.Balances[2].Status)
+	s.Assert().Equal(uint(150), res.Balances[2].OverdraftLimit)
+	s.Assert().Equal(150, res.Balances[2].Spendable)
+}
+
+func (s *testManageCreditsSuite) TestGetBalancesValidation() {
+	_, err := s.Service.GetBalances(context.Background(), api.GetBalancesRequest{
+		Application: "cloudsim",
+	})
+	s.Assert().Equal(api.ErrHandleNotProvided, err)
+
+	_, err = s.Service.GetBalances(context.Background(), api.GetBalancesRequest{
+		Handles:     make([]string, api.MaxBalancesHandles+1),
+		Application: "cloudsim",
+	})
+	s.Assert().Equal(api.ErrTooManyHandles, err)
+
+	_, err = s.Service.GetBalances(context.Background(), api.GetBalancesRequest{
+		Handles: []string{"test1"},
+	})
+	s.Assert().Equal(api.ErrMissingApplication, err)
+}
+
 func (s *testManageCreditsSuite) TestConvertCreditsAmountIsZero() {
 	res, err := s.Service.ConvertCurrency(context.Background(), api.ConvertCurrencyRequest{
 		Amount:   0,
@@ -384,6 +460,55 @@ func (s *testManageCreditsSuite) TestDecreaseCreditsConversionRoundedUp() {
 	s.Assert().Equal(before.Credits-2, after.Credits)
 }
 
+func (s *testManageCreditsSuite) TestConcurrentUpdatesAreNotLost() {
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := persistence.UpdateCredits(s.DB, "test3", "cloudsim", 5, models.OperationIncrease)
+			s.Assert().NoError(err)
+		}()
+	}
+	wg.Wait()
+
+	c, err := persistence.GetCustomer(s.DB, "test3", "cloudsim")
+	s.Require().NoError(err)
+	s.Assert().Equal(50, c.Credits)
+
+	changes, err := persistence.GetBalanceChangesSince(s.DB, "test3", "cloudsim", time.Time{})
+	s.Require().NoError(err)
+	balances := make(map[int]bool, len(changes))
+	for _, change := range changes {
+		balances[change.Balance] = true
+	}
+	s.Assert().Len(balances, 10)
+	s.Assert().True(balances[50])
+}
+
+func (s *testManageCreditsSuite) TestConcurrentFirstOperationsCreateOneCustomer() {
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := s.Service.IncreaseCredits(context.Background(), api.IncreaseCreditsRequest{
+				Transaction: api.Transaction{Handle: "new", Amount: 500, Currency: "usd", Application: "cloudsim"},
+			})
+			s.Assert().NoError(err)
+		}()
+	}
+	wg.Wait()
+
+	count, err := persistence.CountCustomers(s.DB, "cloudsim")
+	s.Require().NoError(err)
+	s.Assert().Equal(int64(3), count)
+
+	c, err := persistence.GetCustomer(s.DB, "new", "cloudsim")
+	s.Require().NoError(err)
+	s.Assert().Equal(5, c.Credits)
+}
+
 func (s *testManageCreditsSuite) TestGetUnitPriceValidationFails() {
 	_, err := s.Service.GetUnitPrice(context.Background(), api.GetUnitPriceRequest{Currency: ""})
 	s.Assert().Error(err)
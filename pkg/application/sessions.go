@@ -0,0 +1,410 @@
+package application
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/api"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/domain/models"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/domain/persistence"
+	"gorm.io/gorm"
+	"io"
+	"log"
+	"net/http"
+	"time"
+)
+
+// OpenSession opens a new usage session for the given customer. The customer needs to be active and have spendable
+// credits, including its overdraft limit.
+func (s *service) OpenSession(ctx context.Context, req api.OpenSessionRequest) (api.OpenSessionResponse, error) {
+	if err := req.Validate(); err != nil {
+		return api.OpenSessionResponse{}, err
+	}
+
+	c, err := persistence.GetCustomer(s.db, req.Handle, req.Application)
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return api.OpenSessionResponse{}, err
+	}
+	if err = checkCustomerStatus(c); err != nil {
+		return api.OpenSessionResponse{}, err
+	}
+	limit, err := getOverdraftLimit(s.db, req.Handle, req.Application)
+	if err != nil {
+		return api.OpenSessionResponse{}, err
+	}
+	if spendableCredits(c.Credits, limit) == 0 {
+		s.logger.Println("Cannot open session, insufficient credits:", req.Handle, req.Application)
+		return api.OpenSessionResponse{}, api.ErrInsufficientCredits
+	}
+
+	now := time.Now()
+	session, err := persistence.CreateSession(s.db, models.Session{
+		Handle:          req.Handle,
+		Application:     req.Application,
+		Price:           req.Price,
+		Unit:            req.Unit,
+		Status:          string(api.SessionOpen),
+		NotifyURL:       req.NotifyURL,
+		ChargedUntil:    now,
+		LastHeartbeatAt: now,
+	})
+	if err != nil {
+		return api.OpenSessionResponse{}, err
+	}
+
+	return api.OpenSessionResponse{Session: toSessionAPI(session)}, nil
+}
+
+// SendHeartbeat updates the last time a certain session was seen alive. Closed sessions are returned as they are,
+// clients should stop using the metered resource when that happens.
+func (s *service) SendHeartbeat(ctx context.Context, req api.SendHeartbeatRequest) (api.SendHeartbeatResponse, error) {
+	var session models.Session
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		session, err = getSessionForUpdate(tx, req.ID)
+		if err != nil {
+			return err
+		}
+		if session.Status != string(api.SessionOpen) {
+			return nil
+		}
+		session.LastHeartbeatAt = time.Now()
+		session, err = persistence.SaveSession(tx, session)
+		return err
+	})
+	if err != nil {
+		return api.SendHeartbeatResponse{}, err
+	}
+	return api.SendHeartbeatResponse{Session: toSessionAPI(session)}, nil
+}
+
+// CloseSession closes the given session, charging the usage since the last charge. Partial time units are rounded up.
+// Usage that the customer can't pay for is not charged, see chargeSession.
+func (s *service) CloseSession(ctx context.Context, req api.CloseSessionRequest) (api.CloseSessionResponse, error) {
+	var session models.Session
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		session, err = getSessionForUpdate(tx, req.ID)
+		if err != nil {
+			return err
+		}
+		if session.Status != string(api.SessionOpen) {
+			return api.ErrSessionClosed
+		}
+		now := time.Now()
+		if err = chargeSession(tx, &session, now, true); err != nil && !unpaidSessionCharge(err) {
+			return err
+		}
+		session, err = closeSession(tx, session, now, api.CloseReasonRequested)
+		return err
+	})
+	if err != nil {
+		return api.CloseSessionResponse{}, err
+	}
+	return api.CloseSessionResponse{Session: toSessionAPI(session)}, nil
+}
+
+// GetSession returns the current state of the given session.
+func (s *service) GetSession(ctx context.Context, req api.GetSessionRequest) (api.GetSessionResponse, error) {
+	session, err := persistence.GetSession(s.db, req.ID)
+	if err == gorm.ErrRecordNotFound {
+		return api.GetSessionResponse{}, api.ErrSessionNotFound
+	}
+	if err != nil {
+		return api.GetSessionResponse{}, err
+	}
+	return api.GetSessionResponse{Session: toSessionAPI(session)}, nil
+}
+
+// getSessionForUpdate returns a locked session, it returns api.ErrSessionNotFound if the session doesn't exist.
+func getSessionForUpdate(tx *gorm.DB, id uint) (models.Session, error) {
+	session, err := persistence.GetSessionForUpdate(tx, id)
+	if err == gorm.ErrRecordNotFound {
+		return models.Session{}, api.ErrSessionNotFound
+	}
+	return session, err
+}
+
+// chargeSession debits the usage of the given session between the last time it was charged and until.
+// Only complete time units are charged, unless final is true, in which case the last partial unit is rounded up.
+// The customer is locked, and it needs to be active, and it can't go below its overdraft limit nor exceed its
+// spending limits. Charges that the customer can't pay for are rejected with an error for which unpaidSessionCharge
+// returns true.
+func chargeSession(tx *gorm.DB, session *models.Session, until time.Time, final bool) error {
+	period, amount, err := pendingSessionCharge(*session, until, final)
+	if err != nil {
+		return err
+	}
+	if amount == 0 {
+		return nil
+	}
+
+	c, err := lockActiveCustomer(tx, session.Handle, session.Application)
+	if err != nil {
+		return err
+	}
+	if err = checkOverdraft(tx, c, amount); err != nil {
+		return err
+	}
+	if err = checkSpendingLimits(tx, session.Handle, session.Application, amount); err != nil {
+		return err
+	}
+	if _, err = updateCredits(tx, session.Handle, session.Application, -1*int(amount), models.OperationSession); err != nil {
+		return err
+	}
+
+	session.Charged += amount
+	session.ChargedUntil = session.ChargedUntil.Add(period)
+	return nil
+}
+
+// unpaidSessionCharge returns true if the given chargeSession error means that the customer can't pay for the usage.
+func unpaidSessionCharge(err error) bool {
+	return errors.Is(err, api.ErrInsufficientCredits) || errors.Is(err, api.ErrSpendingLimitExceeded) || inactiveCustomer(err)
+}
+
+// inactiveCustomer returns true if the given error is an api.CustomerStatusError.
+func inactiveCustomer(err error) bool {
+	var statusErr *api.CustomerStatusError
+	return errors.As(err, &statusErr)
+}
+
+// pendingSessionCharge returns the amount of credits that the given session has used between the last time it was
+// charged and until, alongside the period of time that amount covers.
+// Only complete time units are counted, unless final is true, in which case the last partial unit is rounded up.
+// Amounts that don't fit in a balance are rejected with api.ErrInsufficientCredits.
+func pendingSessionCharge(session models.Session, until time.Time, final bool) (time.Duration, uint, error) {
+	unit, err := api.ParseTimeUnit(session.Unit)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	elapsed := until.Sub(session.ChargedUntil)
+	if elapsed <= 0 {
+		return 0, 0, nil
+	}
+
+	units := elapsed / unit
+	if final && elapsed%unit != 0 {
+		units++
+	}
+	amount, ok := multiplyCredits(uint(units), session.Price)
+	if !ok {
+		return 0, 0, api.ErrInsufficientCredits
+	}
+	return units * unit, amount, nil
+}
+
+// heldCredits returns the amount of credits used by the open sessions of a certain customer that haven't been
+// charged yet.
+func heldCredits(db *gorm.DB, handle, application string, at time.Time) (uint, error) {
+	sessions, err := persistence.GetCustomerSessions(db, handle, application, string(api.SessionOpen))
+	if err != nil {
+		return 0, err
+	}
+
+	var held uint
+	for _, session := range sessions {
+		_, amount, err := pendingSessionCharge(session, at, true)
+		if err != nil {
+			return 0, err
+		}
+		held += amount
+	}
+	return held, nil
+}
+
+// closeSession marks the given session as closed for the given reason.
+func closeSession(tx *gorm.DB, session models.Session, at time.Time, reason string) (models.Session, error) {
+	session.Status = string(api.SessionClosed)
+	session.CloseReason = reason
+	session.ClosedAt = &at
+	return persistence.SaveSession(tx, session)
+}
+
+// toSessionAPI converts the given session model into its API representation.
+func toSessionAPI(session models.Session) api.Session {
+	return api.Session{
+		ID:              session.ID,
+		Handle:          session.Handle,
+		Application:     session.Application,
+		Price:           session.Price,
+		Unit:            session.Unit,
+		Status:          api.SessionStatus(session.Status),
+		CloseReason:     session.CloseReason,
+		Charged:         session.Charged,
+		StartedAt:       session.CreatedAt,
+		LastHeartbeatAt: session.LastHeartbeatAt,
+		ClosedAt:        session.ClosedAt,
+	}
+}
+
+// SessionNotifier notifies clients about sessions being closed by the credits service.
+type SessionNotifier interface {
+	// NotifySessionClosed notifies that the given session has been closed.
+	NotifySessionClosed(ctx context.Context, url string, session api.Session) error
+}
+
+// httpSessionNotifier is a SessionNotifier implementation that sends the session as a JSON body to the URL
+// provided when the session was opened.
+type httpSessionNotifier struct {
+	client *http.Client
+}
+
+// NotifySessionClosed sends a POST request to the given url with the session as body.
+func (n *httpSessionNotifier) NotifySessionClosed(ctx context.Context, url string, session api.Session) error {
+	body, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewBuffer(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	_, _ = io.Copy(io.Discard, res.Body)
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("unexpected status code notifying session %d: %d", session.ID, res.StatusCode)
+	}
+	return nil
+}
+
+// NewHTTPSessionNotifier initializes a new SessionNotifier that uses HTTP requests to notify clients.
+func NewHTTPSessionNotifier(timeout time.Duration) SessionNotifier {
+	return &httpSessionNotifier{
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+// sessionMeter is a Worker that charges open sessions periodically.
+type sessionMeter struct {
+	db               *gorm.DB
+	logger           *log.Logger
+	notifier         SessionNotifier
+	heartbeatTimeout time.Duration
+}
+
+// Run charges open sessions every interval until ctx is done.
+func (m *sessionMeter) Run(ctx context.Context, interval time.Duration) {
+	runPeriodically(ctx, interval, m.logger, "Session metering", m.RunOnce)
+}
+
+// RunOnce charges all the open sessions. Sessions that haven't received a heartbeat within the heartbeat timeout
+// are charged until their last heartbeat and closed. Sessions whose customer ran out of spendable credits, can't
+// pay for the usage, or has been frozen or closed, are closed without charging it.
+func (m *sessionMeter) RunOnce(ctx context.Context) error {
+	ids, err := persistence.GetSessionIDsByStatus(m.db, string(api.SessionOpen))
+	if err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		if err = m.meter(ctx, id); err != nil {
+			m.logger.Println("Failed to meter session:", id, "Error:", err)
+		}
+	}
+	return nil
+}
+
+// meter charges the session identified by the given id, closing it if needed.
+func (m *sessionMeter) meter(ctx context.Context, id uint) error {
+	var session models.Session
+	var closed bool
+	err := m.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		session, err = getSessionForUpdate(tx, id)
+		if err != nil {
+			return err
+		}
+		if session.Status != string(api.SessionOpen) {
+			return nil
+		}
+
+		now := time.Now()
+		reason, err := m.charge(tx, &session, now)
+		if err != nil {
+			return err
+		}
+		if len(reason) > 0 {
+			closed = true
+			session, err = closeSession(tx, session, now, reason)
+			return err
+		}
+
+		session, err = persistence.SaveSession(tx, session)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	if closed && len(session.NotifyURL) > 0 {
+		if err = m.notifier.NotifySessionClosed(ctx, session.NotifyURL, toSessionAPI(session)); err != nil {
+			m.logger.Println("Failed to notify session closed:", session.ID, "Error:", err)
+		}
+	}
+	return nil
+}
+
+// charge charges the usage of the given session until now, and returns the reason to close it, if it needs to be
+// closed.
+func (m *sessionMeter) charge(tx *gorm.DB, session *models.Session, now time.Time) (string, error) {
+	if now.Sub(session.LastHeartbeatAt) > m.heartbeatTimeout {
+		err := chargeSession(tx, session, session.LastHeartbeatAt, true)
+		if err != nil && !unpaidSessionCharge(err) {
+			return "", err
+		}
+		return api.CloseReasonHeartbeatTimeout, nil
+	}
+
+	err := chargeSession(tx, session, now, false)
+	if err == nil {
+		_, err = lockActiveCustomer(tx, session.Handle, session.Application)
+	}
+	if inactiveCustomer(err) {
+		return api.CloseReasonCustomerInactive, nil
+	}
+	if unpaidSessionCharge(err) {
+		return api.CloseReasonInsufficientCredits, nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	c, err := persistence.GetCustomer(tx, session.Handle, session.Application)
+	if err != nil {
+		return "", err
+	}
+	limit, err := getOverdraftLimit(tx, session.Handle, session.Application)
+	if err != nil {
+		return "", err
+	}
+	if spendableCredits(c.Credits, limit) == 0 {
+		return api.CloseReasonInsufficientCredits, nil
+	}
+	return "", nil
+}
+
+// NewSessionMeter initializes a new Worker that charges open usage sessions. Sessions that don't receive a heartbeat
+// within heartbeatTimeout get closed.
+func NewSessionMeter(db *gorm.DB, logger *log.Logger, notifier SessionNotifier, heartbeatTimeout time.Duration) Worker {
+	if logger == nil {
+		logger = log.New(io.Discard, "", log.LstdFlags)
+	}
+	return &sessionMeter{
+		db:               db,
+		logger:           logger,
+		notifier:         notifier,
+		heartbeatTimeout: heartbeatTimeout,
+	}
+}
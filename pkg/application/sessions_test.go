@@ -0,0 +1,277 @@
+package application
+
+import (
+	"context"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"gitlab.com/ignitionrobotics/billing/credits/internal/conf"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/api"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/domain/models"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/domain/persistence"
+	"gorm.io/gorm"
+	"log"
+	"math"
+	"os"
+	"testing"
+	"time"
+)
+
+type fakeSessionNotifier struct {
+	mock.Mock
+}
+
+func (n *fakeSessionNotifier) NotifySessionClosed(ctx context.Context, url string, session api.Session) error {
+	args := n.Called(ctx, url, session)
+	return args.Error(0)
+}
+
+type testSessionsSuite struct {
+	suite.Suite
+	DB       *gorm.DB
+	Logger   *log.Logger
+	Service  Service
+	Notifier *fakeSessionNotifier
+	Meter    Worker
+}
+
+func TestSessions(t *testing.T) {
+	suite.Run(t, new(testSessionsSuite))
+}
+
+func (s *testSessionsSuite) SetupSuite() {
+	s.Logger = log.New(os.Stdout, "[TestSessions] ", log.LstdFlags|log.Lshortfile|log.Lmsgprefix)
+
+	var c conf.Config
+	s.Require().NoError(c.Parse())
+
+	var err error
+	s.DB, err = persistence.OpenConn(c.Database)
+	s.Require().NoError(err)
+
+	s.Require().NoError(persistence.DropTables(s.DB))
+}
+
+func (s *testSessionsSuite) SetupTest() {
+	s.Require().NoError(persistence.MigrateTables(s.DB))
+
+	s.Service = NewCreditsService(s.DB, s.Logger, 1)
+	s.Notifier = new(fakeSessionNotifier)
+	s.Meter = NewSessionMeter(s.DB, s.Logger, s.Notifier, time.Hour)
+
+	_, err := persistence.CreateCustomer(s.DB, models.Customer{
+		Handle:      "test1",
+		Application: "cloudsim",
+		Credits:     100,
+	})
+	s.Require().NoError(err)
+}
+
+func (s *testSessionsSuite) TearDownTest() {
+	s.Require().NoError(persistence.DropTables(s.DB))
+}
+
+func (s *testSessionsSuite) openSession(price uint, notifyURL string) api.Session {
+	res, err := s.Service.OpenSession(context.Background(), api.OpenSessionRequest{
+		Handle:      "test1",
+		Application: "cloudsim",
+		Price:       price,
+		Unit:        "1m",
+		NotifyURL:   notifyURL,
+	})
+	s.Require().NoError(err)
+	return res.Session
+}
+
+// rewind moves the charging and heartbeat times of the given session back in time.
+func (s *testSessionsSuite) rewind(id uint, d time.Duration) {
+	session, err := persistence.GetSession(s.DB, id)
+	s.Require().NoError(err)
+	session.ChargedUntil = session.ChargedUntil.Add(-d)
+	session.LastHeartbeatAt = session.LastHeartbeatAt.Add(-d)
+	_, err = persistence.SaveSession(s.DB, session)
+	s.Require().NoError(err)
+}
+
+func (s *testSessionsSuite) TestOpenSessionValidation() {
+	_, err := s.Service.OpenSession(context.Background(), api.OpenSessionRequest{
+		Handle:      "test1",
+		Application: "cloudsim",
+		Price:       0,
+		Unit:        "1m",
+	})
+	s.Assert().Equal(api.ErrInvalidPrice, err)
+
+	_, err = s.Service.OpenSession(context.Background(), api.OpenSessionRequest{
+		Handle:      "test1",
+		Application: "cloudsim",
+		Price:       1,
+		Unit:        "minute",
+	})
+	s.Assert().Equal(api.ErrInvalidTimeUnit, err)
+}
+
+func (s *testSessionsSuite) TestOpenSessionInsufficientCredits() {
+	_, err := s.Service.OpenSession(context.Background(), api.OpenSessionRequest{
+		Handle:      "test2",
+		Application: "cloudsim",
+		Price:       1,
+		Unit:        "1m",
+	})
+	s.Assert().Equal(api.ErrInsufficientCredits, err)
+}
+
+func (s *testSessionsSuite) TestMeterChargesCompleteUnits() {
+	session := s.openSession(2, "")
+	s.rewind(session.ID, 10*time.Minute+30*time.Second)
+
+	s.Require().NoError(s.Meter.RunOnce(context.Background()))
+
+	res, err := s.Service.GetSession(context.Background(), api.GetSessionRequest{ID: session.ID})
+	s.Require().NoError(err)
+	s.Assert().Equal(api.SessionOpen, res.Status)
+	s.Assert().Equal(uint(20), res.Charged)
+
+	c, err := persistence.GetCustomer(s.DB, "test1", "cloudsim")
+	s.Require().NoError(err)
+	s.Assert().Equal(80, c.Credits)
+}
+
+func (s *testSessionsSuite) TestCloseSessionRoundsUp() {
+	session := s.openSession(2, "")
+	s.rewind(session.ID, 90*time.Second)
+
+	res, err := s.Service.CloseSession(context.Background(), api.CloseSessionRequest{ID: session.ID})
+	s.Require().NoError(err)
+	s.Assert().Equal(api.SessionClosed, res.Status)
+	s.Assert().Equal(api.CloseReasonRequested, res.CloseReason)
+	s.Assert().Equal(uint(4), res.Charged)
+
+	_, err = s.Service.CloseSession(context.Background(), api.CloseSessionRequest{ID: session.ID})
+	s.Assert().Equal(api.ErrSessionClosed, err)
+}
+
+func (s *testSessionsSuite) TestMeterClosesSessionWhenCreditsRunOut() {
+	const notifyURL = "http://localhost/notify"
+	session := s.openSession(10, notifyURL)
+	s.rewind(session.ID, 10*time.Minute)
+
+	s.Notifier.On("NotifySessionClosed", mock.Anything, notifyURL, mock.AnythingOfType("api.Session")).Return(error(nil)).Once()
+
+	s.Require().NoError(s.Meter.RunOnce(context.Background()))
+	s.Notifier.AssertExpectations(s.T())
+
+	res, err := s.Service.SendHeartbeat(context.Background(), api.SendHeartbeatRequest{ID: session.ID})
+	s.Require().NoError(err)
+	s.Assert().Equal(api.SessionClosed, res.Status)
+	s.Assert().Equal(api.CloseReasonInsufficientCredits, res.CloseReason)
+
+	c, err := persistence.GetCustomer(s.DB, "test1", "cloudsim")
+	s.Require().NoError(err)
+	s.Assert().Equal(0, c.Credits)
+}
+
+func (s *testSessionsSuite) TestMeterKeepsSessionOpenWithinOverdraft() {
+	_, err := s.Service.SetOverdraftLimit(context.Background(), api.SetOverdraftLimitRequest{
+		OverdraftLimit: api.OverdraftLimit{Application: "cloudsim", Handle: "test1", Limit: 50},
+	})
+	s.Require().NoError(err)
+
+	session := s.openSession(10, "")
+	s.rewind(session.ID, 10*time.Minute)
+	s.Require().NoError(s.Meter.RunOnce(context.Background()))
+
+	res, err := s.Service.GetSession(context.Background(), api.GetSessionRequest{ID: session.ID})
+	s.Require().NoError(err)
+	s.Assert().Equal(api.SessionOpen, res.Status)
+	s.Assert().Equal(uint(100), res.Charged)
+
+	// The next charge would go below the overdraft limit, so the session is closed without charging it.
+	s.rewind(session.ID, 10*time.Minute)
+	s.Require().NoError(s.Meter.RunOnce(context.Background()))
+
+	res, err = s.Service.GetSession(context.Background(), api.GetSessionRequest{ID: session.ID})
+	s.Require().NoError(err)
+	s.Assert().Equal(api.SessionClosed, res.Status)
+	s.Assert().Equal(api.CloseReasonInsufficientCredits, res.CloseReason)
+	s.Assert().Equal(uint(100), res.Charged)
+
+	c, err := persistence.GetCustomer(s.DB, "test1", "cloudsim")
+	s.Require().NoError(err)
+	s.Assert().Equal(0, c.Credits)
+}
+
+func (s *testSessionsSuite) TestMeterRespectsSpendingLimits() {
+	_, err := s.Service.SetSpendingLimits(context.Background(), api.SetSpendingLimitsRequest{
+		SpendingLimits: api.SpendingLimits{Application: "cloudsim", Handle: "test1", CreditsPerHour: 50},
+	})
+	s.Require().NoError(err)
+
+	session := s.openSession(10, "")
+	s.rewind(session.ID, 10*time.Minute)
+	s.Require().NoError(s.Meter.RunOnce(context.Background()))
+
+	res, err := s.Service.GetSession(context.Background(), api.GetSessionRequest{ID: session.ID})
+	s.Require().NoError(err)
+	s.Assert().Equal(api.SessionClosed, res.Status)
+	s.Assert().Equal(api.CloseReasonInsufficientCredits, res.CloseReason)
+	s.Assert().Zero(res.Charged)
+
+	c, err := persistence.GetCustomer(s.DB, "test1", "cloudsim")
+	s.Require().NoError(err)
+	s.Assert().Equal(100, c.Credits)
+}
+
+func (s *testSessionsSuite) TestMeterClosesSessionOnOverflow() {
+	session := s.openSession(math.MaxUint64, "")
+	s.rewind(session.ID, 10*time.Minute)
+	s.Require().NoError(s.Meter.RunOnce(context.Background()))
+
+	res, err := s.Service.GetSession(context.Background(), api.GetSessionRequest{ID: session.ID})
+	s.Require().NoError(err)
+	s.Assert().Equal(api.SessionClosed, res.Status)
+	s.Assert().Equal(api.CloseReasonInsufficientCredits, res.CloseReason)
+	s.Assert().Zero(res.Charged)
+}
+
+func (s *testSessionsSuite) TestMeterClosesSessionOfFrozenCustomer() {
+	session := s.openSession(1, "")
+	s.rewind(session.ID, 10*time.Minute)
+
+	_, err := s.Service.SetCustomerStatus(context.Background(), api.SetCustomerStatusRequest{
+		Handle:      "test1",
+		Application: "cloudsim",
+		Status:      api.CustomerFrozen,
+		Reason:      "fraud review",
+	})
+	s.Require().NoError(err)
+
+	s.Require().NoError(s.Meter.RunOnce(context.Background()))
+
+	res, err := s.Service.GetSession(context.Background(), api.GetSessionRequest{ID: session.ID})
+	s.Require().NoError(err)
+	s.Assert().Equal(api.SessionClosed, res.Status)
+	s.Assert().Equal(api.CloseReasonCustomerInactive, res.CloseReason)
+	s.Assert().Zero(res.Charged)
+
+	c, err := persistence.GetCustomer(s.DB, "test1", "cloudsim")
+	s.Require().NoError(err)
+	s.Assert().Equal(100, c.Credits)
+}
+
+func (s *testSessionsSuite) TestMeterClosesSessionOnHeartbeatTimeout() {
+	session := s.openSession(1, "")
+	s.rewind(session.ID, 2*time.Hour)
+
+	s.Require().NoError(s.Meter.RunOnce(context.Background()))
+
+	res, err := s.Service.GetSession(context.Background(), api.GetSessionRequest{ID: session.ID})
+	s.Require().NoError(err)
+	s.Assert().Equal(api.SessionClosed, res.Status)
+	s.Assert().Equal(api.CloseReasonHeartbeatTimeout, res.CloseReason)
+	s.Assert().Equal(uint(0), res.Charged)
+}
+
+func (s *testSessionsSuite) TestGetSessionNotFound() {
+	_, err := s.Service.GetSession(context.Background(), api.GetSessionRequest{ID: 1234})
+	s.Assert().Equal(api.ErrSessionNotFound, err)
+}
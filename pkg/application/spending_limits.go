@@ -0,0 +1,170 @@
+package application
+
+import (
+	"context"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/api"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/domain/models"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/domain/persistence"
+	"gorm.io/gorm"
+	"time"
+)
+
+// spendingCaps contains the spending caps in the order they're checked and reported.
+var spendingCaps = []api.SpendingCap{api.CapCreditsPerHour, api.CapCreditsPerDay, api.CapDecreasesPerMinute}
+
+// SetSpendingLimits sets the spending limits of an application, or of a single customer if a handle is given.
+func (s *service) SetSpendingLimits(ctx context.Context, req api.SetSpendingLimitsRequest) (api.SetSpendingLimitsResponse, error) {
+	if err := req.Validate(); err != nil {
+		return api.SetSpendingLimitsResponse{}, err
+	}
+
+	var limit models.SpendingLimit
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := shareConversionPolicy(tx, req.Application); err != nil {
+			return err
+		}
+		var err error
+		limit, err = persistence.SetSpendingLimit(tx, models.SpendingLimit{
+			Application:        req.Application,
+			Handle:             req.Handle,
+			CreditsPerHour:     req.CreditsPerHour,
+			CreditsPerDay:      req.CreditsPerDay,
+			DecreasesPerMinute: req.DecreasesPerMinute,
+		})
+		return err
+	})
+	if err != nil {
+		return api.SetSpendingLimitsResponse{}, err
+	}
+
+	return api.SetSpendingLimitsResponse{SpendingLimits: toSpendingLimitsAPI(limit)}, nil
+}
+
+// GetSpendingLimits returns the spending limits that apply to an application or to a single customer.
+func (s *service) GetSpendingLimits(ctx context.Context, req api.GetSpendingLimitsRequest) (api.GetSpendingLimitsResponse, error) {
+	if len(req.Application) == 0 {
+		return api.GetSpendingLimitsResponse{}, api.ErrMissingApplication
+	}
+
+	limit, err := persistence.GetEffectiveSpendingLimit(s.db, req.Application, req.Handle)
+	if err == gorm.ErrRecordNotFound {
+		return api.GetSpendingLimitsResponse{}, api.ErrSpendingLimitsNotFound
+	}
+	if err != nil {
+		return api.GetSpendingLimitsResponse{}, err
+	}
+
+	return api.GetSpendingLimitsResponse{SpendingLimits: toSpendingLimitsAPI(limit)}, nil
+}
+
+// DeleteSpendingLimits removes the spending limits of an application, or of a single customer if a handle is given.
+// Customers without their own limits fall back to the application limits.
+func (s *service) DeleteSpendingLimits(ctx context.Context, req api.DeleteSpendingLimitsRequest) (api.DeleteSpendingLimitsResponse, error) {
+	if len(req.Application) == 0 {
+		return api.DeleteSpendingLimitsResponse{}, api.ErrMissingApplication
+	}
+
+	limit, err := persistence.GetSpendingLimit(s.db, req.Application, req.Handle)
+	if err == gorm.ErrRecordNotFound {
+		return api.DeleteSpendingLimitsResponse{}, api.ErrSpendingLimitsNotFound
+	}
+	if err != nil {
+		return api.DeleteSpendingLimitsResponse{}, err
+	}
+
+	if err = persistence.DeleteSpendingLimit(s.db, limit); err != nil {
+		return api.DeleteSpendingLimitsResponse{}, err
+	}
+	return api.DeleteSpendingLimitsResponse{}, nil
+}
+
+// GetSpendingUsage returns the current usage of a customer against each of the spending caps that apply to it.
+func (s *service) GetSpendingUsage(ctx context.Context, req api.GetSpendingUsageRequest) (api.GetSpendingUsageResponse, error) {
+	if err := req.Validate(); err != nil {
+		return api.GetSpendingUsageResponse{}, err
+	}
+
+	usage, err := getSpendingUsage(s.db, req.Handle, req.Application, time.Now())
+	if err != nil {
+		return api.GetSpendingUsageResponse{}, err
+	}
+
+	out := api.GetSpendingUsageResponse{Usage: usage}
+	if out.Usage == nil {
+		out.Usage = []api.SpendingUsage{}
+	}
+	return out, nil
+}
+
+// spendingOperations are the operations that spend credits. Every debit recorded with them counts toward the spending
+// caps. Refunds, chargebacks, reversals and expirations take back credits instead of spending them, so they don't.
+var spendingOperations = []string{
+	models.OperationDecrease,
+	models.OperationCharge,
+	models.OperationSession,
+	models.OperationTransfer,
+}
+
+// getSpendingUsage returns the usage of a customer against each of the enabled spending caps that apply to it at
+// the given time. The usage includes every debit made with one of the spendingOperations.
+func getSpendingUsage(db *gorm.DB, handle, application string, at time.Time) ([]api.SpendingUsage, error) {
+	limit, err := persistence.GetEffectiveSpendingLimit(db, application, handle)
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	caps := toSpendingLimitsAPI(limit).Caps()
+
+	var out []api.SpendingUsage
+	for _, c := range spendingCaps {
+		max, ok := caps[c]
+		if !ok {
+			continue
+		}
+		credits, count, err := persistence.SumDebitsSince(db, handle, application, spendingOperations, at.Add(-c.Window()))
+		if err != nil {
+			return nil, err
+		}
+		used := credits
+		if c == api.CapDecreasesPerMinute {
+			used = count
+		}
+		out = append(out, api.SpendingUsage{Cap: c, Limit: max, Used: used})
+	}
+	return out, nil
+}
+
+// checkSpendingLimits checks that decreasing value credits from a customer doesn't exceed any of its spending caps.
+// The customer must have been locked in the same transaction that removes the credits, so concurrent decreases are
+// checked one at a time.
+func checkSpendingLimits(tx *gorm.DB, handle, application string, value uint) error {
+	usage, err := getSpendingUsage(tx, handle, application, time.Now())
+	if err != nil {
+		return err
+	}
+
+	for _, u := range usage {
+		next := u.Used + value
+		if u.Cap == api.CapDecreasesPerMinute {
+			next = u.Used + 1
+		}
+		if next > u.Limit {
+			return &api.SpendingLimitError{SpendingUsage: u}
+		}
+	}
+	return nil
+}
+
+// toSpendingLimitsAPI converts the given spending limit model into its API representation.
+func toSpendingLimitsAPI(limit models.SpendingLimit) api.SpendingLimits {
+	return api.SpendingLimits{
+		Application:        limit.Application,
+		Handle:             limit.Handle,
+		CreditsPerHour:     limit.CreditsPerHour,
+		CreditsPerDay:      limit.CreditsPerDay,
+		DecreasesPerMinute: limit.DecreasesPerMinute,
+	}
+}
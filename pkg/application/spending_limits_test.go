@@ -0,0 +1,189 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/suite"
+	"gitlab.com/ignitionrobotics/billing/credits/internal/conf"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/api"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/domain/models"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/domain/persistence"
+	"gorm.io/gorm"
+	"log"
+	"os"
+	"testing"
+	"time"
+)
+
+type testSpendingLimitsSuite struct {
+	suite.Suite
+	DB      *gorm.DB
+	Logger  *log.Logger
+	Service Service
+}
+
+func TestSpendingLimits(t *testing.T) {
+	suite.Run(t, new(testSpendingLimitsSuite))
+}
+
+func (s *testSpendingLimitsSuite) SetupSuite() {
+	s.Logger = log.New(os.Stdout, "[TestSpendingLimits] ", log.LstdFlags|log.Lshortfile|log.Lmsgprefix)
+
+	var c conf.Config
+	s.Require().NoError(c.Parse())
+
+	var err error
+	s.DB, err = persistence.OpenConn(c.Database)
+	s.Require().NoError(err)
+
+	s.Require().NoError(persistence.DropTables(s.DB))
+}
+
+func (s *testSpendingLimitsSuite) SetupTest() {
+	s.Require().NoError(persistence.MigrateTables(s.DB))
+	s.Service = NewCreditsService(s.DB, s.Logger, 1)
+
+	for _, handle := range []string{"test1", "test2"} {
+		_, err := persistence.CreateCustomer(s.DB, models.Customer{
+			Handle:      handle,
+			Application: "cloudsim",
+			Credits:     1000,
+		})
+		s.Require().NoError(err)
+	}
+}
+
+func (s *testSpendingLimitsSuite) TearDownTest() {
+	s.Require().NoError(persistence.DropTables(s.DB))
+}
+
+func (s *testSpendingLimitsSuite) decrease(handle string, amount uint) error {
+	_, err := s.Service.DecreaseCredits(context.Background(), api.DecreaseCreditsRequest{
+		Transaction: api.Transaction{
+			Handle:      handle,
+			Amount:      amount,
+			Currency:    "usd",
+			Application: "cloudsim",
+		},
+	})
+	return err
+}
+
+func (s *testSpendingLimitsSuite) setLimits(limits api.SpendingLimits) {
+	limits.Application = "cloudsim"
+	_, err := s.Service.SetSpendingLimits(context.Background(), api.SetSpendingLimitsRequest{SpendingLimits: limits})
+	s.Require().NoError(err)
+}
+
+func (s *testSpendingLimitsSuite) TestCreditsPerHour() {
+	s.setLimits(api.SpendingLimits{CreditsPerHour: 100})
+
+	s.Require().NoError(s.decrease("test1", 60))
+	s.Require().NoError(s.decrease("test1", 40))
+
+	err := s.decrease("test1", 1)
+	s.Require().True(errors.Is(err, api.ErrSpendingLimitExceeded))
+	var limitErr *api.SpendingLimitError
+	s.Require().True(errors.As(err, &limitErr))
+	s.Assert().Equal(api.CapCreditsPerHour, limitErr.Cap)
+	s.Assert().Equal(uint(100), limitErr.Used)
+
+	// Decreases older than the window don't count
+	s.Require().NoError(s.DB.Model(&models.BalanceChange{}).
+		Where("handle = ? AND operation = ?", "test1", models.OperationDecrease).
+		Update("created_at", time.Now().Add(-2*time.Hour)).Error)
+	s.Assert().NoError(s.decrease("test1", 100))
+}
+
+func (s *testSpendingLimitsSuite) TestDecreasesPerMinute() {
+	s.setLimits(api.SpendingLimits{DecreasesPerMinute: 3})
+
+	for i := 0; i < 3; i++ {
+		s.Require().NoError(s.decrease("test1", 1))
+	}
+	s.Assert().True(errors.Is(s.decrease("test1", 1), api.ErrSpendingLimitExceeded))
+
+	// Limits are tracked per customer
+	s.Assert().NoError(s.decrease("test2", 1))
+}
+
+func (s *testSpendingLimitsSuite) TestCustomerOverrideAndUsage() {
+	s.setLimits(api.SpendingLimits{CreditsPerDay: 50})
+	s.setLimits(api.SpendingLimits{Handle: "test2", CreditsPerHour: 500, DecreasesPerMinute: 10})
+
+	s.Require().NoError(s.decrease("test2", 200))
+	s.Assert().True(errors.Is(s.decrease("test1", 60), api.ErrSpendingLimitExceeded))
+
+	usage, err := s.Service.GetSpendingUsage(context.Background(), api.GetSpendingUsageRequest{
+		Handle:      "test2",
+		Application: "cloudsim",
+	})
+	s.Require().NoError(err)
+	s.Assert().Equal([]api.SpendingUsage{
+		{Cap: api.CapCreditsPerHour, Limit: 500, Used: 200},
+		{Cap: api.CapDecreasesPerMinute, Limit: 10, Used: 1},
+	}, usage.Usage)
+
+	_, err = s.Service.DeleteSpendingLimits(context.Background(), api.DeleteSpendingLimitsRequest{Application: "cloudsim"})
+	s.Require().NoError(err)
+
+	_, err = s.Service.GetSpendingLimits(context.Background(), api.GetSpendingLimitsRequest{
+		Application: "cloudsim",
+		Handle:      "test1",
+	})
+	s.Assert().Equal(api.ErrSpendingLimitsNotFound, err)
+
+	s.Assert().NoError(s.decrease("test1", 60))
+}
+
+func (s *testSpendingLimitsSuite) TestBatchChecksSpendingLimits() {
+	s.setLimits(api.SpendingLimits{CreditsPerHour: 100})
+
+	batch := func(t api.OperationType, amount uint, recipient string) error {
+		_, err := s.Service.ExecuteBatch(context.Background(), api.ExecuteBatchRequest{
+			Operations: []api.BatchOperation{{
+				Type:        t,
+				Transaction: api.Transaction{Handle: "test1", Amount: amount, Currency: "usd", Application: "cloudsim"},
+				Recipient:   recipient,
+			}},
+		})
+		return err
+	}
+
+	s.Assert().True(errors.Is(batch(api.OperationDecrease, 101, ""), api.ErrSpendingLimitExceeded))
+	s.Require().NoError(batch(api.OperationTransfer, 60, "test2"))
+	s.Assert().True(errors.Is(batch(api.OperationTransfer, 41, "test2"), api.ErrSpendingLimitExceeded))
+	s.Assert().True(errors.Is(s.decrease("test1", 41), api.ErrSpendingLimitExceeded))
+
+	// Only the sender spends credits in a transfer.
+	s.Assert().NoError(s.decrease("test2", 100))
+}
+
+func (s *testSpendingLimitsSuite) TestUsageIncludesEveryDebit() {
+	s.setLimits(api.SpendingLimits{CreditsPerHour: 500, DecreasesPerMinute: 10})
+
+	_, err := s.Service.CreateSKU(context.Background(), api.CreateSKURequest{
+		SKUIdentifier: api.SKUIdentifier{Application: "cloudsim", Code: "gpu"},
+		Unit:          "hour",
+		Price:         5,
+	})
+	s.Require().NoError(err)
+	_, err = s.Service.Charge(context.Background(), api.ChargeRequest{
+		Handle:      "test1",
+		Application: "cloudsim",
+		SKU:         "gpu",
+		Quantity:    4,
+	})
+	s.Require().NoError(err)
+	s.Require().NoError(s.decrease("test1", 30))
+
+	usage, err := s.Service.GetSpendingUsage(context.Background(), api.GetSpendingUsageRequest{
+		Handle:      "test1",
+		Application: "cloudsim",
+	})
+	s.Require().NoError(err)
+	s.Assert().Equal([]api.SpendingUsage{
+		{Cap: api.CapCreditsPerHour, Limit: 500, Used: 50},
+		{Cap: api.CapDecreasesPerMinute, Limit: 10, Used: 2},
+	}, usage.Usage)
+}
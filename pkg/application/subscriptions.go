@@ -0,0 +1,329 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/api"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/domain/models"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/domain/persistence"
+	"gorm.io/gorm"
+	"io"
+	"log"
+	"time"
+)
+
+// CreateSubscription creates a new subscription. The first period starts at the given start time, and it's granted
+// by the subscription scheduler.
+func (s *service) CreateSubscription(ctx context.Context, req api.CreateSubscriptionRequest) (api.CreateSubscriptionResponse, error) {
+	if err := req.Validate(); err != nil {
+		return api.CreateSubscriptionResponse{}, err
+	}
+
+	c, err := persistence.GetCustomer(s.db, req.Handle, req.Application)
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return api.CreateSubscriptionResponse{}, err
+	}
+	if err == nil {
+		if err = checkCustomerStatus(c); err != nil {
+			return api.CreateSubscriptionResponse{}, err
+		}
+	}
+
+	start := time.Now().UTC()
+	if req.StartAt != nil {
+		start = req.StartAt.UTC()
+	}
+
+	var subscription models.Subscription
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := shareConversionPolicy(tx, req.Application); err != nil {
+			return err
+		}
+		var err error
+		subscription, err = persistence.CreateSubscription(tx, models.Subscription{
+			Handle:      req.Handle,
+			Application: req.Application,
+			Credits:     req.Credits,
+			Schedule:    string(req.Schedule),
+			Rollover:    string(req.Rollover),
+			RolloverCap: req.RolloverCap,
+			Status:      string(api.SubscriptionActive),
+			StartAt:     start,
+			NextGrantAt: start,
+		})
+		return err
+	})
+	if err != nil {
+		return api.CreateSubscriptionResponse{}, err
+	}
+
+	return api.CreateSubscriptionResponse{Subscription: toSubscriptionAPI(subscription)}, nil
+}
+
+// CancelSubscription cancels a subscription. The credits already granted are kept by the customer.
+func (s *service) CancelSubscription(ctx context.Context, req api.CancelSubscriptionRequest) (api.CancelSubscriptionResponse, error) {
+	var out api.CancelSubscriptionResponse
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		subscription, err := persistence.GetSubscriptionForUpdate(tx, req.ID)
+		if err == gorm.ErrRecordNotFound {
+			return api.ErrSubscriptionNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if subscription.Status == string(api.SubscriptionCanceled) {
+			return api.ErrSubscriptionCanceled
+		}
+
+		subscription, err = persistence.SetSubscriptionStatus(tx, subscription, string(api.SubscriptionCanceled))
+		if err != nil {
+			return err
+		}
+		out.Subscription = toSubscriptionAPI(subscription)
+		return nil
+	})
+	if err != nil {
+		return api.CancelSubscriptionResponse{}, err
+	}
+	return out, nil
+}
+
+// ListSubscriptions returns the subscriptions of a customer.
+func (s *service) ListSubscriptions(ctx context.Context, req api.ListSubscriptionsRequest) (api.ListSubscriptionsResponse, error) {
+	if len(req.Handle) == 0 {
+		return api.ListSubscriptionsResponse{}, api.ErrHandleNotProvided
+	}
+	if len(req.Application) == 0 {
+		return api.ListSubscriptionsResponse{}, api.ErrMissingApplication
+	}
+
+	list, err := persistence.GetSubscriptions(s.db, req.Handle, req.Application)
+	if err != nil {
+		return api.ListSubscriptionsResponse{}, err
+	}
+
+	out := api.ListSubscriptionsResponse{Subscriptions: make([]api.Subscription, len(list))}
+	for i, subscription := range list {
+		out.Subscriptions[i] = toSubscriptionAPI(subscription)
+	}
+	return out, nil
+}
+
+// ListSubscriptionGrants returns the latest grants of a subscription.
+func (s *service) ListSubscriptionGrants(ctx context.Context, req api.ListSubscriptionGrantsRequest) (api.ListSubscriptionGrantsResponse, error) {
+	if err := req.Validate(); err != nil {
+		return api.ListSubscriptionGrantsResponse{}, err
+	}
+
+	_, err := persistence.GetSubscription(s.db, req.ID)
+	if err == gorm.ErrRecordNotFound {
+		return api.ListSubscriptionGrantsResponse{}, api.ErrSubscriptionNotFound
+	}
+	if err != nil {
+		return api.ListSubscriptionGrantsResponse{}, err
+	}
+
+	limit := req.Limit
+	if limit == 0 {
+		limit = api.DefaultPageSize
+	}
+
+	list, err := persistence.GetSubscriptionGrants(s.db, req.ID, limit)
+	if err != nil {
+		return api.ListSubscriptionGrantsResponse{}, err
+	}
+
+	out := api.ListSubscriptionGrantsResponse{Grants: make([]api.SubscriptionGrant, len(list))}
+	for i, grant := range list {
+		out.Grants[i] = toSubscriptionGrantAPI(grant)
+	}
+	return out, nil
+}
+
+// rolloverCredits returns how many of the credits granted in the previous period are carried over to the next one,
+// and how many of them expire, given the credits the customer spent since they were granted.
+func rolloverCredits(rule api.RolloverRule, rolloverCap, allowance, spent uint, balance int) (carried, expired uint) {
+	unused := unusedCredits(allowance, spent, balance)
+	if rule == api.RolloverCapped {
+		carried = unused
+		if carried > rolloverCap {
+			carried = rolloverCap
+		}
+	}
+	return carried, unused - carried
+}
+
+// grantSpendingOperations are the operations that spend granted credits. Unlike spendingOperations, transfers are
+// not included, and neither are refunds, chargebacks or the expiration of other grants, since they move or take back
+// credits instead of using them.
+var grantSpendingOperations = []string{
+	models.OperationDecrease,
+	models.OperationCharge,
+	models.OperationSession,
+}
+
+// unusedCredits returns how many of the granted credits are still unused. Granted credits are spent before any other
+// credits of the customer, and no more credits than the current balance can be unused. Only debits made by
+// grantSpendingOperations count as spent.
+func unusedCredits(granted, spent uint, balance int) uint {
+	if spent >= granted {
+		return 0
+	}
+	unused := granted - spent
+	if balance < int(unused) {
+		if balance <= 0 {
+			return 0
+		}
+		return uint(balance)
+	}
+	return unused
+}
+
+// toSubscriptionAPI converts the given subscription model into its API representation.
+func toSubscriptionAPI(subscription models.Subscription) api.Subscription {
+	return api.Subscription{
+		ID:          subscription.ID,
+		Handle:      subscription.Handle,
+		Application: subscription.Application,
+		Credits:     subscription.Credits,
+		Schedule:    api.SubscriptionSchedule(subscription.Schedule),
+		Rollover:    api.RolloverRule(subscription.Rollover),
+		RolloverCap: subscription.RolloverCap,
+		Status:      api.SubscriptionStatus(subscription.Status),
+		StartAt:     subscription.StartAt,
+		Periods:     subscription.Periods,
+		NextGrantAt: subscription.NextGrantAt,
+		Allowance:   subscription.Allowance,
+	}
+}
+
+// toSubscriptionGrantAPI converts the given subscription grant model into its API representation.
+func toSubscriptionGrantAPI(grant models.SubscriptionGrant) api.SubscriptionGrant {
+	return api.SubscriptionGrant{
+		Period:      grant.Period,
+		PeriodStart: grant.PeriodStart,
+		Granted:     grant.Granted,
+		Carried:     grant.Carried,
+		Expired:     grant.Expired,
+		CreatedAt:   grant.CreatedAt,
+	}
+}
+
+// subscriptionBatchSize is the maximum amount of subscriptions granted on every run of the scheduler.
+const subscriptionBatchSize = 100
+
+// errSubscriptionSkipped is returned when a subscription has no period to grant.
+var errSubscriptionSkipped = errors.New("subscription skipped")
+
+// subscriptionScheduler is a Worker that grants the periods of the subscriptions that are due.
+type subscriptionScheduler struct {
+	db     *gorm.DB
+	logger *log.Logger
+}
+
+// Run grants the due subscriptions every interval until ctx is done.
+func (w *subscriptionScheduler) Run(ctx context.Context, interval time.Duration) {
+	runPeriodically(ctx, interval, w.logger, "Subscription scheduler", w.RunOnce)
+}
+
+// RunOnce grants every period of the subscriptions that are due. Periods missed while the scheduler wasn't running
+// are granted one by one, in order.
+func (w *subscriptionScheduler) RunOnce(ctx context.Context) error {
+	now := time.Now().UTC()
+	due, err := persistence.GetDueSubscriptions(w.db, string(api.SubscriptionActive), now, subscriptionBatchSize)
+	if err != nil {
+		return err
+	}
+
+	for _, subscription := range due {
+		for {
+			err = w.grant(subscription.ID, now)
+			if err == errSubscriptionSkipped {
+				break
+			}
+			if err != nil {
+				w.logger.Println("Failed to grant subscription:", subscription.ID, "Error:", err)
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// grant grants the next period of the given subscription if it started at or before now. The subscription is locked
+// and its period is advanced in the same transaction that grants the credits, and grants are unique per period, so
+// a period is never granted twice, even by concurrent schedulers. Customers that can't receive credits skip the
+// period.
+func (w *subscriptionScheduler) grant(id uint, now time.Time) error {
+	return w.db.Transaction(func(tx *gorm.DB) error {
+		subscription, err := persistence.GetSubscriptionForUpdate(tx, id)
+		if err != nil {
+			return err
+		}
+		if subscription.Status != string(api.SubscriptionActive) || subscription.NextGrantAt.After(now) {
+			return errSubscriptionSkipped
+		}
+
+		c, err := lockCustomer(tx, subscription.Handle, subscription.Application)
+		if err != nil {
+			return err
+		}
+
+		schedule := api.SubscriptionSchedule(subscription.Schedule)
+		grant := models.SubscriptionGrant{
+			SubscriptionID: subscription.ID,
+			Period:         subscription.Periods,
+			PeriodStart:    schedule.PeriodStart(subscription.StartAt, subscription.Periods),
+		}
+
+		if checkCustomerStatus(c) == nil {
+			var spent uint
+			if subscription.TransactionID != nil {
+				spent, err = persistence.SumDebitsAfter(tx, c.Handle, c.Application, grantSpendingOperations, *subscription.TransactionID)
+				if err != nil {
+					return err
+				}
+			}
+
+			rule := api.RolloverRule(subscription.Rollover)
+			carried, expired := rolloverCredits(rule, subscription.RolloverCap, subscription.Allowance, spent, c.Credits)
+			if expired > 0 {
+				_, err = updateCredits(tx, c.Handle, c.Application, -int(expired), models.OperationExpiration)
+				if err != nil {
+					return err
+				}
+			}
+
+			change, err := updateCredits(tx, c.Handle, c.Application, int(subscription.Credits), models.OperationAllowance)
+			if err != nil {
+				return err
+			}
+
+			grant.Granted = subscription.Credits
+			grant.Carried = carried
+			grant.Expired = expired
+			grant.TransactionID = &change.ID
+			subscription.Allowance = carried + subscription.Credits
+			subscription.TransactionID = &change.ID
+		}
+
+		if _, err = persistence.CreateSubscriptionGrant(tx, grant); err != nil {
+			return err
+		}
+
+		subscription.Periods++
+		subscription.NextGrantAt = schedule.PeriodStart(subscription.StartAt, subscription.Periods)
+		return persistence.AdvanceSubscription(tx, subscription)
+	})
+}
+
+// NewSubscriptionScheduler initializes a new Worker that grants the credits of the subscriptions.
+func NewSubscriptionScheduler(db *gorm.DB, logger *log.Logger) Worker {
+	if logger == nil {
+		logger = log.New(io.Discard, "", log.LstdFlags)
+	}
+	return &subscriptionScheduler{
+		db:     db,
+		logger: logger,
+	}
+}
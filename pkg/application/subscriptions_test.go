@@ -0,0 +1,259 @@
+package application
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"gitlab.com/ignitionrobotics/billing/credits/internal/conf"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/api"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/domain/models"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/domain/persistence"
+	"gorm.io/gorm"
+	"log"
+	"os"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRolloverCredits(t *testing.T) {
+	carried, expired := rolloverCredits(api.RolloverReset, 0, 50, 0, 100)
+	assert.Equal(t, uint(0), carried)
+	assert.Equal(t, uint(50), expired)
+
+	carried, expired = rolloverCredits(api.RolloverCapped, 30, 50, 0, 100)
+	assert.Equal(t, uint(30), carried)
+	assert.Equal(t, uint(20), expired)
+
+	// Subscription credits are spent first
+	carried, expired = rolloverCredits(api.RolloverCapped, 30, 50, 40, 100)
+	assert.Equal(t, uint(10), carried)
+	assert.Equal(t, uint(0), expired)
+
+	carried, expired = rolloverCredits(api.RolloverReset, 0, 50, 80, 100)
+	assert.Equal(t, uint(0), carried)
+	assert.Equal(t, uint(0), expired)
+}
+
+func TestUnusedCredits(t *testing.T) {
+	assert.Equal(t, uint(50), unusedCredits(50, 0, 120))
+	assert.Equal(t, uint(30), unusedCredits(50, 20, 120))
+	assert.Equal(t, uint(10), unusedCredits(50, 20, 10))
+	assert.Equal(t, uint(0), unusedCredits(50, 20, -10))
+	assert.Equal(t, uint(0), unusedCredits(50, 70, 100))
+}
+
+type testSubscriptionsSuite struct {
+	suite.Suite
+	DB      *gorm.DB
+	Logger  *log.Logger
+	Service Service
+}
+
+func TestSubscriptions(t *testing.T) {
+	suite.Run(t, new(testSubscriptionsSuite))
+}
+
+func (s *testSubscriptionsSuite) SetupSuite() {
+	s.Logger = log.New(os.Stdout, "[TestSubscriptions] ", log.LstdFlags|log.Lshortfile|log.Lmsgprefix)
+
+	var c conf.Config
+	s.Require().NoError(c.Parse())
+
+	var err error
+	s.DB, err = persistence.OpenConn(c.Database)
+	s.Require().NoError(err)
+
+	s.Require().NoError(persistence.DropTables(s.DB))
+}
+
+func (s *testSubscriptionsSuite) SetupTest() {
+	s.Require().NoError(persistence.MigrateTables(s.DB))
+	s.Service = NewCreditsService(s.DB, s.Logger, 1)
+
+	_, err := persistence.CreateCustomer(s.DB, models.Customer{
+		Handle:      "test1",
+		Application: "cloudsim",
+		Credits:     100,
+	})
+	s.Require().NoError(err)
+}
+
+func (s *testSubscriptionsSuite) TearDownTest() {
+	s.Require().NoError(persistence.DropTables(s.DB))
+}
+
+func (s *testSubscriptionsSuite) create(rollover api.RolloverRule, rolloverCap uint, start time.Time) api.Subscription {
+	res, err := s.Service.CreateSubscription(context.Background(), api.CreateSubscriptionRequest{
+		Handle:      "test1",
+		Application: "cloudsim",
+		Credits:     50,
+		Schedule:    api.ScheduleMonthly,
+		Rollover:    rollover,
+		RolloverCap: rolloverCap,
+		StartAt:     &start,
+	})
+	s.Require().NoError(err)
+	return res.Subscription
+}
+
+func (s *testSubscriptionsSuite) balance() int {
+	c, err := persistence.GetCustomer(s.DB, "test1", "cloudsim")
+	s.Require().NoError(err)
+	return c.Credits
+}
+
+func (s *testSubscriptionsSuite) TestGrantOncePerPeriod() {
+	sub := s.create(api.RolloverReset, 0, time.Now().Add(-time.Minute))
+
+	scheduler := NewSubscriptionScheduler(s.DB, s.Logger)
+	s.Require().NoError(scheduler.RunOnce(context.Background()))
+	s.Require().NoError(scheduler.RunOnce(context.Background()))
+	s.Assert().Equal(150, s.balance())
+
+	grants, err := s.Service.ListSubscriptionGrants(context.Background(), api.ListSubscriptionGrantsRequest{ID: sub.ID})
+	s.Require().NoError(err)
+	s.Require().Len(grants.Grants, 1)
+	s.Assert().Equal(uint(50), grants.Grants[0].Granted)
+
+	list, err := s.Service.ListSubscriptions(context.Background(), api.ListSubscriptionsRequest{Handle: "test1", Application: "cloudsim"})
+	s.Require().NoError(err)
+	s.Require().Len(list.Subscriptions, 1)
+	s.Assert().Equal(uint(1), list.Subscriptions[0].Periods)
+	s.Assert().Equal(uint(50), list.Subscriptions[0].Allowance)
+	s.Assert().True(list.Subscriptions[0].NextGrantAt.After(time.Now()))
+}
+
+func (s *testSubscriptionsSuite) TestCatchUpWithReset() {
+	sub := s.create(api.RolloverReset, 0, time.Now().AddDate(0, -2, 0).Add(-time.Minute))
+
+	s.Require().NoError(NewSubscriptionScheduler(s.DB, s.Logger).RunOnce(context.Background()))
+
+	// The unused allowance of every period expires before granting the next one
+	s.Assert().Equal(150, s.balance())
+
+	grants, err := s.Service.ListSubscriptionGrants(context.Background(), api.ListSubscriptionGrantsRequest{ID: sub.ID})
+	s.Require().NoError(err)
+	s.Require().Len(grants.Grants, 3)
+	s.Assert().Equal(uint(2), grants.Grants[0].Period)
+	s.Assert().Equal(uint(50), grants.Grants[0].Expired)
+	s.Assert().Zero(grants.Grants[2].Expired)
+}
+
+func (s *testSubscriptionsSuite) TestCatchUpWithRollover() {
+	s.create(api.RolloverCapped, 30, time.Now().AddDate(0, -2, 0).Add(-time.Minute))
+
+	s.Require().NoError(NewSubscriptionScheduler(s.DB, s.Logger).RunOnce(context.Background()))
+
+	// 100 + 50 on the first period. The next periods carry 30 credits over, expiring 20 and then 50 of them.
+	s.Assert().Equal(180, s.balance())
+}
+
+func (s *testSubscriptionsSuite) TestConcurrentSchedulers() {
+	sub := s.create(api.RolloverReset, 0, time.Now().AddDate(0, -2, 0).Add(-time.Minute))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.Assert().NoError(NewSubscriptionScheduler(s.DB, s.Logger).RunOnce(context.Background()))
+		}()
+	}
+	wg.Wait()
+
+	grants, err := s.Service.ListSubscriptionGrants(context.Background(), api.ListSubscriptionGrantsRequest{ID: sub.ID})
+	s.Require().NoError(err)
+	s.Assert().Len(grants.Grants, 3)
+	s.Assert().Equal(150, s.balance())
+}
+
+func (s *testSubscriptionsSuite) TestSpentAllowanceDoesntExpire() {
+	sub := s.create(api.RolloverReset, 0, time.Now().AddDate(0, -1, 0).Add(time.Minute))
+
+	scheduler := NewSubscriptionScheduler(s.DB, s.Logger)
+	s.Require().NoError(scheduler.RunOnce(context.Background()))
+	s.Assert().Equal(150, s.balance())
+
+	_, err := s.Service.DecreaseCredits(context.Background(), api.DecreaseCreditsRequest{
+		Transaction: api.Transaction{Handle: "test1", Amount: 30, Currency: "usd", Application: "cloudsim"},
+	})
+	s.Require().NoError(err)
+
+	// Start the next period
+	s.Require().NoError(s.DB.Model(&models.Subscription{}).Where("id = ?", sub.ID).
+		Update("next_grant_at", time.Now().Add(-time.Minute)).Error)
+	s.Require().NoError(scheduler.RunOnce(context.Background()))
+
+	// Only the 20 allowance credits left expire, the credits the customer had before are kept.
+	s.Assert().Equal(150, s.balance())
+
+	grants, err := s.Service.ListSubscriptionGrants(context.Background(), api.ListSubscriptionGrantsRequest{ID: sub.ID})
+	s.Require().NoError(err)
+	s.Require().Len(grants.Grants, 2)
+	s.Assert().Equal(uint(20), grants.Grants[0].Expired)
+}
+
+func (s *testSubscriptionsSuite) TestTransferredCreditsDontSpendAllowance() {
+	sub := s.create(api.RolloverReset, 0, time.Now().AddDate(0, -1, 0).Add(time.Minute))
+
+	scheduler := NewSubscriptionScheduler(s.DB, s.Logger)
+	s.Require().NoError(scheduler.RunOnce(context.Background()))
+	s.Assert().Equal(150, s.balance())
+
+	_, err := s.Service.ExecuteBatch(context.Background(), api.ExecuteBatchRequest{
+		Operations: []api.BatchOperation{{
+			Type:        api.OperationTransfer,
+			Transaction: api.Transaction{Handle: "test1", Amount: 30, Currency: "usd", Application: "cloudsim"},
+			Recipient:   "test2",
+		}},
+	})
+	s.Require().NoError(err)
+
+	// Start the next period
+	s.Require().NoError(s.DB.Model(&models.Subscription{}).Where("id = ?", sub.ID).
+		Update("next_grant_at", time.Now().Add(-time.Minute)).Error)
+	s.Require().NoError(scheduler.RunOnce(context.Background()))
+
+	// Transfers move credits instead of spending them, so the whole allowance expires.
+	s.Assert().Equal(120, s.balance())
+
+	grants, err := s.Service.ListSubscriptionGrants(context.Background(), api.ListSubscriptionGrantsRequest{ID: sub.ID})
+	s.Require().NoError(err)
+	s.Require().Len(grants.Grants, 2)
+	s.Assert().Equal(uint(50), grants.Grants[0].Expired)
+}
+
+func (s *testSubscriptionsSuite) TestCancel() {
+	sub := s.create(api.RolloverReset, 0, time.Now().Add(-time.Minute))
+
+	res, err := s.Service.CancelSubscription(context.Background(), api.CancelSubscriptionRequest{ID: sub.ID})
+	s.Require().NoError(err)
+	s.Assert().Equal(api.SubscriptionCanceled, res.Status)
+
+	_, err = s.Service.CancelSubscription(context.Background(), api.CancelSubscriptionRequest{ID: sub.ID})
+	s.Assert().Equal(api.ErrSubscriptionCanceled, err)
+
+	s.Require().NoError(NewSubscriptionScheduler(s.DB, s.Logger).RunOnce(context.Background()))
+	s.Assert().Equal(100, s.balance())
+}
+
+func (s *testSubscriptionsSuite) TestFrozenCustomerSkipsPeriod() {
+	sub := s.create(api.RolloverReset, 0, time.Now().Add(-time.Minute))
+
+	_, err := s.Service.SetCustomerStatus(context.Background(), api.SetCustomerStatusRequest{
+		Handle:      "test1",
+		Application: "cloudsim",
+		Status:      api.CustomerFrozen,
+		Reason:      "fraud review",
+	})
+	s.Require().NoError(err)
+
+	s.Require().NoError(NewSubscriptionScheduler(s.DB, s.Logger).RunOnce(context.Background()))
+	s.Assert().Equal(100, s.balance())
+
+	grants, err := s.Service.ListSubscriptionGrants(context.Background(), api.ListSubscriptionGrantsRequest{ID: sub.ID})
+	s.Require().NoError(err)
+	s.Require().Len(grants.Grants, 1)
+	s.Assert().Zero(grants.Grants[0].Granted)
+}
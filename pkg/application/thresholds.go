@@ -0,0 +1,246 @@
+package application
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/api"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/domain/models"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/domain/persistence"
+	"gorm.io/gorm"
+	"io"
+	"log"
+	"net/http"
+	"time"
+)
+
+// SetLowBalanceThreshold sets the low balance threshold of an application, or of a single customer if a handle
+// is given.
+func (s *service) SetLowBalanceThreshold(ctx context.Context, req api.SetLowBalanceThresholdRequest) (api.SetLowBalanceThresholdResponse, error) {
+	if err := req.Validate(); err != nil {
+		return api.SetLowBalanceThresholdResponse{}, err
+	}
+
+	var threshold models.LowBalanceThreshold
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := shareConversionPolicy(tx, req.Application); err != nil {
+			return err
+		}
+		var err error
+		threshold, err = persistence.SetLowBalanceThreshold(tx, models.LowBalanceThreshold{
+			Application: req.Application,
+			Handle:      req.Handle,
+			Threshold:   req.Threshold,
+		})
+		return err
+	})
+	if err != nil {
+		return api.SetLowBalanceThresholdResponse{}, err
+	}
+
+	return api.SetLowBalanceThresholdResponse{LowBalanceThreshold: toLowBalanceThresholdAPI(threshold)}, nil
+}
+
+// GetLowBalanceThreshold returns the low balance threshold that applies to an application or to a single customer.
+func (s *service) GetLowBalanceThreshold(ctx context.Context, req api.GetLowBalanceThresholdRequest) (api.GetLowBalanceThresholdResponse, error) {
+	if len(req.Application) == 0 {
+		return api.GetLowBalanceThresholdResponse{}, api.ErrMissingApplication
+	}
+
+	threshold, err := persistence.GetEffectiveLowBalanceThreshold(s.db, req.Application, req.Handle)
+	if err == gorm.ErrRecordNotFound {
+		return api.GetLowBalanceThresholdResponse{}, api.ErrThresholdNotFound
+	}
+	if err != nil {
+		return api.GetLowBalanceThresholdResponse{}, err
+	}
+
+	return api.GetLowBalanceThresholdResponse{LowBalanceThreshold: toLowBalanceThresholdAPI(threshold)}, nil
+}
+
+// DeleteLowBalanceThreshold removes the low balance threshold of an application, or of a single customer if a
+// handle is given. Customers without their own threshold fall back to the application threshold.
+func (s *service) DeleteLowBalanceThreshold(ctx context.Context, req api.DeleteLowBalanceThresholdRequest) (api.DeleteLowBalanceThresholdResponse, error) {
+	if len(req.Application) == 0 {
+		return api.DeleteLowBalanceThresholdResponse{}, api.ErrMissingApplication
+	}
+
+	threshold, err := persistence.GetLowBalanceThreshold(s.db, req.Application, req.Handle)
+	if err == gorm.ErrRecordNotFound {
+		return api.DeleteLowBalanceThresholdResponse{}, api.ErrThresholdNotFound
+	}
+	if err != nil {
+		return api.DeleteLowBalanceThresholdResponse{}, err
+	}
+
+	if err = persistence.DeleteLowBalanceThreshold(s.db, threshold); err != nil {
+		return api.DeleteLowBalanceThresholdResponse{}, err
+	}
+	return api.DeleteLowBalanceThresholdResponse{}, nil
+}
+
+// checkLowBalance checks the given balance change against the low balance threshold of the customer. An alert is
+// created the first time a debit leaves the balance below the threshold, and the customer is re-armed once its
+// balance recovers to the threshold or above. It returns true if an alert was created. It must be called in the same
+// transaction that recorded the change.
+func checkLowBalance(tx *gorm.DB, change models.BalanceChange) (bool, error) {
+	threshold, err := persistence.GetEffectiveLowBalanceThreshold(tx, change.Application, change.Handle)
+	if err == gorm.ErrRecordNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	if change.Balance >= threshold.Threshold {
+		_, err = persistence.SetLowBalanceAlerted(tx, change.Handle, change.Application, false)
+		return false, err
+	}
+
+	if change.Value >= 0 {
+		return false, nil
+	}
+
+	crossed, err := persistence.SetLowBalanceAlerted(tx, change.Handle, change.Application, true)
+	if err != nil || !crossed {
+		return false, err
+	}
+
+	_, err = persistence.CreateLowBalanceAlert(tx, models.LowBalanceAlert{
+		Handle:      change.Handle,
+		Application: change.Application,
+		Threshold:   threshold.Threshold,
+		Balance:     change.Balance,
+	})
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// toLowBalanceThresholdAPI converts the given threshold model into its API representation.
+func toLowBalanceThresholdAPI(threshold models.LowBalanceThreshold) api.LowBalanceThreshold {
+	return api.LowBalanceThreshold{
+		Application: threshold.Application,
+		Handle:      threshold.Handle,
+		Threshold:   threshold.Threshold,
+	}
+}
+
+// LowBalanceNotifier notifies customers about their balance dropping below their low balance threshold.
+type LowBalanceNotifier interface {
+	// NotifyLowBalance sends the given alert.
+	NotifyLowBalance(ctx context.Context, alert api.LowBalanceAlert) error
+}
+
+// httpLowBalanceNotifier is a LowBalanceNotifier implementation that sends alerts as a JSON body to a fixed URL.
+type httpLowBalanceNotifier struct {
+	client *http.Client
+	url    string
+}
+
+// NotifyLowBalance sends a POST request to the notifier URL with the alert as body.
+func (n *httpLowBalanceNotifier) NotifyLowBalance(ctx context.Context, alert api.LowBalanceAlert) error {
+	body, err := json.Marshal(alert)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewBuffer(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	_, _ = io.Copy(io.Discard, res.Body)
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("unexpected status code notifying low balance of %s: %d", alert.Handle, res.StatusCode)
+	}
+	return nil
+}
+
+// NewHTTPLowBalanceNotifier initializes a new LowBalanceNotifier that sends alerts to the given URL.
+func NewHTTPLowBalanceNotifier(url string, timeout time.Duration) LowBalanceNotifier {
+	return &httpLowBalanceNotifier{
+		client: &http.Client{Timeout: timeout},
+		url:    url,
+	}
+}
+
+// logLowBalanceNotifier is a LowBalanceNotifier implementation that only logs alerts.
+type logLowBalanceNotifier struct {
+	logger *log.Logger
+}
+
+// NotifyLowBalance logs the given alert.
+func (n *logLowBalanceNotifier) NotifyLowBalance(ctx context.Context, alert api.LowBalanceAlert) error {
+	n.logger.Println("Low balance:", alert.Handle, "Application:", alert.Application, "Balance:", alert.Balance,
+		"Threshold:", alert.Threshold)
+	return nil
+}
+
+// NewLogLowBalanceNotifier initializes a new LowBalanceNotifier that logs alerts using the given logger.
+func NewLogLowBalanceNotifier(logger *log.Logger) LowBalanceNotifier {
+	return &logLowBalanceNotifier{logger: logger}
+}
+
+// lowBalanceAlertBatchSize is the maximum amount of alerts sent in a single run of the low balance alerter.
+const lowBalanceAlertBatchSize = 100
+
+// lowBalanceAlerter is a Worker that sends pending low balance alerts.
+type lowBalanceAlerter struct {
+	db       *gorm.DB
+	logger   *log.Logger
+	notifier LowBalanceNotifier
+}
+
+// Run sends the pending alerts every interval until ctx is done.
+func (a *lowBalanceAlerter) Run(ctx context.Context, interval time.Duration) {
+	runPeriodically(ctx, interval, a.logger, "Low balance alerting", a.RunOnce)
+}
+
+// RunOnce sends the pending alerts. Alerts that fail to be sent are retried in the next run.
+func (a *lowBalanceAlerter) RunOnce(ctx context.Context) error {
+	alerts, err := persistence.GetPendingLowBalanceAlerts(a.db, lowBalanceAlertBatchSize)
+	if err != nil {
+		return err
+	}
+
+	for _, alert := range alerts {
+		err = a.notifier.NotifyLowBalance(ctx, api.LowBalanceAlert{
+			Handle:      alert.Handle,
+			Application: alert.Application,
+			Threshold:   alert.Threshold,
+			Balance:     alert.Balance,
+			CreatedAt:   alert.CreatedAt,
+		})
+		if err != nil {
+			a.logger.Println("Failed to notify low balance:", alert.Handle, "Error:", err)
+			continue
+		}
+
+		if err = persistence.MarkLowBalanceAlertNotified(a.db, alert, time.Now()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// NewLowBalanceAlerter initializes a new Worker that sends low balance alerts using the given notifier.
+func NewLowBalanceAlerter(db *gorm.DB, logger *log.Logger, notifier LowBalanceNotifier) Worker {
+	if logger == nil {
+		logger = log.New(io.Discard, "", log.LstdFlags)
+	}
+	return &lowBalanceAlerter{
+		db:       db,
+		logger:   logger,
+		notifier: notifier,
+	}
+}
@@ -0,0 +1,181 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"gitlab.com/ignitionrobotics/billing/credits/internal/conf"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/api"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/domain/models"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/domain/persistence"
+	"gorm.io/gorm"
+	"log"
+	"os"
+	"testing"
+)
+
+type fakeLowBalanceNotifier struct {
+	mock.Mock
+}
+
+func (n *fakeLowBalanceNotifier) NotifyLowBalance(ctx context.Context, alert api.LowBalanceAlert) error {
+	args := n.Called(ctx, alert)
+	return args.Error(0)
+}
+
+type testThresholdsSuite struct {
+	suite.Suite
+	DB       *gorm.DB
+	Logger   *log.Logger
+	Service  Service
+	Notifier *fakeLowBalanceNotifier
+	Alerter  Worker
+}
+
+func TestThresholds(t *testing.T) {
+	suite.Run(t, new(testThresholdsSuite))
+}
+
+func (s *testThresholdsSuite) SetupSuite() {
+	s.Logger = log.New(os.Stdout, "[TestThresholds] ", log.LstdFlags|log.Lshortfile|log.Lmsgprefix)
+
+	var c conf.Config
+	s.Require().NoError(c.Parse())
+
+	var err error
+	s.DB, err = persistence.OpenConn(c.Database)
+	s.Require().NoError(err)
+
+	s.Require().NoError(persistence.DropTables(s.DB))
+}
+
+func (s *testThresholdsSuite) SetupTest() {
+	s.Require().NoError(persistence.MigrateTables(s.DB))
+	s.Service = NewCreditsService(s.DB, s.Logger, 1)
+	s.Notifier = new(fakeLowBalanceNotifier)
+	s.Alerter = NewLowBalanceAlerter(s.DB, s.Logger, s.Notifier)
+
+	for _, handle := range []string{"test1", "test2"} {
+		_, err := persistence.CreateCustomer(s.DB, models.Customer{
+			Handle:      handle,
+			Application: "cloudsim",
+			Credits:     100,
+		})
+		s.Require().NoError(err)
+	}
+
+	_, err := s.Service.SetLowBalanceThreshold(context.Background(), api.SetLowBalanceThresholdRequest{
+		LowBalanceThreshold: api.LowBalanceThreshold{Application: "cloudsim", Threshold: 50},
+	})
+	s.Require().NoError(err)
+}
+
+func (s *testThresholdsSuite) TearDownTest() {
+	s.Require().NoError(persistence.DropTables(s.DB))
+}
+
+func (s *testThresholdsSuite) transaction(handle string, amount uint) api.Transaction {
+	return api.Transaction{
+		Handle:      handle,
+		Amount:      amount,
+		Currency:    "usd",
+		Application: "cloudsim",
+	}
+}
+
+func (s *testThresholdsSuite) decrease(handle string, amount uint) {
+	_, err := s.Service.DecreaseCredits(context.Background(), api.DecreaseCreditsRequest{
+		Transaction: s.transaction(handle, amount),
+	})
+	s.Require().NoError(err)
+}
+
+func (s *testThresholdsSuite) increase(handle string, amount uint) {
+	_, err := s.Service.IncreaseCredits(context.Background(), api.IncreaseCreditsRequest{
+		Transaction: s.transaction(handle, amount),
+	})
+	s.Require().NoError(err)
+}
+
+func (s *testThresholdsSuite) TestGetLowBalanceThreshold() {
+	_, err := s.Service.SetLowBalanceThreshold(context.Background(), api.SetLowBalanceThresholdRequest{
+		LowBalanceThreshold: api.LowBalanceThreshold{Application: "cloudsim", Handle: "test2", Threshold: 10},
+	})
+	s.Require().NoError(err)
+
+	res, err := s.Service.GetLowBalanceThreshold(context.Background(), api.GetLowBalanceThresholdRequest{
+		Application: "cloudsim",
+		Handle:      "test1",
+	})
+	s.Require().NoError(err)
+	s.Assert().Equal(50, res.Threshold)
+	s.Assert().Empty(res.Handle)
+
+	res, err = s.Service.GetLowBalanceThreshold(context.Background(), api.GetLowBalanceThresholdRequest{
+		Application: "cloudsim",
+		Handle:      "test2",
+	})
+	s.Require().NoError(err)
+	s.Assert().Equal(10, res.Threshold)
+	s.Assert().Equal("test2", res.Handle)
+
+	_, err = s.Service.DeleteLowBalanceThreshold(context.Background(), api.DeleteLowBalanceThresholdRequest{
+		Application: "cloudsim",
+	})
+	s.Require().NoError(err)
+
+	_, err = s.Service.GetLowBalanceThreshold(context.Background(), api.GetLowBalanceThresholdRequest{
+		Application: "cloudsim",
+	})
+	s.Assert().Equal(api.ErrThresholdNotFound, err)
+}
+
+func (s *testThresholdsSuite) TestSingleAlertPerCrossing() {
+	s.Notifier.On("NotifyLowBalance", mock.Anything, mock.MatchedBy(func(alert api.LowBalanceAlert) bool {
+		return alert.Handle == "test1" && alert.Threshold == 50 && alert.Balance == 40
+	})).Return(nil).Once()
+
+	s.decrease("test1", 60)
+	s.decrease("test1", 10)
+	s.Require().NoError(s.Alerter.RunOnce(context.Background()))
+	s.Require().NoError(s.Alerter.RunOnce(context.Background()))
+	s.Notifier.AssertNumberOfCalls(s.T(), "NotifyLowBalance", 1)
+
+	// Recovering above the threshold re-arms the alert
+	s.increase("test1", 100)
+	s.Notifier.On("NotifyLowBalance", mock.Anything, mock.MatchedBy(func(alert api.LowBalanceAlert) bool {
+		return alert.Handle == "test1" && alert.Balance == 30
+	})).Return(nil).Once()
+
+	s.decrease("test1", 100)
+	s.Require().NoError(s.Alerter.RunOnce(context.Background()))
+	s.Notifier.AssertExpectations(s.T())
+}
+
+func (s *testThresholdsSuite) TestCustomerOverride() {
+	_, err := s.Service.SetLowBalanceThreshold(context.Background(), api.SetLowBalanceThresholdRequest{
+		LowBalanceThreshold: api.LowBalanceThreshold{Application: "cloudsim", Handle: "test2", Threshold: 10},
+	})
+	s.Require().NoError(err)
+
+	s.decrease("test2", 60)
+	s.Require().NoError(s.Alerter.RunOnce(context.Background()))
+	s.Notifier.AssertNotCalled(s.T(), "NotifyLowBalance", mock.Anything, mock.Anything)
+
+	s.Notifier.On("NotifyLowBalance", mock.Anything, mock.Anything).Return(nil).Once()
+	s.decrease("test2", 35)
+	s.Require().NoError(s.Alerter.RunOnce(context.Background()))
+	s.Notifier.AssertExpectations(s.T())
+}
+
+func (s *testThresholdsSuite) TestNotifierFailure() {
+	s.Notifier.On("NotifyLowBalance", mock.Anything, mock.Anything).Return(errors.New("unavailable")).Once()
+	s.Notifier.On("NotifyLowBalance", mock.Anything, mock.Anything).Return(nil).Once()
+
+	s.decrease("test1", 60)
+	s.Require().NoError(s.Alerter.RunOnce(context.Background()))
+	s.Require().NoError(s.Alerter.RunOnce(context.Background()))
+	s.Require().NoError(s.Alerter.RunOnce(context.Background()))
+	s.Notifier.AssertNumberOfCalls(s.T(), "NotifyLowBalance", 2)
+}
@@ -0,0 +1,471 @@
+package application
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/api"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/domain/models"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/domain/persistence"
+	"gorm.io/gorm"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// SetAutoTopUp enables or updates the automatic top-ups of a customer.
+func (s *service) SetAutoTopUp(ctx context.Context, req api.SetAutoTopUpRequest) (api.SetAutoTopUpResponse, error) {
+	if err := req.Validate(); err != nil {
+		return api.SetAutoTopUpResponse{}, err
+	}
+
+	var settings models.AutoTopUp
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := shareConversionPolicy(tx, req.Application); err != nil {
+			return err
+		}
+		var err error
+		settings, err = persistence.SetAutoTopUp(tx, models.AutoTopUp{
+			Handle:        req.Handle,
+			Application:   req.Application,
+			Threshold:     req.Threshold,
+			Amount:        req.Amount,
+			Currency:      req.Currency,
+			MaxPerDay:     req.MaxPerDay,
+			PaymentMethod: req.PaymentMethod,
+		})
+		return err
+	})
+	if err != nil {
+		return api.SetAutoTopUpResponse{}, err
+	}
+
+	return api.SetAutoTopUpResponse{AutoTopUp: toAutoTopUpAPI(settings)}, nil
+}
+
+// GetAutoTopUp returns the automatic top-up settings of a customer.
+func (s *service) GetAutoTopUp(ctx context.Context, req api.GetAutoTopUpRequest) (api.GetAutoTopUpResponse, error) {
+	settings, err := s.getAutoTopUp(req.Handle, req.Application)
+	if err != nil {
+		return api.GetAutoTopUpResponse{}, err
+	}
+	return api.GetAutoTopUpResponse{AutoTopUp: toAutoTopUpAPI(settings)}, nil
+}
+
+// DisableAutoTopUp disables the automatic top-ups of a customer. Pending top-ups are still completed.
+func (s *service) DisableAutoTopUp(ctx context.Context, req api.DisableAutoTopUpRequest) (api.DisableAutoTopUpResponse, error) {
+	settings, err := s.getAutoTopUp(req.Handle, req.Application)
+	if err != nil {
+		return api.DisableAutoTopUpResponse{}, err
+	}
+
+	if err = persistence.DeleteAutoTopUp(s.db, settings); err != nil {
+		return api.DisableAutoTopUpResponse{}, err
+	}
+	return api.DisableAutoTopUpResponse{}, nil
+}
+
+// ListTopUps returns the latest top-ups of a customer.
+func (s *service) ListTopUps(ctx context.Context, req api.ListTopUpsRequest) (api.ListTopUpsResponse, error) {
+	if len(req.Handle) == 0 {
+		return api.ListTopUpsResponse{}, api.ErrHandleNotProvided
+	}
+	if len(req.Application) == 0 {
+		return api.ListTopUpsResponse{}, api.ErrMissingApplication
+	}
+	if req.Limit < 0 || req.Limit > api.MaxPageSize {
+		return api.ListTopUpsResponse{}, api.ErrInvalidLimit
+	}
+	limit := req.Limit
+	if limit == 0 {
+		limit = api.DefaultPageSize
+	}
+
+	list, err := persistence.GetTopUps(s.db, req.Handle, req.Application, limit)
+	if err != nil {
+		return api.ListTopUpsResponse{}, err
+	}
+
+	out := api.ListTopUpsResponse{TopUps: make([]api.TopUp, len(list))}
+	for i, t := range list {
+		out.TopUps[i] = toTopUpAPI(t)
+	}
+	return out, nil
+}
+
+// getAutoTopUp returns the automatic top-up settings of a customer.
+func (s *service) getAutoTopUp(handle, application string) (models.AutoTopUp, error) {
+	if len(handle) == 0 {
+		return models.AutoTopUp{}, api.ErrHandleNotProvided
+	}
+	if len(application) == 0 {
+		return models.AutoTopUp{}, api.ErrMissingApplication
+	}
+
+	settings, err := persistence.GetAutoTopUp(s.db, handle, application)
+	if err == gorm.ErrRecordNotFound {
+		return models.AutoTopUp{}, api.ErrAutoTopUpNotFound
+	}
+	if err != nil {
+		return models.AutoTopUp{}, err
+	}
+	return settings, nil
+}
+
+// toAutoTopUpAPI converts the given settings model into its API representation.
+func toAutoTopUpAPI(settings models.AutoTopUp) api.AutoTopUp {
+	return api.AutoTopUp{
+		Handle:        settings.Handle,
+		Application:   settings.Application,
+		Threshold:     settings.Threshold,
+		Amount:        settings.Amount,
+		Currency:      settings.Currency,
+		MaxPerDay:     settings.MaxPerDay,
+		PaymentMethod: settings.PaymentMethod,
+	}
+}
+
+// toTopUpAPI converts the given top-up model into its API representation.
+func toTopUpAPI(topUp models.TopUp) api.TopUp {
+	return api.TopUp{
+		ID:          topUp.ID,
+		Handle:      topUp.Handle,
+		Application: topUp.Application,
+		Amount:      topUp.Amount,
+		Currency:    topUp.Currency,
+		Credits:     topUp.Credits,
+		Status:      api.TopUpStatus(topUp.Status),
+		PaymentID:   topUp.PaymentID,
+		Error:       topUp.Error,
+		CreatedAt:   topUp.CreatedAt,
+	}
+}
+
+// Payment is a request to charge a customer through a PaymentProvider.
+type Payment struct {
+	// IdempotencyKey uniquely identifies the payment. Payment providers must not charge the same key twice.
+	IdempotencyKey string `json:"idempotency_key"`
+
+	// Handle is the customer being charged.
+	Handle string `json:"handle"`
+
+	// Application is the application that credits are tracked for.
+	Application string `json:"application"`
+
+	// PaymentMethod identifies the payment method charged in the payment provider.
+	PaymentMethod string `json:"payment_method"`
+
+	// Amount is the amount of money charged.
+	Amount uint `json:"amount"`
+
+	// Currency is the currency of Amount.
+	Currency string `json:"currency"`
+}
+
+// ErrPaymentDeclined is returned by a PaymentProvider when a payment has been rejected without charging the
+// customer (e.g. the card was declined).
+var ErrPaymentDeclined = errors.New("payment declined")
+
+// PaymentProvider charges customers for automatic top-ups.
+type PaymentProvider interface {
+	// CreatePayment charges the given payment and returns its identifier in the payment provider. Calling it again
+	// with the same idempotency key must return the identifier of the original payment without charging again.
+	// Errors wrapping ErrPaymentDeclined mean the customer wasn't charged. Any other error means the payment may
+	// have been charged, so it's retried later with the same idempotency key.
+	CreatePayment(ctx context.Context, payment Payment) (string, error)
+}
+
+// httpPaymentProvider is a PaymentProvider implementation that creates payments by sending them as a JSON body to a
+// fixed URL. The idempotency key is also sent in the Idempotency-Key header.
+type httpPaymentProvider struct {
+	client *http.Client
+	url    string
+}
+
+// httpPaymentResponse is the body returned by the payment provider after creating a payment.
+type httpPaymentResponse struct {
+	// ID is the identifier of the payment in the payment provider.
+	ID string `json:"id"`
+}
+
+// CreatePayment sends a POST request to the payment provider URL with the payment as body, and returns the
+// identifier of the payment found in the response. Client errors are considered declined payments, except for
+// timeouts, conflicts and rate limits, which may be retried.
+func (p *httpPaymentProvider) CreatePayment(ctx context.Context, payment Payment) (string, error) {
+	body, err := json.Marshal(payment)
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewBuffer(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Idempotency-Key", payment.IdempotencyKey)
+
+	res, err := p.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		_, _ = io.Copy(io.Discard, res.Body)
+		if declinedStatusCode(res.StatusCode) {
+			return "", fmt.Errorf("%w: status code creating payment %s: %d", ErrPaymentDeclined, payment.IdempotencyKey, res.StatusCode)
+		}
+		return "", fmt.Errorf("unexpected status code creating payment %s: %d", payment.IdempotencyKey, res.StatusCode)
+	}
+
+	var out httpPaymentResponse
+	if err = json.NewDecoder(res.Body).Decode(&out); err != nil {
+		return "", err
+	}
+	if len(out.ID) == 0 {
+		return "", fmt.Errorf("missing payment id creating payment %s", payment.IdempotencyKey)
+	}
+	return out.ID, nil
+}
+
+// declinedStatusCode returns true if the given response status code means that the payment provider rejected a
+// payment without charging it.
+func declinedStatusCode(code int) bool {
+	switch code {
+	case http.StatusRequestTimeout, http.StatusConflict, http.StatusTooManyRequests:
+		return false
+	}
+	return code >= 400 && code < 500
+}
+
+// NewHTTPPaymentProvider initializes a new PaymentProvider that creates payments through the given URL.
+func NewHTTPPaymentProvider(url string, timeout time.Duration) PaymentProvider {
+	return &httpPaymentProvider{
+		client: &http.Client{Timeout: timeout},
+		url:    url,
+	}
+}
+
+// FakePaymentProvider is a PaymentProvider that keeps payments in memory. It's intended to be used in tests.
+type FakePaymentProvider struct {
+	lock     sync.Mutex
+	err      error
+	ids      map[string]string
+	payments []Payment
+}
+
+// CreatePayment records the given payment, unless a payment with the same idempotency key has already been made.
+func (p *FakePaymentProvider) CreatePayment(ctx context.Context, payment Payment) (string, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if p.err != nil {
+		return "", p.err
+	}
+	if id, ok := p.ids[payment.IdempotencyKey]; ok {
+		return id, nil
+	}
+
+	id := fmt.Sprintf("pay_%d", len(p.payments)+1)
+	p.ids[payment.IdempotencyKey] = id
+	p.payments = append(p.payments, payment)
+	return id, nil
+}
+
+// SetError sets the error returned by the following payments. A nil error makes payments succeed again.
+func (p *FakePaymentProvider) SetError(err error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.err = err
+}
+
+// Payments returns the payments charged so far.
+func (p *FakePaymentProvider) Payments() []Payment {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	out := make([]Payment, len(p.payments))
+	copy(out, p.payments)
+	return out
+}
+
+// NewFakePaymentProvider initializes a new FakePaymentProvider.
+func NewFakePaymentProvider() *FakePaymentProvider {
+	return &FakePaymentProvider{ids: make(map[string]string)}
+}
+
+// topUpBatchSize is the maximum amount of customers topped up in a single run of the top-up worker.
+const topUpBatchSize = 100
+
+// errTopUpSkipped is returned when a customer shouldn't be topped up.
+var errTopUpSkipped = errors.New("top-up skipped")
+
+// autoTopUpWorker is a Worker that tops up the customers whose balance dropped below their threshold.
+type autoTopUpWorker struct {
+	db             *gorm.DB
+	logger         *log.Logger
+	provider       PaymentProvider
+	conversionRate uint
+}
+
+// Run tops up customers every interval until ctx is done.
+func (w *autoTopUpWorker) Run(ctx context.Context, interval time.Duration) {
+	runPeriodically(ctx, interval, w.logger, "Automatic top-up", w.RunOnce)
+}
+
+// RunOnce completes the pending top-ups left by previous runs, and then tops up the customers whose balance is
+// below their threshold.
+func (w *autoTopUpWorker) RunOnce(ctx context.Context) error {
+	pending, err := persistence.GetTopUpsByStatus(w.db, string(api.TopUpPending), topUpBatchSize)
+	if err != nil {
+		return err
+	}
+	for _, topUp := range pending {
+		if err = w.complete(ctx, topUp); err != nil {
+			w.logger.Println("Failed to complete top-up:", topUp.ID, "Error:", err)
+		}
+	}
+
+	candidates, err := persistence.GetAutoTopUpCandidates(w.db, topUpBatchSize)
+	if err != nil {
+		return err
+	}
+	for _, settings := range candidates {
+		topUp, err := w.start(settings)
+		if err == errTopUpSkipped {
+			continue
+		}
+		if err != nil {
+			w.logger.Println("Failed to start top-up:", settings.Handle, "Error:", err)
+			continue
+		}
+		if err = w.complete(ctx, topUp); err != nil {
+			w.logger.Println("Failed to complete top-up:", topUp.ID, "Error:", err)
+		}
+	}
+	return nil
+}
+
+// start creates a pending top-up for the given settings. The settings are locked while checking that the customer
+// doesn't have another pending top-up, hasn't reached its daily limit, is still below its threshold and can receive
+// credits, so concurrent workers can't top up the same customer twice and frozen or closed customers aren't charged.
+func (w *autoTopUpWorker) start(settings models.AutoTopUp) (models.TopUp, error) {
+	var topUp models.TopUp
+	err := w.db.Transaction(func(tx *gorm.DB) error {
+		settings, err := persistence.GetAutoTopUpForUpdate(tx, settings.Handle, settings.Application)
+		if err == gorm.ErrRecordNotFound {
+			return errTopUpSkipped
+		}
+		if err != nil {
+			return err
+		}
+
+		pending, err := persistence.CountTopUpsSince(tx, settings.Handle, settings.Application, string(api.TopUpPending), time.Time{})
+		if err != nil {
+			return err
+		}
+		if pending > 0 {
+			return errTopUpSkipped
+		}
+
+		today, err := persistence.CountTopUpsSince(tx, settings.Handle, settings.Application, "", time.Now().Add(-24*time.Hour))
+		if err != nil {
+			return err
+		}
+		if today >= int64(settings.MaxPerDay) {
+			return errTopUpSkipped
+		}
+
+		c, err := persistence.GetCustomer(tx, settings.Handle, settings.Application)
+		if err != nil {
+			return err
+		}
+		if c.Credits >= settings.Threshold || checkCustomerStatus(c) != nil {
+			return errTopUpSkipped
+		}
+
+		topUp, err = persistence.CreateTopUp(tx, models.TopUp{
+			Handle:        settings.Handle,
+			Application:   settings.Application,
+			Amount:        settings.Amount,
+			Currency:      settings.Currency,
+			PaymentMethod: settings.PaymentMethod,
+			Status:        string(api.TopUpPending),
+		})
+		return err
+	})
+	if err != nil {
+		return models.TopUp{}, err
+	}
+	return topUp, nil
+}
+
+// complete charges the payment provider for the given pending top-up and adds the credits to the customer. The
+// top-up ID is used as idempotency key, so completing a top-up again after a failure doesn't charge twice. Top-ups
+// are only marked as failed when the payment is declined. Other errors leave them pending to be retried, since the
+// payment may have been charged.
+func (w *autoTopUpWorker) complete(ctx context.Context, topUp models.TopUp) error {
+	paymentID, err := w.provider.CreatePayment(ctx, Payment{
+		IdempotencyKey: fmt.Sprintf("top_up_%d", topUp.ID),
+		Handle:         topUp.Handle,
+		Application:    topUp.Application,
+		PaymentMethod:  topUp.PaymentMethod,
+		Amount:         topUp.Amount,
+		Currency:       topUp.Currency,
+	})
+	if err != nil {
+		if errors.Is(err, ErrPaymentDeclined) {
+			topUp.Status = string(api.TopUpFailed)
+		}
+		topUp.Error = err.Error()
+		_, ferr := persistence.FinishTopUp(w.db, topUp, string(api.TopUpPending))
+		if ferr != nil {
+			return ferr
+		}
+		return err
+	}
+
+	return w.db.Transaction(func(tx *gorm.DB) error {
+		credits := &service{db: tx, logger: w.logger, conversionRate: w.conversionRate}
+
+		topUp.Status = string(api.TopUpSucceeded)
+		topUp.PaymentID = paymentID
+		topUp.Error = ""
+		p, err := credits.purchaseCredits(tx, topUp.Application, topUp.Amount, topUp.Currency)
+		if err != nil {
+			return err
+		}
+		topUp.Credits = p.Credits
+		finished, err := persistence.FinishTopUp(tx, topUp, string(api.TopUpPending))
+		if err != nil || !finished {
+			return err
+		}
+
+		_, err = credits.IncreaseCredits(ctx, api.IncreaseCreditsRequest{
+			Transaction: api.Transaction{
+				Handle:      topUp.Handle,
+				Amount:      topUp.Amount,
+				Currency:    topUp.Currency,
+				Application: topUp.Application,
+			},
+		})
+		return err
+	})
+}
+
+// NewAutoTopUpWorker initializes a new Worker that tops up customers using the given payment provider. The
+// conversion rate is used to calculate the credits added on every top-up.
+func NewAutoTopUpWorker(db *gorm.DB, logger *log.Logger, provider PaymentProvider, rate uint) Worker {
+	if logger == nil {
+		logger = log.New(io.Discard, "", log.LstdFlags)
+	}
+	return &autoTopUpWorker{
+		db:             db,
+		logger:         logger,
+		provider:       provider,
+		conversionRate: rate,
+	}
+}
@@ -0,0 +1,282 @@
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"gitlab.com/ignitionrobotics/billing/credits/internal/conf"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/api"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/domain/models"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/domain/persistence"
+	"gorm.io/gorm"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestHTTPPaymentProvider(t *testing.T) {
+	var received Payment
+	var key string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key = r.Header.Get("Idempotency-Key")
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		if received.Amount == 0 {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if received.Amount == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`{"id":"pay_1"}`))
+	}))
+	defer server.Close()
+
+	provider := NewHTTPPaymentProvider(server.URL, time.Second)
+
+	payment := Payment{
+		IdempotencyKey: "topup_1",
+		Handle:         "test1",
+		Application:    "cloudsim",
+		PaymentMethod:  "pm_1",
+		Amount:         1000,
+		Currency:       "usd",
+	}
+	id, err := provider.CreatePayment(context.Background(), payment)
+	require.NoError(t, err)
+	assert.Equal(t, "pay_1", id)
+	assert.Equal(t, payment, received)
+	assert.Equal(t, "topup_1", key)
+
+	payment.Amount = 0
+	_, err = provider.CreatePayment(context.Background(), payment)
+	assert.True(t, errors.Is(err, ErrPaymentDeclined))
+
+	// Server errors may have charged the payment, so they are not declined payments.
+	payment.Amount = 1
+	_, err = provider.CreatePayment(context.Background(), payment)
+	assert.Error(t, err)
+	assert.False(t, errors.Is(err, ErrPaymentDeclined))
+}
+
+type testTopUpsSuite struct {
+	suite.Suite
+	DB       *gorm.DB
+	Logger   *log.Logger
+	Service  Service
+	Provider *FakePaymentProvider
+	Worker   Worker
+}
+
+func TestTopUps(t *testing.T) {
+	suite.Run(t, new(testTopUpsSuite))
+}
+
+func (s *testTopUpsSuite) SetupSuite() {
+	s.Logger = log.New(os.Stdout, "[TestTopUps] ", log.LstdFlags|log.Lshortfile|log.Lmsgprefix)
+
+	var c conf.Config
+	s.Require().NoError(c.Parse())
+
+	var err error
+	s.DB, err = persistence.OpenConn(c.Database)
+	s.Require().NoError(err)
+
+	s.Require().NoError(persistence.DropTables(s.DB))
+}
+
+func (s *testTopUpsSuite) SetupTest() {
+	s.Require().NoError(persistence.MigrateTables(s.DB))
+	s.Service = NewCreditsService(s.DB, s.Logger, 2)
+	s.Provider = NewFakePaymentProvider()
+	s.Worker = NewAutoTopUpWorker(s.DB, s.Logger, s.Provider, 2)
+
+	_, err := persistence.CreateCustomer(s.DB, models.Customer{
+		Handle:      "test1",
+		Application: "cloudsim",
+		Credits:     100,
+	})
+	s.Require().NoError(err)
+
+	_, err = s.Service.SetAutoTopUp(context.Background(), api.SetAutoTopUpRequest{
+		AutoTopUp: api.AutoTopUp{
+			Handle:        "test1",
+			Application:   "cloudsim",
+			Threshold:     50,
+			Amount:        200,
+			Currency:      "usd",
+			MaxPerDay:     2,
+			PaymentMethod: "pm_card",
+		},
+	})
+	s.Require().NoError(err)
+}
+
+func (s *testTopUpsSuite) TearDownTest() {
+	s.Require().NoError(persistence.DropTables(s.DB))
+}
+
+func (s *testTopUpsSuite) setBalance(credits int) {
+	c, err := persistence.GetCustomer(s.DB, "test1", "cloudsim")
+	s.Require().NoError(err)
+	_, err = persistence.UpdateCredits(s.DB, "test1", "cloudsim", credits-c.Credits, models.OperationDecrease)
+	s.Require().NoError(err)
+}
+
+func (s *testTopUpsSuite) balance() int {
+	c, err := persistence.GetCustomer(s.DB, "test1", "cloudsim")
+	s.Require().NoError(err)
+	return c.Credits
+}
+
+func (s *testTopUpsSuite) TestSetAutoTopUpValidation() {
+	_, err := s.Service.SetAutoTopUp(context.Background(), api.SetAutoTopUpRequest{
+		AutoTopUp: api.AutoTopUp{
+			Handle:      "test1",
+			Application: "cloudsim",
+			Amount:      200,
+			Currency:    "usd",
+		},
+	})
+	s.Assert().Equal(api.ErrInvalidDailyLimit, err)
+}
+
+func (s *testTopUpsSuite) TestTopUpBelowThreshold() {
+	// Above the threshold
+	s.Require().NoError(s.Worker.RunOnce(context.Background()))
+	s.Assert().Empty(s.Provider.Payments())
+
+	s.setBalance(40)
+	s.Require().NoError(s.Worker.RunOnce(context.Background()))
+
+	payments := s.Provider.Payments()
+	s.Require().Len(payments, 1)
+	s.Assert().Equal(uint(200), payments[0].Amount)
+	s.Assert().Equal("pm_card", payments[0].PaymentMethod)
+	s.Assert().Equal(140, s.balance())
+
+	res, err := s.Service.ListTopUps(context.Background(), api.ListTopUpsRequest{Handle: "test1", Application: "cloudsim"})
+	s.Require().NoError(err)
+	s.Require().Len(res.TopUps, 1)
+	s.Assert().Equal(api.TopUpSucceeded, res.TopUps[0].Status)
+	s.Assert().Equal(uint(100), res.TopUps[0].Credits)
+	s.Assert().NotEmpty(res.TopUps[0].PaymentID)
+
+	// Back above the threshold
+	s.Require().NoError(s.Worker.RunOnce(context.Background()))
+	s.Assert().Len(s.Provider.Payments(), 1)
+}
+
+func (s *testTopUpsSuite) TestDailyLimit() {
+	for i := 0; i < 3; i++ {
+		s.setBalance(0)
+		s.Require().NoError(s.Worker.RunOnce(context.Background()))
+	}
+	s.Assert().Len(s.Provider.Payments(), 2)
+	s.Assert().Equal(0, s.balance())
+}
+
+func (s *testTopUpsSuite) TestFailedPayment() {
+	s.Provider.SetError(fmt.Errorf("%w: card declined", ErrPaymentDeclined))
+	s.setBalance(0)
+
+	s.Require().NoError(s.Worker.RunOnce(context.Background()))
+	s.Assert().Equal(0, s.balance())
+
+	res, err := s.Service.ListTopUps(context.Background(), api.ListTopUpsRequest{Handle: "test1", Application: "cloudsim"})
+	s.Require().NoError(err)
+	s.Require().Len(res.TopUps, 1)
+	s.Assert().Equal(api.TopUpFailed, res.TopUps[0].Status)
+	s.Assert().Equal("payment declined: card declined", res.TopUps[0].Error)
+}
+
+func (s *testTopUpsSuite) TestAmbiguousPaymentFailureIsRetried() {
+	s.Provider.SetError(errors.New("timeout"))
+	s.setBalance(0)
+
+	s.Require().NoError(s.Worker.RunOnce(context.Background()))
+	s.Assert().Equal(0, s.balance())
+
+	res, err := s.Service.ListTopUps(context.Background(), api.ListTopUpsRequest{Handle: "test1", Application: "cloudsim"})
+	s.Require().NoError(err)
+	s.Require().Len(res.TopUps, 1)
+	s.Assert().Equal(api.TopUpPending, res.TopUps[0].Status)
+	s.Assert().Equal("timeout", res.TopUps[0].Error)
+
+	// The same top-up is retried with the same idempotency key instead of starting a new one.
+	s.Provider.SetError(nil)
+	s.Require().NoError(s.Worker.RunOnce(context.Background()))
+	s.Assert().Equal(100, s.balance())
+
+	payments := s.Provider.Payments()
+	s.Require().Len(payments, 1)
+	s.Assert().Equal(fmt.Sprintf("top_up_%d", res.TopUps[0].ID), payments[0].IdempotencyKey)
+
+	res, err = s.Service.ListTopUps(context.Background(), api.ListTopUpsRequest{Handle: "test1", Application: "cloudsim"})
+	s.Require().NoError(err)
+	s.Require().Len(res.TopUps, 1)
+	s.Assert().Equal(api.TopUpSucceeded, res.TopUps[0].Status)
+	s.Assert().Empty(res.TopUps[0].Error)
+}
+
+func (s *testTopUpsSuite) TestSkipInactiveCustomers() {
+	s.setBalance(0)
+	c, err := persistence.GetCustomer(s.DB, "test1", "cloudsim")
+	s.Require().NoError(err)
+	s.Require().NoError(persistence.SetCustomerStatus(s.DB, c, string(api.CustomerFrozen)))
+
+	s.Require().NoError(s.Worker.RunOnce(context.Background()))
+	s.Assert().Empty(s.Provider.Payments())
+
+	res, err := s.Service.ListTopUps(context.Background(), api.ListTopUpsRequest{Handle: "test1", Application: "cloudsim"})
+	s.Require().NoError(err)
+	s.Assert().Empty(res.TopUps)
+}
+
+func (s *testTopUpsSuite) TestResumePendingTopUp() {
+	s.setBalance(0)
+
+	// Simulate a worker that was stopped after charging the payment provider.
+	topUp, err := persistence.CreateTopUp(s.DB, models.TopUp{
+		Handle:      "test1",
+		Application: "cloudsim",
+		Amount:      200,
+		Currency:    "usd",
+		Status:      string(api.TopUpPending),
+	})
+	s.Require().NoError(err)
+	_, err = s.Provider.CreatePayment(context.Background(), Payment{IdempotencyKey: "top_up_1", Amount: 200})
+	s.Require().NoError(err)
+	s.Require().Equal(uint(1), topUp.ID)
+
+	s.Require().NoError(s.Worker.RunOnce(context.Background()))
+
+	// The pending top-up is completed without charging again, and no additional top-up is started.
+	s.Assert().Len(s.Provider.Payments(), 1)
+	s.Assert().Equal(100, s.balance())
+}
+
+func (s *testTopUpsSuite) TestDisableAutoTopUp() {
+	_, err := s.Service.DisableAutoTopUp(context.Background(), api.DisableAutoTopUpRequest{
+		Handle:      "test1",
+		Application: "cloudsim",
+	})
+	s.Require().NoError(err)
+
+	s.setBalance(0)
+	s.Require().NoError(s.Worker.RunOnce(context.Background()))
+	s.Assert().Empty(s.Provider.Payments())
+
+	_, err = s.Service.GetAutoTopUp(context.Background(), api.GetAutoTopUpRequest{
+		Handle:      "test1",
+		Application: "cloudsim",
+	})
+	s.Assert().Equal(api.ErrAutoTopUpNotFound, err)
+}
@@ -0,0 +1,286 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/api"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/domain/models"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/domain/persistence"
+	"gorm.io/gorm"
+	"io"
+	"log"
+	"time"
+)
+
+// SetTrialPolicy sets the welcome credits granted to the new customers of an application. Customers that already
+// exist are not affected.
+func (s *service) SetTrialPolicy(ctx context.Context, req api.SetTrialPolicyRequest) (api.SetTrialPolicyResponse, error) {
+	if err := req.Validate(); err != nil {
+		return api.SetTrialPolicyResponse{}, err
+	}
+
+	var policy models.TrialPolicy
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := shareConversionPolicy(tx, req.Application); err != nil {
+			return err
+		}
+		var err error
+		policy, err = persistence.SetTrialPolicy(tx, models.TrialPolicy{
+			Application: req.Application,
+			Credits:     req.Credits,
+			ExpiresIn:   req.ExpiresIn,
+		})
+		return err
+	})
+	if err != nil {
+		return api.SetTrialPolicyResponse{}, err
+	}
+
+	return api.SetTrialPolicyResponse{TrialPolicy: toTrialPolicyAPI(policy)}, nil
+}
+
+// GetTrialPolicy returns the trial policy of an application.
+func (s *service) GetTrialPolicy(ctx context.Context, req api.GetTrialPolicyRequest) (api.GetTrialPolicyResponse, error) {
+	if len(req.Application) == 0 {
+		return api.GetTrialPolicyResponse{}, api.ErrMissingApplication
+	}
+
+	policy, err := persistence.GetTrialPolicy(s.db, req.Application)
+	if err == gorm.ErrRecordNotFound {
+		return api.GetTrialPolicyResponse{}, api.ErrTrialPolicyNotFound
+	}
+	if err != nil {
+		return api.GetTrialPolicyResponse{}, err
+	}
+
+	return api.GetTrialPolicyResponse{TrialPolicy: toTrialPolicyAPI(policy)}, nil
+}
+
+// DeleteTrialPolicy removes the trial policy of an application. The welcome credits already granted are kept.
+func (s *service) DeleteTrialPolicy(ctx context.Context, req api.DeleteTrialPolicyRequest) (api.DeleteTrialPolicyResponse, error) {
+	if len(req.Application) == 0 {
+		return api.DeleteTrialPolicyResponse{}, api.ErrMissingApplication
+	}
+
+	policy, err := persistence.GetTrialPolicy(s.db, req.Application)
+	if err == gorm.ErrRecordNotFound {
+		return api.DeleteTrialPolicyResponse{}, api.ErrTrialPolicyNotFound
+	}
+	if err != nil {
+		return api.DeleteTrialPolicyResponse{}, err
+	}
+
+	if err = persistence.DeleteTrialPolicy(s.db, policy); err != nil {
+		return api.DeleteTrialPolicyResponse{}, err
+	}
+	return api.DeleteTrialPolicyResponse{}, nil
+}
+
+// GetTrialGrant returns the welcome credits granted to a customer.
+func (s *service) GetTrialGrant(ctx context.Context, req api.GetTrialGrantRequest) (api.GetTrialGrantResponse, error) {
+	if len(req.Handle) == 0 {
+		return api.GetTrialGrantResponse{}, api.ErrHandleNotProvided
+	}
+	if len(req.Application) == 0 {
+		return api.GetTrialGrantResponse{}, api.ErrMissingApplication
+	}
+
+	grant, err := persistence.GetTrialGrant(s.db, req.Handle, req.Application)
+	if err == gorm.ErrRecordNotFound {
+		return api.GetTrialGrantResponse{}, api.ErrTrialGrantNotFound
+	}
+	if err != nil {
+		return api.GetTrialGrantResponse{}, err
+	}
+
+	return api.GetTrialGrantResponse{TrialGrant: toTrialGrantAPI(grant)}, nil
+}
+
+// RecordHandleChange records that a customer changed its handle. If the previous handle received the welcome
+// credits, an empty grant is recorded for the new handle so it doesn't receive them again. Nothing is recorded if
+// the new handle already has a grant.
+func (s *service) RecordHandleChange(ctx context.Context, req api.RecordHandleChangeRequest) (api.RecordHandleChangeResponse, error) {
+	if err := req.Validate(); err != nil {
+		return api.RecordHandleChangeResponse{}, err
+	}
+
+	_, err := persistence.GetTrialGrant(s.db, req.PreviousHandle, req.Application)
+	if err == gorm.ErrRecordNotFound {
+		return api.RecordHandleChangeResponse{}, api.ErrTrialGrantNotFound
+	}
+	if err != nil {
+		return api.RecordHandleChangeResponse{}, err
+	}
+
+	_, _, err = persistence.CreateTrialGrant(s.db, models.TrialGrant{
+		Application:    req.Application,
+		Handle:         req.Handle,
+		PreviousHandle: req.PreviousHandle,
+	})
+	if err != nil {
+		return api.RecordHandleChangeResponse{}, err
+	}
+	return api.RecordHandleChangeResponse{}, nil
+}
+
+// createCustomer creates a new customer without credits, and grants it the welcome credits of its application if it
+// has a trial policy. Handles that already received the welcome credits don't receive them again, even if their
+// customer was removed. The conversion policy of the application is locked first, so its precision can't change
+// while the customer is being created. If another transaction creates the same customer first, nothing is done.
+func createCustomer(tx *gorm.DB, handle, application string) error {
+	if err := shareConversionPolicy(tx, application); err != nil {
+		return err
+	}
+
+	_, created, err := persistence.CreateCustomerIfNotExists(tx, models.Customer{Handle: handle, Application: application})
+	if err != nil || !created {
+		return err
+	}
+
+	policy, err := persistence.GetTrialPolicy(tx, application)
+	if err == gorm.ErrRecordNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	grant := models.TrialGrant{
+		Application: application,
+		Handle:      handle,
+		Credits:     policy.Credits,
+	}
+	if policy.ExpiresIn > 0 {
+		expiresAt := time.Now().UTC().Add(time.Duration(policy.ExpiresIn) * time.Second)
+		grant.ExpiresAt = &expiresAt
+	}
+
+	grant, created, err = persistence.CreateTrialGrant(tx, grant)
+	if err != nil || !created {
+		return err
+	}
+
+	change, err := updateCredits(tx, handle, application, int(policy.Credits), models.OperationTrial)
+	if err != nil {
+		return err
+	}
+	return persistence.SetTrialGrantTransaction(tx, grant, change.ID)
+}
+
+// ensureCustomer creates the given customer with createCustomer if it doesn't exist yet.
+func ensureCustomer(tx *gorm.DB, handle, application string) error {
+	_, err := persistence.GetCustomer(tx, handle, application)
+	if err == gorm.ErrRecordNotFound {
+		return createCustomer(tx, handle, application)
+	}
+	return err
+}
+
+// toTrialPolicyAPI converts the given trial policy model into its API representation.
+func toTrialPolicyAPI(policy models.TrialPolicy) api.TrialPolicy {
+	return api.TrialPolicy{
+		Application: policy.Application,
+		Credits:     policy.Credits,
+		ExpiresIn:   policy.ExpiresIn,
+	}
+}
+
+// toTrialGrantAPI converts the given trial grant model into its API representation.
+func toTrialGrantAPI(grant models.TrialGrant) api.TrialGrant {
+	return api.TrialGrant{
+		Handle:         grant.Handle,
+		Application:    grant.Application,
+		PreviousHandle: grant.PreviousHandle,
+		Credits:        grant.Credits,
+		ExpiresAt:      grant.ExpiresAt,
+		ExpiredAt:      grant.ExpiredAt,
+		Expired:        grant.Expired,
+		CreatedAt:      grant.CreatedAt,
+	}
+}
+
+// trialExpirationBatchSize is the maximum amount of trial grants expired on every run of the trial expirer.
+const trialExpirationBatchSize = 100
+
+// errTrialGrantSkipped is returned when a trial grant has already expired.
+var errTrialGrantSkipped = errors.New("trial grant skipped")
+
+// trialExpirer is a Worker that removes the unused welcome credits once they expire.
+type trialExpirer struct {
+	db     *gorm.DB
+	logger *log.Logger
+}
+
+// Run expires welcome credits every interval until ctx is done.
+func (w *trialExpirer) Run(ctx context.Context, interval time.Duration) {
+	runPeriodically(ctx, interval, w.logger, "Trial expiration", w.RunOnce)
+}
+
+// RunOnce removes the unused credits of the trial grants that expired.
+func (w *trialExpirer) RunOnce(ctx context.Context) error {
+	now := time.Now().UTC()
+	grants, err := persistence.GetExpiredTrialGrants(w.db, now, trialExpirationBatchSize)
+	if err != nil {
+		return err
+	}
+
+	for _, grant := range grants {
+		err = w.expire(grant.ID, now)
+		if err != nil && err != errTrialGrantSkipped {
+			w.logger.Println("Failed to expire trial grant:", grant.ID, "Error:", err)
+		}
+	}
+	return nil
+}
+
+// expire removes the unused credits of the given trial grant.
+func (w *trialExpirer) expire(id uint, now time.Time) error {
+	return w.db.Transaction(func(tx *gorm.DB) error {
+		grant, err := persistence.GetTrialGrantForUpdate(tx, id)
+		if err != nil {
+			return err
+		}
+		if grant.ExpiredAt != nil {
+			return errTrialGrantSkipped
+		}
+
+		if err = lockLedger(tx, grant.Application); err != nil {
+			return err
+		}
+		c, err := persistence.GetCustomerForUpdate(tx, grant.Handle, grant.Application)
+		if err != nil && err != gorm.ErrRecordNotFound {
+			return err
+		}
+
+		var spent uint
+		if grant.TransactionID != nil {
+			spent, err = persistence.SumDebitsAfter(tx, grant.Handle, grant.Application, grantSpendingOperations, *grant.TransactionID)
+			if err != nil {
+				return err
+			}
+		}
+
+		unused := unusedCredits(grant.Credits, spent, c.Credits)
+		if unused > 0 {
+			_, err = updateCredits(tx, grant.Handle, grant.Application, -int(unused), models.OperationExpiration)
+			if err != nil {
+				return err
+			}
+		}
+
+		grant.ExpiredAt = &now
+		grant.Expired = unused
+		return persistence.ExpireTrialGrant(tx, grant)
+	})
+}
+
+// NewTrialExpirer initializes a new Worker that removes the unused welcome credits once they expire.
+func NewTrialExpirer(db *gorm.DB, logger *log.Logger) Worker {
+	if logger == nil {
+		logger = log.New(io.Discard, "", log.LstdFlags)
+	}
+	return &trialExpirer{
+		db:     db,
+		logger: logger,
+	}
+}
@@ -0,0 +1,170 @@
+package application
+
+import (
+	"context"
+	"github.com/stretchr/testify/suite"
+	"gitlab.com/ignitionrobotics/billing/credits/internal/conf"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/api"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/domain/models"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/domain/persistence"
+	"gorm.io/gorm"
+	"log"
+	"os"
+	"testing"
+	"time"
+)
+
+type testTrialsSuite struct {
+	suite.Suite
+	DB      *gorm.DB
+	Logger  *log.Logger
+	Service Service
+}
+
+func TestTrials(t *testing.T) {
+	suite.Run(t, new(testTrialsSuite))
+}
+
+func (s *testTrialsSuite) SetupSuite() {
+	s.Logger = log.New(os.Stdout, "[TestTrials] ", log.LstdFlags|log.Lshortfile|log.Lmsgprefix)
+
+	var c conf.Config
+	s.Require().NoError(c.Parse())
+
+	var err error
+	s.DB, err = persistence.OpenConn(c.Database)
+	s.Require().NoError(err)
+
+	s.Require().NoError(persistence.DropTables(s.DB))
+}
+
+func (s *testTrialsSuite) SetupTest() {
+	s.Require().NoError(persistence.MigrateTables(s.DB))
+	s.Service = NewCreditsService(s.DB, s.Logger, 1)
+
+	_, err := persistence.CreateCustomer(s.DB, models.Customer{
+		Handle:      "existing",
+		Application: "cloudsim",
+		Credits:     100,
+	})
+	s.Require().NoError(err)
+}
+
+func (s *testTrialsSuite) TearDownTest() {
+	s.Require().NoError(persistence.DropTables(s.DB))
+}
+
+func (s *testTrialsSuite) setPolicy(credits, expiresIn uint) {
+	_, err := s.Service.SetTrialPolicy(context.Background(), api.SetTrialPolicyRequest{
+		TrialPolicy: api.TrialPolicy{Application: "cloudsim", Credits: credits, ExpiresIn: expiresIn},
+	})
+	s.Require().NoError(err)
+}
+
+func (s *testTrialsSuite) increase(handle string, amount uint) api.IncreaseCreditsResponse {
+	res, err := s.Service.IncreaseCredits(context.Background(), api.IncreaseCreditsRequest{
+		Transaction: api.Transaction{Handle: handle, Amount: amount, Currency: "usd", Application: "cloudsim"},
+	})
+	s.Require().NoError(err)
+	return res
+}
+
+func (s *testTrialsSuite) TestWelcomeCreditsOnFirstOperation() {
+	s.setPolicy(500, 0)
+
+	res := s.increase("new", 10)
+	s.Assert().Equal(510, res.Balance)
+
+	res = s.increase("new", 10)
+	s.Assert().Equal(520, res.Balance)
+
+	// Existing customers don't receive welcome credits
+	res = s.increase("existing", 10)
+	s.Assert().Equal(110, res.Balance)
+
+	grant, err := s.Service.GetTrialGrant(context.Background(), api.GetTrialGrantRequest{Handle: "new", Application: "cloudsim"})
+	s.Require().NoError(err)
+	s.Assert().Equal(uint(500), grant.Credits)
+	s.Assert().Nil(grant.ExpiresAt)
+}
+
+func (s *testTrialsSuite) TestWelcomeCreditsCanBeSpentOnFirstDecrease() {
+	s.setPolicy(500, 0)
+
+	res, err := s.Service.DecreaseCredits(context.Background(), api.DecreaseCreditsRequest{
+		Transaction: api.Transaction{Handle: "new", Amount: 200, Currency: "usd", Application: "cloudsim"},
+	})
+	s.Require().NoError(err)
+	s.Assert().Equal(300, res.Balance)
+}
+
+func (s *testTrialsSuite) TestNoTrialAfterDeletion() {
+	s.setPolicy(500, 0)
+	s.increase("new", 10)
+
+	s.Require().NoError(s.DB.Where("handle = ? AND application = ?", "new", "cloudsim").Delete(&models.Customer{}).Error)
+
+	res := s.increase("new", 10)
+	s.Assert().Equal(10, res.Balance)
+}
+
+func (s *testTrialsSuite) TestNoTrialAfterHandleChange() {
+	s.setPolicy(500, 0)
+	s.increase("old", 10)
+
+	_, err := s.Service.RecordHandleChange(context.Background(), api.RecordHandleChangeRequest{
+		Application:    "cloudsim",
+		PreviousHandle: "old",
+		Handle:         "renamed",
+	})
+	s.Require().NoError(err)
+
+	res := s.increase("renamed", 10)
+	s.Assert().Equal(10, res.Balance)
+
+	grant, err := s.Service.GetTrialGrant(context.Background(), api.GetTrialGrantRequest{Handle: "renamed", Application: "cloudsim"})
+	s.Require().NoError(err)
+	s.Assert().Equal("old", grant.PreviousHandle)
+	s.Assert().Zero(grant.Credits)
+
+	_, err = s.Service.RecordHandleChange(context.Background(), api.RecordHandleChangeRequest{
+		Application:    "cloudsim",
+		PreviousHandle: "unknown",
+		Handle:         "other",
+	})
+	s.Assert().Equal(api.ErrTrialGrantNotFound, err)
+}
+
+func (s *testTrialsSuite) TestExpiration() {
+	s.setPolicy(500, 60)
+	s.increase("new", 100)
+
+	_, err := s.Service.DecreaseCredits(context.Background(), api.DecreaseCreditsRequest{
+		Transaction: api.Transaction{Handle: "new", Amount: 150, Currency: "usd", Application: "cloudsim"},
+	})
+	s.Require().NoError(err)
+
+	expirer := NewTrialExpirer(s.DB, s.Logger)
+
+	// Not expired yet
+	s.Require().NoError(expirer.RunOnce(context.Background()))
+	c, err := persistence.GetCustomer(s.DB, "new", "cloudsim")
+	s.Require().NoError(err)
+	s.Assert().Equal(450, c.Credits)
+
+	s.Require().NoError(s.DB.Model(&models.TrialGrant{}).Where("handle = ?", "new").
+		Update("expires_at", time.Now().Add(-time.Minute)).Error)
+
+	s.Require().NoError(expirer.RunOnce(context.Background()))
+	s.Require().NoError(expirer.RunOnce(context.Background()))
+
+	// The 150 credits spent came from the welcome credits, the purchased ones are kept.
+	c, err = persistence.GetCustomer(s.DB, "new", "cloudsim")
+	s.Require().NoError(err)
+	s.Assert().Equal(100, c.Credits)
+
+	grant, err := s.Service.GetTrialGrant(context.Background(), api.GetTrialGrantRequest{Handle: "new", Application: "cloudsim"})
+	s.Require().NoError(err)
+	s.Assert().Equal(uint(350), grant.Expired)
+	s.Assert().NotNil(grant.ExpiredAt)
+}
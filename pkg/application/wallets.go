@@ -0,0 +1,199 @@
+package application
+
+import (
+	"context"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/api"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/domain/models"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/domain/persistence"
+	"gorm.io/gorm"
+)
+
+// SetWalletMember adds a member to a wallet, or updates the allowance of an existing member. The credits already
+// spent by the member are kept.
+func (s *service) SetWalletMember(ctx context.Context, req api.SetWalletMemberRequest) (api.SetWalletMemberResponse, error) {
+	if err := req.Validate(); err != nil {
+		return api.SetWalletMemberResponse{}, err
+	}
+
+	var member models.WalletMember
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := shareConversionPolicy(tx, req.Application); err != nil {
+			return err
+		}
+		var err error
+		member, err = persistence.SetWalletMember(tx, models.WalletMember{
+			Application: req.Application,
+			Wallet:      req.Wallet,
+			Member:      req.Member,
+			Allowance:   req.Allowance,
+		})
+		return err
+	})
+	if err != nil {
+		return api.SetWalletMemberResponse{}, err
+	}
+
+	return api.SetWalletMemberResponse{WalletMember: toWalletMemberAPI(member)}, nil
+}
+
+// RemoveWalletMember removes a member from a wallet. The spending already attributed to the member is kept.
+func (s *service) RemoveWalletMember(ctx context.Context, req api.RemoveWalletMemberRequest) (api.RemoveWalletMemberResponse, error) {
+	member, err := persistence.GetWalletMember(s.db, req.Application, req.Wallet, req.Member)
+	if err == gorm.ErrRecordNotFound {
+		return api.RemoveWalletMemberResponse{}, api.ErrNotWalletMember
+	}
+	if err != nil {
+		return api.RemoveWalletMemberResponse{}, err
+	}
+
+	if err = persistence.DeleteWalletMember(s.db, member); err != nil {
+		return api.RemoveWalletMemberResponse{}, err
+	}
+	return api.RemoveWalletMemberResponse{}, nil
+}
+
+// ListWalletMembers returns the members of a wallet.
+func (s *service) ListWalletMembers(ctx context.Context, req api.ListWalletMembersRequest) (api.ListWalletMembersResponse, error) {
+	if len(req.Application) == 0 {
+		return api.ListWalletMembersResponse{}, api.ErrMissingApplication
+	}
+	if len(req.Wallet) == 0 {
+		return api.ListWalletMembersResponse{}, api.ErrMissingWallet
+	}
+
+	list, err := persistence.GetWalletMembers(s.db, req.Application, req.Wallet)
+	if err != nil {
+		return api.ListWalletMembersResponse{}, err
+	}
+
+	out := api.ListWalletMembersResponse{Members: make([]api.WalletMember, len(list))}
+	for i, m := range list {
+		out.Members[i] = toWalletMemberAPI(m)
+	}
+	return out, nil
+}
+
+// SpendFromWallet decreases the credits of a wallet on behalf of one of its members, and attributes the spending to
+// the member. The wallet is subject to the same rules as DecreaseCredits, and members can't spend more than their
+// allowance.
+func (s *service) SpendFromWallet(ctx context.Context, req api.SpendFromWalletRequest) (api.SpendFromWalletResponse, error) {
+	if err := req.Validate(); err != nil {
+		return api.SpendFromWalletResponse{}, err
+	}
+
+	var out api.SpendFromWalletResponse
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		// Reversals lock the member after the ledger, so it's locked first here too.
+		if err := lockLedger(tx, req.Application); err != nil {
+			return err
+		}
+		member, err := persistence.GetWalletMemberForUpdate(tx, req.Application, req.Wallet, req.Handle)
+		if err == gorm.ErrRecordNotFound {
+			return api.ErrNotWalletMember
+		}
+		if err != nil {
+			return err
+		}
+
+		value, err := s.calculateCredits(tx, req.Application, req.Amount, req.Currency)
+		if err != nil {
+			return err
+		}
+		if member.Allowance != nil && member.Spent+value > *member.Allowance {
+			return api.ErrAllowanceExceeded
+		}
+
+		spender, err := persistence.GetCustomer(tx, req.Handle, req.Application)
+		if err != nil && err != gorm.ErrRecordNotFound {
+			return err
+		}
+		if err = checkCustomerStatus(spender); err != nil {
+			return err
+		}
+
+		wallet, err := lockActiveCustomer(tx, req.Wallet, req.Application)
+		if err != nil {
+			return err
+		}
+		if err = checkOverdraft(tx, wallet, value); err != nil {
+			return err
+		}
+		if err = checkSpendingLimits(tx, req.Wallet, req.Application, value); err != nil {
+			return err
+		}
+
+		change, err := updateCredits(tx, req.Wallet, req.Application, -1*int(value), models.OperationDecrease)
+		if err != nil {
+			return err
+		}
+
+		if err = persistence.AddWalletMemberSpent(tx, member, value); err != nil {
+			return err
+		}
+		member.Spent += value
+
+		_, err = persistence.CreateWalletSpend(tx, models.WalletSpend{
+			Application:   req.Application,
+			Wallet:        req.Wallet,
+			Member:        req.Handle,
+			TransactionID: change.ID,
+			Credits:       value,
+		})
+		if err != nil {
+			return err
+		}
+
+		out = api.SpendFromWalletResponse{
+			TransactionID: change.ID,
+			Balance:       change.Balance,
+			Credits:       value,
+			Member:        toWalletMemberAPI(member),
+		}
+		return nil
+	})
+	if err != nil {
+		return api.SpendFromWalletResponse{}, err
+	}
+
+	return out, nil
+}
+
+// ListWalletSpending returns the latest spending of a wallet, attributed to the members that made it.
+func (s *service) ListWalletSpending(ctx context.Context, req api.ListWalletSpendingRequest) (api.ListWalletSpendingResponse, error) {
+	if err := req.Validate(); err != nil {
+		return api.ListWalletSpendingResponse{}, err
+	}
+
+	limit := req.Limit
+	if limit == 0 {
+		limit = api.DefaultPageSize
+	}
+
+	list, err := persistence.GetWalletSpends(s.db, req.Application, req.Wallet, req.Member, limit)
+	if err != nil {
+		return api.ListWalletSpendingResponse{}, err
+	}
+
+	out := api.ListWalletSpendingResponse{Spending: make([]api.WalletSpend, len(list))}
+	for i, spend := range list {
+		out.Spending[i] = api.WalletSpend{
+			TransactionID:   spend.TransactionID,
+			Member:          spend.Member,
+			Credits:         spend.Credits,
+			ReversedCredits: spend.ReversedCredits,
+			CreatedAt:       spend.CreatedAt,
+		}
+	}
+	return out, nil
+}
+
+// toWalletMemberAPI converts the given wallet member model into its API representation.
+func toWalletMemberAPI(m models.WalletMember) api.WalletMember {
+	return api.WalletMember{
+		Application: m.Application,
+		Wallet:      m.Wallet,
+		Member:      m.Member,
+		Allowance:   m.Allowance,
+		Spent:       m.Spent,
+	}
+}
@@ -0,0 +1,179 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/suite"
+	"gitlab.com/ignitionrobotics/billing/credits/internal/conf"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/api"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/domain/models"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/domain/persistence"
+	"gorm.io/gorm"
+	"log"
+	"os"
+	"testing"
+)
+
+type testWalletsSuite struct {
+	suite.Suite
+	DB      *gorm.DB
+	Logger  *log.Logger
+	Service Service
+}
+
+func TestWallets(t *testing.T) {
+	suite.Run(t, new(testWalletsSuite))
+}
+
+func (s *testWalletsSuite) SetupSuite() {
+	s.Logger = log.New(os.Stdout, "[TestWallets] ", log.LstdFlags|log.Lshortfile|log.Lmsgprefix)
+
+	var c conf.Config
+	s.Require().NoError(c.Parse())
+
+	var err error
+	s.DB, err = persistence.OpenConn(c.Database)
+	s.Require().NoError(err)
+
+	s.Require().NoError(persistence.DropTables(s.DB))
+}
+
+func (s *testWalletsSuite) SetupTest() {
+	s.Require().NoError(persistence.MigrateTables(s.DB))
+	s.Service = NewCreditsService(s.DB, s.Logger, 1)
+
+	_, err := persistence.CreateCustomer(s.DB, models.Customer{
+		Handle:      "team",
+		Application: "cloudsim",
+		Credits:     100,
+	})
+	s.Require().NoError(err)
+
+	allowance := uint(30)
+	for _, req := range []api.SetWalletMemberRequest{
+		{Application: "cloudsim", Wallet: "team", Member: "alice"},
+		{Application: "cloudsim", Wallet: "team", Member: "bob", Allowance: &allowance},
+	} {
+		_, err = s.Service.SetWalletMember(context.Background(), req)
+		s.Require().NoError(err)
+	}
+}
+
+func (s *testWalletsSuite) TearDownTest() {
+	s.Require().NoError(persistence.DropTables(s.DB))
+}
+
+func (s *testWalletsSuite) spend(member string, amount uint) (api.SpendFromWalletResponse, error) {
+	return s.Service.SpendFromWallet(context.Background(), api.SpendFromWalletRequest{
+		Transaction: api.Transaction{
+			Handle:      member,
+			Amount:      amount,
+			Currency:    "usd",
+			Application: "cloudsim",
+		},
+		Wallet: "team",
+	})
+}
+
+func (s *testWalletsSuite) TestSpendingIsAttributed() {
+	res, err := s.spend("alice", 40)
+	s.Require().NoError(err)
+	s.Assert().Equal(60, res.Balance)
+	s.Assert().Equal(uint(40), res.Member.Spent)
+
+	_, err = s.spend("bob", 20)
+	s.Require().NoError(err)
+
+	spending, err := s.Service.ListWalletSpending(context.Background(), api.ListWalletSpendingRequest{
+		Application: "cloudsim",
+		Wallet:      "team",
+	})
+	s.Require().NoError(err)
+	s.Require().Len(spending.Spending, 2)
+	s.Assert().Equal("bob", spending.Spending[0].Member)
+	s.Assert().Equal(uint(20), spending.Spending[0].Credits)
+	s.Assert().Equal("alice", spending.Spending[1].Member)
+	s.Assert().Equal(res.TransactionID, spending.Spending[1].TransactionID)
+
+	// Members keep their own balance untouched
+	_, err = persistence.GetCustomer(s.DB, "alice", "cloudsim")
+	s.Assert().Equal(gorm.ErrRecordNotFound, err)
+
+	members, err := s.Service.ListWalletMembers(context.Background(), api.ListWalletMembersRequest{
+		Application: "cloudsim",
+		Wallet:      "team",
+	})
+	s.Require().NoError(err)
+	s.Require().Len(members.Members, 2)
+	s.Assert().Equal(uint(40), members.Members[0].Spent)
+	s.Assert().Equal(uint(20), members.Members[1].Spent)
+}
+
+func (s *testWalletsSuite) TestAllowance() {
+	_, err := s.spend("bob", 20)
+	s.Require().NoError(err)
+
+	_, err = s.spend("bob", 20)
+	s.Assert().Equal(api.ErrAllowanceExceeded, err)
+
+	c, err := persistence.GetCustomer(s.DB, "team", "cloudsim")
+	s.Require().NoError(err)
+	s.Assert().Equal(80, c.Credits)
+}
+
+func (s *testWalletsSuite) TestReverseSpend() {
+	res, err := s.spend("bob", 20)
+	s.Require().NoError(err)
+
+	_, err = s.Service.ReverseTransaction(context.Background(), api.ReverseTransactionRequest{
+		TransactionID: res.TransactionID,
+		Credits:       15,
+	})
+	s.Require().NoError(err)
+
+	member, err := persistence.GetWalletMember(s.DB, "cloudsim", "team", "bob")
+	s.Require().NoError(err)
+	s.Assert().Equal(uint(5), member.Spent)
+
+	spending, err := s.Service.ListWalletSpending(context.Background(), api.ListWalletSpendingRequest{
+		Application: "cloudsim",
+		Wallet:      "team",
+	})
+	s.Require().NoError(err)
+	s.Require().Len(spending.Spending, 1)
+	s.Assert().Equal(uint(15), spending.Spending[0].ReversedCredits)
+
+	// The reversed credits can be spent again
+	_, err = s.spend("bob", 25)
+	s.Assert().NoError(err)
+}
+
+func (s *testWalletsSuite) TestWalletRules() {
+	_, err := s.spend("carol", 10)
+	s.Assert().Equal(api.ErrNotWalletMember, err)
+
+	// The wallet can't go below its overdraft limit
+	_, err = s.spend("alice", 101)
+	s.Assert().Equal(api.ErrInsufficientCredits, err)
+
+	_, err = s.Service.SetCustomerStatus(context.Background(), api.SetCustomerStatusRequest{
+		Handle:      "team",
+		Application: "cloudsim",
+		Status:      api.CustomerFrozen,
+		Reason:      "test",
+	})
+	s.Require().NoError(err)
+
+	_, err = s.spend("alice", 10)
+	s.Assert().True(errors.Is(err, api.ErrCustomerFrozen))
+
+	_, err = s.Service.RemoveWalletMember(context.Background(), api.RemoveWalletMemberRequest{
+		Application: "cloudsim",
+		Wallet:      "team",
+		Member:      "alice",
+	})
+	s.Require().NoError(err)
+
+	_, err = s.spend("alice", 10)
+	s.Assert().Equal(api.ErrNotWalletMember, err)
+}
@@ -0,0 +1,426 @@
+package application
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/api"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/domain/models"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/domain/persistence"
+	"gorm.io/gorm"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// CreateWebhook registers a new webhook for an application. A random secret is generated to sign its events. The
+// secret is only returned here, so it can't be read back later.
+func (s *service) CreateWebhook(ctx context.Context, req api.CreateWebhookRequest) (api.CreateWebhookResponse, error) {
+	if err := req.Validate(); err != nil {
+		return api.CreateWebhookResponse{}, err
+	}
+
+	secret, err := randomToken(32)
+	if err != nil {
+		return api.CreateWebhookResponse{}, err
+	}
+
+	events := make([]string, len(req.Events))
+	for i, e := range req.Events {
+		events[i] = string(e)
+	}
+
+	webhook, err := persistence.CreateWebhook(s.db, models.Webhook{
+		Application: req.Application,
+		URL:         req.URL,
+		Secret:      secret,
+		Events:      strings.Join(events, ","),
+	})
+	if err != nil {
+		return api.CreateWebhookResponse{}, err
+	}
+
+	out := api.CreateWebhookResponse{Webhook: toWebhookAPI(webhook)}
+	out.Secret = webhook.Secret
+	return out, nil
+}
+
+// ListWebhooks returns the webhooks of an application.
+func (s *service) ListWebhooks(ctx context.Context, req api.ListWebhooksRequest) (api.ListWebhooksResponse, error) {
+	if len(req.Application) == 0 {
+		return api.ListWebhooksResponse{}, api.ErrMissingApplication
+	}
+
+	list, err := persistence.GetWebhooks(s.db, req.Application)
+	if err != nil {
+		return api.ListWebhooksResponse{}, err
+	}
+
+	out := api.ListWebhooksResponse{Webhooks: make([]api.Webhook, len(list))}
+	for i, w := range list {
+		out.Webhooks[i] = toWebhookAPI(w)
+	}
+	return out, nil
+}
+
+// DeleteWebhook removes a webhook. Its pending deliveries are discarded by the dispatcher.
+func (s *service) DeleteWebhook(ctx context.Context, req api.DeleteWebhookRequest) (api.DeleteWebhookResponse, error) {
+	webhook, err := persistence.GetWebhook(s.db, req.ID)
+	if err == gorm.ErrRecordNotFound {
+		return api.DeleteWebhookResponse{}, api.ErrWebhookNotFound
+	}
+	if err != nil {
+		return api.DeleteWebhookResponse{}, err
+	}
+
+	if err = persistence.DeleteWebhook(s.db, webhook); err != nil {
+		return api.DeleteWebhookResponse{}, err
+	}
+	return api.DeleteWebhookResponse{}, nil
+}
+
+// ListWebhookDeliveries returns the delivery log of a webhook.
+func (s *service) ListWebhookDeliveries(ctx context.Context, req api.ListWebhookDeliveriesRequest) (api.ListWebhookDeliveriesResponse, error) {
+	if req.Limit < 0 || req.Limit > api.MaxPageSize {
+		return api.ListWebhookDeliveriesResponse{}, api.ErrInvalidLimit
+	}
+	limit := req.Limit
+	if limit == 0 {
+		limit = api.DefaultPageSize
+	}
+
+	if _, err := persistence.GetWebhook(s.db, req.WebhookID); err == gorm.ErrRecordNotFound {
+		return api.ListWebhookDeliveriesResponse{}, api.ErrWebhookNotFound
+	} else if err != nil {
+		return api.ListWebhookDeliveriesResponse{}, err
+	}
+
+	list, err := persistence.GetWebhookDeliveries(s.db, req.WebhookID, string(req.Status), limit)
+	if err != nil {
+		return api.ListWebhookDeliveriesResponse{}, err
+	}
+
+	out := api.ListWebhookDeliveriesResponse{Deliveries: make([]api.WebhookDelivery, len(list))}
+	for i, d := range list {
+		if out.Deliveries[i], err = toWebhookDeliveryAPI(d); err != nil {
+			return api.ListWebhookDeliveriesResponse{}, err
+		}
+	}
+	return out, nil
+}
+
+// ReplayWebhookDelivery schedules a delivery to be sent again as soon as possible, resetting its attempts.
+func (s *service) ReplayWebhookDelivery(ctx context.Context, req api.ReplayWebhookDeliveryRequest) (api.ReplayWebhookDeliveryResponse, error) {
+	delivery, err := persistence.GetWebhookDelivery(s.db, req.ID)
+	if err == gorm.ErrRecordNotFound {
+		return api.ReplayWebhookDeliveryResponse{}, api.ErrDeliveryNotFound
+	}
+	if err != nil {
+		return api.ReplayWebhookDeliveryResponse{}, err
+	}
+
+	delivery.Status = string(api.DeliveryPending)
+	delivery.Attempts = 0
+	delivery.NextAttemptAt = time.Now()
+	delivery.LastError = ""
+	delivery.ResponseCode = 0
+
+	delivery, err = persistence.SaveWebhookDelivery(s.db, delivery)
+	if err != nil {
+		return api.ReplayWebhookDeliveryResponse{}, err
+	}
+
+	out, err := toWebhookDeliveryAPI(delivery)
+	if err != nil {
+		return api.ReplayWebhookDeliveryResponse{}, err
+	}
+	return api.ReplayWebhookDeliveryResponse{WebhookDelivery: out}, nil
+}
+
+// enqueueWebhookEvents creates the deliveries of the events triggered by the given balance change for every webhook
+// of the customer's application. low is true if the change left the customer below its low balance threshold.
+func enqueueWebhookEvents(tx *gorm.DB, change models.BalanceChange, low bool) error {
+	webhooks, err := persistence.GetWebhooks(tx, change.Application)
+	if err != nil || len(webhooks) == 0 {
+		return err
+	}
+
+	for _, webhook := range webhooks {
+		for _, t := range webhookEventTypes(webhook, change, low) {
+			event, payload, err := newBalanceEvent(t, change)
+			if err != nil {
+				return err
+			}
+			_, err = persistence.CreateWebhookDelivery(tx, models.WebhookDelivery{
+				WebhookID:     webhook.ID,
+				EventID:       event.ID,
+				EventType:     string(t),
+				Payload:       string(payload),
+				Status:        string(api.DeliveryPending),
+				NextAttemptAt: change.CreatedAt,
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// webhookEventTypes returns the event types triggered by the given balance change that the given webhook is
+// subscribed to. low is true if the change left the customer below its low balance threshold, as found by
+// checkLowBalance.
+func webhookEventTypes(webhook models.Webhook, change models.BalanceChange, low bool) []api.EventType {
+	previous := change.Balance - change.Value
+
+	var types []api.EventType
+	switch {
+	case change.Value > 0:
+		types = append(types, api.EventCreditsIncreased)
+	case change.Value < 0:
+		types = append(types, api.EventCreditsDecreased)
+	}
+	if low {
+		types = append(types, api.EventBalanceLow)
+	}
+	if previous > 0 && change.Balance <= 0 {
+		types = append(types, api.EventBalanceDepleted)
+	}
+
+	if len(webhook.Events) == 0 {
+		return types
+	}
+
+	subscribed := strings.Split(webhook.Events, ",")
+	var out []api.EventType
+	for _, t := range types {
+		for _, e := range subscribed {
+			if string(t) == e {
+				out = append(out, t)
+				break
+			}
+		}
+	}
+	return out
+}
+
+// toWebhookAPI converts the given webhook model into its API representation. The secret is left out.
+func toWebhookAPI(webhook models.Webhook) api.Webhook {
+	out := api.Webhook{
+		ID:          webhook.ID,
+		Application: webhook.Application,
+		URL:         webhook.URL,
+		Events:      []api.EventType{},
+	}
+	if len(webhook.Events) > 0 {
+		for _, e := range strings.Split(webhook.Events, ",") {
+			out.Events = append(out.Events, api.EventType(e))
+		}
+	}
+	return out
+}
+
+// toWebhookDeliveryAPI converts the given delivery model into its API representation.
+func toWebhookDeliveryAPI(delivery models.WebhookDelivery) (api.WebhookDelivery, error) {
+	var event api.Event
+	if err := json.Unmarshal([]byte(delivery.Payload), &event); err != nil {
+		return api.WebhookDelivery{}, err
+	}
+	return api.WebhookDelivery{
+		ID:            delivery.ID,
+		WebhookID:     delivery.WebhookID,
+		Event:         event,
+		Status:        api.DeliveryStatus(delivery.Status),
+		Attempts:      delivery.Attempts,
+		NextAttemptAt: delivery.NextAttemptAt,
+		ResponseCode:  delivery.ResponseCode,
+		LastError:     delivery.LastError,
+		DeliveredAt:   delivery.DeliveredAt,
+	}, nil
+}
+
+// randomToken returns a hex encoded random token of n bytes.
+func randomToken(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// webhookBatchSize is the maximum amount of deliveries sent in a single run of the webhook dispatcher.
+const webhookBatchSize = 100
+
+// maxWebhookBackoff is the maximum time between two attempts of the same delivery.
+const maxWebhookBackoff = 6 * time.Hour
+
+// minWebhookLease is the minimum time a claimed delivery is hidden from other dispatchers.
+const minWebhookLease = time.Minute
+
+// webhookDispatcher is a Worker that sends pending webhook deliveries.
+type webhookDispatcher struct {
+	db          *gorm.DB
+	logger      *log.Logger
+	client      *http.Client
+	maxAttempts int
+	backoff     time.Duration
+}
+
+// Run sends the pending deliveries every interval until ctx is done.
+func (d *webhookDispatcher) Run(ctx context.Context, interval time.Duration) {
+	runPeriodically(ctx, interval, d.logger, "Webhook dispatching", d.RunOnce)
+}
+
+// RunOnce sends the deliveries that are due. Failed deliveries are retried with exponential backoff until they
+// reach the maximum amount of attempts. Deliveries are claimed before being sent, so concurrent dispatchers don't
+// send the same delivery twice.
+func (d *webhookDispatcher) RunOnce(ctx context.Context) error {
+	deliveries, err := d.claim(time.Now())
+	if err != nil {
+		return err
+	}
+
+	webhooks := make(map[uint]*models.Webhook)
+	for _, delivery := range deliveries {
+		webhook, ok := webhooks[delivery.WebhookID]
+		if !ok {
+			w, err := persistence.GetWebhook(d.db, delivery.WebhookID)
+			if err != nil && err != gorm.ErrRecordNotFound {
+				return err
+			}
+			if err == nil {
+				webhook = &w
+			}
+			webhooks[delivery.WebhookID] = webhook
+		}
+
+		now := time.Now()
+		if webhook == nil {
+			delivery.Status = string(api.DeliveryFailed)
+			delivery.LastError = api.ErrWebhookNotFound.Error()
+		} else {
+			d.deliver(ctx, *webhook, &delivery, now)
+		}
+
+		if _, err = persistence.SaveWebhookDelivery(d.db, delivery); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// claim returns the deliveries due at the given time, postponing their next attempt until they have all been sent.
+// Other dispatchers skip them until then, and they become due again if this dispatcher stops before sending them.
+func (d *webhookDispatcher) claim(now time.Time) ([]models.WebhookDelivery, error) {
+	var deliveries []models.WebhookDelivery
+	err := d.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		deliveries, err = persistence.GetDueWebhookDeliveriesForUpdate(tx, string(api.DeliveryPending), now, webhookBatchSize)
+		if err != nil || len(deliveries) == 0 {
+			return err
+		}
+
+		lease := now.Add(webhookLease(d.client.Timeout, len(deliveries)))
+		ids := make([]uint, len(deliveries))
+		for i := range deliveries {
+			ids[i] = deliveries[i].ID
+			deliveries[i].NextAttemptAt = lease
+		}
+		return persistence.SetWebhookDeliveriesNextAttempt(tx, ids, lease)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// deliver sends the given delivery to the webhook and updates its status with the result.
+func (d *webhookDispatcher) deliver(ctx context.Context, webhook models.Webhook, delivery *models.WebhookDelivery, now time.Time) {
+	delivery.Attempts++
+
+	code, err := d.send(ctx, webhook, *delivery, now)
+	delivery.ResponseCode = code
+	if err == nil {
+		delivery.Status = string(api.DeliverySucceeded)
+		delivery.LastError = ""
+		delivery.DeliveredAt = &now
+		return
+	}
+
+	d.logger.Println("Failed to deliver webhook event:", delivery.EventID, "Webhook:", webhook.ID, "Error:", err)
+	delivery.LastError = err.Error()
+	if delivery.Attempts >= d.maxAttempts {
+		delivery.Status = string(api.DeliveryFailed)
+		return
+	}
+	delivery.NextAttemptAt = now.Add(webhookBackoff(d.backoff, delivery.Attempts))
+}
+
+// send performs the HTTP request of the given delivery. It returns the response status code.
+func (d *webhookDispatcher) send(ctx context.Context, webhook models.Webhook, delivery models.WebhookDelivery, now time.Time) (int, error) {
+	payload := []byte(delivery.Payload)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewBuffer(payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(api.SignatureHeader, api.SignPayload(webhook.Secret, now, payload))
+	req.Header.Set(api.EventHeader, delivery.EventType)
+	req.Header.Set(api.DeliveryHeader, fmt.Sprint(delivery.ID))
+
+	res, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+	_, _ = io.Copy(io.Discard, res.Body)
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return res.StatusCode, fmt.Errorf("unexpected status code: %d", res.StatusCode)
+	}
+	return res.StatusCode, nil
+}
+
+// webhookLease returns how long the given amount of claimed deliveries are hidden from other dispatchers. Every
+// request is bounded by the client timeout, so the deliveries are sent before the lease expires. Clients without
+// timeout get minWebhookLease.
+func webhookLease(timeout time.Duration, deliveries int) time.Duration {
+	lease := time.Duration(deliveries+1) * timeout
+	if lease < minWebhookLease {
+		return minWebhookLease
+	}
+	return lease
+}
+
+// webhookBackoff returns the time to wait before the next attempt of a delivery that has been attempted the given
+// amount of times. It doubles on every attempt, up to maxWebhookBackoff.
+func webhookBackoff(base time.Duration, attempts int) time.Duration {
+	backoff := base
+	for i := 1; i < attempts; i++ {
+		backoff *= 2
+		if backoff >= maxWebhookBackoff {
+			return maxWebhookBackoff
+		}
+	}
+	return backoff
+}
+
+// NewWebhookDispatcher initializes a new Worker that sends webhook deliveries. Deliveries are attempted up to
+// maxAttempts times, waiting an exponentially growing time starting at backoff between attempts.
+func NewWebhookDispatcher(db *gorm.DB, logger *log.Logger, timeout time.Duration, maxAttempts int, backoff time.Duration) Worker {
+	if logger == nil {
+		logger = log.New(io.Discard, "", log.LstdFlags)
+	}
+	return &webhookDispatcher{
+		db:          db,
+		logger:      logger,
+		client:      &http.Client{Timeout: timeout},
+		maxAttempts: maxAttempts,
+		backoff:     backoff,
+	}
+}
@@ -0,0 +1,278 @@
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"gitlab.com/ignitionrobotics/billing/credits/internal/conf"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/api"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/domain/models"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/domain/persistence"
+	"gorm.io/gorm"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWebhookBackoff(t *testing.T) {
+	assert.Equal(t, time.Minute, webhookBackoff(time.Minute, 1))
+	assert.Equal(t, 2*time.Minute, webhookBackoff(time.Minute, 2))
+	assert.Equal(t, 8*time.Minute, webhookBackoff(time.Minute, 4))
+	assert.Equal(t, maxWebhookBackoff, webhookBackoff(time.Minute, 20))
+}
+
+func TestWebhookLease(t *testing.T) {
+	assert.Equal(t, minWebhookLease, webhookLease(0, 100))
+	assert.Equal(t, minWebhookLease, webhookLease(time.Second, 1))
+	assert.Equal(t, 101*10*time.Second, webhookLease(10*time.Second, 100))
+}
+
+func TestWebhookEventTypes(t *testing.T) {
+	var webhook models.Webhook
+
+	types := webhookEventTypes(webhook, models.BalanceChange{Value: 5, Balance: 20}, false)
+	assert.Equal(t, []api.EventType{api.EventCreditsIncreased}, types)
+
+	types = webhookEventTypes(webhook, models.BalanceChange{Value: -15, Balance: 5}, true)
+	assert.Equal(t, []api.EventType{api.EventCreditsDecreased, api.EventBalanceLow}, types)
+
+	// Already below the threshold
+	types = webhookEventTypes(webhook, models.BalanceChange{Value: -5, Balance: 0}, false)
+	assert.Equal(t, []api.EventType{api.EventCreditsDecreased, api.EventBalanceDepleted}, types)
+
+	webhook.Events = "balance.low,balance.depleted"
+	types = webhookEventTypes(webhook, models.BalanceChange{Value: -20, Balance: 0}, true)
+	assert.Equal(t, []api.EventType{api.EventBalanceLow, api.EventBalanceDepleted}, types)
+}
+
+// webhookReceiver is an HTTP server that records the events it receives.
+type webhookReceiver struct {
+	*httptest.Server
+	secret string
+	status int
+	lock   sync.Mutex
+	events []api.Event
+	errors []error
+}
+
+func (r *webhookReceiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	body, err := io.ReadAll(req.Body)
+	if err == nil {
+		err = api.VerifySignature(r.secret, req.Header.Get(api.SignatureHeader), body, time.Minute)
+	}
+	if err != nil {
+		r.errors = append(r.errors, err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	var event api.Event
+	if err = json.Unmarshal(body, &event); err != nil {
+		r.errors = append(r.errors, err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	r.events = append(r.events, event)
+	w.WriteHeader(r.status)
+}
+
+type testWebhooksSuite struct {
+	suite.Suite
+	DB         *gorm.DB
+	Logger     *log.Logger
+	Service    Service
+	Dispatcher Worker
+	Receiver   *webhookReceiver
+	Webhook    api.Webhook
+}
+
+func TestWebhooks(t *testing.T) {
+	suite.Run(t, new(testWebhooksSuite))
+}
+
+func (s *testWebhooksSuite) SetupSuite() {
+	s.Logger = log.New(os.Stdout, "[TestWebhooks] ", log.LstdFlags|log.Lshortfile|log.Lmsgprefix)
+
+	var c conf.Config
+	s.Require().NoError(c.Parse())
+
+	var err error
+	s.DB, err = persistence.OpenConn(c.Database)
+	s.Require().NoError(err)
+
+	s.Require().NoError(persistence.DropTables(s.DB))
+}
+
+func (s *testWebhooksSuite) SetupTest() {
+	s.Require().NoError(persistence.MigrateTables(s.DB))
+	s.Service = NewCreditsService(s.DB, s.Logger, 1)
+	// A zero backoff allows retrying failed deliveries right away.
+	s.Dispatcher = NewWebhookDispatcher(s.DB, s.Logger, time.Second, 3, 0)
+
+	s.Receiver = &webhookReceiver{status: http.StatusOK}
+	s.Receiver.Server = httptest.NewServer(s.Receiver)
+
+	res, err := s.Service.CreateWebhook(context.Background(), api.CreateWebhookRequest{
+		Application: "cloudsim",
+		URL:         s.Receiver.URL,
+	})
+	s.Require().NoError(err)
+	s.Webhook = res.Webhook
+	s.Receiver.secret = res.Secret
+
+	_, err = s.Service.SetLowBalanceThreshold(context.Background(), api.SetLowBalanceThresholdRequest{
+		LowBalanceThreshold: api.LowBalanceThreshold{Application: "cloudsim", Threshold: 50},
+	})
+	s.Require().NoError(err)
+
+	_, err = persistence.CreateCustomer(s.DB, models.Customer{
+		Handle:      "test1",
+		Application: "cloudsim",
+		Credits:     100,
+	})
+	s.Require().NoError(err)
+}
+
+func (s *testWebhooksSuite) TearDownTest() {
+	s.Receiver.Close()
+	s.Require().NoError(persistence.DropTables(s.DB))
+}
+
+func (s *testWebhooksSuite) decrease(amount uint) {
+	_, err := s.Service.DecreaseCredits(context.Background(), api.DecreaseCreditsRequest{
+		Transaction: api.Transaction{
+			Handle:      "test1",
+			Amount:      amount,
+			Currency:    "usd",
+			Application: "cloudsim",
+		},
+	})
+	s.Require().NoError(err)
+}
+
+func (s *testWebhooksSuite) deliveries() []api.WebhookDelivery {
+	res, err := s.Service.ListWebhookDeliveries(context.Background(), api.ListWebhookDeliveriesRequest{
+		WebhookID: s.Webhook.ID,
+	})
+	s.Require().NoError(err)
+	return res.Deliveries
+}
+
+func (s *testWebhooksSuite) TestCreateWebhookInvalidURL() {
+	_, err := s.Service.CreateWebhook(context.Background(), api.CreateWebhookRequest{
+		Application: "cloudsim",
+		URL:         "ftp://example.com",
+	})
+	s.Assert().Equal(api.ErrInvalidURL, err)
+}
+
+func (s *testWebhooksSuite) TestListWebhooksHidesSecret() {
+	s.Assert().NotEmpty(s.Receiver.secret)
+
+	res, err := s.Service.ListWebhooks(context.Background(), api.ListWebhooksRequest{Application: "cloudsim"})
+	s.Require().NoError(err)
+	s.Require().Len(res.Webhooks, 1)
+	s.Assert().Equal(s.Webhook.ID, res.Webhooks[0].ID)
+	s.Assert().Empty(res.Webhooks[0].Secret)
+}
+
+func (s *testWebhooksSuite) TestDeliverSignedEvents() {
+	s.decrease(60)
+	s.decrease(40)
+
+	s.Require().NoError(s.Dispatcher.RunOnce(context.Background()))
+
+	s.Require().Empty(s.Receiver.errors)
+	s.Require().Len(s.Receiver.events, 4)
+	s.Assert().Equal(api.EventCreditsDecreased, s.Receiver.events[0].Type)
+	s.Assert().Equal(api.EventBalanceLow, s.Receiver.events[1].Type)
+	s.Assert().Equal(40, s.Receiver.events[1].Data.Balance)
+	s.Assert().Equal(api.EventCreditsDecreased, s.Receiver.events[2].Type)
+	s.Assert().Equal(api.EventBalanceDepleted, s.Receiver.events[3].Type)
+	s.Assert().Equal("test1", s.Receiver.events[3].Data.Handle)
+
+	for _, d := range s.deliveries() {
+		s.Assert().Equal(api.DeliverySucceeded, d.Status)
+		s.Assert().Equal(1, d.Attempts)
+		s.Assert().Equal(http.StatusOK, d.ResponseCode)
+		s.Assert().NotNil(d.DeliveredAt)
+	}
+
+	// Succeeded deliveries are not sent again
+	s.Require().NoError(s.Dispatcher.RunOnce(context.Background()))
+	s.Assert().Len(s.Receiver.events, 4)
+}
+
+func (s *testWebhooksSuite) TestClaimedDeliveriesAreSkipped() {
+	s.decrease(10)
+
+	claimed, err := s.Dispatcher.(*webhookDispatcher).claim(time.Now())
+	s.Require().NoError(err)
+	s.Require().Len(claimed, 1)
+
+	// Another dispatcher doesn't send deliveries claimed by a different one until their lease expires.
+	other := NewWebhookDispatcher(s.DB, s.Logger, time.Second, 3, 0)
+	s.Require().NoError(other.RunOnce(context.Background()))
+	s.Assert().Empty(s.Receiver.events)
+
+	deliveries := s.deliveries()
+	s.Require().Len(deliveries, 1)
+	s.Assert().Equal(api.DeliveryPending, deliveries[0].Status)
+	s.Assert().Equal(0, deliveries[0].Attempts)
+}
+
+func (s *testWebhooksSuite) TestRetryAndReplay() {
+	s.Receiver.status = http.StatusServiceUnavailable
+	s.decrease(10)
+
+	for i := 0; i < 3; i++ {
+		s.Require().NoError(s.Dispatcher.RunOnce(context.Background()))
+	}
+
+	deliveries := s.deliveries()
+	s.Require().Len(deliveries, 1)
+	s.Assert().Equal(api.DeliveryFailed, deliveries[0].Status)
+	s.Assert().Equal(3, deliveries[0].Attempts)
+	s.Assert().Equal(http.StatusServiceUnavailable, deliveries[0].ResponseCode)
+	s.Assert().NotEmpty(deliveries[0].LastError)
+
+	// Failed deliveries are not attempted again
+	s.Require().NoError(s.Dispatcher.RunOnce(context.Background()))
+	s.Assert().Len(s.Receiver.events, 3)
+
+	s.Receiver.status = http.StatusOK
+	res, err := s.Service.ReplayWebhookDelivery(context.Background(), api.ReplayWebhookDeliveryRequest{
+		ID: deliveries[0].ID,
+	})
+	s.Require().NoError(err)
+	s.Assert().Equal(api.DeliveryPending, res.Status)
+
+	s.Require().NoError(s.Dispatcher.RunOnce(context.Background()))
+	s.Assert().Len(s.Receiver.events, 4)
+
+	deliveries = s.deliveries()
+	s.Assert().Equal(api.DeliverySucceeded, deliveries[0].Status)
+	s.Assert().Equal(deliveries[0].Event.ID, s.Receiver.events[3].ID)
+}
+
+func (s *testWebhooksSuite) TestDeletedWebhook() {
+	s.decrease(10)
+
+	_, err := s.Service.DeleteWebhook(context.Background(), api.DeleteWebhookRequest{ID: s.Webhook.ID})
+	s.Require().NoError(err)
+
+	s.Require().NoError(s.Dispatcher.RunOnce(context.Background()))
+	s.Assert().Empty(s.Receiver.events)
+
+	_, err = s.Service.DeleteWebhook(context.Background(), api.DeleteWebhookRequest{ID: s.Webhook.ID})
+	s.Assert().Equal(api.ErrWebhookNotFound, err)
+}
@@ -0,0 +1,33 @@
+package application
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// Worker is a background process that performs a certain task periodically.
+type Worker interface {
+	// Run performs the worker task every interval until the given context is done.
+	Run(ctx context.Context, interval time.Duration)
+
+	// RunOnce performs a single iteration of the worker task.
+	RunOnce(ctx context.Context) error
+}
+
+// runPeriodically calls fn every interval until ctx is done. Errors returned by fn are logged and don't stop
+// the loop.
+func runPeriodically(ctx context.Context, interval time.Duration, logger *log.Logger, name string, fn func(ctx context.Context) error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := fn(ctx); err != nil {
+				logger.Printf("%s failed: %s\n", name, err)
+			}
+		}
+	}
+}
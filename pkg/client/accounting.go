@@ -0,0 +1,33 @@
+package client
+
+import (
+	"context"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/api"
+)
+
+// ListAccounts performs an HTTP request to list the accounts of an application.
+func (c *client) ListAccounts(ctx context.Context, in api.ListAccountsRequest) (api.ListAccountsResponse, error) {
+	var out api.ListAccountsResponse
+	if err := c.client.Call(ctx, "ListAccounts", &in, &out); err != nil {
+		return api.ListAccountsResponse{}, err
+	}
+	return out, nil
+}
+
+// ListEntries performs an HTTP request to list the latest entries posted in an account.
+func (c *client) ListEntries(ctx context.Context, in api.ListEntriesRequest) (api.ListEntriesResponse, error) {
+	var out api.ListEntriesResponse
+	if err := c.client.Call(ctx, "ListEntries", &in, &out); err != nil {
+		return api.ListEntriesResponse{}, err
+	}
+	return out, nil
+}
+
+// CheckLedger performs an HTTP request to check the double-entry ledger of an application is balanced.
+func (c *client) CheckLedger(ctx context.Context, in api.CheckLedgerRequest) (api.CheckLedgerResponse, error) {
+	var out api.CheckLedgerResponse
+	if err := c.client.Call(ctx, "CheckLedger", &in, &out); err != nil {
+		return api.CheckLedgerResponse{}, err
+	}
+	return out, nil
+}
@@ -51,6 +51,33 @@ func (c *client) GetBalance(ctx context.Context, in api.GetBalanceRequest) (api.
 	return out, nil
 }
 
+// GetBalances performs an HTTP request to get the balance of a list of customers.
+func (c *client) GetBalances(ctx context.Context, in api.GetBalancesRequest) (api.GetBalancesResponse, error) {
+	var out api.GetBalancesResponse
+	if err := c.client.Call(ctx, "GetBalances", &in, &out); err != nil {
+		return api.GetBalancesResponse{}, err
+	}
+	return out, nil
+}
+
+// GetBalanceAt performs an HTTP request to get the customer's balance at a certain point in time.
+func (c *client) GetBalanceAt(ctx context.Context, in api.GetBalanceAtRequest) (api.GetBalanceAtResponse, error) {
+	var out api.GetBalanceAtResponse
+	if err := c.client.Call(ctx, "GetBalanceAt", &in, &out); err != nil {
+		return api.GetBalanceAtResponse{}, err
+	}
+	return out, nil
+}
+
+// GetBalanceHistory performs an HTTP request to get the customer's balance history.
+func (c *client) GetBalanceHistory(ctx context.Context, in api.GetBalanceHistoryRequest) (api.GetBalanceHistoryResponse, error) {
+	var out api.GetBalanceHistoryResponse
+	if err := c.client.Call(ctx, "GetBalanceHistory", &in, &out); err != nil {
+		return api.GetBalanceHistoryResponse{}, err
+	}
+	return out, nil
+}
+
 // ConvertCurrency performs an HTTP request to convert a certain FIAT currency into credits units.
 func (c *client) ConvertCurrency(ctx context.Context, in api.ConvertCurrencyRequest) (api.ConvertCurrencyResponse, error) {
 	var out api.ConvertCurrencyResponse
@@ -60,9 +87,62 @@ func (c *client) ConvertCurrency(ctx context.Context, in api.ConvertCurrencyRequ
 	return out, nil
 }
 
+// Charge performs an HTTP request to charge the given user for a certain quantity of a SKU.
+func (c *client) Charge(ctx context.Context, in api.ChargeRequest) (api.ChargeResponse, error) {
+	var out api.ChargeResponse
+	if err := c.client.Call(ctx, "Charge", &in, &out); err != nil {
+		return api.ChargeResponse{}, err
+	}
+	return out, nil
+}
+
+// ExecuteBatch performs an HTTP request to apply a batch of credit operations atomically.
+func (c *client) ExecuteBatch(ctx context.Context, in api.ExecuteBatchRequest) (api.ExecuteBatchResponse, error) {
+	var out api.ExecuteBatchResponse
+	if err := c.client.Call(ctx, "ExecuteBatch", &in, &out); err != nil {
+		return api.ExecuteBatchResponse{}, err
+	}
+	return out, nil
+}
+
+// EstimateCost performs an HTTP request to estimate the cost of a SKU and check if a user can afford it.
+func (c *client) EstimateCost(ctx context.Context, in api.EstimateCostRequest) (api.EstimateCostResponse, error) {
+	var out api.EstimateCostResponse
+	if err := c.client.Call(ctx, "EstimateCost", &in, &out); err != nil {
+		return api.EstimateCostResponse{}, err
+	}
+	return out, nil
+}
+
+// ReverseTransaction performs an HTTP request to reverse a previous transaction.
+func (c *client) ReverseTransaction(ctx context.Context, in api.ReverseTransactionRequest) (api.ReverseTransactionResponse, error) {
+	var out api.ReverseTransactionResponse
+	if err := c.client.Call(ctx, "ReverseTransaction", &in, &out); err != nil {
+		return api.ReverseTransactionResponse{}, err
+	}
+	return out, nil
+}
+
 // Client holds methods to interact with the api.CreditsV1.
 type Client interface {
 	api.CreditsV1
+	api.SessionsV1
+	api.PricingV1
+	api.CustomersV1
+	api.WebhooksV1
+	api.ThresholdsV1
+	api.TopUpsV1
+	api.AccountingV1
+	api.LedgerV1
+	api.OverdraftsV1
+	api.SpendingLimitsV1
+	api.WalletsV1
+	api.SubscriptionsV1
+	api.ScheduledOperationsV1
+	api.CouponsV1
+	api.TrialsV1
+	api.PurchasePricingV1
+	api.ConversionV1
 }
 
 // NewCreditsClientV1 initializes a new api.CreditsV1 client implementation using an HTTP client.
@@ -80,6 +160,18 @@ func NewCreditsClientV1(baseURL *url.URL, timeout time.Duration) Client {
 			Method: http.MethodGet,
 			Path:   "/credits",
 		},
+		"GetBalances": {
+			Method: http.MethodPost,
+			Path:   "/credits/balances",
+		},
+		"GetBalanceAt": {
+			Method: http.MethodGet,
+			Path:   "/credits/at",
+		},
+		"GetBalanceHistory": {
+			Method: http.MethodGet,
+			Path:   "/credits/history",
+		},
 		"ConvertCurrency": {
 			Method: http.MethodPost,
 			Path:   "/credits/convert",
@@ -88,6 +180,278 @@ func NewCreditsClientV1(baseURL *url.URL, timeout time.Duration) Client {
 			Method: http.MethodPost,
 			Path:   "/credits/unit_price",
 		},
+		"Charge": {
+			Method: http.MethodPost,
+			Path:   "/credits/charge",
+		},
+		"ExecuteBatch": {
+			Method: http.MethodPost,
+			Path:   "/credits/batch",
+		},
+		"EstimateCost": {
+			Method: http.MethodPost,
+			Path:   "/credits/estimate",
+		},
+		"ReverseTransaction": {
+			Method: http.MethodPost,
+			Path:   "/credits/reverse",
+		},
+		"OpenSession": {
+			Method: http.MethodPost,
+			Path:   "/sessions/open",
+		},
+		"SendHeartbeat": {
+			Method: http.MethodPost,
+			Path:   "/sessions/heartbeat",
+		},
+		"CloseSession": {
+			Method: http.MethodPost,
+			Path:   "/sessions/close",
+		},
+		"GetSession": {
+			Method: http.MethodGet,
+			Path:   "/sessions",
+		},
+		"CreateSKU": {
+			Method: http.MethodPost,
+			Path:   "/skus/create",
+		},
+		"GetSKU": {
+			Method: http.MethodGet,
+			Path:   "/skus/detail",
+		},
+		"ListSKUs": {
+			Method: http.MethodGet,
+			Path:   "/skus",
+		},
+		"UpdateSKU": {
+			Method: http.MethodPost,
+			Path:   "/skus/update",
+		},
+		"DeleteSKU": {
+			Method: http.MethodPost,
+			Path:   "/skus/delete",
+		},
+		"SetSKUPrice": {
+			Method: http.MethodPost,
+			Path:   "/skus/price",
+		},
+		"ListCustomers": {
+			Method: http.MethodGet,
+			Path:   "/customers",
+		},
+		"SetCustomerStatus": {
+			Method: http.MethodPost,
+			Path:   "/customers/status",
+		},
+		"ListCustomerStatusChanges": {
+			Method: http.MethodGet,
+			Path:   "/customers/status",
+		},
+		"CreateWebhook": {
+			Method: http.MethodPost,
+			Path:   "/webhooks/create",
+		},
+		"ListWebhooks": {
+			Method: http.MethodGet,
+			Path:   "/webhooks",
+		},
+		"DeleteWebhook": {
+			Method: http.MethodPost,
+			Path:   "/webhooks/delete",
+		},
+		"ListWebhookDeliveries": {
+			Method: http.MethodGet,
+			Path:   "/webhooks/deliveries",
+		},
+		"ReplayWebhookDelivery": {
+			Method: http.MethodPost,
+			Path:   "/webhooks/deliveries/replay",
+		},
+		"SetLowBalanceThreshold": {
+			Method: http.MethodPost,
+			Path:   "/thresholds/set",
+		},
+		"GetLowBalanceThreshold": {
+			Method: http.MethodGet,
+			Path:   "/thresholds",
+		},
+		"DeleteLowBalanceThreshold": {
+			Method: http.MethodPost,
+			Path:   "/thresholds/delete",
+		},
+		"SetAutoTopUp": {
+			Method: http.MethodPost,
+			Path:   "/top_ups/auto/set",
+		},
+		"GetAutoTopUp": {
+			Method: http.MethodGet,
+			Path:   "/top_ups/auto",
+		},
+		"DisableAutoTopUp": {
+			Method: http.MethodPost,
+			Path:   "/top_ups/auto/disable",
+		},
+		"ListTopUps": {
+			Method: http.MethodGet,
+			Path:   "/top_ups",
+		},
+		"ListAccounts": {
+			Method: http.MethodGet,
+			Path:   "/accounts",
+		},
+		"ListEntries": {
+			Method: http.MethodGet,
+			Path:   "/accounts/entries",
+		},
+		"CheckLedger": {
+			Method: http.MethodGet,
+			Path:   "/accounts/check",
+		},
+		"VerifyLedger": {
+			Method: http.MethodGet,
+			Path:   "/ledger/verify",
+		},
+		"SetOverdraftLimit": {
+			Method: http.MethodPost,
+			Path:   "/overdrafts/set",
+		},
+		"GetOverdraftLimit": {
+			Method: http.MethodGet,
+			Path:   "/overdrafts",
+		},
+		"DeleteOverdraftLimit": {
+			Method: http.MethodPost,
+			Path:   "/overdrafts/delete",
+		},
+		"SetSpendingLimits": {
+			Method: http.MethodPost,
+			Path:   "/spending_limits/set",
+		},
+		"GetSpendingLimits": {
+			Method: http.MethodGet,
+			Path:   "/spending_limits",
+		},
+		"DeleteSpendingLimits": {
+			Method: http.MethodPost,
+			Path:   "/spending_limits/delete",
+		},
+		"GetSpendingUsage": {
+			Method: http.MethodGet,
+			Path:   "/spending_limits/usage",
+		},
+		"SetWalletMember": {
+			Method: http.MethodPost,
+			Path:   "/wallets/members/set",
+		},
+		"RemoveWalletMember": {
+			Method: http.MethodPost,
+			Path:   "/wallets/members/remove",
+		},
+		"ListWalletMembers": {
+			Method: http.MethodGet,
+			Path:   "/wallets/members",
+		},
+		"SpendFromWallet": {
+			Method: http.MethodPost,
+			Path:   "/wallets/spend",
+		},
+		"ListWalletSpending": {
+			Method: http.MethodGet,
+			Path:   "/wallets/spending",
+		},
+		"CreateSubscription": {
+			Method: http.MethodPost,
+			Path:   "/subscriptions/create",
+		},
+		"CancelSubscription": {
+			Method: http.MethodPost,
+			Path:   "/subscriptions/cancel",
+		},
+		"ListSubscriptions": {
+			Method: http.MethodGet,
+			Path:   "/subscriptions",
+		},
+		"ListSubscriptionGrants": {
+			Method: http.MethodGet,
+			Path:   "/subscriptions/grants",
+		},
+		"ScheduleOperation": {
+			Method: http.MethodPost,
+			Path:   "/scheduled_operations/create",
+		},
+		"CancelScheduledOperation": {
+			Method: http.MethodPost,
+			Path:   "/scheduled_operations/cancel",
+		},
+		"ListScheduledOperations": {
+			Method: http.MethodGet,
+			Path:   "/scheduled_operations",
+		},
+		"CreateCoupon": {
+			Method: http.MethodPost,
+			Path:   "/coupons/create",
+		},
+		"ListCoupons": {
+			Method: http.MethodGet,
+			Path:   "/coupons",
+		},
+		"RedeemCoupon": {
+			Method: http.MethodPost,
+			Path:   "/coupons/redeem",
+		},
+		"GetCouponStats": {
+			Method: http.MethodGet,
+			Path:   "/coupons/stats",
+		},
+		"SetTrialPolicy": {
+			Method: http.MethodPost,
+			Path:   "/trials/policy/set",
+		},
+		"GetTrialPolicy": {
+			Method: http.MethodGet,
+			Path:   "/trials/policy",
+		},
+		"DeleteTrialPolicy": {
+			Method: http.MethodPost,
+			Path:   "/trials/policy/delete",
+		},
+		"GetTrialGrant": {
+			Method: http.MethodGet,
+			Path:   "/trials/grant",
+		},
+		"RecordHandleChange": {
+			Method: http.MethodPost,
+			Path:   "/trials/handle_change",
+		},
+		"SetVolumeTiers": {
+			Method: http.MethodPost,
+			Path:   "/purchase_pricing/tiers/set",
+		},
+		"ListVolumeTiers": {
+			Method: http.MethodGet,
+			Path:   "/purchase_pricing/tiers",
+		},
+		"CreatePackage": {
+			Method: http.MethodPost,
+			Path:   "/purchase_pricing/packages/create",
+		},
+		"DeletePackage": {
+			Method: http.MethodPost,
+			Path:   "/purchase_pricing/packages/delete",
+		},
+		"ListPackages": {
+			Method: http.MethodGet,
+			Path:   "/purchase_pricing/packages",
+		},
+		"SetConversionPolicy": {
+			Method: http.MethodPost,
+			Path:   "/conversion/policy/set",
+		},
+		"GetConversionPolicy": {
+			Method: http.MethodGet,
+			Path:   "/conversion/policy",
+		},
 	}
 	return &client{
 		client: net.NewClient(net.NewCallerHTTP(baseURL, endpoints, timeout), encoders.JSON),
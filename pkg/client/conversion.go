@@ -0,0 +1,24 @@
+package client
+
+import (
+	"context"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/api"
+)
+
+// SetConversionPolicy performs an HTTP request to set the conversion policy of an application.
+func (c *client) SetConversionPolicy(ctx context.Context, in api.SetConversionPolicyRequest) (api.SetConversionPolicyResponse, error) {
+	var out api.SetConversionPolicyResponse
+	if err := c.client.Call(ctx, "SetConversionPolicy", &in, &out); err != nil {
+		return api.SetConversionPolicyResponse{}, err
+	}
+	return out, nil
+}
+
+// GetConversionPolicy performs an HTTP request to get the conversion policy of an application.
+func (c *client) GetConversionPolicy(ctx context.Context, in api.GetConversionPolicyRequest) (api.GetConversionPolicyResponse, error) {
+	var out api.GetConversionPolicyResponse
+	if err := c.client.Call(ctx, "GetConversionPolicy", &in, &out); err != nil {
+		return api.GetConversionPolicyResponse{}, err
+	}
+	return out, nil
+}
@@ -0,0 +1,42 @@
+package client
+
+import (
+	"context"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/api"
+)
+
+// CreateCoupon performs an HTTP request to create a coupon.
+func (c *client) CreateCoupon(ctx context.Context, in api.CreateCouponRequest) (api.CreateCouponResponse, error) {
+	var out api.CreateCouponResponse
+	if err := c.client.Call(ctx, "CreateCoupon", &in, &out); err != nil {
+		return api.CreateCouponResponse{}, err
+	}
+	return out, nil
+}
+
+// ListCoupons performs an HTTP request to list the coupons of an application.
+func (c *client) ListCoupons(ctx context.Context, in api.ListCouponsRequest) (api.ListCouponsResponse, error) {
+	var out api.ListCouponsResponse
+	if err := c.client.Call(ctx, "ListCoupons", &in, &out); err != nil {
+		return api.ListCouponsResponse{}, err
+	}
+	return out, nil
+}
+
+// RedeemCoupon performs an HTTP request to redeem a coupon.
+func (c *client) RedeemCoupon(ctx context.Context, in api.RedeemCouponRequest) (api.RedeemCouponResponse, error) {
+	var out api.RedeemCouponResponse
+	if err := c.client.Call(ctx, "RedeemCoupon", &in, &out); err != nil {
+		return api.RedeemCouponResponse{}, err
+	}
+	return out, nil
+}
+
+// GetCouponStats performs an HTTP request to get the usage statistics of a coupon.
+func (c *client) GetCouponStats(ctx context.Context, in api.GetCouponStatsRequest) (api.GetCouponStatsResponse, error) {
+	var out api.GetCouponStatsResponse
+	if err := c.client.Call(ctx, "GetCouponStats", &in, &out); err != nil {
+		return api.GetCouponStatsResponse{}, err
+	}
+	return out, nil
+}
@@ -0,0 +1,33 @@
+package client
+
+import (
+	"context"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/api"
+)
+
+// ListCustomers performs an HTTP request to list the customers of an application.
+func (c *client) ListCustomers(ctx context.Context, in api.ListCustomersRequest) (api.ListCustomersResponse, error) {
+	var out api.ListCustomersResponse
+	if err := c.client.Call(ctx, "ListCustomers", &in, &out); err != nil {
+		return api.ListCustomersResponse{}, err
+	}
+	return out, nil
+}
+
+// SetCustomerStatus performs an HTTP request to change the status of a customer.
+func (c *client) SetCustomerStatus(ctx context.Context, in api.SetCustomerStatusRequest) (api.SetCustomerStatusResponse, error) {
+	var out api.SetCustomerStatusResponse
+	if err := c.client.Call(ctx, "SetCustomerStatus", &in, &out); err != nil {
+		return api.SetCustomerStatusResponse{}, err
+	}
+	return out, nil
+}
+
+// ListCustomerStatusChanges performs an HTTP request to list the status changes of a customer.
+func (c *client) ListCustomerStatusChanges(ctx context.Context, in api.ListCustomerStatusChangesRequest) (api.ListCustomerStatusChangesResponse, error) {
+	var out api.ListCustomerStatusChangesResponse
+	if err := c.client.Call(ctx, "ListCustomerStatusChanges", &in, &out); err != nil {
+		return api.ListCustomerStatusChangesResponse{}, err
+	}
+	return out, nil
+}
@@ -0,0 +1,15 @@
+package client
+
+import (
+	"context"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/api"
+)
+
+// VerifyLedger performs an HTTP request to verify the hash chain of the ledger of an application.
+func (c *client) VerifyLedger(ctx context.Context, in api.VerifyLedgerRequest) (api.VerifyLedgerResponse, error) {
+	var out api.VerifyLedgerResponse
+	if err := c.client.Call(ctx, "VerifyLedger", &in, &out); err != nil {
+		return api.VerifyLedgerResponse{}, err
+	}
+	return out, nil
+}
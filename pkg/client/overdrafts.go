@@ -0,0 +1,33 @@
+package client
+
+import (
+	"context"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/api"
+)
+
+// SetOverdraftLimit performs an HTTP request to set the overdraft limit of an application or a customer.
+func (c *client) SetOverdraftLimit(ctx context.Context, in api.SetOverdraftLimitRequest) (api.SetOverdraftLimitResponse, error) {
+	var out api.SetOverdraftLimitResponse
+	if err := c.client.Call(ctx, "SetOverdraftLimit", &in, &out); err != nil {
+		return api.SetOverdraftLimitResponse{}, err
+	}
+	return out, nil
+}
+
+// GetOverdraftLimit performs an HTTP request to get the overdraft limit of an application or a customer.
+func (c *client) GetOverdraftLimit(ctx context.Context, in api.GetOverdraftLimitRequest) (api.GetOverdraftLimitResponse, error) {
+	var out api.GetOverdraftLimitResponse
+	if err := c.client.Call(ctx, "GetOverdraftLimit", &in, &out); err != nil {
+		return api.GetOverdraftLimitResponse{}, err
+	}
+	return out, nil
+}
+
+// DeleteOverdraftLimit performs an HTTP request to remove the overdraft limit of an application or a customer.
+func (c *client) DeleteOverdraftLimit(ctx context.Context, in api.DeleteOverdraftLimitRequest) (api.DeleteOverdraftLimitResponse, error) {
+	var out api.DeleteOverdraftLimitResponse
+	if err := c.client.Call(ctx, "DeleteOverdraftLimit", &in, &out); err != nil {
+		return api.DeleteOverdraftLimitResponse{}, err
+	}
+	return out, nil
+}
@@ -0,0 +1,60 @@
+package client
+
+import (
+	"context"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/api"
+)
+
+// CreateSKU performs an HTTP request to create a new SKU in the pricing catalog.
+func (c *client) CreateSKU(ctx context.Context, in api.CreateSKURequest) (api.CreateSKUResponse, error) {
+	var out api.CreateSKUResponse
+	if err := c.client.Call(ctx, "CreateSKU", &in, &out); err != nil {
+		return api.CreateSKUResponse{}, err
+	}
+	return out, nil
+}
+
+// GetSKU performs an HTTP request to get a SKU from the pricing catalog.
+func (c *client) GetSKU(ctx context.Context, in api.GetSKURequest) (api.GetSKUResponse, error) {
+	var out api.GetSKUResponse
+	if err := c.client.Call(ctx, "GetSKU", &in, &out); err != nil {
+		return api.GetSKUResponse{}, err
+	}
+	return out, nil
+}
+
+// ListSKUs performs an HTTP request to list the SKUs of an application.
+func (c *client) ListSKUs(ctx context.Context, in api.ListSKUsRequest) (api.ListSKUsResponse, error) {
+	var out api.ListSKUsResponse
+	if err := c.client.Call(ctx, "ListSKUs", &in, &out); err != nil {
+		return api.ListSKUsResponse{}, err
+	}
+	return out, nil
+}
+
+// UpdateSKU performs an HTTP request to update a SKU from the pricing catalog.
+func (c *client) UpdateSKU(ctx context.Context, in api.UpdateSKURequest) (api.UpdateSKUResponse, error) {
+	var out api.UpdateSKUResponse
+	if err := c.client.Call(ctx, "UpdateSKU", &in, &out); err != nil {
+		return api.UpdateSKUResponse{}, err
+	}
+	return out, nil
+}
+
+// DeleteSKU performs an HTTP request to delete a SKU from the pricing catalog.
+func (c *client) DeleteSKU(ctx context.Context, in api.DeleteSKURequest) (api.DeleteSKUResponse, error) {
+	var out api.DeleteSKUResponse
+	if err := c.client.Call(ctx, "DeleteSKU", &in, &out); err != nil {
+		return api.DeleteSKUResponse{}, err
+	}
+	return out, nil
+}
+
+// SetSKUPrice performs an HTTP request to set a new price version of a SKU.
+func (c *client) SetSKUPrice(ctx context.Context, in api.SetSKUPriceRequest) (api.SetSKUPriceResponse, error) {
+	var out api.SetSKUPriceResponse
+	if err := c.client.Call(ctx, "SetSKUPrice", &in, &out); err != nil {
+		return api.SetSKUPriceResponse{}, err
+	}
+	return out, nil
+}
@@ -0,0 +1,51 @@
+package client
+
+import (
+	"context"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/api"
+)
+
+// SetVolumeTiers performs an HTTP request to replace the volume tiers of an application.
+func (c *client) SetVolumeTiers(ctx context.Context, in api.SetVolumeTiersRequest) (api.SetVolumeTiersResponse, error) {
+	var out api.SetVolumeTiersResponse
+	if err := c.client.Call(ctx, "SetVolumeTiers", &in, &out); err != nil {
+		return api.SetVolumeTiersResponse{}, err
+	}
+	return out, nil
+}
+
+// ListVolumeTiers performs an HTTP request to list the volume tiers of an application.
+func (c *client) ListVolumeTiers(ctx context.Context, in api.ListVolumeTiersRequest) (api.ListVolumeTiersResponse, error) {
+	var out api.ListVolumeTiersResponse
+	if err := c.client.Call(ctx, "ListVolumeTiers", &in, &out); err != nil {
+		return api.ListVolumeTiersResponse{}, err
+	}
+	return out, nil
+}
+
+// CreatePackage performs an HTTP request to create a credit package.
+func (c *client) CreatePackage(ctx context.Context, in api.CreatePackageRequest) (api.CreatePackageResponse, error) {
+	var out api.CreatePackageResponse
+	if err := c.client.Call(ctx, "CreatePackage", &in, &out); err != nil {
+		return api.CreatePackageResponse{}, err
+	}
+	return out, nil
+}
+
+// DeletePackage performs an HTTP request to delete a credit package.
+func (c *client) DeletePackage(ctx context.Context, in api.DeletePackageRequest) (api.DeletePackageResponse, error) {
+	var out api.DeletePackageResponse
+	if err := c.client.Call(ctx, "DeletePackage", &in, &out); err != nil {
+		return api.DeletePackageResponse{}, err
+	}
+	return out, nil
+}
+
+// ListPackages performs an HTTP request to list the credit packages of an application.
+func (c *client) ListPackages(ctx context.Context, in api.ListPackagesRequest) (api.ListPackagesResponse, error) {
+	var out api.ListPackagesResponse
+	if err := c.client.Call(ctx, "ListPackages", &in, &out); err != nil {
+		return api.ListPackagesResponse{}, err
+	}
+	return out, nil
+}
@@ -0,0 +1,33 @@
+package client
+
+import (
+	"context"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/api"
+)
+
+// ScheduleOperation performs an HTTP request to schedule an operation.
+func (c *client) ScheduleOperation(ctx context.Context, in api.ScheduleOperationRequest) (api.ScheduleOperationResponse, error) {
+	var out api.ScheduleOperationResponse
+	if err := c.client.Call(ctx, "ScheduleOperation", &in, &out); err != nil {
+		return api.ScheduleOperationResponse{}, err
+	}
+	return out, nil
+}
+
+// CancelScheduledOperation performs an HTTP request to cancel a scheduled operation.
+func (c *client) CancelScheduledOperation(ctx context.Context, in api.CancelScheduledOperationRequest) (api.CancelScheduledOperationResponse, error) {
+	var out api.CancelScheduledOperationResponse
+	if err := c.client.Call(ctx, "CancelScheduledOperation", &in, &out); err != nil {
+		return api.CancelScheduledOperationResponse{}, err
+	}
+	return out, nil
+}
+
+// ListScheduledOperations performs an HTTP request to list the scheduled operations of an application.
+func (c *client) ListScheduledOperations(ctx context.Context, in api.ListScheduledOperationsRequest) (api.ListScheduledOperationsResponse, error) {
+	var out api.ListScheduledOperationsResponse
+	if err := c.client.Call(ctx, "ListScheduledOperations", &in, &out); err != nil {
+		return api.ListScheduledOperationsResponse{}, err
+	}
+	return out, nil
+}
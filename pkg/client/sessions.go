@@ -0,0 +1,42 @@
+package client
+
+import (
+	"context"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/api"
+)
+
+// OpenSession performs an HTTP request to open a new usage session.
+func (c *client) OpenSession(ctx context.Context, in api.OpenSessionRequest) (api.OpenSessionResponse, error) {
+	var out api.OpenSessionResponse
+	if err := c.client.Call(ctx, "OpenSession", &in, &out); err != nil {
+		return api.OpenSessionResponse{}, err
+	}
+	return out, nil
+}
+
+// SendHeartbeat performs an HTTP request to notify that a usage session is still alive.
+func (c *client) SendHeartbeat(ctx context.Context, in api.SendHeartbeatRequest) (api.SendHeartbeatResponse, error) {
+	var out api.SendHeartbeatResponse
+	if err := c.client.Call(ctx, "SendHeartbeat", &in, &out); err != nil {
+		return api.SendHeartbeatResponse{}, err
+	}
+	return out, nil
+}
+
+// CloseSession performs an HTTP request to close a usage session.
+func (c *client) CloseSession(ctx context.Context, in api.CloseSessionRequest) (api.CloseSessionResponse, error) {
+	var out api.CloseSessionResponse
+	if err := c.client.Call(ctx, "CloseSession", &in, &out); err != nil {
+		return api.CloseSessionResponse{}, err
+	}
+	return out, nil
+}
+
+// GetSession performs an HTTP request to get the current state of a usage session.
+func (c *client) GetSession(ctx context.Context, in api.GetSessionRequest) (api.GetSessionResponse, error) {
+	var out api.GetSessionResponse
+	if err := c.client.Call(ctx, "GetSession", &in, &out); err != nil {
+		return api.GetSessionResponse{}, err
+	}
+	return out, nil
+}
@@ -0,0 +1,42 @@
+package client
+
+import (
+	"context"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/api"
+)
+
+// SetSpendingLimits performs an HTTP request to set the spending limits of an application or a customer.
+func (c *client) SetSpendingLimits(ctx context.Context, in api.SetSpendingLimitsRequest) (api.SetSpendingLimitsResponse, error) {
+	var out api.SetSpendingLimitsResponse
+	if err := c.client.Call(ctx, "SetSpendingLimits", &in, &out); err != nil {
+		return api.SetSpendingLimitsResponse{}, err
+	}
+	return out, nil
+}
+
+// GetSpendingLimits performs an HTTP request to get the spending limits of an application or a customer.
+func (c *client) GetSpendingLimits(ctx context.Context, in api.GetSpendingLimitsRequest) (api.GetSpendingLimitsResponse, error) {
+	var out api.GetSpendingLimitsResponse
+	if err := c.client.Call(ctx, "GetSpendingLimits", &in, &out); err != nil {
+		return api.GetSpendingLimitsResponse{}, err
+	}
+	return out, nil
+}
+
+// DeleteSpendingLimits performs an HTTP request to remove the spending limits of an application or a customer.
+func (c *client) DeleteSpendingLimits(ctx context.Context, in api.DeleteSpendingLimitsRequest) (api.DeleteSpendingLimitsResponse, error) {
+	var out api.DeleteSpendingLimitsResponse
+	if err := c.client.Call(ctx, "DeleteSpendingLimits", &in, &out); err != nil {
+		return api.DeleteSpendingLimitsResponse{}, err
+	}
+	return out, nil
+}
+
+// GetSpendingUsage performs an HTTP request to get the usage of a customer against its spending caps.
+func (c *client) GetSpendingUsage(ctx context.Context, in api.GetSpendingUsageRequest) (api.GetSpendingUsageResponse, error) {
+	var out api.GetSpendingUsageResponse
+	if err := c.client.Call(ctx, "GetSpendingUsage", &in, &out); err != nil {
+		return api.GetSpendingUsageResponse{}, err
+	}
+	return out, nil
+}
@@ -0,0 +1,42 @@
+package client
+
+import (
+	"context"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/api"
+)
+
+// CreateSubscription performs an HTTP request to create a subscription.
+func (c *client) CreateSubscription(ctx context.Context, in api.CreateSubscriptionRequest) (api.CreateSubscriptionResponse, error) {
+	var out api.CreateSubscriptionResponse
+	if err := c.client.Call(ctx, "CreateSubscription", &in, &out); err != nil {
+		return api.CreateSubscriptionResponse{}, err
+	}
+	return out, nil
+}
+
+// CancelSubscription performs an HTTP request to cancel a subscription.
+func (c *client) CancelSubscription(ctx context.Context, in api.CancelSubscriptionRequest) (api.CancelSubscriptionResponse, error) {
+	var out api.CancelSubscriptionResponse
+	if err := c.client.Call(ctx, "CancelSubscription", &in, &out); err != nil {
+		return api.CancelSubscriptionResponse{}, err
+	}
+	return out, nil
+}
+
+// ListSubscriptions performs an HTTP request to list the subscriptions of a customer.
+func (c *client) ListSubscriptions(ctx context.Context, in api.ListSubscriptionsRequest) (api.ListSubscriptionsResponse, error) {
+	var out api.ListSubscriptionsResponse
+	if err := c.client.Call(ctx, "ListSubscriptions", &in, &out); err != nil {
+		return api.ListSubscriptionsResponse{}, err
+	}
+	return out, nil
+}
+
+// ListSubscriptionGrants performs an HTTP request to list the grants of a subscription.
+func (c *client) ListSubscriptionGrants(ctx context.Context, in api.ListSubscriptionGrantsRequest) (api.ListSubscriptionGrantsResponse, error) {
+	var out api.ListSubscriptionGrantsResponse
+	if err := c.client.Call(ctx, "ListSubscriptionGrants", &in, &out); err != nil {
+		return api.ListSubscriptionGrantsResponse{}, err
+	}
+	return out, nil
+}
@@ -0,0 +1,33 @@
+package client
+
+import (
+	"context"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/api"
+)
+
+// SetLowBalanceThreshold performs an HTTP request to set a low balance threshold.
+func (c *client) SetLowBalanceThreshold(ctx context.Context, in api.SetLowBalanceThresholdRequest) (api.SetLowBalanceThresholdResponse, error) {
+	var out api.SetLowBalanceThresholdResponse
+	if err := c.client.Call(ctx, "SetLowBalanceThreshold", &in, &out); err != nil {
+		return api.SetLowBalanceThresholdResponse{}, err
+	}
+	return out, nil
+}
+
+// GetLowBalanceThreshold performs an HTTP request to get the low balance threshold of an application or customer.
+func (c *client) GetLowBalanceThreshold(ctx context.Context, in api.GetLowBalanceThresholdRequest) (api.GetLowBalanceThresholdResponse, error) {
+	var out api.GetLowBalanceThresholdResponse
+	if err := c.client.Call(ctx, "GetLowBalanceThreshold", &in, &out); err != nil {
+		return api.GetLowBalanceThresholdResponse{}, err
+	}
+	return out, nil
+}
+
+// DeleteLowBalanceThreshold performs an HTTP request to delete a low balance threshold.
+func (c *client) DeleteLowBalanceThreshold(ctx context.Context, in api.DeleteLowBalanceThresholdRequest) (api.DeleteLowBalanceThresholdResponse, error) {
+	var out api.DeleteLowBalanceThresholdResponse
+	if err := c.client.Call(ctx, "DeleteLowBalanceThreshold", &in, &out); err != nil {
+		return api.DeleteLowBalanceThresholdResponse{}, err
+	}
+	return out, nil
+}
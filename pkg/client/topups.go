@@ -0,0 +1,42 @@
+package client
+
+import (
+	"context"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/api"
+)
+
+// SetAutoTopUp performs an HTTP request to enable or update the automatic top-ups of a customer.
+func (c *client) SetAutoTopUp(ctx context.Context, in api.SetAutoTopUpRequest) (api.SetAutoTopUpResponse, error) {
+	var out api.SetAutoTopUpResponse
+	if err := c.client.Call(ctx, "SetAutoTopUp", &in, &out); err != nil {
+		return api.SetAutoTopUpResponse{}, err
+	}
+	return out, nil
+}
+
+// GetAutoTopUp performs an HTTP request to get the automatic top-up settings of a customer.
+func (c *client) GetAutoTopUp(ctx context.Context, in api.GetAutoTopUpRequest) (api.GetAutoTopUpResponse, error) {
+	var out api.GetAutoTopUpResponse
+	if err := c.client.Call(ctx, "GetAutoTopUp", &in, &out); err != nil {
+		return api.GetAutoTopUpResponse{}, err
+	}
+	return out, nil
+}
+
+// DisableAutoTopUp performs an HTTP request to disable the automatic top-ups of a customer.
+func (c *client) DisableAutoTopUp(ctx context.Context, in api.DisableAutoTopUpRequest) (api.DisableAutoTopUpResponse, error) {
+	var out api.DisableAutoTopUpResponse
+	if err := c.client.Call(ctx, "DisableAutoTopUp", &in, &out); err != nil {
+		return api.DisableAutoTopUpResponse{}, err
+	}
+	return out, nil
+}
+
+// ListTopUps performs an HTTP request to list the top-ups of a customer.
+func (c *client) ListTopUps(ctx context.Context, in api.ListTopUpsRequest) (api.ListTopUpsResponse, error) {
+	var out api.ListTopUpsResponse
+	if err := c.client.Call(ctx, "ListTopUps", &in, &out); err != nil {
+		return api.ListTopUpsResponse{}, err
+	}
+	return out, nil
+}
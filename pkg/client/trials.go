@@ -0,0 +1,51 @@
+package client
+
+import (
+	"context"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/api"
+)
+
+// SetTrialPolicy performs an HTTP request to set the trial policy of an application.
+func (c *client) SetTrialPolicy(ctx context.Context, in api.SetTrialPolicyRequest) (api.SetTrialPolicyResponse, error) {
+	var out api.SetTrialPolicyResponse
+	if err := c.client.Call(ctx, "SetTrialPolicy", &in, &out); err != nil {
+		return api.SetTrialPolicyResponse{}, err
+	}
+	return out, nil
+}
+
+// GetTrialPolicy performs an HTTP request to get the trial policy of an application.
+func (c *client) GetTrialPolicy(ctx context.Context, in api.GetTrialPolicyRequest) (api.GetTrialPolicyResponse, error) {
+	var out api.GetTrialPolicyResponse
+	if err := c.client.Call(ctx, "GetTrialPolicy", &in, &out); err != nil {
+		return api.GetTrialPolicyResponse{}, err
+	}
+	return out, nil
+}
+
+// DeleteTrialPolicy performs an HTTP request to delete the trial policy of an application.
+func (c *client) DeleteTrialPolicy(ctx context.Context, in api.DeleteTrialPolicyRequest) (api.DeleteTrialPolicyResponse, error) {
+	var out api.DeleteTrialPolicyResponse
+	if err := c.client.Call(ctx, "DeleteTrialPolicy", &in, &out); err != nil {
+		return api.DeleteTrialPolicyResponse{}, err
+	}
+	return out, nil
+}
+
+// GetTrialGrant performs an HTTP request to get the welcome credits granted to a customer.
+func (c *client) GetTrialGrant(ctx context.Context, in api.GetTrialGrantRequest) (api.GetTrialGrantResponse, error) {
+	var out api.GetTrialGrantResponse
+	if err := c.client.Call(ctx, "GetTrialGrant", &in, &out); err != nil {
+		return api.GetTrialGrantResponse{}, err
+	}
+	return out, nil
+}
+
+// RecordHandleChange performs an HTTP request to record a handle change.
+func (c *client) RecordHandleChange(ctx context.Context, in api.RecordHandleChangeRequest) (api.RecordHandleChangeResponse, error) {
+	var out api.RecordHandleChangeResponse
+	if err := c.client.Call(ctx, "RecordHandleChange", &in, &out); err != nil {
+		return api.RecordHandleChangeResponse{}, err
+	}
+	return out, nil
+}
@@ -0,0 +1,51 @@
+package client
+
+import (
+	"context"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/api"
+)
+
+// SetWalletMember performs an HTTP request to add a member to a wallet or update its allowance.
+func (c *client) SetWalletMember(ctx context.Context, in api.SetWalletMemberRequest) (api.SetWalletMemberResponse, error) {
+	var out api.SetWalletMemberResponse
+	if err := c.client.Call(ctx, "SetWalletMember", &in, &out); err != nil {
+		return api.SetWalletMemberResponse{}, err
+	}
+	return out, nil
+}
+
+// RemoveWalletMember performs an HTTP request to remove a member from a wallet.
+func (c *client) RemoveWalletMember(ctx context.Context, in api.RemoveWalletMemberRequest) (api.RemoveWalletMemberResponse, error) {
+	var out api.RemoveWalletMemberResponse
+	if err := c.client.Call(ctx, "RemoveWalletMember", &in, &out); err != nil {
+		return api.RemoveWalletMemberResponse{}, err
+	}
+	return out, nil
+}
+
+// ListWalletMembers performs an HTTP request to list the members of a wallet.
+func (c *client) ListWalletMembers(ctx context.Context, in api.ListWalletMembersRequest) (api.ListWalletMembersResponse, error) {
+	var out api.ListWalletMembersResponse
+	if err := c.client.Call(ctx, "ListWalletMembers", &in, &out); err != nil {
+		return api.ListWalletMembersResponse{}, err
+	}
+	return out, nil
+}
+
+// SpendFromWallet performs an HTTP request to spend credits from a wallet on behalf of a member.
+func (c *client) SpendFromWallet(ctx context.Context, in api.SpendFromWalletRequest) (api.SpendFromWalletResponse, error) {
+	var out api.SpendFromWalletResponse
+	if err := c.client.Call(ctx, "SpendFromWallet", &in, &out); err != nil {
+		return api.SpendFromWalletResponse{}, err
+	}
+	return out, nil
+}
+
+// ListWalletSpending performs an HTTP request to list the spending of a wallet.
+func (c *client) ListWalletSpending(ctx context.Context, in api.ListWalletSpendingRequest) (api.ListWalletSpendingResponse, error) {
+	var out api.ListWalletSpendingResponse
+	if err := c.client.Call(ctx, "ListWalletSpending", &in, &out); err != nil {
+		return api.ListWalletSpendingResponse{}, err
+	}
+	return out, nil
+}
@@ -0,0 +1,51 @@
+package client
+
+import (
+	"context"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/api"
+)
+
+// CreateWebhook performs an HTTP request to register a webhook.
+func (c *client) CreateWebhook(ctx context.Context, in api.CreateWebhookRequest) (api.CreateWebhookResponse, error) {
+	var out api.CreateWebhookResponse
+	if err := c.client.Call(ctx, "CreateWebhook", &in, &out); err != nil {
+		return api.CreateWebhookResponse{}, err
+	}
+	return out, nil
+}
+
+// ListWebhooks performs an HTTP request to list the webhooks of an application.
+func (c *client) ListWebhooks(ctx context.Context, in api.ListWebhooksRequest) (api.ListWebhooksResponse, error) {
+	var out api.ListWebhooksResponse
+	if err := c.client.Call(ctx, "ListWebhooks", &in, &out); err != nil {
+		return api.ListWebhooksResponse{}, err
+	}
+	return out, nil
+}
+
+// DeleteWebhook performs an HTTP request to delete a webhook.
+func (c *client) DeleteWebhook(ctx context.Context, in api.DeleteWebhookRequest) (api.DeleteWebhookResponse, error) {
+	var out api.DeleteWebhookResponse
+	if err := c.client.Call(ctx, "DeleteWebhook", &in, &out); err != nil {
+		return api.DeleteWebhookResponse{}, err
+	}
+	return out, nil
+}
+
+// ListWebhookDeliveries performs an HTTP request to list the deliveries of a webhook.
+func (c *client) ListWebhookDeliveries(ctx context.Context, in api.ListWebhookDeliveriesRequest) (api.ListWebhookDeliveriesResponse, error) {
+	var out api.ListWebhookDeliveriesResponse
+	if err := c.client.Call(ctx, "ListWebhookDeliveries", &in, &out); err != nil {
+		return api.ListWebhookDeliveriesResponse{}, err
+	}
+	return out, nil
+}
+
+// ReplayWebhookDelivery performs an HTTP request to replay a webhook delivery.
+func (c *client) ReplayWebhookDelivery(ctx context.Context, in api.ReplayWebhookDeliveryRequest) (api.ReplayWebhookDeliveryResponse, error) {
+	var out api.ReplayWebhookDeliveryResponse
+	if err := c.client.Call(ctx, "ReplayWebhookDelivery", &in, &out); err != nil {
+		return api.ReplayWebhookDeliveryResponse{}, err
+	}
+	return out, nil
+}
@@ -0,0 +1,53 @@
+package models
+
+import "gorm.io/gorm"
+
+const (
+	// AccountCustomer is the account holding the credits of a single customer.
+	AccountCustomer = "customer"
+	// AccountIssuance is the account credits are issued from when customers get or buy credits.
+	AccountIssuance = "issuance"
+	// AccountRevenue is the account receiving the credits spent by customers.
+	AccountRevenue = "revenue"
+	// AccountPromotions is the account promotional credits are granted from.
+	AccountPromotions = "promotions"
+	// AccountExpired is the account receiving the credits that expired.
+	AccountExpired = "expired"
+	// AccountTransfers is the account used to move credits between customers. Its balance is always zero after
+	// every transfer.
+	AccountTransfers = "transfers"
+)
+
+// Account is an account of the double-entry ledger of an application. Every customer has an account, and every
+// application has a set of system accounts (e.g. AccountIssuance) used as counterpart of the customer accounts.
+type Account struct {
+	gorm.Model
+
+	// Application is the application the account belongs to.
+	Application string `gorm:"uniqueIndex:idx_account;size:255"`
+
+	// Type is the account type (e.g. AccountCustomer).
+	Type string `gorm:"uniqueIndex:idx_account;size:32"`
+
+	// Handle is the customer that owns the account. It's empty for system accounts.
+	Handle string `gorm:"uniqueIndex:idx_account;size:255"`
+
+	// Balance is the sum of the entries of the account.
+	Balance int
+}
+
+// Entry is a single posting in an Account. Entries are posted in balanced groups whose amounts add up to zero.
+// Positive amounts increase the balance of the account, negative amounts decrease it.
+type Entry struct {
+	gorm.Model
+
+	// TransactionID is the ID of the BalanceChange that posted the entry. It's nil for the opening entries of
+	// customers that had credits before their account was created.
+	TransactionID *uint `gorm:"index"`
+
+	// AccountID is the ID of the account the entry is posted in.
+	AccountID uint `gorm:"index"`
+
+	// Amount is the amount of credits posted.
+	Amount int
+}
@@ -0,0 +1,61 @@
+package models
+
+import "gorm.io/gorm"
+
+const (
+	// OperationOpening is used for the initial balance of a customer.
+	OperationOpening = "opening"
+	// OperationIncrease is used when credits are added to a customer.
+	OperationIncrease = "increase"
+	// OperationDecrease is used when credits are removed from a customer.
+	OperationDecrease = "decrease"
+	// OperationCharge is used when a customer is charged for a SKU from the pricing catalog.
+	OperationCharge = "charge"
+	// OperationSession is used when a customer is charged for a usage session.
+	OperationSession = "session"
+	// OperationTransfer is used when credits are moved between two customers.
+	OperationTransfer = "transfer"
+	// OperationPurchase is used when a customer is credited for a purchase made through the payment provider.
+	OperationPurchase = "purchase"
+	// OperationRefund is used when a purchase is refunded.
+	OperationRefund = "refund"
+	// OperationChargeback is used when the customer opens a chargeback for a purchase.
+	OperationChargeback = "chargeback"
+	// OperationReversal is used when a previous balance change is reversed.
+	OperationReversal = "reversal"
+	// OperationPromotion is used when promotional credits are granted to a customer.
+	OperationPromotion = "promotion"
+	// OperationExpiration is used when credits of a customer expire.
+	OperationExpiration = "expiration"
+	// OperationAllowance is used when a subscription grants its recurring allowance to a customer.
+	OperationAllowance = "allowance"
+	// OperationTrial is used when the welcome credits are granted to a new customer.
+	OperationTrial = "trial"
+)
+
+// BalanceChange is a record of a single change in the amount of credits of a Customer. Balance changes are used to
+// compute the balance of a customer at a certain point in time, their values are never updated.
+type BalanceChange struct {
+	gorm.Model
+
+	// Handle contains the handle of the customer whose balance changed.
+	Handle string `gorm:"index:idx_balance_change_customer"`
+
+	// Application is the application that the credits are being tracked for.
+	Application string `gorm:"index:idx_balance_change_customer"`
+
+	// Operation is the operation that changed the balance (e.g. OperationIncrease).
+	Operation string
+
+	// Value is the amount of credits added to (positive) or removed from (negative) the customer balance.
+	Value int
+
+	// Balance is the customer balance after applying Value.
+	Balance int
+
+	// ReversalOf is the ID of the balance change reversed by this change. It's nil for changes that aren't reversals.
+	ReversalOf *uint `gorm:"index"`
+
+	// ReversedCredits is the amount of credits of this change that have been reversed so far.
+	ReversedCredits uint
+}
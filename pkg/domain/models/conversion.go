@@ -0,0 +1,17 @@
+package models
+
+import "gorm.io/gorm"
+
+// ConversionPolicy defines how money is converted to the credits of an application.
+type ConversionPolicy struct {
+	gorm.Model
+
+	// Application is the application the policy applies to.
+	Application string `gorm:"uniqueIndex;size:255"`
+
+	// Rounding is the rounding mode applied to fractions of a credit.
+	Rounding string `gorm:"size:16"`
+
+	// MilliCredits tracks the balances of the application in milli-credits instead of credits.
+	MilliCredits bool
+}
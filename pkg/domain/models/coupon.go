@@ -0,0 +1,46 @@
+package models
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+// Coupon is a promotional code that grants credits to the customers that redeem it.
+type Coupon struct {
+	gorm.Model
+
+	// Application is the application the coupon can be redeemed for.
+	Application string `gorm:"uniqueIndex:idx_coupon;size:255"`
+
+	// Code is the upper case code customers use to redeem the coupon.
+	Code string `gorm:"uniqueIndex:idx_coupon;size:64"`
+
+	// Credits is the amount of credits granted on every redemption.
+	Credits uint
+
+	// ExpiresAt is the time the coupon can't be redeemed anymore. It's nil for coupons that never expire.
+	ExpiresAt *time.Time
+
+	// MaxRedemptions is the maximum amount of times the coupon can be redeemed. Zero means no limit.
+	MaxRedemptions uint
+
+	// Redemptions is the amount of times the coupon has been redeemed.
+	Redemptions uint
+}
+
+// CouponRedemption records a customer redeeming a coupon. Customers can redeem every coupon once.
+type CouponRedemption struct {
+	gorm.Model
+
+	// CouponID is the redeemed coupon.
+	CouponID uint `gorm:"uniqueIndex:idx_coupon_redemption"`
+
+	// Handle is the customer that redeemed the coupon.
+	Handle string `gorm:"uniqueIndex:idx_coupon_redemption;size:255"`
+
+	// Application is the application the coupon was redeemed for.
+	Application string
+
+	// TransactionID is the ID of the BalanceChange that granted the credits.
+	TransactionID uint
+}
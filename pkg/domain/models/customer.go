@@ -10,11 +10,38 @@ type Customer struct {
 	gorm.Model
 
 	// Handle contains the customer handle. This handle is specific to the Application.
-	Handle string
+	Handle string `gorm:"uniqueIndex:idx_customer,priority:2;size:255"`
 
 	// Application is the application that the credits are being tracked for.
-	Application string
+	Application string `gorm:"uniqueIndex:idx_customer,priority:1;size:255"`
 
 	// Credits is the amount of credits this Customer can use in services provided by Application.
 	Credits int
+
+	// Status is the status of the customer (e.g. active). Only active customers can receive and spend credits.
+	Status string `gorm:"size:16;default:active"`
+
+	// LowBalanceAlerted is true when the customer has been alerted about its balance being below its low balance
+	// threshold. It's reset once the balance recovers.
+	LowBalanceAlerted bool
+}
+
+// CustomerStatusChange is a record of a change of the Status of a Customer.
+type CustomerStatusChange struct {
+	gorm.Model
+
+	// Handle contains the handle of the customer whose status changed.
+	Handle string `gorm:"index:idx_customer_status_change"`
+
+	// Application is the application that the credits are being tracked for.
+	Application string `gorm:"index:idx_customer_status_change"`
+
+	// PreviousStatus is the status of the customer before the change.
+	PreviousStatus string
+
+	// Status is the status of the customer after the change.
+	Status string
+
+	// Reason explains why the status was changed.
+	Reason string `gorm:"type:text"`
 }
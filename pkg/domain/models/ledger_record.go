@@ -0,0 +1,55 @@
+package models
+
+import "gorm.io/gorm"
+
+// LedgerRecord is an append-only copy of a BalanceChange chained to the previous record of the same application.
+// Every record contains the hash of its contents and of the previous record, so any edit, insertion or removal of
+// records breaks the chain. Ledger records are never updated nor deleted.
+type LedgerRecord struct {
+	gorm.Model
+
+	// Application is the application whose chain contains the record.
+	Application string `gorm:"uniqueIndex:idx_ledger_record_sequence;size:255"`
+
+	// Sequence is the position of the record in the chain of the application, starting at 1.
+	Sequence uint `gorm:"uniqueIndex:idx_ledger_record_sequence"`
+
+	// TransactionID is the ID of the BalanceChange recorded.
+	TransactionID uint `gorm:"index"`
+
+	// Handle is the customer whose balance changed.
+	Handle string
+
+	// Operation is the operation that changed the balance.
+	Operation string
+
+	// Value is the amount of credits added to or removed from the customer balance.
+	Value int
+
+	// Balance is the customer balance after applying Value.
+	Balance int
+
+	// Timestamp is the time the record was appended, in nanoseconds since the Unix epoch.
+	Timestamp int64
+
+	// PreviousHash is the hash of the previous record of the chain. It's empty for the first record.
+	PreviousHash string `gorm:"size:64"`
+
+	// Hash is the hex-encoded SHA-256 hash of the contents of the record, including PreviousHash.
+	Hash string `gorm:"size:64"`
+}
+
+// LedgerHead is the last record of the ledger chain of an application. It's locked while appending records to
+// serialize writers, and allows detecting records removed from the end of the chain.
+type LedgerHead struct {
+	gorm.Model
+
+	// Application is the application the chain belongs to.
+	Application string `gorm:"uniqueIndex;size:255"`
+
+	// Sequence is the sequence of the last record of the chain.
+	Sequence uint
+
+	// Hash is the hash of the last record of the chain.
+	Hash string `gorm:"size:64"`
+}
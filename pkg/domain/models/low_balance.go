@@ -0,0 +1,41 @@
+package models
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+// LowBalanceThreshold is the balance below which customers of an application are alerted. Thresholds with an empty
+// Handle apply to every customer of the application, thresholds with a Handle override it for that customer.
+type LowBalanceThreshold struct {
+	gorm.Model
+
+	// Application is the application the threshold applies to.
+	Application string `gorm:"uniqueIndex:idx_low_balance_threshold;size:255"`
+
+	// Handle is the customer the threshold applies to. It's empty for the application default.
+	Handle string `gorm:"uniqueIndex:idx_low_balance_threshold;size:255"`
+
+	// Threshold is the balance below which the customer is alerted.
+	Threshold int
+}
+
+// LowBalanceAlert is an alert triggered when the balance of a customer crossed its low balance threshold.
+type LowBalanceAlert struct {
+	gorm.Model
+
+	// Handle is the customer whose balance crossed the threshold.
+	Handle string
+
+	// Application is the application that credits are tracked for.
+	Application string
+
+	// Threshold is the threshold that was crossed.
+	Threshold int
+
+	// Balance is the balance of the customer after crossing the threshold.
+	Balance int
+
+	// NotifiedAt is the time the alert was notified. It's nil for alerts that haven't been notified yet.
+	NotifiedAt *time.Time `gorm:"index"`
+}
@@ -0,0 +1,24 @@
+package models
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+// OutboxEvent is a domain event written in the same transaction as the change that caused it. Outbox events are
+// published to external systems by a relay once the transaction has been committed.
+type OutboxEvent struct {
+	gorm.Model
+
+	// EventID is the unique identifier of the event.
+	EventID string `gorm:"uniqueIndex;size:64"`
+
+	// EventType is the type of the event.
+	EventType string
+
+	// Payload is the JSON representation of the event.
+	Payload string `gorm:"type:text"`
+
+	// PublishedAt is the time the event was published. It's nil for events that haven't been published yet.
+	PublishedAt *time.Time `gorm:"index"`
+}
@@ -0,0 +1,18 @@
+package models
+
+import "gorm.io/gorm"
+
+// OverdraftLimit is the maximum negative balance allowed for the customers of an application. Limits with an empty
+// Handle apply to every customer of the application, limits with a Handle override it for that customer.
+type OverdraftLimit struct {
+	gorm.Model
+
+	// Application is the application the limit applies to.
+	Application string `gorm:"uniqueIndex:idx_overdraft_limit;size:255"`
+
+	// Handle is the customer the limit applies to. It's empty for the application default.
+	Handle string `gorm:"uniqueIndex:idx_overdraft_limit;size:255"`
+
+	// Amount is the maximum negative balance allowed, in credits.
+	Amount uint
+}
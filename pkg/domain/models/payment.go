@@ -0,0 +1,50 @@
+package models
+
+import "gorm.io/gorm"
+
+// Payment is a purchase of credits made through the payment provider.
+type Payment struct {
+	gorm.Model
+
+	// PaymentID is the identifier of the payment in the payment provider. Each payment is credited only once.
+	PaymentID string `gorm:"uniqueIndex;size:255"`
+
+	// Handle is the customer that made the purchase.
+	Handle string
+
+	// Application is the application that credits are tracked for.
+	Application string
+
+	// Amount is the amount paid.
+	Amount uint
+
+	// Currency is the currency of Amount.
+	Currency string
+
+	// Credits is the amount of credits added to the customer.
+	Credits uint
+
+	// DebitedCredits is the amount of credits removed from the customer because of refunds, chargebacks and
+	// reversals.
+	DebitedCredits uint
+
+	// TransactionID is the ID of the BalanceChange that added the credits to the customer.
+	TransactionID *uint `gorm:"index"`
+}
+
+// PaymentEvent is an event received from the payment provider. Events are recorded to process each of them once.
+type PaymentEvent struct {
+	gorm.Model
+
+	// EventID is the identifier of the event in the payment provider.
+	EventID string `gorm:"uniqueIndex;size:255"`
+
+	// PaymentID is the identifier of the payment the event refers to.
+	PaymentID string `gorm:"index"`
+
+	// Type is the event type.
+	Type string
+
+	// Credits is the amount of credits added (positive) or removed (negative) when processing the event.
+	Credits int
+}
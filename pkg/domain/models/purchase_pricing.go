@@ -0,0 +1,37 @@
+package models
+
+import "gorm.io/gorm"
+
+// VolumeTier gives bonus credits to the purchases of an application of at least a certain amount.
+type VolumeTier struct {
+	gorm.Model
+
+	// Application is the application the tier applies to.
+	Application string `gorm:"uniqueIndex:idx_volume_tier;size:255"`
+
+	// MinAmount is the minimum amount a purchase must have to reach the tier, inclusive.
+	MinAmount uint `gorm:"uniqueIndex:idx_volume_tier"`
+
+	// BonusPercent is the percentage of extra credits given to the purchases that reach the tier.
+	BonusPercent uint
+}
+
+// CreditPackage is a fixed amount of credits sold by an application for a fixed amount.
+type CreditPackage struct {
+	gorm.Model
+
+	// Application is the application that sells the package.
+	Application string `gorm:"uniqueIndex:idx_credit_package_code;uniqueIndex:idx_credit_package_amount;size:255"`
+
+	// Code is the unique identifier of the package in the Application.
+	Code string `gorm:"uniqueIndex:idx_credit_package_code;size:64"`
+
+	// Name is a human-readable name of the package.
+	Name string
+
+	// Credits is the amount of credits the package contains.
+	Credits uint
+
+	// Amount is the price of the package. Packages are matched by amount, so it's unique in the Application.
+	Amount uint `gorm:"uniqueIndex:idx_credit_package_amount"`
+}
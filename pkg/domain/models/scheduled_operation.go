@@ -0,0 +1,41 @@
+package models
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+// ScheduledOperation is an operation that increases or decreases the credits of a customer at a certain time.
+type ScheduledOperation struct {
+	gorm.Model
+
+	// Handle is the customer whose credits are changed.
+	Handle string `gorm:"index:idx_scheduled_operation_customer;size:255"`
+
+	// Application is the application that the credits are being tracked for.
+	Application string `gorm:"index:idx_scheduled_operation_customer;size:255"`
+
+	// Type is the type of operation (e.g. increase).
+	Type string `gorm:"size:16"`
+
+	// Amount is the money amount of the operation, converted to credits when it runs.
+	Amount uint
+
+	// Currency is the currency of Amount.
+	Currency string `gorm:"size:3"`
+
+	// RunAt is the time the operation runs at.
+	RunAt time.Time `gorm:"index:idx_scheduled_operation_due"`
+
+	// Status is the status of the operation.
+	Status string `gorm:"index:idx_scheduled_operation_due;size:16"`
+
+	// TransactionID is the ID of the BalanceChange made by the operation. It's nil until the operation succeeds.
+	TransactionID *uint
+
+	// Error is the reason the operation failed.
+	Error string `gorm:"size:1024"`
+
+	// ExecutedAt is the time the operation ran.
+	ExecutedAt *time.Time
+}
@@ -0,0 +1,45 @@
+package models
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+// Session is a usage session of a certain resource billed by time. Customers are charged Price credits for every
+// Unit of time the session stays open.
+type Session struct {
+	gorm.Model
+
+	// Handle contains the handle of the customer being charged.
+	Handle string `gorm:"index:idx_session_customer"`
+
+	// Application is the application that the credits are being tracked for.
+	Application string `gorm:"index:idx_session_customer"`
+
+	// Price is the amount of credits charged for each Unit of time.
+	Price uint
+
+	// Unit is the time unit used to charge the session, it should be a valid duration string (e.g. "1m").
+	Unit string
+
+	// Status is the current status of the session. See api.SessionStatus.
+	Status string `gorm:"index"`
+
+	// CloseReason contains the reason why the session was closed.
+	CloseReason string
+
+	// NotifyURL is an optional URL that will be notified when the session gets closed by the service.
+	NotifyURL string
+
+	// Charged is the total amount of credits charged so far.
+	Charged uint
+
+	// ChargedUntil is the point in time until which the usage has been charged.
+	ChargedUntil time.Time
+
+	// LastHeartbeatAt is the time the last heartbeat was received.
+	LastHeartbeatAt time.Time
+
+	// ClosedAt is the time the session was closed.
+	ClosedAt *time.Time
+}
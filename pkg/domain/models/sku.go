@@ -0,0 +1,42 @@
+package models
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+// SKU is a billable resource offered by a certain application. The amount of credits charged for each unit of a SKU
+// is defined by its price versions, see SKUPrice.
+type SKU struct {
+	gorm.Model
+
+	// Application is the application that offers this SKU.
+	Application string `gorm:"index:idx_sku_code"`
+
+	// Code is the identifier of this SKU. It's unique for each Application.
+	Code string `gorm:"index:idx_sku_code"`
+
+	// Description is a human-readable description of the SKU.
+	Description string
+
+	// Unit is the unit the SKU is billed by.
+	Unit string
+
+	// Prices contains the price versions of this SKU.
+	Prices []SKUPrice
+}
+
+// SKUPrice is a version of the price of a certain SKU. The price in effect at a certain time is the one with the
+// latest EffectiveFrom date that is not after that time.
+type SKUPrice struct {
+	gorm.Model
+
+	// SKUID is the ID of the SKU this price belongs to.
+	SKUID uint `gorm:"index"`
+
+	// Price is the amount of credits charged for each unit.
+	Price uint
+
+	// EffectiveFrom is the date this price is in effect since.
+	EffectiveFrom time.Time
+}
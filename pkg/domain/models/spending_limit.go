@@ -0,0 +1,25 @@
+package models
+
+import "gorm.io/gorm"
+
+// SpendingLimit contains the caps on how fast the customers of an application can decrease their credits. Limits
+// with an empty Handle apply to every customer of the application, limits with a Handle override them for that
+// customer. Zero values disable the cap.
+type SpendingLimit struct {
+	gorm.Model
+
+	// Application is the application the limits apply to.
+	Application string `gorm:"uniqueIndex:idx_spending_limit;size:255"`
+
+	// Handle is the customer the limits apply to. It's empty for the application default.
+	Handle string `gorm:"uniqueIndex:idx_spending_limit;size:255"`
+
+	// CreditsPerHour is the maximum amount of credits decreased in the last hour.
+	CreditsPerHour uint
+
+	// CreditsPerDay is the maximum amount of credits decreased in the last 24 hours.
+	CreditsPerDay uint
+
+	// DecreasesPerMinute is the maximum amount of decreases made in the last minute.
+	DecreasesPerMinute uint
+}
@@ -0,0 +1,75 @@
+package models
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+// Subscription grants a fixed amount of credits to a customer every period.
+type Subscription struct {
+	gorm.Model
+
+	// Handle is the customer that receives the credits.
+	Handle string `gorm:"index:idx_subscription_customer;size:255"`
+
+	// Application is the application that the credits are being tracked for.
+	Application string `gorm:"index:idx_subscription_customer;size:255"`
+
+	// Credits is the amount of credits granted every period.
+	Credits uint
+
+	// Schedule is how often credits are granted (e.g. monthly).
+	Schedule string `gorm:"size:16"`
+
+	// Rollover is what happens to the unused credits when a new period starts.
+	Rollover string `gorm:"size:16"`
+
+	// RolloverCap is the maximum amount of unused credits carried over to the next period.
+	RolloverCap uint
+
+	// Status is the status of the subscription.
+	Status string `gorm:"index:idx_subscription_due;size:16"`
+
+	// StartAt is the time the first period starts. Periods are computed from this time.
+	StartAt time.Time
+
+	// Periods is the amount of periods granted so far.
+	Periods uint
+
+	// NextGrantAt is the time the next period starts.
+	NextGrantAt time.Time `gorm:"index:idx_subscription_due"`
+
+	// Allowance is the amount of credits granted by the subscription that the customer had after the last grant.
+	Allowance uint
+
+	// TransactionID is the ID of the BalanceChange of the last grant. Credits removed after it are taken from the
+	// allowance first.
+	TransactionID *uint
+}
+
+// SubscriptionGrant records the grant of a single period of a subscription. There is at most one grant per period,
+// so periods can't be granted twice.
+type SubscriptionGrant struct {
+	gorm.Model
+
+	// SubscriptionID is the subscription that made the grant.
+	SubscriptionID uint `gorm:"uniqueIndex:idx_subscription_grant"`
+
+	// Period is the index of the granted period, starting at 0.
+	Period uint `gorm:"uniqueIndex:idx_subscription_grant"`
+
+	// PeriodStart is the time the period started.
+	PeriodStart time.Time
+
+	// Granted is the amount of credits granted.
+	Granted uint
+
+	// Carried is the amount of unused credits carried over from the previous period.
+	Carried uint
+
+	// Expired is the amount of unused credits of the previous period that expired.
+	Expired uint
+
+	// TransactionID is the ID of the BalanceChange that granted the credits. It's nil if no credits were granted.
+	TransactionID *uint
+}
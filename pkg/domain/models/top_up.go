@@ -0,0 +1,63 @@
+package models
+
+import "gorm.io/gorm"
+
+// AutoTopUp contains the automatic top-up settings of a customer. Customers are charged Amount through the payment
+// provider, and credited the equivalent amount of credits, when their balance drops below Threshold.
+type AutoTopUp struct {
+	gorm.Model
+
+	// Handle is the customer that is topped up.
+	Handle string `gorm:"uniqueIndex:idx_auto_top_up_customer;size:255"`
+
+	// Application is the application that credits are tracked for.
+	Application string `gorm:"uniqueIndex:idx_auto_top_up_customer;size:255"`
+
+	// Threshold is the balance below which the customer is topped up.
+	Threshold int
+
+	// Amount is the amount of money charged on every top-up.
+	Amount uint
+
+	// Currency is the currency of Amount.
+	Currency string
+
+	// MaxPerDay is the maximum amount of top-ups in a 24 hours period.
+	MaxPerDay int
+
+	// PaymentMethod identifies the payment method charged in the payment provider.
+	PaymentMethod string
+}
+
+// TopUp is a single automatic top-up. Top-ups are created as pending before charging the payment provider, and
+// they're completed once the payment is made and the credits are added to the customer.
+type TopUp struct {
+	gorm.Model
+
+	// Handle is the customer that is topped up.
+	Handle string `gorm:"index:idx_top_up_customer"`
+
+	// Application is the application that credits are tracked for.
+	Application string `gorm:"index:idx_top_up_customer"`
+
+	// Amount is the amount of money charged.
+	Amount uint
+
+	// Currency is the currency of Amount.
+	Currency string
+
+	// PaymentMethod identifies the payment method charged in the payment provider.
+	PaymentMethod string
+
+	// Credits is the amount of credits added to the customer.
+	Credits uint
+
+	// Status is the top-up status. See api.TopUpStatus.
+	Status string `gorm:"index"`
+
+	// PaymentID is the identifier of the payment in the payment provider.
+	PaymentID string
+
+	// Error contains the reason a failed top-up failed, or the last error of a pending top-up being retried.
+	Error string `gorm:"type:text"`
+}
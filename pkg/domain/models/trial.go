@@ -0,0 +1,51 @@
+package models
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+// TrialPolicy defines the welcome credits granted to the new customers of an application.
+type TrialPolicy struct {
+	gorm.Model
+
+	// Application is the application the policy applies to.
+	Application string `gorm:"uniqueIndex;size:255"`
+
+	// Credits is the amount of credits granted to new customers.
+	Credits uint
+
+	// ExpiresIn is the amount of seconds the welcome credits last. Zero means they never expire.
+	ExpiresIn uint
+}
+
+// TrialGrant records the welcome credits granted to a handle. Grants are never deleted, so every handle receives the
+// welcome credits at most once.
+type TrialGrant struct {
+	gorm.Model
+
+	// Application is the application that the credits are being tracked for.
+	Application string `gorm:"uniqueIndex:idx_trial_grant;size:255"`
+
+	// Handle is the customer that received the credits.
+	Handle string `gorm:"uniqueIndex:idx_trial_grant;size:255"`
+
+	// PreviousHandle is the handle that received the credits before changing to Handle. It's empty unless the
+	// grant was recorded for a handle change.
+	PreviousHandle string `gorm:"size:255"`
+
+	// Credits is the amount of credits granted.
+	Credits uint
+
+	// TransactionID is the ID of the BalanceChange that granted the credits.
+	TransactionID *uint
+
+	// ExpiresAt is the time the unused credits expire. It's nil for credits that never expire.
+	ExpiresAt *time.Time `gorm:"index"`
+
+	// ExpiredAt is the time the unused credits were removed.
+	ExpiredAt *time.Time
+
+	// Expired is the amount of unused credits removed.
+	Expired uint
+}
@@ -0,0 +1,48 @@
+package models
+
+import "gorm.io/gorm"
+
+// WalletMember is a customer allowed to spend the credits of a wallet. Wallets are regular customers whose credits
+// are shared by their members.
+type WalletMember struct {
+	gorm.Model
+
+	// Application is the application that the credits are being tracked for.
+	Application string `gorm:"uniqueIndex:idx_wallet_member;size:255"`
+
+	// Wallet is the handle of the wallet customer.
+	Wallet string `gorm:"uniqueIndex:idx_wallet_member;size:255"`
+
+	// Member is the handle of the member.
+	Member string `gorm:"uniqueIndex:idx_wallet_member;size:255"`
+
+	// Allowance is the maximum amount of credits the member can spend from the wallet. It's nil for members without
+	// an allowance.
+	Allowance *uint
+
+	// Spent is the amount of credits the member has spent from the wallet.
+	Spent uint
+}
+
+// WalletSpend attributes a BalanceChange of a wallet to the member that spent the credits.
+type WalletSpend struct {
+	gorm.Model
+
+	// Application is the application that the credits are being tracked for.
+	Application string `gorm:"index:idx_wallet_spend"`
+
+	// Wallet is the handle of the wallet customer.
+	Wallet string `gorm:"index:idx_wallet_spend"`
+
+	// Member is the handle of the member that spent the credits.
+	Member string `gorm:"index"`
+
+	// TransactionID is the ID of the BalanceChange that decreased the credits of the wallet.
+	TransactionID uint `gorm:"uniqueIndex"`
+
+	// Credits is the amount of credits spent.
+	Credits uint
+
+	// ReversedCredits is the amount of the spent credits returned to the wallet by reversals.
+	ReversedCredits uint
+}
@@ -0,0 +1,58 @@
+package models
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+// Webhook is a URL registered by an application to receive events about the balance of its customers.
+type Webhook struct {
+	gorm.Model
+
+	// Application is the application whose events are sent to the webhook.
+	Application string `gorm:"index"`
+
+	// URL is the URL that receives the events.
+	URL string
+
+	// Secret is the key used to sign the events.
+	Secret string
+
+	// Events contains a comma-separated list of the event types sent to the webhook. All events are sent if empty.
+	Events string
+}
+
+// WebhookDelivery is an event that should be delivered to a certain Webhook. Deliveries are kept as a delivery log.
+type WebhookDelivery struct {
+	gorm.Model
+
+	// WebhookID is the ID of the webhook the event is sent to.
+	WebhookID uint `gorm:"index"`
+
+	// EventID is the unique identifier of the event.
+	EventID string
+
+	// EventType is the type of the event.
+	EventType string
+
+	// Payload is the JSON body sent to the webhook.
+	Payload string `gorm:"type:text"`
+
+	// Status is the delivery status. See api.DeliveryStatus.
+	Status string `gorm:"index:idx_webhook_delivery_status"`
+
+	// Attempts is the amount of times the delivery has been attempted.
+	Attempts int
+
+	// NextAttemptAt is the time the next attempt should be made.
+	NextAttemptAt time.Time `gorm:"index:idx_webhook_delivery_status"`
+
+	// ResponseCode is the HTTP status code returned in the last attempt.
+	ResponseCode int
+
+	// LastError contains the error of the last failed attempt.
+	LastError string `gorm:"type:text"`
+
+	// DeliveredAt is the time the event was accepted by the receiver.
+	DeliveredAt *time.Time
+}
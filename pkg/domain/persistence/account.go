@@ -0,0 +1,118 @@
+package persistence
+
+import (
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/domain/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// FirstOrCreateAccount returns the account of the given application, type and handle, creating it if it doesn't
+// exist. It returns true if the account was created.
+func FirstOrCreateAccount(db *gorm.DB, account models.Account) (models.Account, bool, error) {
+	result := db.Model(&models.Account{}).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&account)
+	if result.Error != nil {
+		return models.Account{}, false, result.Error
+	}
+	if result.RowsAffected > 0 {
+		return account, true, nil
+	}
+
+	var existing models.Account
+	err := db.Model(&models.Account{}).
+		Where("application = ? AND type = ? AND handle = ?", account.Application, account.Type, account.Handle).
+		First(&existing).Error
+	if err != nil {
+		return models.Account{}, false, err
+	}
+	return existing, false, nil
+}
+
+// GetAccount returns the account identified by the given id.
+func GetAccount(db *gorm.DB, id uint) (models.Account, error) {
+	var result models.Account
+	if err := db.Model(&models.Account{}).First(&result, id).Error; err != nil {
+		return models.Account{}, err
+	}
+	return result, nil
+}
+
+// GetAccounts returns the accounts of an application. If accountType is not empty, only accounts of that type
+// are returned.
+func GetAccounts(db *gorm.DB, application, accountType string) ([]models.Account, error) {
+	q := db.Model(&models.Account{}).Where("application = ?", application)
+	if len(accountType) > 0 {
+		q = q.Where("type = ?", accountType)
+	}
+
+	var result []models.Account
+	if err := q.Order("id").Find(&result).Error; err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// PostEntries creates the given entries and adds their amounts to the balance of their accounts.
+func PostEntries(db *gorm.DB, entries []models.Entry) error {
+	if err := db.Model(&models.Entry{}).Create(&entries).Error; err != nil {
+		return err
+	}
+	for _, e := range entries {
+		err := db.Model(&models.Account{}).
+			Where("id = ?", e.AccountID).
+			Update("balance", gorm.Expr("balance + ?", e.Amount)).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetEntries returns the latest entries of an account, newest first.
+func GetEntries(db *gorm.DB, accountID uint, limit int) ([]models.Entry, error) {
+	var result []models.Entry
+	err := db.Model(&models.Entry{}).
+		Where("account_id = ?", accountID).
+		Order("id DESC").
+		Limit(limit).
+		Find(&result).Error
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetTransactionEntries returns the entries posted by the given transaction.
+func GetTransactionEntries(db *gorm.DB, transactionID uint) ([]models.Entry, error) {
+	var result []models.Entry
+	err := db.Model(&models.Entry{}).
+		Where("transaction_id = ?", transactionID).
+		Order("id").
+		Find(&result).Error
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// AccountEntriesSum is the sum of the entries of a single account.
+type AccountEntriesSum struct {
+	AccountID uint
+	Sum       int
+}
+
+// SumEntriesByAccount returns the sum of the entries of each account of an application.
+func SumEntriesByAccount(db *gorm.DB, application string) ([]AccountEntriesSum, error) {
+	var result []AccountEntriesSum
+	err := db.Model(&models.Entry{}).
+		Select("entries.account_id, COALESCE(SUM(entries.amount), 0) AS sum").
+		Joins("JOIN accounts ON accounts.id = entries.account_id").
+		Where("accounts.application = ?", application).
+		Group("entries.account_id").
+		Scan(&result).Error
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,107 @@
+package persistence
+
+import (
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/domain/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+// CreateBalanceChange records a new balance change.
+func CreateBalanceChange(db *gorm.DB, change models.BalanceChange) (models.BalanceChange, error) {
+	if err := db.Model(&models.BalanceChange{}).Create(&change).Error; err != nil {
+		return models.BalanceChange{}, err
+	}
+	return change, nil
+}
+
+// GetBalanceChanges returns the balance changes identified by the given ids.
+func GetBalanceChanges(db *gorm.DB, ids []uint) ([]models.BalanceChange, error) {
+	var result []models.BalanceChange
+	err := db.Model(&models.BalanceChange{}).
+		Unscoped().
+		Where("id IN ?", ids).
+		Find(&result).Error
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetBalanceChangesSince returns the balance changes of a certain customer recorded after the given time, sorted
+// from the oldest to the newest.
+func GetBalanceChangesSince(db *gorm.DB, handle, application string, since time.Time) ([]models.BalanceChange, error) {
+	var result []models.BalanceChange
+	err := db.Model(&models.BalanceChange{}).
+		Where("handle = ? AND application = ? AND created_at > ?", handle, application, since).
+		Order("created_at, id").
+		Find(&result).Error
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// SumBalanceChangesSince returns the sum of the balance changes of a certain customer recorded after the given time.
+func SumBalanceChangesSince(db *gorm.DB, handle, application string, since time.Time) (int, error) {
+	var result int
+	err := db.Model(&models.BalanceChange{}).
+		Select("COALESCE(SUM(value), 0)").
+		Where("handle = ? AND application = ? AND created_at > ?", handle, application, since).
+		Row().Scan(&result)
+	if err != nil {
+		return 0, err
+	}
+	return result, nil
+}
+
+// SumDebitsSince returns the amount of credits removed and the amount of balance changes that removed them with any
+// of the given operations for a certain customer after the given time.
+func SumDebitsSince(db *gorm.DB, handle, application string, operations []string, since time.Time) (uint, uint, error) {
+	var credits, count uint
+	err := db.Model(&models.BalanceChange{}).
+		Select("COALESCE(SUM(-value), 0), COUNT(*)").
+		Where("handle = ? AND application = ? AND operation IN ? AND value < 0 AND created_at > ?", handle, application, operations, since).
+		Row().Scan(&credits, &count)
+	if err != nil {
+		return 0, 0, err
+	}
+	return credits, count, nil
+}
+
+// SumDebitsAfter returns the amount of credits removed from a customer by the balance changes with the given
+// operations recorded after the given one.
+func SumDebitsAfter(db *gorm.DB, handle, application string, operations []string, id uint) (uint, error) {
+	var credits uint
+	err := db.Model(&models.BalanceChange{}).
+		Select("COALESCE(SUM(-value), 0)").
+		Where("handle = ? AND application = ? AND id > ? AND value < 0 AND operation IN ?", handle, application, id, operations).
+		Row().Scan(&credits)
+	if err != nil {
+		return 0, err
+	}
+	return credits, nil
+}
+
+// GetBalanceChangeForUpdate returns the balance change identified by the given id, locking it until the end of the
+// current transaction.
+func GetBalanceChangeForUpdate(db *gorm.DB, id uint) (models.BalanceChange, error) {
+	var result models.BalanceChange
+	err := db.Model(&models.BalanceChange{}).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		First(&result, id).Error
+	if err != nil {
+		return models.BalanceChange{}, err
+	}
+	return result, nil
+}
+
+// SetBalanceChangeReversal links the given reversal to the balance change it reverses, and adds the reversed
+// credits to the original balance change.
+func SetBalanceChangeReversal(db *gorm.DB, original, reversal models.BalanceChange, credits uint) error {
+	err := db.Model(&reversal).Update("reversal_of", original.ID).Error
+	if err != nil {
+		return err
+	}
+	return db.Model(&original).Update("reversed_credits", original.ReversedCredits+credits).Error
+}
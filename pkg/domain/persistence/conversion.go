@@ -0,0 +1,75 @@
+package persistence
+
+import (
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/domain/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SetConversionPolicy creates or updates the conversion policy of an application.
+func SetConversionPolicy(db *gorm.DB, policy models.ConversionPolicy) (models.ConversionPolicy, error) {
+	err := db.Model(&models.ConversionPolicy{}).
+		Clauses(clause.OnConflict{DoUpdates: clause.AssignmentColumns([]string{"rounding", "milli_credits", "updated_at"})}).
+		Create(&policy).Error
+	if err != nil {
+		return models.ConversionPolicy{}, err
+	}
+	return policy, nil
+}
+
+// GetConversionPolicy returns the conversion policy of an application.
+func GetConversionPolicy(db *gorm.DB, application string) (models.ConversionPolicy, error) {
+	var result models.ConversionPolicy
+	err := db.Model(&models.ConversionPolicy{}).
+		Where("application = ?", application).
+		First(&result).Error
+	if err != nil {
+		return models.ConversionPolicy{}, err
+	}
+	return result, nil
+}
+
+// GetConversionPolicyForUpdate returns the conversion policy of an application, locking it until the end of the
+// current transaction. Applications without a policy get the gap where it would be inserted locked instead.
+func GetConversionPolicyForUpdate(db *gorm.DB, application string) (models.ConversionPolicy, error) {
+	return GetConversionPolicy(db.Clauses(clause.Locking{Strength: "UPDATE"}), application)
+}
+
+// GetConversionPolicyForShare returns the conversion policy of an application, preventing other transactions from
+// changing it until the end of the current transaction.
+func GetConversionPolicyForShare(db *gorm.DB, application string) (models.ConversionPolicy, error) {
+	return GetConversionPolicy(db.Clauses(clause.Locking{Strength: "SHARE"}), application)
+}
+
+// creditSettings contains the models that hold credit values configured for an application.
+var creditSettings = []interface{}{
+	&models.SKU{},
+	&models.Coupon{},
+	&models.CreditPackage{},
+	&models.TrialPolicy{},
+	&models.LowBalanceThreshold{},
+	&models.OverdraftLimit{},
+	&models.SpendingLimit{},
+	&models.Subscription{},
+	&models.AutoTopUp{},
+	&models.WalletMember{},
+}
+
+// HasCreditSettings returns true if an application has any setting expressed in credits, such as SKU prices,
+// coupons, packages, trial policies, low balance thresholds, overdraft limits, spending limits, subscriptions,
+// automatic top-ups or wallet allowances.
+func HasCreditSettings(db *gorm.DB, application string) (bool, error) {
+	for _, model := range creditSettings {
+		var count int64
+		err := db.Model(model).
+			Where("application = ?", application).
+			Count(&count).Error
+		if err != nil {
+			return false, err
+		}
+		if count > 0 {
+			return true, nil
+		}
+	}
+	return false, nil
+}
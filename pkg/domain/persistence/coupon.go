@@ -0,0 +1,84 @@
+package persistence
+
+import (
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/domain/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CreateCoupon creates the given coupon.
+func CreateCoupon(db *gorm.DB, coupon models.Coupon) (models.Coupon, error) {
+	if err := db.Model(&models.Coupon{}).Create(&coupon).Error; err != nil {
+		return models.Coupon{}, err
+	}
+	return coupon, nil
+}
+
+// GetCoupon returns the coupon of an application with the given code.
+func GetCoupon(db *gorm.DB, application, code string) (models.Coupon, error) {
+	var result models.Coupon
+	err := db.Model(&models.Coupon{}).
+		Where("application = ? AND code = ?", application, code).
+		First(&result).Error
+	if err != nil {
+		return models.Coupon{}, err
+	}
+	return result, nil
+}
+
+// GetCouponForUpdate returns the coupon of an application with the given code, locking it until the end of the
+// current transaction.
+func GetCouponForUpdate(db *gorm.DB, application, code string) (models.Coupon, error) {
+	return GetCoupon(db.Clauses(clause.Locking{Strength: "UPDATE"}), application, code)
+}
+
+// GetCoupons returns the coupons of an application sorted by code.
+func GetCoupons(db *gorm.DB, application string) ([]models.Coupon, error) {
+	var result []models.Coupon
+	err := db.Model(&models.Coupon{}).
+		Where("application = ?", application).
+		Order("code").
+		Find(&result).Error
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// IncrementCouponRedemptions adds a redemption to the given coupon.
+func IncrementCouponRedemptions(db *gorm.DB, coupon models.Coupon) error {
+	return db.Model(&coupon).Update("redemptions", gorm.Expr("redemptions + 1")).Error
+}
+
+// CreateCouponRedemption records a new coupon redemption.
+func CreateCouponRedemption(db *gorm.DB, redemption models.CouponRedemption) (models.CouponRedemption, error) {
+	if err := db.Model(&models.CouponRedemption{}).Create(&redemption).Error; err != nil {
+		return models.CouponRedemption{}, err
+	}
+	return redemption, nil
+}
+
+// CountCouponRedemptions returns the amount of times a customer has redeemed the given coupon.
+func CountCouponRedemptions(db *gorm.DB, couponID uint, handle string) (int64, error) {
+	var count int64
+	err := db.Model(&models.CouponRedemption{}).
+		Where("coupon_id = ? AND handle = ?", couponID, handle).
+		Count(&count).Error
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// GetLatestCouponRedemption returns the latest redemption of the given coupon.
+func GetLatestCouponRedemption(db *gorm.DB, couponID uint) (models.CouponRedemption, error) {
+	var result models.CouponRedemption
+	err := db.Model(&models.CouponRedemption{}).
+		Where("coupon_id = ?", couponID).
+		Order("id DESC").
+		First(&result).Error
+	if err != nil {
+		return models.CouponRedemption{}, err
+	}
+	return result, nil
+}
@@ -1,28 +1,59 @@
 package persistence
 
 import (
+	"fmt"
 	"gitlab.com/ignitionrobotics/billing/credits/pkg/domain/models"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"strings"
 )
 
-// CreateCustomer creates a new customer.
+// CreateCustomer creates a new customer. If the customer has an initial amount of credits, an opening
+// models.BalanceChange is recorded.
 func CreateCustomer(db *gorm.DB, customer models.Customer) (models.Customer, error) {
-	if err := db.Model(&models.Customer{}).Create(&customer).Error; err != nil {
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Customer{}).Create(&customer).Error; err != nil {
+			return err
+		}
+		if customer.Credits == 0 {
+			return nil
+		}
+		_, err := CreateBalanceChange(tx, models.BalanceChange{
+			Handle:      customer.Handle,
+			Application: customer.Application,
+			Operation:   models.OperationOpening,
+			Value:       customer.Credits,
+			Balance:     customer.Credits,
+		})
+		return err
+	})
+	if err != nil {
 		return models.Customer{}, err
 	}
 	return customer, nil
 }
 
+// CreateCustomerIfNotExists creates a new customer without credits. It returns false if the customer already exists,
+// in which case nothing is created. Concurrent calls for the same customer wait for each other instead of failing.
+func CreateCustomerIfNotExists(db *gorm.DB, customer models.Customer) (models.Customer, bool, error) {
+	customer.Credits = 0
+	result := db.Model(&models.Customer{}).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&customer)
+	if result.Error != nil {
+		return models.Customer{}, false, result.Error
+	}
+	return customer, result.RowsAffected > 0, nil
+}
+
 // UpdateCredits increases or decreases a certain amount of credits to a specific customer given by its handle
-// for the given application.
-// It creates a new customer if it doesn't exist.
-func UpdateCredits(db *gorm.DB, handle, application string, value int) error {
-	return db.Transaction(func(tx *gorm.DB) error {
-		var c models.Customer
-
-		err := tx.
-			Model(&models.Customer{}).Where("handle = ? AND application = ?", handle, application).
-			First(&c).Error
+// for the given application. The change is recorded as a models.BalanceChange of the given operation.
+// It creates a new customer if it doesn't exist. The customer is locked until the end of the current transaction,
+// and the recorded balance is read back after the update, so concurrent changes are never lost.
+func UpdateCredits(db *gorm.DB, handle, application string, value int, operation string) (models.BalanceChange, error) {
+	var change models.BalanceChange
+	err := db.Transaction(func(tx *gorm.DB) error {
+		c, err := GetCustomerForUpdate(tx, handle, application)
 		if err != nil && err != gorm.ErrRecordNotFound {
 			return err
 		}
@@ -40,7 +71,7 @@ func UpdateCredits(db *gorm.DB, handle, application string, value int) error {
 
 		result := tx.
 			Model(&c).
-			Updates(map[string]interface{}{"credits": c.Credits + value})
+			Update("credits", gorm.Expr("credits + ?", value))
 		if result.Error != nil {
 			return result.Error
 		}
@@ -49,8 +80,23 @@ func UpdateCredits(db *gorm.DB, handle, application string, value int) error {
 			return gorm.ErrRecordNotFound
 		}
 
-		return nil
+		if err = tx.Model(&models.Customer{}).First(&c, c.ID).Error; err != nil {
+			return err
+		}
+
+		change, err = CreateBalanceChange(tx, models.BalanceChange{
+			Handle:      handle,
+			Application: application,
+			Operation:   operation,
+			Value:       value,
+			Balance:     c.Credits,
+		})
+		return err
 	})
+	if err != nil {
+		return models.BalanceChange{}, err
+	}
+	return change, nil
 }
 
 // GetCustomer returns a customer based on the given handle and application.
@@ -64,3 +110,137 @@ func GetCustomer(db *gorm.DB, handle, application string) (models.Customer, erro
 	}
 	return result, nil
 }
+
+// GetCustomerForUpdate returns a customer based on the given handle and application, locking it until the end of
+// the current transaction.
+func GetCustomerForUpdate(db *gorm.DB, handle, application string) (models.Customer, error) {
+	var result models.Customer
+	err := db.Model(&models.Customer{}).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("handle = ? AND application = ?", handle, application).
+		First(&result).Error
+	if err != nil {
+		return models.Customer{}, err
+	}
+	return result, nil
+}
+
+// SetCustomerStatus sets the status of the given customer.
+func SetCustomerStatus(db *gorm.DB, customer models.Customer, status string) error {
+	return db.Model(&customer).Update("status", status).Error
+}
+
+// CreateCustomerStatusChange records a new customer status change.
+func CreateCustomerStatusChange(db *gorm.DB, change models.CustomerStatusChange) (models.CustomerStatusChange, error) {
+	if err := db.Model(&models.CustomerStatusChange{}).Create(&change).Error; err != nil {
+		return models.CustomerStatusChange{}, err
+	}
+	return change, nil
+}
+
+// GetCustomerStatusChanges returns the latest status changes of a customer, newest first.
+func GetCustomerStatusChanges(db *gorm.DB, handle, application string, limit int) ([]models.CustomerStatusChange, error) {
+	var result []models.CustomerStatusChange
+	err := db.Model(&models.CustomerStatusChange{}).
+		Where("handle = ? AND application = ?", handle, application).
+		Order("id DESC").
+		Limit(limit).
+		Find(&result).Error
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetCustomers returns the customers of the given application identified by the given handles. Handles that don't
+// belong to any customer are ignored.
+func GetCustomers(db *gorm.DB, handles []string, application string) ([]models.Customer, error) {
+	var result []models.Customer
+	err := db.Model(&models.Customer{}).
+		Where("application = ? AND handle IN ?", application, handles).
+		Find(&result).Error
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// CountCustomers returns the amount of customers of the given application, including the removed ones.
+func CountCustomers(db *gorm.DB, application string) (int64, error) {
+	var count int64
+	err := db.Unscoped().Model(&models.Customer{}).
+		Where("application = ?", application).
+		Count(&count).Error
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// CustomerListOptions contains the options used to list customers with ListCustomers.
+type CustomerListOptions struct {
+	// Application is the application of the customers.
+	Application string
+
+	// HandlePrefix filters customers whose handle starts with the given prefix.
+	HandlePrefix string
+
+	// MinCredits and MaxCredits filter customers by balance. Nil values are ignored.
+	MinCredits *int
+	MaxCredits *int
+
+	// SortColumn is the column used to sort the customers. Ties are broken by id.
+	SortColumn string
+
+	// Descending sorts the customers from the highest to the lowest value of SortColumn.
+	Descending bool
+
+	// After contains the SortColumn value and AfterID the id of the last customer of the previous page.
+	// They're ignored if AfterID is zero.
+	After   interface{}
+	AfterID uint
+
+	// Limit is the maximum amount of customers returned.
+	Limit int
+}
+
+// ListCustomers returns a page of customers using keyset pagination.
+func ListCustomers(db *gorm.DB, opts CustomerListOptions) ([]models.Customer, error) {
+	q := db.Model(&models.Customer{}).Where("application = ?", opts.Application)
+
+	if len(opts.HandlePrefix) > 0 {
+		q = q.Where("handle LIKE ?", escapeLike(opts.HandlePrefix)+"%")
+	}
+	if opts.MinCredits != nil {
+		q = q.Where("credits >= ?", *opts.MinCredits)
+	}
+	if opts.MaxCredits != nil {
+		q = q.Where("credits <= ?", *opts.MaxCredits)
+	}
+
+	cmp, order := ">", "ASC"
+	if opts.Descending {
+		cmp, order = "<", "DESC"
+	}
+	if opts.AfterID != 0 {
+		q = q.Where(
+			fmt.Sprintf("(%[1]s %[2]s ?) OR (%[1]s = ? AND id %[2]s ?)", opts.SortColumn, cmp),
+			opts.After, opts.After, opts.AfterID,
+		)
+	}
+
+	var result []models.Customer
+	err := q.
+		Order(fmt.Sprintf("%s %s, id %s", opts.SortColumn, order, order)).
+		Limit(opts.Limit).
+		Find(&result).Error
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// escapeLike escapes the wildcard characters of a LIKE pattern.
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
+}
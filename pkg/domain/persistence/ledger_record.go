@@ -0,0 +1,73 @@
+package persistence
+
+import (
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/domain/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// GetLedgerHeadForUpdate returns the head of the ledger chain of an application, creating an empty one if it doesn't
+// exist. The head is locked until the end of the current transaction.
+func GetLedgerHeadForUpdate(db *gorm.DB, application string) (models.LedgerHead, error) {
+	err := db.Model(&models.LedgerHead{}).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&models.LedgerHead{Application: application}).Error
+	if err != nil {
+		return models.LedgerHead{}, err
+	}
+
+	var result models.LedgerHead
+	err = db.Model(&models.LedgerHead{}).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("application = ?", application).
+		First(&result).Error
+	if err != nil {
+		return models.LedgerHead{}, err
+	}
+	return result, nil
+}
+
+// GetLedgerHead returns the head of the ledger chain of an application.
+func GetLedgerHead(db *gorm.DB, application string) (models.LedgerHead, error) {
+	var result models.LedgerHead
+	err := db.Model(&models.LedgerHead{}).
+		Where("application = ?", application).
+		First(&result).Error
+	if err != nil {
+		return models.LedgerHead{}, err
+	}
+	return result, nil
+}
+
+// AppendLedgerRecord creates the given record and moves the given head to it. The head must have been locked with
+// GetLedgerHeadForUpdate in the same transaction.
+func AppendLedgerRecord(db *gorm.DB, head models.LedgerHead, record models.LedgerRecord) (models.LedgerRecord, error) {
+	if err := db.Model(&models.LedgerRecord{}).Create(&record).Error; err != nil {
+		return models.LedgerRecord{}, err
+	}
+
+	err := db.Model(&head).Updates(map[string]interface{}{
+		"sequence": record.Sequence,
+		"hash":     record.Hash,
+	}).Error
+	if err != nil {
+		return models.LedgerRecord{}, err
+	}
+	return record, nil
+}
+
+// GetLedgerRecords returns up to limit records of the ledger chain of an application with a sequence greater than
+// the given one, sorted by sequence.
+func GetLedgerRecords(db *gorm.DB, application string, after uint, limit int) ([]models.LedgerRecord, error) {
+	var result []models.LedgerRecord
+	err := db.Model(&models.LedgerRecord{}).
+		Unscoped().
+		Where("application = ? AND sequence > ?", application, after).
+		Order("sequence").
+		Limit(limit).
+		Find(&result).Error
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,91 @@
+package persistence
+
+import (
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/domain/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+// SetLowBalanceThreshold creates or updates the low balance threshold of an application, or of a single customer
+// if handle is not empty.
+func SetLowBalanceThreshold(db *gorm.DB, threshold models.LowBalanceThreshold) (models.LowBalanceThreshold, error) {
+	err := db.Model(&models.LowBalanceThreshold{}).
+		Clauses(clause.OnConflict{DoUpdates: clause.AssignmentColumns([]string{"threshold", "updated_at"})}).
+		Create(&threshold).Error
+	if err != nil {
+		return models.LowBalanceThreshold{}, err
+	}
+	return threshold, nil
+}
+
+// GetLowBalanceThreshold returns the low balance threshold of an application, or of a single customer if handle
+// is not empty.
+func GetLowBalanceThreshold(db *gorm.DB, application, handle string) (models.LowBalanceThreshold, error) {
+	var result models.LowBalanceThreshold
+	err := db.Model(&models.LowBalanceThreshold{}).
+		Where("application = ? AND handle = ?", application, handle).
+		First(&result).Error
+	if err != nil {
+		return models.LowBalanceThreshold{}, err
+	}
+	return result, nil
+}
+
+// GetEffectiveLowBalanceThreshold returns the low balance threshold that applies to a customer: its own threshold
+// if it has one, or the application threshold otherwise.
+func GetEffectiveLowBalanceThreshold(db *gorm.DB, application, handle string) (models.LowBalanceThreshold, error) {
+	var result models.LowBalanceThreshold
+	err := db.Model(&models.LowBalanceThreshold{}).
+		Where("application = ? AND handle IN ?", application, []string{handle, ""}).
+		Order("handle DESC").
+		First(&result).Error
+	if err != nil {
+		return models.LowBalanceThreshold{}, err
+	}
+	return result, nil
+}
+
+// DeleteLowBalanceThreshold deletes the given low balance threshold.
+func DeleteLowBalanceThreshold(db *gorm.DB, threshold models.LowBalanceThreshold) error {
+	return db.Unscoped().Delete(&threshold).Error
+}
+
+// SetLowBalanceAlerted sets the low balance alert flag of a customer. It returns false if the flag already had
+// the given value, which allows callers to act only once per change even with concurrent updates.
+func SetLowBalanceAlerted(db *gorm.DB, handle, application string, alerted bool) (bool, error) {
+	result := db.Model(&models.Customer{}).
+		Where("handle = ? AND application = ? AND low_balance_alerted = ?", handle, application, !alerted).
+		Update("low_balance_alerted", alerted)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// CreateLowBalanceAlert creates a new low balance alert.
+func CreateLowBalanceAlert(db *gorm.DB, alert models.LowBalanceAlert) (models.LowBalanceAlert, error) {
+	if err := db.Model(&models.LowBalanceAlert{}).Create(&alert).Error; err != nil {
+		return models.LowBalanceAlert{}, err
+	}
+	return alert, nil
+}
+
+// GetPendingLowBalanceAlerts returns up to limit alerts that haven't been notified yet, oldest first.
+func GetPendingLowBalanceAlerts(db *gorm.DB, limit int) ([]models.LowBalanceAlert, error) {
+	var result []models.LowBalanceAlert
+	err := db.Model(&models.LowBalanceAlert{}).
+		Where("notified_at IS NULL").
+		Order("id").
+		Limit(limit).
+		Find(&result).Error
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// MarkLowBalanceAlertNotified sets the notification time of the given alert.
+func MarkLowBalanceAlertNotified(db *gorm.DB, alert models.LowBalanceAlert, at time.Time) error {
+	return db.Model(&alert).Update("notified_at", at).Error
+}
@@ -0,0 +1,40 @@
+package persistence
+
+import (
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/domain/models"
+	"gorm.io/gorm"
+	"time"
+)
+
+// CreateOutboxEvent creates a new outbox event.
+func CreateOutboxEvent(db *gorm.DB, event models.OutboxEvent) (models.OutboxEvent, error) {
+	if err := db.Model(&models.OutboxEvent{}).Create(&event).Error; err != nil {
+		return models.OutboxEvent{}, err
+	}
+	return event, nil
+}
+
+// GetUnpublishedOutboxEvents returns up to limit outbox events that haven't been published yet, in the order they
+// were created.
+func GetUnpublishedOutboxEvents(db *gorm.DB, limit int) ([]models.OutboxEvent, error) {
+	var result []models.OutboxEvent
+	err := db.Model(&models.OutboxEvent{}).
+		Where("published_at IS NULL").
+		Order("id").
+		Limit(limit).
+		Find(&result).Error
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// MarkOutboxEventsPublished sets the publication time of the outbox events identified by the given ids.
+func MarkOutboxEventsPublished(db *gorm.DB, ids []uint, at time.Time) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return db.Model(&models.OutboxEvent{}).
+		Where("id IN ?", ids).
+		Update("published_at", at).Error
+}
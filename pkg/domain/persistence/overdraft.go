@@ -0,0 +1,63 @@
+package persistence
+
+import (
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/domain/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SetOverdraftLimit creates or updates the overdraft limit of an application, or of a single customer if handle is
+// not empty.
+func SetOverdraftLimit(db *gorm.DB, limit models.OverdraftLimit) (models.OverdraftLimit, error) {
+	err := db.Model(&models.OverdraftLimit{}).
+		Clauses(clause.OnConflict{DoUpdates: clause.AssignmentColumns([]string{"amount", "updated_at"})}).
+		Create(&limit).Error
+	if err != nil {
+		return models.OverdraftLimit{}, err
+	}
+	return limit, nil
+}
+
+// GetOverdraftLimit returns the overdraft limit of an application, or of a single customer if handle is not empty.
+func GetOverdraftLimit(db *gorm.DB, application, handle string) (models.OverdraftLimit, error) {
+	var result models.OverdraftLimit
+	err := db.Model(&models.OverdraftLimit{}).
+		Where("application = ? AND handle = ?", application, handle).
+		First(&result).Error
+	if err != nil {
+		return models.OverdraftLimit{}, err
+	}
+	return result, nil
+}
+
+// GetEffectiveOverdraftLimit returns the overdraft limit that applies to a customer: its own limit if it has one,
+// or the application limit otherwise.
+func GetEffectiveOverdraftLimit(db *gorm.DB, application, handle string) (models.OverdraftLimit, error) {
+	var result models.OverdraftLimit
+	err := db.Model(&models.OverdraftLimit{}).
+		Where("application = ? AND handle IN ?", application, []string{handle, ""}).
+		Order("handle DESC").
+		First(&result).Error
+	if err != nil {
+		return models.OverdraftLimit{}, err
+	}
+	return result, nil
+}
+
+// GetEffectiveOverdraftLimits returns the overdraft limits of the given customers of an application, alongside the
+// application limit if it has one. Customers without their own limit are not included.
+func GetEffectiveOverdraftLimits(db *gorm.DB, application string, handles []string) ([]models.OverdraftLimit, error) {
+	var result []models.OverdraftLimit
+	err := db.Model(&models.OverdraftLimit{}).
+		Where("application = ? AND handle IN ?", application, append([]string{""}, handles...)).
+		Find(&result).Error
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// DeleteOverdraftLimit deletes the given overdraft limit.
+func DeleteOverdraftLimit(db *gorm.DB, limit models.OverdraftLimit) error {
+	return db.Unscoped().Delete(&limit).Error
+}
@@ -0,0 +1,68 @@
+package persistence
+
+import (
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/domain/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CreatePayment creates a new payment.
+func CreatePayment(db *gorm.DB, payment models.Payment) (models.Payment, error) {
+	if err := db.Model(&models.Payment{}).Create(&payment).Error; err != nil {
+		return models.Payment{}, err
+	}
+	return payment, nil
+}
+
+// GetPaymentForUpdate returns the payment identified by the given payment provider id, locking it until the end of
+// the current transaction.
+func GetPaymentForUpdate(db *gorm.DB, paymentID string) (models.Payment, error) {
+	var result models.Payment
+	err := db.Model(&models.Payment{}).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("payment_id = ?", paymentID).
+		First(&result).Error
+	if err != nil {
+		return models.Payment{}, err
+	}
+	return result, nil
+}
+
+// GetPaymentByTransactionForUpdate returns the payment credited by the given balance change, locking it until the end
+// of the current transaction.
+func GetPaymentByTransactionForUpdate(db *gorm.DB, transactionID uint) (models.Payment, error) {
+	var result models.Payment
+	err := db.Model(&models.Payment{}).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("transaction_id = ?", transactionID).
+		First(&result).Error
+	if err != nil {
+		return models.Payment{}, err
+	}
+	return result, nil
+}
+
+// SetPaymentDebitedCredits updates the amount of credits debited from the given payment.
+func SetPaymentDebitedCredits(db *gorm.DB, payment models.Payment, debited uint) error {
+	return db.Model(&payment).Update("debited_credits", debited).Error
+}
+
+// CreatePaymentEvent records a new payment event.
+func CreatePaymentEvent(db *gorm.DB, event models.PaymentEvent) (models.PaymentEvent, error) {
+	if err := db.Model(&models.PaymentEvent{}).Create(&event).Error; err != nil {
+		return models.PaymentEvent{}, err
+	}
+	return event, nil
+}
+
+// GetPaymentEvent returns the payment event identified by the given payment provider id.
+func GetPaymentEvent(db *gorm.DB, eventID string) (models.PaymentEvent, error) {
+	var result models.PaymentEvent
+	err := db.Model(&models.PaymentEvent{}).
+		Where("event_id = ?", eventID).
+		First(&result).Error
+	if err != nil {
+		return models.PaymentEvent{}, err
+	}
+	return result, nil
+}
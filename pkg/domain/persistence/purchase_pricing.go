@@ -0,0 +1,82 @@
+package persistence
+
+import (
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/domain/models"
+	"gorm.io/gorm"
+)
+
+// SetVolumeTiers replaces the volume tiers of an application with the given ones.
+func SetVolumeTiers(db *gorm.DB, application string, tiers []models.VolumeTier) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Unscoped().
+			Where("application = ?", application).
+			Delete(&models.VolumeTier{}).Error
+		if err != nil || len(tiers) == 0 {
+			return err
+		}
+		return tx.Model(&models.VolumeTier{}).Create(&tiers).Error
+	})
+}
+
+// GetVolumeTiers returns the volume tiers of an application sorted by minimum amount.
+func GetVolumeTiers(db *gorm.DB, application string) ([]models.VolumeTier, error) {
+	var result []models.VolumeTier
+	err := db.Model(&models.VolumeTier{}).
+		Where("application = ?", application).
+		Order("min_amount").
+		Find(&result).Error
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// CreateCreditPackage creates the given credit package.
+func CreateCreditPackage(db *gorm.DB, creditPackage models.CreditPackage) (models.CreditPackage, error) {
+	if err := db.Model(&models.CreditPackage{}).Create(&creditPackage).Error; err != nil {
+		return models.CreditPackage{}, err
+	}
+	return creditPackage, nil
+}
+
+// GetCreditPackage returns the credit package of an application with the given code.
+func GetCreditPackage(db *gorm.DB, application, code string) (models.CreditPackage, error) {
+	var result models.CreditPackage
+	err := db.Model(&models.CreditPackage{}).
+		Where("application = ? AND code = ?", application, code).
+		First(&result).Error
+	if err != nil {
+		return models.CreditPackage{}, err
+	}
+	return result, nil
+}
+
+// GetCreditPackageByAmount returns the credit package of an application with the given amount.
+func GetCreditPackageByAmount(db *gorm.DB, application string, amount uint) (models.CreditPackage, error) {
+	var result models.CreditPackage
+	err := db.Model(&models.CreditPackage{}).
+		Where("application = ? AND amount = ?", application, amount).
+		First(&result).Error
+	if err != nil {
+		return models.CreditPackage{}, err
+	}
+	return result, nil
+}
+
+// GetCreditPackages returns the credit packages of an application sorted by amount.
+func GetCreditPackages(db *gorm.DB, application string) ([]models.CreditPackage, error) {
+	var result []models.CreditPackage
+	err := db.Model(&models.CreditPackage{}).
+		Where("application = ?", application).
+		Order("amount").
+		Find(&result).Error
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// DeleteCreditPackage deletes the given credit package.
+func DeleteCreditPackage(db *gorm.DB, creditPackage models.CreditPackage) error {
+	return db.Unscoped().Delete(&creditPackage).Error
+}
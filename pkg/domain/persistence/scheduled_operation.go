@@ -0,0 +1,79 @@
+package persistence
+
+import (
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/domain/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+// CreateScheduledOperation creates the given scheduled operation.
+func CreateScheduledOperation(db *gorm.DB, op models.ScheduledOperation) (models.ScheduledOperation, error) {
+	if err := db.Model(&models.ScheduledOperation{}).Create(&op).Error; err != nil {
+		return models.ScheduledOperation{}, err
+	}
+	return op, nil
+}
+
+// GetScheduledOperation returns the scheduled operation with the given ID.
+func GetScheduledOperation(db *gorm.DB, id uint) (models.ScheduledOperation, error) {
+	var result models.ScheduledOperation
+	if err := db.Model(&models.ScheduledOperation{}).First(&result, id).Error; err != nil {
+		return models.ScheduledOperation{}, err
+	}
+	return result, nil
+}
+
+// GetScheduledOperationForUpdate returns the scheduled operation with the given ID, locking it until the end of the
+// current transaction.
+func GetScheduledOperationForUpdate(db *gorm.DB, id uint) (models.ScheduledOperation, error) {
+	return GetScheduledOperation(db.Clauses(clause.Locking{Strength: "UPDATE"}), id)
+}
+
+// GetScheduledOperations returns up to limit scheduled operations of an application sorted by run time. If handle or
+// status are not empty, only the operations of that customer or with that status are returned.
+func GetScheduledOperations(db *gorm.DB, application, handle, status string, limit int) ([]models.ScheduledOperation, error) {
+	q := db.Model(&models.ScheduledOperation{}).Where("application = ?", application)
+	if len(handle) > 0 {
+		q = q.Where("handle = ?", handle)
+	}
+	if len(status) > 0 {
+		q = q.Where("status = ?", status)
+	}
+
+	var result []models.ScheduledOperation
+	if err := q.Order("run_at, id").Limit(limit).Find(&result).Error; err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetDueScheduledOperations returns up to limit scheduled operations with the given status that should have run at
+// or before the given time, oldest first.
+func GetDueScheduledOperations(db *gorm.DB, status string, at time.Time, limit int) ([]models.ScheduledOperation, error) {
+	var result []models.ScheduledOperation
+	err := db.Model(&models.ScheduledOperation{}).
+		Where("status = ? AND run_at <= ?", status, at).
+		Order("run_at, id").
+		Limit(limit).
+		Find(&result).Error
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// FinishScheduledOperation saves the status, the transaction, the error and the execution time of the given
+// scheduled operation.
+func FinishScheduledOperation(db *gorm.DB, op models.ScheduledOperation) (models.ScheduledOperation, error) {
+	err := db.Model(&op).Updates(map[string]interface{}{
+		"status":         op.Status,
+		"transaction_id": op.TransactionID,
+		"error":          op.Error,
+		"executed_at":    op.ExecutedAt,
+	}).Error
+	if err != nil {
+		return models.ScheduledOperation{}, err
+	}
+	return op, nil
+}
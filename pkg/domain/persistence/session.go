@@ -0,0 +1,71 @@
+package persistence
+
+import (
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/domain/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CreateSession creates a new usage session.
+func CreateSession(db *gorm.DB, session models.Session) (models.Session, error) {
+	if err := db.Model(&models.Session{}).Create(&session).Error; err != nil {
+		return models.Session{}, err
+	}
+	return session, nil
+}
+
+// GetSession returns the session identified by the given id.
+func GetSession(db *gorm.DB, id uint) (models.Session, error) {
+	var result models.Session
+	if err := db.Model(&models.Session{}).First(&result, id).Error; err != nil {
+		return models.Session{}, err
+	}
+	return result, nil
+}
+
+// GetSessionForUpdate returns the session identified by the given id, locking its row until the end of the
+// current transaction.
+func GetSessionForUpdate(tx *gorm.DB, id uint) (models.Session, error) {
+	var result models.Session
+	err := tx.Model(&models.Session{}).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		First(&result, id).Error
+	if err != nil {
+		return models.Session{}, err
+	}
+	return result, nil
+}
+
+// GetSessionIDsByStatus returns the identifiers of all the sessions with the given status.
+func GetSessionIDsByStatus(db *gorm.DB, status string) ([]uint, error) {
+	var ids []uint
+	err := db.Model(&models.Session{}).
+		Where("status = ?", status).
+		Order("id").
+		Pluck("id", &ids).Error
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// SaveSession persists all the fields of the given session.
+func SaveSession(db *gorm.DB, session models.Session) (models.Session, error) {
+	if err := db.Save(&session).Error; err != nil {
+		return models.Session{}, err
+	}
+	return session, nil
+}
+
+// GetCustomerSessions returns all the sessions of a certain customer with the given status.
+func GetCustomerSessions(db *gorm.DB, handle, application, status string) ([]models.Session, error) {
+	var result []models.Session
+	err := db.Model(&models.Session{}).
+		Where("handle = ? AND application = ? AND status = ?", handle, application, status).
+		Order("id").
+		Find(&result).Error
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
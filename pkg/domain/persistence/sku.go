@@ -0,0 +1,90 @@
+package persistence
+
+import (
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/domain/models"
+	"gorm.io/gorm"
+	"time"
+)
+
+// CreateSKU creates a new SKU alongside its price versions.
+func CreateSKU(db *gorm.DB, sku models.SKU) (models.SKU, error) {
+	if err := db.Model(&models.SKU{}).Create(&sku).Error; err != nil {
+		return models.SKU{}, err
+	}
+	return sku, nil
+}
+
+// GetSKU returns the SKU identified by the given application and code, including its price versions sorted by
+// effective date.
+func GetSKU(db *gorm.DB, application, code string) (models.SKU, error) {
+	var result models.SKU
+	err := db.Model(&models.SKU{}).
+		Preload("Prices", func(db *gorm.DB) *gorm.DB {
+			return db.Order("effective_from")
+		}).
+		Where("application = ? AND code = ?", application, code).
+		First(&result).Error
+	if err != nil {
+		return models.SKU{}, err
+	}
+	return result, nil
+}
+
+// ListSKUs returns all the SKUs of the given application sorted by code, including their price versions.
+func ListSKUs(db *gorm.DB, application string) ([]models.SKU, error) {
+	var result []models.SKU
+	err := db.Model(&models.SKU{}).
+		Preload("Prices", func(db *gorm.DB) *gorm.DB {
+			return db.Order("effective_from")
+		}).
+		Where("application = ?", application).
+		Order("code").
+		Find(&result).Error
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// UpdateSKU updates the description and unit of the given SKU.
+func UpdateSKU(db *gorm.DB, sku models.SKU, description, unit string) (models.SKU, error) {
+	err := db.Model(&sku).
+		Updates(map[string]interface{}{"description": description, "unit": unit}).Error
+	if err != nil {
+		return models.SKU{}, err
+	}
+	sku.Description = description
+	sku.Unit = unit
+	return sku, nil
+}
+
+// DeleteSKU deletes the given SKU and its price versions.
+func DeleteSKU(db *gorm.DB, sku models.SKU) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("sku_id = ?", sku.ID).Delete(&models.SKUPrice{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&sku).Error
+	})
+}
+
+// CreateSKUPrice adds a new price version to a SKU.
+func CreateSKUPrice(db *gorm.DB, price models.SKUPrice) (models.SKUPrice, error) {
+	if err := db.Model(&models.SKUPrice{}).Create(&price).Error; err != nil {
+		return models.SKUPrice{}, err
+	}
+	return price, nil
+}
+
+// GetEffectiveSKUPrice returns the price version of the given SKU that is in effect at the given time.
+func GetEffectiveSKUPrice(db *gorm.DB, skuID uint, at time.Time) (models.SKUPrice, error) {
+	var result models.SKUPrice
+	err := db.Model(&models.SKUPrice{}).
+		Where("sku_id = ? AND effective_from <= ?", skuID, at).
+		Order("effective_from DESC").
+		First(&result).Error
+	if err != nil {
+		return models.SKUPrice{}, err
+	}
+	return result, nil
+}
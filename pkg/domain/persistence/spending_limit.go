@@ -0,0 +1,52 @@
+package persistence
+
+import (
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/domain/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SetSpendingLimit creates or updates the spending limits of an application, or of a single customer if handle is
+// not empty.
+func SetSpendingLimit(db *gorm.DB, limit models.SpendingLimit) (models.SpendingLimit, error) {
+	err := db.Model(&models.SpendingLimit{}).
+		Clauses(clause.OnConflict{DoUpdates: clause.AssignmentColumns([]string{
+			"credits_per_hour", "credits_per_day", "decreases_per_minute", "updated_at",
+		})}).
+		Create(&limit).Error
+	if err != nil {
+		return models.SpendingLimit{}, err
+	}
+	return limit, nil
+}
+
+// GetSpendingLimit returns the spending limits of an application, or of a single customer if handle is not empty.
+func GetSpendingLimit(db *gorm.DB, application, handle string) (models.SpendingLimit, error) {
+	var result models.SpendingLimit
+	err := db.Model(&models.SpendingLimit{}).
+		Where("application = ? AND handle = ?", application, handle).
+		First(&result).Error
+	if err != nil {
+		return models.SpendingLimit{}, err
+	}
+	return result, nil
+}
+
+// GetEffectiveSpendingLimit returns the spending limits that apply to a customer: its own limits if it has them,
+// or the application limits otherwise.
+func GetEffectiveSpendingLimit(db *gorm.DB, application, handle string) (models.SpendingLimit, error) {
+	var result models.SpendingLimit
+	err := db.Model(&models.SpendingLimit{}).
+		Where("application = ? AND handle IN ?", application, []string{handle, ""}).
+		Order("handle DESC").
+		First(&result).Error
+	if err != nil {
+		return models.SpendingLimit{}, err
+	}
+	return result, nil
+}
+
+// DeleteSpendingLimit deletes the given spending limits.
+func DeleteSpendingLimit(db *gorm.DB, limit models.SpendingLimit) error {
+	return db.Unscoped().Delete(&limit).Error
+}
@@ -0,0 +1,100 @@
+package persistence
+
+import (
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/domain/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+// CreateSubscription creates the given subscription.
+func CreateSubscription(db *gorm.DB, subscription models.Subscription) (models.Subscription, error) {
+	if err := db.Model(&models.Subscription{}).Create(&subscription).Error; err != nil {
+		return models.Subscription{}, err
+	}
+	return subscription, nil
+}
+
+// GetSubscription returns the subscription with the given ID.
+func GetSubscription(db *gorm.DB, id uint) (models.Subscription, error) {
+	var result models.Subscription
+	if err := db.Model(&models.Subscription{}).First(&result, id).Error; err != nil {
+		return models.Subscription{}, err
+	}
+	return result, nil
+}
+
+// GetSubscriptionForUpdate returns the subscription with the given ID, locking it until the end of the current
+// transaction.
+func GetSubscriptionForUpdate(db *gorm.DB, id uint) (models.Subscription, error) {
+	return GetSubscription(db.Clauses(clause.Locking{Strength: "UPDATE"}), id)
+}
+
+// GetSubscriptions returns the subscriptions of a customer, oldest first.
+func GetSubscriptions(db *gorm.DB, handle, application string) ([]models.Subscription, error) {
+	var result []models.Subscription
+	err := db.Model(&models.Subscription{}).
+		Where("handle = ? AND application = ?", handle, application).
+		Order("id").
+		Find(&result).Error
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetDueSubscriptions returns up to limit subscriptions with the given status whose next period started at or
+// before the given time.
+func GetDueSubscriptions(db *gorm.DB, status string, at time.Time, limit int) ([]models.Subscription, error) {
+	var result []models.Subscription
+	err := db.Model(&models.Subscription{}).
+		Where("status = ? AND next_grant_at <= ?", status, at).
+		Order("next_grant_at").
+		Limit(limit).
+		Find(&result).Error
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// SetSubscriptionStatus sets the status of the given subscription.
+func SetSubscriptionStatus(db *gorm.DB, subscription models.Subscription, status string) (models.Subscription, error) {
+	if err := db.Model(&subscription).Update("status", status).Error; err != nil {
+		return models.Subscription{}, err
+	}
+	return subscription, nil
+}
+
+// AdvanceSubscription saves the period, the next grant time, the allowance and the last grant transaction of the
+// given subscription.
+func AdvanceSubscription(db *gorm.DB, subscription models.Subscription) error {
+	return db.Model(&subscription).Updates(map[string]interface{}{
+		"periods":        subscription.Periods,
+		"next_grant_at":  subscription.NextGrantAt,
+		"allowance":      subscription.Allowance,
+		"transaction_id": subscription.TransactionID,
+	}).Error
+}
+
+// CreateSubscriptionGrant records a new subscription grant. It fails if the period has already been granted.
+func CreateSubscriptionGrant(db *gorm.DB, grant models.SubscriptionGrant) (models.SubscriptionGrant, error) {
+	if err := db.Model(&models.SubscriptionGrant{}).Create(&grant).Error; err != nil {
+		return models.SubscriptionGrant{}, err
+	}
+	return grant, nil
+}
+
+// GetSubscriptionGrants returns the latest grants of a subscription, newest first.
+func GetSubscriptionGrants(db *gorm.DB, subscriptionID uint, limit int) ([]models.SubscriptionGrant, error) {
+	var result []models.SubscriptionGrant
+	err := db.Model(&models.SubscriptionGrant{}).
+		Where("subscription_id = ?", subscriptionID).
+		Order("period DESC").
+		Limit(limit).
+		Find(&result).Error
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -9,6 +9,38 @@ import (
 func MigrateTables(db *gorm.DB) error {
 	return db.Migrator().AutoMigrate(
 		&models.Customer{},
+		&models.BalanceChange{},
+		&models.Session{},
+		&models.SKU{},
+		&models.SKUPrice{},
+		&models.Webhook{},
+		&models.WebhookDelivery{},
+		&models.OutboxEvent{},
+		&models.LowBalanceThreshold{},
+		&models.LowBalanceAlert{},
+		&models.AutoTopUp{},
+		&models.TopUp{},
+		&models.Payment{},
+		&models.PaymentEvent{},
+		&models.Account{},
+		&models.Entry{},
+		&models.LedgerRecord{},
+		&models.LedgerHead{},
+		&models.OverdraftLimit{},
+		&models.CustomerStatusChange{},
+		&models.SpendingLimit{},
+		&models.WalletMember{},
+		&models.WalletSpend{},
+		&models.Subscription{},
+		&models.SubscriptionGrant{},
+		&models.ScheduledOperation{},
+		&models.Coupon{},
+		&models.CouponRedemption{},
+		&models.TrialPolicy{},
+		&models.TrialGrant{},
+		&models.VolumeTier{},
+		&models.CreditPackage{},
+		&models.ConversionPolicy{},
 	)
 }
 
@@ -16,5 +48,37 @@ func MigrateTables(db *gorm.DB) error {
 func DropTables(db *gorm.DB) error {
 	return db.Migrator().DropTable(
 		&models.Customer{},
+		&models.BalanceChange{},
+		&models.Session{},
+		&models.SKU{},
+		&models.SKUPrice{},
+		&models.Webhook{},
+		&models.WebhookDelivery{},
+		&models.OutboxEvent{},
+		&models.LowBalanceThreshold{},
+		&models.LowBalanceAlert{},
+		&models.AutoTopUp{},
+		&models.TopUp{},
+		&models.Payment{},
+		&models.PaymentEvent{},
+		&models.Account{},
+		&models.Entry{},
+		&models.LedgerRecord{},
+		&models.LedgerHead{},
+		&models.OverdraftLimit{},
+		&models.CustomerStatusChange{},
+		&models.SpendingLimit{},
+		&models.WalletMember{},
+		&models.WalletSpend{},
+		&models.Subscription{},
+		&models.SubscriptionGrant{},
+		&models.ScheduledOperation{},
+		&models.Coupon{},
+		&models.CouponRedemption{},
+		&models.TrialPolicy{},
+		&models.TrialGrant{},
+		&models.VolumeTier{},
+		&models.CreditPackage{},
+		&models.ConversionPolicy{},
 	)
 }
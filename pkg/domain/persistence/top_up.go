@@ -0,0 +1,137 @@
+package persistence
+
+import (
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/domain/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+// SetAutoTopUp creates or updates the automatic top-up settings of a customer.
+func SetAutoTopUp(db *gorm.DB, settings models.AutoTopUp) (models.AutoTopUp, error) {
+	err := db.Model(&models.AutoTopUp{}).
+		Clauses(clause.OnConflict{DoUpdates: clause.AssignmentColumns([]string{
+			"threshold", "amount", "currency", "max_per_day", "payment_method", "updated_at",
+		})}).
+		Create(&settings).Error
+	if err != nil {
+		return models.AutoTopUp{}, err
+	}
+	return settings, nil
+}
+
+// GetAutoTopUp returns the automatic top-up settings of a customer.
+func GetAutoTopUp(db *gorm.DB, handle, application string) (models.AutoTopUp, error) {
+	var result models.AutoTopUp
+	err := db.Model(&models.AutoTopUp{}).
+		Where("handle = ? AND application = ?", handle, application).
+		First(&result).Error
+	if err != nil {
+		return models.AutoTopUp{}, err
+	}
+	return result, nil
+}
+
+// GetAutoTopUpForUpdate returns the automatic top-up settings of a customer, locking them until the end of the
+// current transaction.
+func GetAutoTopUpForUpdate(db *gorm.DB, handle, application string) (models.AutoTopUp, error) {
+	var result models.AutoTopUp
+	err := db.Model(&models.AutoTopUp{}).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("handle = ? AND application = ?", handle, application).
+		First(&result).Error
+	if err != nil {
+		return models.AutoTopUp{}, err
+	}
+	return result, nil
+}
+
+// DeleteAutoTopUp deletes the given automatic top-up settings.
+func DeleteAutoTopUp(db *gorm.DB, settings models.AutoTopUp) error {
+	return db.Unscoped().Delete(&settings).Error
+}
+
+// GetAutoTopUpCandidates returns up to limit automatic top-up settings of customers whose balance is below their
+// threshold.
+func GetAutoTopUpCandidates(db *gorm.DB, limit int) ([]models.AutoTopUp, error) {
+	var result []models.AutoTopUp
+	err := db.Model(&models.AutoTopUp{}).
+		Joins("JOIN customers ON customers.handle = auto_top_ups.handle AND customers.application = auto_top_ups.application AND customers.deleted_at IS NULL").
+		Where("customers.credits < auto_top_ups.threshold").
+		Order("auto_top_ups.id").
+		Limit(limit).
+		Find(&result).Error
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// CreateTopUp creates a new top-up.
+func CreateTopUp(db *gorm.DB, topUp models.TopUp) (models.TopUp, error) {
+	if err := db.Model(&models.TopUp{}).Create(&topUp).Error; err != nil {
+		return models.TopUp{}, err
+	}
+	return topUp, nil
+}
+
+// FinishTopUp persists the status, payment and error of a top-up whose status is still the given one. It returns
+// false if the status changed in the meantime, in which case nothing is updated.
+func FinishTopUp(db *gorm.DB, topUp models.TopUp, status string) (bool, error) {
+	result := db.Model(&models.TopUp{}).
+		Where("id = ? AND status = ?", topUp.ID, status).
+		Updates(map[string]interface{}{
+			"status":     topUp.Status,
+			"payment_id": topUp.PaymentID,
+			"credits":    topUp.Credits,
+			"error":      topUp.Error,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// GetTopUps returns the latest top-ups of a customer, newest first.
+func GetTopUps(db *gorm.DB, handle, application string, limit int) ([]models.TopUp, error) {
+	var result []models.TopUp
+	err := db.Model(&models.TopUp{}).
+		Where("handle = ? AND application = ?", handle, application).
+		Order("id DESC").
+		Limit(limit).
+		Find(&result).Error
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetTopUpsByStatus returns up to limit top-ups with the given status, oldest first.
+func GetTopUpsByStatus(db *gorm.DB, status string, limit int) ([]models.TopUp, error) {
+	var result []models.TopUp
+	err := db.Model(&models.TopUp{}).
+		Where("status = ?", status).
+		Order("id").
+		Limit(limit).
+		Find(&result).Error
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// CountTopUpsSince returns the amount of top-ups of a customer created after the given time. If status is not
+// empty, only top-ups with that status are counted.
+func CountTopUpsSince(db *gorm.DB, handle, application, status string, since time.Time) (int64, error) {
+	q := db.Model(&models.TopUp{}).
+		Where("handle = ? AND application = ? AND created_at > ?", handle, application, since)
+	if len(status) > 0 {
+		q = q.Where("status = ?", status)
+	}
+
+	var result int64
+	if err := q.Count(&result).Error; err != nil {
+		return 0, err
+	}
+	return result, nil
+}
@@ -0,0 +1,101 @@
+package persistence
+
+import (
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/domain/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+// SetTrialPolicy creates or updates the trial policy of an application.
+func SetTrialPolicy(db *gorm.DB, policy models.TrialPolicy) (models.TrialPolicy, error) {
+	err := db.Model(&models.TrialPolicy{}).
+		Clauses(clause.OnConflict{DoUpdates: clause.AssignmentColumns([]string{"credits", "expires_in", "updated_at"})}).
+		Create(&policy).Error
+	if err != nil {
+		return models.TrialPolicy{}, err
+	}
+	return policy, nil
+}
+
+// GetTrialPolicy returns the trial policy of an application.
+func GetTrialPolicy(db *gorm.DB, application string) (models.TrialPolicy, error) {
+	var result models.TrialPolicy
+	err := db.Model(&models.TrialPolicy{}).
+		Where("application = ?", application).
+		First(&result).Error
+	if err != nil {
+		return models.TrialPolicy{}, err
+	}
+	return result, nil
+}
+
+// DeleteTrialPolicy deletes the given trial policy.
+func DeleteTrialPolicy(db *gorm.DB, policy models.TrialPolicy) error {
+	return db.Unscoped().Delete(&policy).Error
+}
+
+// CreateTrialGrant records the given trial grant. It returns false if the handle already has a grant, in which case
+// nothing is recorded.
+func CreateTrialGrant(db *gorm.DB, grant models.TrialGrant) (models.TrialGrant, bool, error) {
+	result := db.Model(&models.TrialGrant{}).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&grant)
+	if result.Error != nil {
+		return models.TrialGrant{}, false, result.Error
+	}
+	return grant, result.RowsAffected > 0, nil
+}
+
+// GetTrialGrant returns the trial grant of a handle.
+func GetTrialGrant(db *gorm.DB, handle, application string) (models.TrialGrant, error) {
+	var result models.TrialGrant
+	err := db.Model(&models.TrialGrant{}).
+		Where("handle = ? AND application = ?", handle, application).
+		First(&result).Error
+	if err != nil {
+		return models.TrialGrant{}, err
+	}
+	return result, nil
+}
+
+// GetTrialGrantForUpdate returns the trial grant with the given ID, locking it until the end of the current
+// transaction.
+func GetTrialGrantForUpdate(db *gorm.DB, id uint) (models.TrialGrant, error) {
+	var result models.TrialGrant
+	err := db.Model(&models.TrialGrant{}).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		First(&result, id).Error
+	if err != nil {
+		return models.TrialGrant{}, err
+	}
+	return result, nil
+}
+
+// SetTrialGrantTransaction sets the BalanceChange that granted the credits of the given trial grant.
+func SetTrialGrantTransaction(db *gorm.DB, grant models.TrialGrant, transactionID uint) error {
+	return db.Model(&grant).Update("transaction_id", transactionID).Error
+}
+
+// GetExpiredTrialGrants returns up to limit trial grants whose credits expired at or before the given time and
+// haven't been removed yet.
+func GetExpiredTrialGrants(db *gorm.DB, at time.Time, limit int) ([]models.TrialGrant, error) {
+	var result []models.TrialGrant
+	err := db.Model(&models.TrialGrant{}).
+		Where("expires_at <= ? AND expired_at IS NULL", at).
+		Order("expires_at").
+		Limit(limit).
+		Find(&result).Error
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// ExpireTrialGrant marks the given trial grant as expired.
+func ExpireTrialGrant(db *gorm.DB, grant models.TrialGrant) error {
+	return db.Model(&grant).Updates(map[string]interface{}{
+		"expired_at": grant.ExpiredAt,
+		"expired":    grant.Expired,
+	}).Error
+}
@@ -0,0 +1,103 @@
+package persistence
+
+import (
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/domain/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SetWalletMember creates the given wallet member, or updates its allowance if it already exists.
+func SetWalletMember(db *gorm.DB, member models.WalletMember) (models.WalletMember, error) {
+	err := db.Model(&models.WalletMember{}).
+		Clauses(clause.OnConflict{DoUpdates: clause.AssignmentColumns([]string{"allowance", "updated_at"})}).
+		Create(&member).Error
+	if err != nil {
+		return models.WalletMember{}, err
+	}
+	return GetWalletMember(db, member.Application, member.Wallet, member.Member)
+}
+
+// GetWalletMember returns a single member of a wallet.
+func GetWalletMember(db *gorm.DB, application, wallet, member string) (models.WalletMember, error) {
+	var result models.WalletMember
+	err := db.Model(&models.WalletMember{}).
+		Where("application = ? AND wallet = ? AND member = ?", application, wallet, member).
+		First(&result).Error
+	if err != nil {
+		return models.WalletMember{}, err
+	}
+	return result, nil
+}
+
+// GetWalletMemberForUpdate returns a single member of a wallet, locking it until the end of the current transaction.
+func GetWalletMemberForUpdate(db *gorm.DB, application, wallet, member string) (models.WalletMember, error) {
+	return GetWalletMember(db.Clauses(clause.Locking{Strength: "UPDATE"}), application, wallet, member)
+}
+
+// GetWalletMembers returns the members of a wallet sorted by handle.
+func GetWalletMembers(db *gorm.DB, application, wallet string) ([]models.WalletMember, error) {
+	var result []models.WalletMember
+	err := db.Model(&models.WalletMember{}).
+		Where("application = ? AND wallet = ?", application, wallet).
+		Order("member").
+		Find(&result).Error
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// DeleteWalletMember deletes the given wallet member.
+func DeleteWalletMember(db *gorm.DB, member models.WalletMember) error {
+	return db.Unscoped().Delete(&member).Error
+}
+
+// AddWalletMemberSpent adds the given credits to the credits spent by a wallet member.
+func AddWalletMemberSpent(db *gorm.DB, member models.WalletMember, credits uint) error {
+	return db.Model(&member).Update("spent", gorm.Expr("spent + ?", credits)).Error
+}
+
+// SetWalletMemberSpent sets the credits spent by a wallet member.
+func SetWalletMemberSpent(db *gorm.DB, member models.WalletMember, spent uint) error {
+	return db.Model(&member).Update("spent", spent).Error
+}
+
+// CreateWalletSpend records a new wallet spend.
+func CreateWalletSpend(db *gorm.DB, spend models.WalletSpend) (models.WalletSpend, error) {
+	if err := db.Model(&models.WalletSpend{}).Create(&spend).Error; err != nil {
+		return models.WalletSpend{}, err
+	}
+	return spend, nil
+}
+
+// GetWalletSpends returns the latest spends of a wallet, newest first. If member is not empty, only the spends of
+// that member are returned.
+func GetWalletSpends(db *gorm.DB, application, wallet, member string, limit int) ([]models.WalletSpend, error) {
+	q := db.Model(&models.WalletSpend{}).Where("application = ? AND wallet = ?", application, wallet)
+	if len(member) > 0 {
+		q = q.Where("member = ?", member)
+	}
+
+	var result []models.WalletSpend
+	if err := q.Order("id DESC").Limit(limit).Find(&result).Error; err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetWalletSpendByTransaction returns the wallet spend recorded for the given balance change.
+func GetWalletSpendByTransaction(db *gorm.DB, transactionID uint) (models.WalletSpend, error) {
+	var result models.WalletSpend
+	err := db.Model(&models.WalletSpend{}).
+		Where("transaction_id = ?", transactionID).
+		First(&result).Error
+	if err != nil {
+		return models.WalletSpend{}, err
+	}
+	return result, nil
+}
+
+// AddWalletSpendReversedCredits adds the given credits to the reversed credits of a wallet spend.
+func AddWalletSpendReversedCredits(db *gorm.DB, spend models.WalletSpend, credits uint) error {
+	return db.Model(&spend).Update("reversed_credits", gorm.Expr("reversed_credits + ?", credits)).Error
+}
@@ -0,0 +1,111 @@
+package persistence
+
+import (
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/domain/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+// CreateWebhook creates a new webhook.
+func CreateWebhook(db *gorm.DB, webhook models.Webhook) (models.Webhook, error) {
+	if err := db.Model(&models.Webhook{}).Create(&webhook).Error; err != nil {
+		return models.Webhook{}, err
+	}
+	return webhook, nil
+}
+
+// GetWebhook returns the webhook identified by the given id.
+func GetWebhook(db *gorm.DB, id uint) (models.Webhook, error) {
+	var result models.Webhook
+	if err := db.Model(&models.Webhook{}).First(&result, id).Error; err != nil {
+		return models.Webhook{}, err
+	}
+	return result, nil
+}
+
+// GetWebhooks returns all the webhooks of the given application.
+func GetWebhooks(db *gorm.DB, application string) ([]models.Webhook, error) {
+	var result []models.Webhook
+	err := db.Model(&models.Webhook{}).
+		Where("application = ?", application).
+		Order("id").
+		Find(&result).Error
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// DeleteWebhook deletes the given webhook.
+func DeleteWebhook(db *gorm.DB, webhook models.Webhook) error {
+	return db.Delete(&webhook).Error
+}
+
+// CreateWebhookDelivery creates a new webhook delivery.
+func CreateWebhookDelivery(db *gorm.DB, delivery models.WebhookDelivery) (models.WebhookDelivery, error) {
+	if err := db.Model(&models.WebhookDelivery{}).Create(&delivery).Error; err != nil {
+		return models.WebhookDelivery{}, err
+	}
+	return delivery, nil
+}
+
+// GetWebhookDelivery returns the webhook delivery identified by the given id.
+func GetWebhookDelivery(db *gorm.DB, id uint) (models.WebhookDelivery, error) {
+	var result models.WebhookDelivery
+	if err := db.Model(&models.WebhookDelivery{}).First(&result, id).Error; err != nil {
+		return models.WebhookDelivery{}, err
+	}
+	return result, nil
+}
+
+// GetWebhookDeliveries returns the latest deliveries of a webhook, newest first. If status is not empty, only
+// deliveries with that status are returned.
+func GetWebhookDeliveries(db *gorm.DB, webhookID uint, status string, limit int) ([]models.WebhookDelivery, error) {
+	q := db.Model(&models.WebhookDelivery{}).Where("webhook_id = ?", webhookID)
+	if len(status) > 0 {
+		q = q.Where("status = ?", status)
+	}
+
+	var result []models.WebhookDelivery
+	if err := q.Order("id DESC").Limit(limit).Find(&result).Error; err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetDueWebhookDeliveries returns up to limit deliveries with the given status that should be attempted at the
+// given time, oldest first.
+func GetDueWebhookDeliveries(db *gorm.DB, status string, at time.Time, limit int) ([]models.WebhookDelivery, error) {
+	var result []models.WebhookDelivery
+	err := db.Model(&models.WebhookDelivery{}).
+		Where("status = ? AND next_attempt_at <= ?", status, at).
+		Order("next_attempt_at, id").
+		Limit(limit).
+		Find(&result).Error
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetDueWebhookDeliveriesForUpdate works like GetDueWebhookDeliveries, but locks the returned deliveries until the
+// end of the current transaction. Deliveries already locked by another transaction are skipped.
+func GetDueWebhookDeliveriesForUpdate(db *gorm.DB, status string, at time.Time, limit int) ([]models.WebhookDelivery, error) {
+	return GetDueWebhookDeliveries(db.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}), status, at, limit)
+}
+
+// SetWebhookDeliveriesNextAttempt sets the time of the next attempt of the deliveries identified by the given ids.
+func SetWebhookDeliveriesNextAttempt(db *gorm.DB, ids []uint, at time.Time) error {
+	return db.Model(&models.WebhookDelivery{}).
+		Where("id IN ?", ids).
+		Update("next_attempt_at", at).Error
+}
+
+// SaveWebhookDelivery persists all the fields of the given delivery.
+func SaveWebhookDelivery(db *gorm.DB, delivery models.WebhookDelivery) (models.WebhookDelivery, error) {
+	if err := db.Save(&delivery).Error; err != nil {
+		return models.WebhookDelivery{}, err
+	}
+	return delivery, nil
+}
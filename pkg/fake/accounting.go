@@ -0,0 +1,27 @@
+package fake
+
+import (
+	"context"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/api"
+)
+
+// ListAccounts mocks a call to the Credits API.
+func (c *Fake) ListAccounts(ctx context.Context, req api.ListAccountsRequest) (api.ListAccountsResponse, error) {
+	args := c.Called(ctx, req)
+	res := args.Get(0).(api.ListAccountsResponse)
+	return res, args.Error(1)
+}
+
+// ListEntries mocks a call to the Credits API.
+func (c *Fake) ListEntries(ctx context.Context, req api.ListEntriesRequest) (api.ListEntriesResponse, error) {
+	args := c.Called(ctx, req)
+	res := args.Get(0).(api.ListEntriesResponse)
+	return res, args.Error(1)
+}
+
+// CheckLedger mocks a call to the Credits API.
+func (c *Fake) CheckLedger(ctx context.Context, req api.CheckLedgerRequest) (api.CheckLedgerResponse, error) {
+	args := c.Called(ctx, req)
+	res := args.Get(0).(api.CheckLedgerResponse)
+	return res, args.Error(1)
+}
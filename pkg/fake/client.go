@@ -42,6 +42,27 @@ func (c *Fake) GetBalance(ctx context.Context, req api.GetBalanceRequest) (api.G
 	return res, args.Error(1)
 }
 
+// GetBalances mocks a call to the Credits API.
+func (c *Fake) GetBalances(ctx context.Context, req api.GetBalancesRequest) (api.GetBalancesResponse, error) {
+	args := c.Called(ctx, req)
+	res := args.Get(0).(api.GetBalancesResponse)
+	return res, args.Error(1)
+}
+
+// GetBalanceAt mocks a call to the Credits API.
+func (c *Fake) GetBalanceAt(ctx context.Context, req api.GetBalanceAtRequest) (api.GetBalanceAtResponse, error) {
+	args := c.Called(ctx, req)
+	res := args.Get(0).(api.GetBalanceAtResponse)
+	return res, args.Error(1)
+}
+
+// GetBalanceHistory mocks a call to the Credits API.
+func (c *Fake) GetBalanceHistory(ctx context.Context, req api.GetBalanceHistoryRequest) (api.GetBalanceHistoryResponse, error) {
+	args := c.Called(ctx, req)
+	res := args.Get(0).(api.GetBalanceHistoryResponse)
+	return res, args.Error(1)
+}
+
 // ConvertCurrency mocks a call to the Credits API.
 func (c *Fake) ConvertCurrency(ctx context.Context, req api.ConvertCurrencyRequest) (api.ConvertCurrencyResponse, error) {
 	args := c.Called(ctx, req)
@@ -49,7 +70,35 @@ func (c *Fake) ConvertCurrency(ctx context.Context, req api.ConvertCurrencyReque
 	return res, args.Error(1)
 }
 
+// Charge mocks a call to the Credits API.
+func (c *Fake) Charge(ctx context.Context, req api.ChargeRequest) (api.ChargeResponse, error) {
+	args := c.Called(ctx, req)
+	res := args.Get(0).(api.ChargeResponse)
+	return res, args.Error(1)
+}
+
+// ExecuteBatch mocks a call to the Credits API.
+func (c *Fake) ExecuteBatch(ctx context.Context, req api.ExecuteBatchRequest) (api.ExecuteBatchResponse, error) {
+	args := c.Called(ctx, req)
+	res := args.Get(0).(api.ExecuteBatchResponse)
+	return res, args.Error(1)
+}
+
+// EstimateCost mocks a call to the Credits API.
+func (c *Fake) EstimateCost(ctx context.Context, req api.EstimateCostRequest) (api.EstimateCostResponse, error) {
+	args := c.Called(ctx, req)
+	res := args.Get(0).(api.EstimateCostResponse)
+	return res, args.Error(1)
+}
+
 // NewClient initializes a fake client.Client implementation.
 func NewClient() *Fake {
 	return &Fake{}
 }
+
+// ReverseTransaction mocks a call to the Credits API.
+func (c *Fake) ReverseTransaction(ctx context.Context, req api.ReverseTransactionRequest) (api.ReverseTransactionResponse, error) {
+	args := c.Called(ctx, req)
+	res := args.Get(0).(api.ReverseTransactionResponse)
+	return res, args.Error(1)
+}
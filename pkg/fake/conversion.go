@@ -0,0 +1,20 @@
+package fake
+
+import (
+	"context"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/api"
+)
+
+// SetConversionPolicy mocks a call to the Credits API.
+func (c *Fake) SetConversionPolicy(ctx context.Context, req api.SetConversionPolicyRequest) (api.SetConversionPolicyResponse, error) {
+	args := c.Called(ctx, req)
+	res := args.Get(0).(api.SetConversionPolicyResponse)
+	return res, args.Error(1)
+}
+
+// GetConversionPolicy mocks a call to the Credits API.
+func (c *Fake) GetConversionPolicy(ctx context.Context, req api.GetConversionPolicyRequest) (api.GetConversionPolicyResponse, error) {
+	args := c.Called(ctx, req)
+	res := args.Get(0).(api.GetConversionPolicyResponse)
+	return res, args.Error(1)
+}
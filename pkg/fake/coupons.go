@@ -0,0 +1,34 @@
+package fake
+
+import (
+	"context"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/api"
+)
+
+// CreateCoupon mocks a call to the Credits API.
+func (c *Fake) CreateCoupon(ctx context.Context, req api.CreateCouponRequest) (api.CreateCouponResponse, error) {
+	args := c.Called(ctx, req)
+	res := args.Get(0).(api.CreateCouponResponse)
+	return res, args.Error(1)
+}
+
+// ListCoupons mocks a call to the Credits API.
+func (c *Fake) ListCoupons(ctx context.Context, req api.ListCouponsRequest) (api.ListCouponsResponse, error) {
+	args := c.Called(ctx, req)
+	res := args.Get(0).(api.ListCouponsResponse)
+	return res, args.Error(1)
+}
+
+// RedeemCoupon mocks a call to the Credits API.
+func (c *Fake) RedeemCoupon(ctx context.Context, req api.RedeemCouponRequest) (api.RedeemCouponResponse, error) {
+	args := c.Called(ctx, req)
+	res := args.Get(0).(api.RedeemCouponResponse)
+	return res, args.Error(1)
+}
+
+// GetCouponStats mocks a call to the Credits API.
+func (c *Fake) GetCouponStats(ctx context.Context, req api.GetCouponStatsRequest) (api.GetCouponStatsResponse, error) {
+	args := c.Called(ctx, req)
+	res := args.Get(0).(api.GetCouponStatsResponse)
+	return res, args.Error(1)
+}
@@ -0,0 +1,27 @@
+package fake
+
+import (
+	"context"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/api"
+)
+
+// ListCustomers mocks a call to the Credits API.
+func (c *Fake) ListCustomers(ctx context.Context, req api.ListCustomersRequest) (api.ListCustomersResponse, error) {
+	args := c.Called(ctx, req)
+	res := args.Get(0).(api.ListCustomersResponse)
+	return res, args.Error(1)
+}
+
+// SetCustomerStatus mocks a call to the Credits API.
+func (c *Fake) SetCustomerStatus(ctx context.Context, req api.SetCustomerStatusRequest) (api.SetCustomerStatusResponse, error) {
+	args := c.Called(ctx, req)
+	res := args.Get(0).(api.SetCustomerStatusResponse)
+	return res, args.Error(1)
+}
+
+// ListCustomerStatusChanges mocks a call to the Credits API.
+func (c *Fake) ListCustomerStatusChanges(ctx context.Context, req api.ListCustomerStatusChangesRequest) (api.ListCustomerStatusChangesResponse, error) {
+	args := c.Called(ctx, req)
+	res := args.Get(0).(api.ListCustomerStatusChangesResponse)
+	return res, args.Error(1)
+}
@@ -0,0 +1,13 @@
+package fake
+
+import (
+	"context"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/api"
+)
+
+// VerifyLedger mocks a call to the Credits API.
+func (c *Fake) VerifyLedger(ctx context.Context, req api.VerifyLedgerRequest) (api.VerifyLedgerResponse, error) {
+	args := c.Called(ctx, req)
+	res := args.Get(0).(api.VerifyLedgerResponse)
+	return res, args.Error(1)
+}
@@ -0,0 +1,27 @@
+package fake
+
+import (
+	"context"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/api"
+)
+
+// SetOverdraftLimit mocks a call to the Credits API.
+func (c *Fake) SetOverdraftLimit(ctx context.Context, req api.SetOverdraftLimitRequest) (api.SetOverdraftLimitResponse, error) {
+	args := c.Called(ctx, req)
+	res := args.Get(0).(api.SetOverdraftLimitResponse)
+	return res, args.Error(1)
+}
+
+// GetOverdraftLimit mocks a call to the Credits API.
+func (c *Fake) GetOverdraftLimit(ctx context.Context, req api.GetOverdraftLimitRequest) (api.GetOverdraftLimitResponse, error) {
+	args := c.Called(ctx, req)
+	res := args.Get(0).(api.GetOverdraftLimitResponse)
+	return res, args.Error(1)
+}
+
+// DeleteOverdraftLimit mocks a call to the Credits API.
+func (c *Fake) DeleteOverdraftLimit(ctx context.Context, req api.DeleteOverdraftLimitRequest) (api.DeleteOverdraftLimitResponse, error) {
+	args := c.Called(ctx, req)
+	res := args.Get(0).(api.DeleteOverdraftLimitResponse)
+	return res, args.Error(1)
+}
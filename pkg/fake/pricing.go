@@ -0,0 +1,48 @@
+package fake
+
+import (
+	"context"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/api"
+)
+
+// CreateSKU mocks a call to the Credits API.
+func (c *Fake) CreateSKU(ctx context.Context, req api.CreateSKURequest) (api.CreateSKUResponse, error) {
+	args := c.Called(ctx, req)
+	res := args.Get(0).(api.CreateSKUResponse)
+	return res, args.Error(1)
+}
+
+// GetSKU mocks a call to the Credits API.
+func (c *Fake) GetSKU(ctx context.Context, req api.GetSKURequest) (api.GetSKUResponse, error) {
+	args := c.Called(ctx, req)
+	res := args.Get(0).(api.GetSKUResponse)
+	return res, args.Error(1)
+}
+
+// ListSKUs mocks a call to the Credits API.
+func (c *Fake) ListSKUs(ctx context.Context, req api.ListSKUsRequest) (api.ListSKUsResponse, error) {
+	args := c.Called(ctx, req)
+	res := args.Get(0).(api.ListSKUsResponse)
+	return res, args.Error(1)
+}
+
+// UpdateSKU mocks a call to the Credits API.
+func (c *Fake) UpdateSKU(ctx context.Context, req api.UpdateSKURequest) (api.UpdateSKUResponse, error) {
+	args := c.Called(ctx, req)
+	res := args.Get(0).(api.UpdateSKUResponse)
+	return res, args.Error(1)
+}
+
+// DeleteSKU mocks a call to the Credits API.
+func (c *Fake) DeleteSKU(ctx context.Context, req api.DeleteSKURequest) (api.DeleteSKUResponse, error) {
+	args := c.Called(ctx, req)
+	res := args.Get(0).(api.DeleteSKUResponse)
+	return res, args.Error(1)
+}
+
+// SetSKUPrice mocks a call to the Credits API.
+func (c *Fake) SetSKUPrice(ctx context.Context, req api.SetSKUPriceRequest) (api.SetSKUPriceResponse, error) {
+	args := c.Called(ctx, req)
+	res := args.Get(0).(api.SetSKUPriceResponse)
+	return res, args.Error(1)
+}
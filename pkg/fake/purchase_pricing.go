@@ -0,0 +1,41 @@
+package fake
+
+import (
+	"context"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/api"
+)
+
+// SetVolumeTiers mocks a call to the Credits API.
+func (c *Fake) SetVolumeTiers(ctx context.Context, req api.SetVolumeTiersRequest) (api.SetVolumeTiersResponse, error) {
+	args := c.Called(ctx, req)
+	res := args.Get(0).(api.SetVolumeTiersResponse)
+	return res, args.Error(1)
+}
+
+// ListVolumeTiers mocks a call to the Credits API.
+func (c *Fake) ListVolumeTiers(ctx context.Context, req api.ListVolumeTiersRequest) (api.ListVolumeTiersResponse, error) {
+	args := c.Called(ctx, req)
+	res := args.Get(0).(api.ListVolumeTiersResponse)
+	return res, args.Error(1)
+}
+
+// CreatePackage mocks a call to the Credits API.
+func (c *Fake) CreatePackage(ctx context.Context, req api.CreatePackageRequest) (api.CreatePackageResponse, error) {
+	args := c.Called(ctx, req)
+	res := args.Get(0).(api.CreatePackageResponse)
+	return res, args.Error(1)
+}
+
+// DeletePackage mocks a call to the Credits API.
+func (c *Fake) DeletePackage(ctx context.Context, req api.DeletePackageRequest) (api.DeletePackageResponse, error) {
+	args := c.Called(ctx, req)
+	res := args.Get(0).(api.DeletePackageResponse)
+	return res, args.Error(1)
+}
+
+// ListPackages mocks a call to the Credits API.
+func (c *Fake) ListPackages(ctx context.Context, req api.ListPackagesRequest) (api.ListPackagesResponse, error) {
+	args := c.Called(ctx, req)
+	res := args.Get(0).(api.ListPackagesResponse)
+	return res, args.Error(1)
+}
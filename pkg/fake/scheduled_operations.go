@@ -0,0 +1,27 @@
+package fake
+
+import (
+	"context"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/api"
+)
+
+// ScheduleOperation mocks a call to the Credits API.
+func (c *Fake) ScheduleOperation(ctx context.Context, req api.ScheduleOperationRequest) (api.ScheduleOperationResponse, error) {
+	args := c.Called(ctx, req)
+	res := args.Get(0).(api.ScheduleOperationResponse)
+	return res, args.Error(1)
+}
+
+// CancelScheduledOperation mocks a call to the Credits API.
+func (c *Fake) CancelScheduledOperation(ctx context.Context, req api.CancelScheduledOperationRequest) (api.CancelScheduledOperationResponse, error) {
+	args := c.Called(ctx, req)
+	res := args.Get(0).(api.CancelScheduledOperationResponse)
+	return res, args.Error(1)
+}
+
+// ListScheduledOperations mocks a call to the Credits API.
+func (c *Fake) ListScheduledOperations(ctx context.Context, req api.ListScheduledOperationsRequest) (api.ListScheduledOperationsResponse, error) {
+	args := c.Called(ctx, req)
+	res := args.Get(0).(api.ListScheduledOperationsResponse)
+	return res, args.Error(1)
+}
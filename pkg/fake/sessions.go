@@ -0,0 +1,34 @@
+package fake
+
+import (
+	"context"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/api"
+)
+
+// OpenSession mocks a call to the Credits API.
+func (c *Fake) OpenSession(ctx context.Context, req api.OpenSessionRequest) (api.OpenSessionResponse, error) {
+	args := c.Called(ctx, req)
+	res := args.Get(0).(api.OpenSessionResponse)
+	return res, args.Error(1)
+}
+
+// SendHeartbeat mocks a call to the Credits API.
+func (c *Fake) SendHeartbeat(ctx context.Context, req api.SendHeartbeatRequest) (api.SendHeartbeatResponse, error) {
+	args := c.Called(ctx, req)
+	res := args.Get(0).(api.SendHeartbeatResponse)
+	return res, args.Error(1)
+}
+
+// CloseSession mocks a call to the Credits API.
+func (c *Fake) CloseSession(ctx context.Context, req api.CloseSessionRequest) (api.CloseSessionResponse, error) {
+	args := c.Called(ctx, req)
+	res := args.Get(0).(api.CloseSessionResponse)
+	return res, args.Error(1)
+}
+
+// GetSession mocks a call to the Credits API.
+func (c *Fake) GetSession(ctx context.Context, req api.GetSessionRequest) (api.GetSessionResponse, error) {
+	args := c.Called(ctx, req)
+	res := args.Get(0).(api.GetSessionResponse)
+	return res, args.Error(1)
+}
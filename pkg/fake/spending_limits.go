@@ -0,0 +1,34 @@
+package fake
+
+import (
+	"context"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/api"
+)
+
+// SetSpendingLimits mocks a call to the Credits API.
+func (c *Fake) SetSpendingLimits(ctx context.Context, req api.SetSpendingLimitsRequest) (api.SetSpendingLimitsResponse, error) {
+	args := c.Called(ctx, req)
+	res := args.Get(0).(api.SetSpendingLimitsResponse)
+	return res, args.Error(1)
+}
+
+// GetSpendingLimits mocks a call to the Credits API.
+func (c *Fake) GetSpendingLimits(ctx context.Context, req api.GetSpendingLimitsRequest) (api.GetSpendingLimitsResponse, error) {
+	args := c.Called(ctx, req)
+	res := args.Get(0).(api.GetSpendingLimitsResponse)
+	return res, args.Error(1)
+}
+
+// DeleteSpendingLimits mocks a call to the Credits API.
+func (c *Fake) DeleteSpendingLimits(ctx context.Context, req api.DeleteSpendingLimitsRequest) (api.DeleteSpendingLimitsResponse, error) {
+	args := c.Called(ctx, req)
+	res := args.Get(0).(api.DeleteSpendingLimitsResponse)
+	return res, args.Error(1)
+}
+
+// GetSpendingUsage mocks a call to the Credits API.
+func (c *Fake) GetSpendingUsage(ctx context.Context, req api.GetSpendingUsageRequest) (api.GetSpendingUsageResponse, error) {
+	args := c.Called(ctx, req)
+	res := args.Get(0).(api.GetSpendingUsageResponse)
+	return res, args.Error(1)
+}
@@ -0,0 +1,34 @@
+package fake
+
+import (
+	"context"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/api"
+)
+
+// CreateSubscription mocks a call to the Credits API.
+func (c *Fake) CreateSubscription(ctx context.Context, req api.CreateSubscriptionRequest) (api.CreateSubscriptionResponse, error) {
+	args := c.Called(ctx, req)
+	res := args.Get(0).(api.CreateSubscriptionResponse)
+	return res, args.Error(1)
+}
+
+// CancelSubscription mocks a call to the Credits API.
+func (c *Fake) CancelSubscription(ctx context.Context, req api.CancelSubscriptionRequest) (api.CancelSubscriptionResponse, error) {
+	args := c.Called(ctx, req)
+	res := args.Get(0).(api.CancelSubscriptionResponse)
+	return res, args.Error(1)
+}
+
+// ListSubscriptions mocks a call to the Credits API.
+func (c *Fake) ListSubscriptions(ctx context.Context, req api.ListSubscriptionsRequest) (api.ListSubscriptionsResponse, error) {
+	args := c.Called(ctx, req)
+	res := args.Get(0).(api.ListSubscriptionsResponse)
+	return res, args.Error(1)
+}
+
+// ListSubscriptionGrants mocks a call to the Credits API.
+func (c *Fake) ListSubscriptionGrants(ctx context.Context, req api.ListSubscriptionGrantsRequest) (api.ListSubscriptionGrantsResponse, error) {
+	args := c.Called(ctx, req)
+	res := args.Get(0).(api.ListSubscriptionGrantsResponse)
+	return res, args.Error(1)
+}
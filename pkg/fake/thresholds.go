@@ -0,0 +1,27 @@
+package fake
+
+import (
+	"context"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/api"
+)
+
+// SetLowBalanceThreshold mocks a call to the Credits API.
+func (c *Fake) SetLowBalanceThreshold(ctx context.Context, req api.SetLowBalanceThresholdRequest) (api.SetLowBalanceThresholdResponse, error) {
+	args := c.Called(ctx, req)
+	res := args.Get(0).(api.SetLowBalanceThresholdResponse)
+	return res, args.Error(1)
+}
+
+// GetLowBalanceThreshold mocks a call to the Credits API.
+func (c *Fake) GetLowBalanceThreshold(ctx context.Context, req api.GetLowBalanceThresholdRequest) (api.GetLowBalanceThresholdResponse, error) {
+	args := c.Called(ctx, req)
+	res := args.Get(0).(api.GetLowBalanceThresholdResponse)
+	return res, args.Error(1)
+}
+
+// DeleteLowBalanceThreshold mocks a call to the Credits API.
+func (c *Fake) DeleteLowBalanceThreshold(ctx context.Context, req api.DeleteLowBalanceThresholdRequest) (api.DeleteLowBalanceThresholdResponse, error) {
+	args := c.Called(ctx, req)
+	res := args.Get(0).(api.DeleteLowBalanceThresholdResponse)
+	return res, args.Error(1)
+}
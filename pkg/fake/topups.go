@@ -0,0 +1,34 @@
+package fake
+
+import (
+	"context"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/api"
+)
+
+// SetAutoTopUp mocks a call to the Credits API.
+func (c *Fake) SetAutoTopUp(ctx context.Context, req api.SetAutoTopUpRequest) (api.SetAutoTopUpResponse, error) {
+	args := c.Called(ctx, req)
+	res := args.Get(0).(api.SetAutoTopUpResponse)
+	return res, args.Error(1)
+}
+
+// GetAutoTopUp mocks a call to the Credits API.
+func (c *Fake) GetAutoTopUp(ctx context.Context, req api.GetAutoTopUpRequest) (api.GetAutoTopUpResponse, error) {
+	args := c.Called(ctx, req)
+	res := args.Get(0).(api.GetAutoTopUpResponse)
+	return res, args.Error(1)
+}
+
+// DisableAutoTopUp mocks a call to the Credits API.
+func (c *Fake) DisableAutoTopUp(ctx context.Context, req api.DisableAutoTopUpRequest) (api.DisableAutoTopUpResponse, error) {
+	args := c.Called(ctx, req)
+	res := args.Get(0).(api.DisableAutoTopUpResponse)
+	return res, args.Error(1)
+}
+
+// ListTopUps mocks a call to the Credits API.
+func (c *Fake) ListTopUps(ctx context.Context, req api.ListTopUpsRequest) (api.ListTopUpsResponse, error) {
+	args := c.Called(ctx, req)
+	res := args.Get(0).(api.ListTopUpsResponse)
+	return res, args.Error(1)
+}
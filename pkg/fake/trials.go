@@ -0,0 +1,41 @@
+package fake
+
+import (
+	"context"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/api"
+)
+
+// SetTrialPolicy mocks a call to the Credits API.
+func (c *Fake) SetTrialPolicy(ctx context.Context, req api.SetTrialPolicyRequest) (api.SetTrialPolicyResponse, error) {
+	args := c.Called(ctx, req)
+	res := args.Get(0).(api.SetTrialPolicyResponse)
+	return res, args.Error(1)
+}
+
+// GetTrialPolicy mocks a call to the Credits API.
+func (c *Fake) GetTrialPolicy(ctx context.Context, req api.GetTrialPolicyRequest) (api.GetTrialPolicyResponse, error) {
+	args := c.Called(ctx, req)
+	res := args.Get(0).(api.GetTrialPolicyResponse)
+	return res, args.Error(1)
+}
+
+// DeleteTrialPolicy mocks a call to the Credits API.
+func (c *Fake) DeleteTrialPolicy(ctx context.Context, req api.DeleteTrialPolicyRequest) (api.DeleteTrialPolicyResponse, error) {
+	args := c.Called(ctx, req)
+	res := args.Get(0).(api.DeleteTrialPolicyResponse)
+	return res, args.Error(1)
+}
+
+// GetTrialGrant mocks a call to the Credits API.
+func (c *Fake) GetTrialGrant(ctx context.Context, req api.GetTrialGrantRequest) (api.GetTrialGrantResponse, error) {
+	args := c.Called(ctx, req)
+	res := args.Get(0).(api.GetTrialGrantResponse)
+	return res, args.Error(1)
+}
+
+// RecordHandleChange mocks a call to the Credits API.
+func (c *Fake) RecordHandleChange(ctx context.Context, req api.RecordHandleChangeRequest) (api.RecordHandleChangeResponse, error) {
+	args := c.Called(ctx, req)
+	res := args.Get(0).(api.RecordHandleChangeResponse)
+	return res, args.Error(1)
+}
@@ -0,0 +1,41 @@
+package fake
+
+import (
+	"context"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/api"
+)
+
+// SetWalletMember mocks a call to the Credits API.
+func (c *Fake) SetWalletMember(ctx context.Context, req api.SetWalletMemberRequest) (api.SetWalletMemberResponse, error) {
+	args := c.Called(ctx, req)
+	res := args.Get(0).(api.SetWalletMemberResponse)
+	return res, args.Error(1)
+}
+
+// RemoveWalletMember mocks a call to the Credits API.
+func (c *Fake) RemoveWalletMember(ctx context.Context, req api.RemoveWalletMemberRequest) (api.RemoveWalletMemberResponse, error) {
+	args := c.Called(ctx, req)
+	res := args.Get(0).(api.RemoveWalletMemberResponse)
+	return res, args.Error(1)
+}
+
+// ListWalletMembers mocks a call to the Credits API.
+func (c *Fake) ListWalletMembers(ctx context.Context, req api.ListWalletMembersRequest) (api.ListWalletMembersResponse, error) {
+	args := c.Called(ctx, req)
+	res := args.Get(0).(api.ListWalletMembersResponse)
+	return res, args.Error(1)
+}
+
+// SpendFromWallet mocks a call to the Credits API.
+func (c *Fake) SpendFromWallet(ctx context.Context, req api.SpendFromWalletRequest) (api.SpendFromWalletResponse, error) {
+	args := c.Called(ctx, req)
+	res := args.Get(0).(api.SpendFromWalletResponse)
+	return res, args.Error(1)
+}
+
+// ListWalletSpending mocks a call to the Credits API.
+func (c *Fake) ListWalletSpending(ctx context.Context, req api.ListWalletSpendingRequest) (api.ListWalletSpendingResponse, error) {
+	args := c.Called(ctx, req)
+	res := args.Get(0).(api.ListWalletSpendingResponse)
+	return res, args.Error(1)
+}
@@ -0,0 +1,41 @@
+package fake
+
+import (
+	"context"
+	"gitlab.com/ignitionrobotics/billing/credits/pkg/api"
+)
+
+// CreateWebhook mocks a call to the Credits API.
+func (c *Fake) CreateWebhook(ctx context.Context, req api.CreateWebhookRequest) (api.CreateWebhookResponse, error) {
+	args := c.Called(ctx, req)
+	res := args.Get(0).(api.CreateWebhookResponse)
+	return res, args.Error(1)
+}
+
+// ListWebhooks mocks a call to the Credits API.
+func (c *Fake) ListWebhooks(ctx context.Context, req api.ListWebhooksRequest) (api.ListWebhooksResponse, error) {
+	args := c.Called(ctx, req)
+	res := args.Get(0).(api.ListWebhooksResponse)
+	return res, args.Error(1)
+}
+
+// DeleteWebhook mocks a call to the Credits API.
+func (c *Fake) DeleteWebhook(ctx context.Context, req api.DeleteWebhookRequest) (api.DeleteWebhookResponse, error) {
+	args := c.Called(ctx, req)
+	res := args.Get(0).(api.DeleteWebhookResponse)
+	return res, args.Error(1)
+}
+
+// ListWebhookDeliveries mocks a call to the Credits API.
+func (c *Fake) ListWebhookDeliveries(ctx context.Context, req api.ListWebhookDeliveriesRequest) (api.ListWebhookDeliveriesResponse, error) {
+	args := c.Called(ctx, req)
+	res := args.Get(0).(api.ListWebhookDeliveriesResponse)
+	return res, args.Error(1)
+}
+
+// ReplayWebhookDelivery mocks a call to the Credits API.
+func (c *Fake) ReplayWebhookDelivery(ctx context.Context, req api.ReplayWebhookDeliveryRequest) (api.ReplayWebhookDeliveryResponse, error) {
+	args := c.Called(ctx, req)
+	res := args.Get(0).(api.ReplayWebhookDeliveryResponse)
+	return res, args.Error(1)
+}